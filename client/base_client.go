@@ -36,6 +36,8 @@ type baseClient struct {
 		sync.RWMutex
 		clientConns map[string]*grpc.ClientConn
 		leader      string
+		// followers are the followers passed the last health check.
+		followers []string
 	}
 
 	checkLeaderCh chan struct{}
@@ -47,6 +49,11 @@ type baseClient struct {
 	security SecurityOption
 
 	gRPCDialOptions []grpc.DialOption
+
+	enableTSOFollowerProxy  bool
+	maxTSOBatchWaitInterval time.Duration
+	tsoDispatcherCount      int
+	enableFollowerHandle    bool
 }
 
 // SecurityOption records options about tls
@@ -66,6 +73,43 @@ func WithGRPCDialOptions(opts ...grpc.DialOption) ClientOption {
 	}
 }
 
+// WithTSOFollowerProxy makes the client get timestamps through a random
+// follower, which proxies the requests to the leader. It reduces the streams
+// the leader handles, and the members need to enable the follower proxy too.
+func WithTSOFollowerProxy() ClientOption {
+	return func(c *baseClient) {
+		c.enableTSOFollowerProxy = true
+	}
+}
+
+// WithMaxTSOBatchWaitInterval makes the client wait at most the interval for
+// more TSO requests to send them in a bigger batch. It trades the latency of
+// the requests for fewer requests to PD. The client doesn't wait by default.
+func WithMaxTSOBatchWaitInterval(interval time.Duration) ClientOption {
+	return func(c *baseClient) {
+		c.maxTSOBatchWaitInterval = interval
+	}
+}
+
+// WithTSODispatcherCount makes the client send the TSO requests over count
+// streams in parallel. There is only one stream by default.
+func WithTSODispatcherCount(count int) ClientOption {
+	return func(c *baseClient) {
+		c.tsoDispatcherCount = count
+	}
+}
+
+// WithFollowerHandle allows the region and store reads to be handled by the
+// healthy followers if the leader fails, e.g. during the leader election. The
+// followers may return stale data, and the region syncer must be enabled by
+// the use-region-storage of the PD cluster. The client checks the health of
+// the followers in the background.
+func WithFollowerHandle() ClientOption {
+	return func(c *baseClient) {
+		c.enableFollowerHandle = true
+	}
+}
+
 // newBaseClient returns a new baseClient.
 func newBaseClient(ctx context.Context, urls []string, security SecurityOption, opts ...ClientOption) (*baseClient, error) {
 	ctx1, cancel := context.WithCancel(ctx)
@@ -80,6 +124,9 @@ func newBaseClient(ctx context.Context, urls []string, security SecurityOption,
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.tsoDispatcherCount <= 0 {
+		c.tsoDispatcherCount = 1
+	}
 
 	if err := c.initRetry(c.initClusterID); err != nil {
 		c.cancel()
@@ -93,6 +140,10 @@ func newBaseClient(ctx context.Context, urls []string, security SecurityOption,
 
 	c.wg.Add(1)
 	go c.leaderLoop()
+	if c.enableFollowerHandle {
+		c.wg.Add(1)
+		go c.memberHealthCheckLoop()
+	}
 
 	return c, nil
 }
@@ -132,6 +183,55 @@ func (c *baseClient) leaderLoop() {
 	}
 }
 
+func (c *baseClient) memberHealthCheckLoop() {
+	defer c.wg.Done()
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	defer cancel()
+
+	ticker := time.NewTicker(memberHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		c.checkFollowerHealth(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// checkFollowerHealth checks the followers by getting the members from them,
+// and records the healthy ones.
+func (c *baseClient) checkFollowerHealth(ctx context.Context) {
+	leader := c.GetLeaderAddr()
+	var healthy []string
+	for _, u := range c.getMemberURLs() {
+		if u == leader {
+			continue
+		}
+		checkCtx, checkCancel := context.WithTimeout(ctx, memberHealthCheckTimeout)
+		members, err := c.getMembers(checkCtx, u)
+		checkCancel()
+		if err != nil || members.GetHeader().GetError() != nil {
+			log.Debug("[pd] follower is unhealthy", zap.String("address", u), zap.Error(err))
+			continue
+		}
+		healthy = append(healthy, u)
+	}
+
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
+	c.connMu.followers = healthy
+}
+
+// getHealthyFollowers returns the followers passed the last health check.
+func (c *baseClient) getHealthyFollowers() []string {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.connMu.followers
+}
+
 // ScheduleCheckLeader is used to check leader.
 func (c *baseClient) ScheduleCheckLeader() {
 	select {
@@ -224,9 +324,19 @@ func (c *baseClient) updateURLs(members []*pdpb.Member) {
 	}
 
 	log.Info("[pd] update member urls", zap.Strings("old-urls", c.urls), zap.Strings("new-urls", urls))
+	c.connMu.Lock()
+	defer c.connMu.Unlock()
 	c.urls = urls
 }
 
+// getMemberURLs returns the URLs of the members, it can be called while the
+// member URLs are being updated.
+func (c *baseClient) getMemberURLs() []string {
+	c.connMu.RLock()
+	defer c.connMu.RUnlock()
+	return c.urls
+}
+
 func (c *baseClient) switchLeader(addrs []string) error {
 	// FIXME: How to safely compare leader urls? For now, only allows one client url.
 	addr := addrs[0]
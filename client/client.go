@@ -14,7 +14,13 @@
 package pd
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,8 +29,10 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/metadata"
 )
 
 // Client is a PD (Placement Driver) client.
@@ -39,6 +47,10 @@ type Client interface {
 	GetTS(ctx context.Context) (int64, int64, error)
 	// GetTSAsync gets a timestamp from PD, without block the caller.
 	GetTSAsync(ctx context.Context) TSFuture
+	// GetLocalTS gets a timestamp from the local TSO of the data center. It
+	// is only comparable with the timestamps of the same data center, and the
+	// timestamps got by GetTS are greater than it.
+	GetLocalTS(ctx context.Context, dcLocation string) (int64, int64, error)
 	// GetRegion gets a region and its leader Peer from PD by key.
 	// The region may expire after split. Caller is responsible for caching and
 	// taking care of region change.
@@ -73,8 +85,15 @@ type Client interface {
 	// job. Use UpdateGCSafePoint to trigger the GC job if needed.
 	UpdateServiceGCSafePoint(ctx context.Context, serviceID string, ttl int64, safePoint uint64) (uint64, error)
 	// ScatterRegion scatters the specified region. Should use it for a batch of regions,
-	// and the distribution of these regions will be dispersed.
+	// and the distribution of these regions will be dispersed. The region is
+	// scattered in the default group.
 	ScatterRegion(ctx context.Context, regionID uint64) error
+	// ScatterRegions scatters the regions in the same group, so that their
+	// peers and leaders are spread evenly. It returns the reasons of the
+	// regions failed to be scattered by the region IDs. It is served by the
+	// HTTP API of the leader, since the gRPC request has no batch or group
+	// fields yet.
+	ScatterRegions(ctx context.Context, regionIDs []uint64, group string) (map[uint64]string, error)
 	// GetOperator gets the status of operator of the specified region.
 	GetOperator(ctx context.Context, regionID uint64) (*pdpb.GetOperatorResponse, error)
 	// ConfigClient gets the configuration client.
@@ -105,11 +124,29 @@ type tsoRequest struct {
 }
 
 const (
-	pdTimeout             = 3 * time.Second
-	dialTimeout           = 3 * time.Second
-	updateLeaderTimeout   = time.Second // Use a shorter timeout to recover faster from network isolation.
-	maxMergeTSORequests   = 10000
-	maxInitClusterRetries = 100
+	pdTimeout                 = 3 * time.Second
+	dialTimeout               = 3 * time.Second
+	updateLeaderTimeout       = time.Second // Use a shorter timeout to recover faster from network isolation.
+	memberHealthCheckInterval = 3 * time.Second
+	memberHealthCheckTimeout  = time.Second
+	maxMergeTSORequests       = 10000
+	maxInitClusterRetries     = 100
+	// dcLocationMetadataKey is the key of the gRPC metadata to request the
+	// local TSO of a data center.
+	dcLocationMetadataKey = "pd-dc-location"
+	// tsoProxyMetadataKey is the key of the gRPC metadata to ask a follower
+	// to proxy the TSO requests to the leader.
+	tsoProxyMetadataKey = "pd-tso-follower-proxy"
+	// followerHandleMetadataKey is the key of the gRPC metadata to allow a
+	// follower to handle the region and store reads.
+	followerHandleMetadataKey = "pd-allow-follower-handle"
+	// tsoSuffixBitsMetadataKey is the key of the gRPC header metadata of the
+	// TSO stream in which the server tells its tso-suffix-bits.
+	tsoSuffixBitsMetadataKey = "pd-tso-suffix-bits"
+	// maxTSOSuffixBits is the max tso-suffix-bits of the server.
+	maxTSOSuffixBits = 10
+	// scatterRegionsPath is the path of the HTTP API to scatter regions.
+	scatterRegionsPath = "/pd/api/v1/regions/scatter"
 )
 
 var (
@@ -125,10 +162,17 @@ type client struct {
 	*baseClient
 	tsoRequests chan *tsoRequest
 
-	lastPhysical int64
-	lastLogical  int64
-
-	tsDeadlineCh chan deadline
+	// localTSOMu protects the dispatchers of the local TSOs, which are
+	// created on the first requests of the data centers.
+	localTSOMu struct {
+		sync.Mutex
+		dispatchers map[string]*tsoDispatcher
+	}
+	// localTSOAddrs are the addresses of the leaders of the local TSOs, by
+	// the data centers.
+	localTSOAddrs sync.Map
+	// httpClient is used for the requests only served by the HTTP API.
+	httpClient *http.Client
 }
 
 // NewClient creates a PD client.
@@ -139,23 +183,51 @@ func NewClient(pdAddrs []string, security SecurityOption, opts ...ClientOption)
 // NewClientWithContext creates a PD client with context.
 func NewClientWithContext(ctx context.Context, pdAddrs []string, security SecurityOption, opts ...ClientOption) (Client, error) {
 	log.Info("[pd] create pd client with endpoints", zap.Strings("pd-address", pdAddrs))
+	tlsCfg, err := grpcutil.SecurityConfig{
+		CAPath:   security.CAPath,
+		CertPath: security.CertPath,
+		KeyPath:  security.KeyPath,
+	}.ToTLSConfig()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
 	base, err := newBaseClient(ctx, addrsToUrls(pdAddrs), security, opts...)
 	if err != nil {
 		return nil, err
 	}
 	c := &client{
-		baseClient:   base,
-		tsoRequests:  make(chan *tsoRequest, maxMergeTSORequests),
-		tsDeadlineCh: make(chan deadline, 1),
+		baseClient:  base,
+		tsoRequests: make(chan *tsoRequest, maxMergeTSORequests),
+		httpClient:  &http.Client{Transport: &http.Transport{TLSClientConfig: tlsCfg}},
+	}
+	c.localTSOMu.dispatchers = make(map[string]*tsoDispatcher)
+
+	// Each dispatcher keeps a stream and sends a batch at a time, so the
+	// batches are sent in parallel by the dispatchers.
+	for i := 0; i < c.tsoDispatcherCount; i++ {
+		d := &tsoDispatcher{
+			requests:     c.tsoRequests,
+			createStream: c.createTSOStream,
+			deadlineCh:   make(chan deadline, 1),
+		}
+		c.wg.Add(2)
+		go c.tsLoop(d)
+		go c.tsCancelLoop(d)
 	}
-
-	c.wg.Add(2)
-	go c.tsLoop()
-	go c.tsCancelLoop()
 
 	return c, nil
 }
 
+// tsoDispatcher sends the batched TSO requests over its own stream.
+type tsoDispatcher struct {
+	requests     chan *tsoRequest
+	createStream func(ctx context.Context) (pdpb.PD_TsoClient, error)
+	deadlineCh   chan deadline
+
+	lastPhysical int64
+	lastLogical  int64
+}
+
 func (c *client) ConfigClient() ConfigClient {
 	return &configClient{c.baseClient}
 }
@@ -166,7 +238,7 @@ type deadline struct {
 	cancel context.CancelFunc
 }
 
-func (c *client) tsCancelLoop() {
+func (c *client) tsCancelLoop(d *tsoDispatcher) {
 	defer c.wg.Done()
 
 	ctx, cancel := context.WithCancel(c.ctx)
@@ -174,12 +246,12 @@ func (c *client) tsCancelLoop() {
 
 	for {
 		select {
-		case d := <-c.tsDeadlineCh:
+		case dl := <-d.deadlineCh:
 			select {
-			case <-d.timer:
+			case <-dl.timer:
 				log.Error("tso request is canceled due to timeout")
-				d.cancel()
-			case <-d.done:
+				dl.cancel()
+			case <-dl.done:
 			case <-ctx.Done():
 				return
 			}
@@ -189,7 +261,7 @@ func (c *client) tsCancelLoop() {
 	}
 }
 
-func (c *client) tsLoop() {
+func (c *client) tsLoop(d *tsoDispatcher) {
 	defer c.wg.Done()
 
 	loopCtx, loopCancel := context.WithCancel(c.ctx)
@@ -206,7 +278,7 @@ func (c *client) tsLoop() {
 		if stream == nil {
 			var ctx context.Context
 			ctx, cancel = context.WithCancel(loopCtx)
-			stream, err = c.leaderClient().Tso(ctx)
+			stream, err = d.createStream(ctx)
 			if err != nil {
 				select {
 				case <-loopCtx.Done():
@@ -217,7 +289,7 @@ func (c *client) tsLoop() {
 				log.Error("[pd] create tso stream error", zap.Error(err))
 				c.ScheduleCheckLeader()
 				cancel()
-				c.revokeTSORequest(errors.WithStack(err))
+				c.revokeTSORequest(d.requests, errors.WithStack(err))
 				select {
 				case <-time.After(time.Second):
 				case <-loopCtx.Done():
@@ -228,11 +300,16 @@ func (c *client) tsLoop() {
 		}
 
 		select {
-		case first := <-c.tsoRequests:
+		case first := <-d.requests:
 			requests = append(requests, first)
-			pending := len(c.tsoRequests)
+			pending := len(d.requests)
 			for i := 0; i < pending; i++ {
-				requests = append(requests, <-c.tsoRequests)
+				requests = append(requests, <-d.requests)
+			}
+			if requests = c.waitTSORequests(loopCtx, d, requests); loopCtx.Err() != nil {
+				c.finishTSORequest(requests, 0, 0, errors.WithStack(errClosing))
+				cancel()
+				return
 			}
 			done := make(chan struct{})
 			dl := deadline{
@@ -241,13 +318,14 @@ func (c *client) tsLoop() {
 				cancel: cancel,
 			}
 			select {
-			case c.tsDeadlineCh <- dl:
+			case d.deadlineCh <- dl:
 			case <-loopCtx.Done():
+				c.finishTSORequest(requests, 0, 0, errors.WithStack(errClosing))
 				cancel()
 				return
 			}
 			opts = extractSpanReference(requests, opts[:0])
-			err = c.processTSORequests(stream, requests, opts)
+			err = c.processTSORequests(d, stream, requests, opts)
 			close(done)
 			requests = requests[:0]
 		case <-loopCtx.Done():
@@ -270,6 +348,105 @@ func (c *client) tsLoop() {
 	}
 }
 
+// waitTSORequests waits for more requests to make a bigger batch, until the
+// max batch wait interval passes or the batch is full.
+func (c *client) waitTSORequests(ctx context.Context, d *tsoDispatcher, requests []*tsoRequest) []*tsoRequest {
+	if c.maxTSOBatchWaitInterval <= 0 || len(requests) >= maxMergeTSORequests {
+		return requests
+	}
+	timer := time.NewTimer(c.maxTSOBatchWaitInterval)
+	defer timer.Stop()
+	for len(requests) < maxMergeTSORequests {
+		select {
+		case req := <-d.requests:
+			requests = append(requests, req)
+		case <-timer.C:
+			return requests
+		case <-ctx.Done():
+			return requests
+		}
+	}
+	return requests
+}
+
+// createTSOStream creates the stream to get timestamps from the leader, or
+// from a random follower if the follower proxy is enabled.
+func (c *client) createTSOStream(ctx context.Context) (pdpb.PD_TsoClient, error) {
+	if !c.enableTSOFollowerProxy {
+		return c.leaderClient().Tso(ctx)
+	}
+	leader := c.GetLeaderAddr()
+	var followers []string
+	for _, addr := range c.getMemberURLs() {
+		if addr != leader {
+			followers = append(followers, addr)
+		}
+	}
+	addr := leader
+	if len(followers) > 0 {
+		addr = followers[rand.Intn(len(followers))]
+	}
+	cc, err := c.getOrCreateGRPCConn(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, tsoProxyMetadataKey, "true")
+	return pdpb.NewPDClient(cc).Tso(ctx)
+}
+
+// createLocalTSOStream creates the stream to get timestamps from the local TSO
+// of the data center. The leader of the local TSO is unknown to the client, so
+// it tries the last leader first and then all the members, and keeps the
+// first stream that serves a timestamp.
+func (c *client) createLocalTSOStream(ctx context.Context, dcLocation string) (pdpb.PD_TsoClient, error) {
+	addrs := c.getMemberURLs()
+	if addr, ok := c.localTSOAddrs.Load(dcLocation); ok {
+		addrs = append([]string{addr.(string)}, addrs...)
+	}
+	ctx = metadata.AppendToOutgoingContext(ctx, dcLocationMetadataKey, dcLocation)
+	err := errors.Errorf("[pd] no member to get local tso of %s", dcLocation)
+	for _, addr := range addrs {
+		var stream pdpb.PD_TsoClient
+		if stream, err = c.probeLocalTSOStream(ctx, addr); err == nil {
+			c.localTSOAddrs.Store(dcLocation, addr)
+			return stream, nil
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, err
+}
+
+// probeLocalTSOStream creates a stream to the member and gets a timestamp, to
+// check whether the member is the leader of the local TSO.
+func (c *client) probeLocalTSOStream(ctx context.Context, addr string) (pdpb.PD_TsoClient, error) {
+	cc, err := c.getOrCreateGRPCConn(addr)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	timer := time.AfterFunc(pdTimeout, cancel)
+	stream, err := pdpb.NewPDClient(cc).Tso(ctx)
+	if err == nil {
+		err = stream.Send(&pdpb.TsoRequest{
+			Header: c.requestHeader(),
+			Count:  1,
+		})
+	}
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if !timer.Stop() {
+		return nil, errors.Errorf("[pd] get local tso from %s timeout", addr)
+	}
+	if err != nil {
+		cancel()
+		return nil, errors.WithStack(err)
+	}
+	return stream, nil
+}
+
 func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOption) []opentracing.StartSpanOption {
 	for _, req := range requests {
 		if span := opentracing.SpanFromContext(req.ctx); span != nil {
@@ -279,13 +456,15 @@ func extractSpanReference(requests []*tsoRequest, opts []opentracing.StartSpanOp
 	return opts
 }
 
-func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoRequest, opts []opentracing.StartSpanOption) error {
+func (c *client) processTSORequests(d *tsoDispatcher, stream pdpb.PD_TsoClient, requests []*tsoRequest, opts []opentracing.StartSpanOption) error {
 	if len(opts) > 0 {
 		span := opentracing.StartSpan("pdclient.processTSORequests", opts...)
 		defer span.Finish()
 	}
 	count := len(requests)
 	start := time.Now()
+	// The first request of the batch waits the longest.
+	tsoBatchWaitDuration.Observe(start.Sub(requests[0].start).Seconds())
 	req := &pdpb.TsoRequest{
 		Header: c.requestHeader(),
 		Count:  uint32(count),
@@ -304,6 +483,7 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 	}
 	requestDurationTSO.Observe(time.Since(start).Seconds())
 	tsoBatchSize.Observe(float64(count))
+	tsoBatchRequestDuration.WithLabelValues(batchSizeLabel(count)).Observe(time.Since(start).Seconds())
 
 	if resp.GetCount() != uint32(len(requests)) {
 		err = errors.WithStack(errTSOLength)
@@ -311,19 +491,47 @@ func (c *client) processTSORequests(stream pdpb.PD_TsoClient, requests []*tsoReq
 		return err
 	}
 
+	suffixBits, err := getTSOSuffixBits(stream)
+	if err != nil {
+		c.finishTSORequest(requests, 0, 0, err)
+		return err
+	}
 	physical, logical := resp.GetTimestamp().GetPhysical(), resp.GetTimestamp().GetLogical()
-	// Server returns the highest ts.
-	logical -= int64(resp.GetCount() - 1)
-	if tsLessEqual(physical, logical, c.lastPhysical, c.lastLogical) {
+	// Server returns the highest ts, and the logical part steps by
+	// 1<<suffixBits.
+	step := int64(1) << uint(suffixBits)
+	logical -= int64(resp.GetCount()-1) * step
+	// The timestamps of different dispatchers may arrive out of order, but
+	// they never fall back in a stream.
+	if tsLessEqual(physical, logical, d.lastPhysical, d.lastLogical) {
 		panic(errors.Errorf("timestamp fallback, newly acquired ts (%d,%d) is less or equal to last one (%d, %d)",
-			physical, logical, c.lastLogical, c.lastLogical))
+			physical, logical, d.lastLogical, d.lastLogical))
 	}
-	c.lastPhysical = physical
-	c.lastLogical = logical + int64(len(requests)) - 1
-	c.finishTSORequest(requests, physical, logical, nil)
+	d.lastPhysical = physical
+	d.lastLogical = logical + int64(len(requests)-1)*step
+	c.finishTSORequestWithStep(requests, physical, logical, step, nil)
 	return nil
 }
 
+// getTSOSuffixBits returns the tso-suffix-bits told by the server in the
+// header of the TSO stream, so the server is the only source of truth of the
+// step of the logical part. It is 0 if the server does not tell it.
+func getTSOSuffixBits(stream pdpb.PD_TsoClient) (int, error) {
+	md, err := stream.Header()
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	values := md.Get(tsoSuffixBitsMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+	bits, err := strconv.Atoi(values[0])
+	if err != nil || bits < 0 || bits > maxTSOSuffixBits {
+		return 0, errors.Errorf("invalid tso suffix bits %q", values[0])
+	}
+	return bits, nil
+}
+
 func tsLessEqual(physical, logical, thatPhysical, thatLogical int64) bool {
 	if physical == thatPhysical {
 		return logical <= thatLogical
@@ -332,28 +540,39 @@ func tsLessEqual(physical, logical, thatPhysical, thatLogical int64) bool {
 }
 
 func (c *client) finishTSORequest(requests []*tsoRequest, physical, firstLogical int64, err error) {
+	c.finishTSORequestWithStep(requests, physical, firstLogical, 1, err)
+}
+
+func (c *client) finishTSORequestWithStep(requests []*tsoRequest, physical, firstLogical, step int64, err error) {
 	for i := 0; i < len(requests); i++ {
 		if span := opentracing.SpanFromContext(requests[i].ctx); span != nil {
 			span.Finish()
 		}
-		requests[i].physical, requests[i].logical = physical, firstLogical+int64(i)
+		requests[i].physical, requests[i].logical = physical, firstLogical+int64(i)*step
 		requests[i].done <- err
 	}
 }
 
-func (c *client) revokeTSORequest(err error) {
-	n := len(c.tsoRequests)
+func (c *client) revokeTSORequest(requests chan *tsoRequest, err error) {
+	n := len(requests)
 	for i := 0; i < n; i++ {
-		req := <-c.tsoRequests
+		req := <-requests
 		req.done <- err
 	}
 }
 
 func (c *client) Close() {
+	// No local TSO dispatcher is started after the client is canceled.
+	c.localTSOMu.Lock()
 	c.cancel()
+	c.localTSOMu.Unlock()
 	c.wg.Wait()
 
-	c.revokeTSORequest(errors.WithStack(errClosing))
+	c.revokeTSORequest(c.tsoRequests, errors.WithStack(errClosing))
+	for _, d := range c.localTSOMu.dispatchers {
+		c.revokeTSORequest(d.requests, errors.WithStack(errClosing))
+	}
+	c.httpClient.CloseIdleConnections()
 
 	c.connMu.Lock()
 	defer c.connMu.Unlock()
@@ -372,6 +591,41 @@ func (c *client) leaderClient() pdpb.PDClient {
 	return pdpb.NewPDClient(c.connMu.clientConns[c.connMu.leader])
 }
 
+// readWithFailover sends the read request to the leader. If the leader fails
+// and the follower handle is enabled, the request is sent to the healthy
+// followers one by one until one of them succeeds. The leader attempt is
+// bounded by updateLeaderTimeout so that a hanging leader leaves time for the
+// followers, and all the requests share the deadline of the ctx.
+func (c *client) readWithFailover(ctx context.Context, read func(ctx context.Context, cli pdpb.PDClient) error) error {
+	if !c.enableFollowerHandle {
+		return read(ctx, c.leaderClient())
+	}
+	leaderCtx, cancel := context.WithTimeout(ctx, updateLeaderTimeout)
+	err := read(leaderCtx, c.leaderClient())
+	cancel()
+	if err == nil {
+		return nil
+	}
+	c.ScheduleCheckLeader()
+	followerCtx := metadata.AppendToOutgoingContext(ctx, followerHandleMetadataKey, "true")
+	for _, addr := range c.getHealthyFollowers() {
+		if ctx.Err() != nil {
+			break
+		}
+		cc, connErr := c.getOrCreateGRPCConn(addr)
+		if connErr != nil {
+			continue
+		}
+		followerErr := read(followerCtx, pdpb.NewPDClient(cc))
+		if followerErr == nil {
+			log.Debug("[pd] read is handled by follower", zap.String("follower", addr), zap.Error(err))
+			return nil
+		}
+		log.Debug("[pd] follower failed to handle read", zap.String("follower", addr), zap.Error(followerErr))
+	}
+	return err
+}
+
 var tsoReqPool = sync.Pool{
 	New: func() interface{} {
 		return &tsoRequest{
@@ -385,13 +639,16 @@ func (c *client) GetTSAsync(ctx context.Context) TSFuture {
 		span = opentracing.StartSpan("GetTSAsync", opentracing.ChildOf(span.Context()))
 		ctx = opentracing.ContextWithSpan(ctx, span)
 	}
+	return c.sendTSORequest(ctx, c.tsoRequests)
+}
+
+func (c *client) sendTSORequest(ctx context.Context, requests chan *tsoRequest) *tsoRequest {
 	req := tsoReqPool.Get().(*tsoRequest)
 	req.start = time.Now()
 	req.ctx = ctx
 	req.physical = 0
 	req.logical = 0
-	c.tsoRequests <- req
-
+	requests <- req
 	return req
 }
 
@@ -429,6 +686,53 @@ func (c *client) GetTS(ctx context.Context) (physical int64, logical int64, err
 	return resp.Wait()
 }
 
+func (c *client) GetLocalTS(ctx context.Context, dcLocation string) (physical int64, logical int64, err error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.GetLocalTS", opentracing.ChildOf(span.Context()))
+		ctx = opentracing.ContextWithSpan(ctx, span)
+	}
+	start := time.Now()
+	d, err := c.getLocalTSODispatcher(dcLocation)
+	if err != nil {
+		cmdFailDurationLocalTSO.Observe(time.Since(start).Seconds())
+		return 0, 0, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	defer cancel()
+	physical, logical, err = c.sendTSORequest(ctx, d.requests).Wait()
+	if err != nil {
+		cmdFailDurationLocalTSO.Observe(time.Since(start).Seconds())
+		return 0, 0, err
+	}
+	cmdDurationLocalTSO.Observe(time.Since(start).Seconds())
+	return physical, logical, nil
+}
+
+// getLocalTSODispatcher returns the dispatcher of the local TSO of the data
+// center, which batches the requests like the global TSO does.
+func (c *client) getLocalTSODispatcher(dcLocation string) (*tsoDispatcher, error) {
+	c.localTSOMu.Lock()
+	defer c.localTSOMu.Unlock()
+	if c.ctx.Err() != nil {
+		return nil, errors.WithStack(errClosing)
+	}
+	if d, ok := c.localTSOMu.dispatchers[dcLocation]; ok {
+		return d, nil
+	}
+	d := &tsoDispatcher{
+		requests: make(chan *tsoRequest, maxMergeTSORequests),
+		createStream: func(ctx context.Context) (pdpb.PD_TsoClient, error) {
+			return c.createLocalTSOStream(ctx, dcLocation)
+		},
+		deadlineCh: make(chan deadline, 1),
+	}
+	c.localTSOMu.dispatchers[dcLocation] = d
+	c.wg.Add(2)
+	go c.tsLoop(d)
+	go c.tsCancelLoop(d)
+	return d, nil
+}
+
 func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *metapb.Peer, error) {
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.GetRegion", opentracing.ChildOf(span.Context()))
@@ -438,9 +742,14 @@ func (c *client) GetRegion(ctx context.Context, key []byte) (*metapb.Region, *me
 	defer func() { cmdDurationGetRegion.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetRegion(ctx, &pdpb.GetRegionRequest{
+	req := &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
+	}
+	var resp *pdpb.GetRegionResponse
+	err := c.readWithFailover(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetRegion(ctx, req)
+		return err
 	})
 	cancel()
 
@@ -461,9 +770,14 @@ func (c *client) GetPrevRegion(ctx context.Context, key []byte) (*metapb.Region,
 	defer func() { cmdDurationGetPrevRegion.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetPrevRegion(ctx, &pdpb.GetRegionRequest{
+	req := &pdpb.GetRegionRequest{
 		Header:    c.requestHeader(),
 		RegionKey: key,
+	}
+	var resp *pdpb.GetRegionResponse
+	err := c.readWithFailover(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetPrevRegion(ctx, req)
+		return err
 	})
 	cancel()
 
@@ -484,9 +798,14 @@ func (c *client) GetRegionByID(ctx context.Context, regionID uint64) (*metapb.Re
 	defer func() { cmdDurationGetRegionByID.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetRegionByID(ctx, &pdpb.GetRegionByIDRequest{
+	req := &pdpb.GetRegionByIDRequest{
 		Header:   c.requestHeader(),
 		RegionId: regionID,
+	}
+	var resp *pdpb.GetRegionResponse
+	err := c.readWithFailover(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetRegionByID(ctx, req)
+		return err
 	})
 	cancel()
 
@@ -513,11 +832,16 @@ func (c *client) ScanRegions(ctx context.Context, key, endKey []byte, limit int)
 		defer cancel()
 	}
 
-	resp, err := c.leaderClient().ScanRegions(scanCtx, &pdpb.ScanRegionsRequest{
+	req := &pdpb.ScanRegionsRequest{
 		Header:   c.requestHeader(),
 		StartKey: key,
 		EndKey:   endKey,
 		Limit:    int32(limit),
+	}
+	var resp *pdpb.ScanRegionsResponse
+	err := c.readWithFailover(scanCtx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.ScanRegions(ctx, req)
+		return err
 	})
 	if err != nil {
 		cmdFailedDurationScanRegions.Observe(time.Since(start).Seconds())
@@ -536,9 +860,14 @@ func (c *client) GetStore(ctx context.Context, storeID uint64) (*metapb.Store, e
 	defer func() { cmdDurationGetStore.Observe(time.Since(start).Seconds()) }()
 
 	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
-	resp, err := c.leaderClient().GetStore(ctx, &pdpb.GetStoreRequest{
+	req := &pdpb.GetStoreRequest{
 		Header:  c.requestHeader(),
 		StoreId: storeID,
+	}
+	var resp *pdpb.GetStoreResponse
+	err := c.readWithFailover(ctx, func(ctx context.Context, cli pdpb.PDClient) (err error) {
+		resp, err = cli.GetStore(ctx, req)
+		return err
 	})
 	cancel()
 
@@ -663,6 +992,56 @@ func (c *client) ScatterRegion(ctx context.Context, regionID uint64) error {
 	return nil
 }
 
+func (c *client) ScatterRegions(ctx context.Context, regionIDs []uint64, group string) (map[uint64]string, error) {
+	if span := opentracing.SpanFromContext(ctx); span != nil {
+		span = opentracing.StartSpan("pdclient.ScatterRegions", opentracing.ChildOf(span.Context()))
+		defer span.Finish()
+	}
+	start := time.Now()
+	defer func() { cmdDurationScatterRegions.Observe(time.Since(start).Seconds()) }()
+
+	body, err := json.Marshal(&scatterRegionsInput{RegionsID: regionIDs, Group: group})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, pdTimeout)
+	defer cancel()
+	req, err := http.NewRequest(http.MethodPost, c.GetLeaderAddr()+scatterRegionsPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		c.ScheduleCheckLeader()
+		return nil, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("scatter regions failed: %s", data)
+	}
+	var output scatterRegionsOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return output.Failures, nil
+}
+
+// scatterRegionsInput is the request of the HTTP API to scatter regions.
+type scatterRegionsInput struct {
+	RegionsID []uint64 `json:"regions_id"`
+	Group     string   `json:"group"`
+}
+
+// scatterRegionsOutput is the response of the HTTP API to scatter regions.
+type scatterRegionsOutput struct {
+	Failures map[uint64]string `json:"failures"`
+}
+
 func (c *client) GetOperator(ctx context.Context, regionID uint64) (*pdpb.GetOperatorResponse, error) {
 	if span := opentracing.SpanFromContext(ctx); span != nil {
 		span = opentracing.StartSpan("pdclient.GetOperator", opentracing.ChildOf(span.Context()))
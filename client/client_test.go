@@ -15,6 +15,11 @@ package pd
 
 import (
 	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +28,9 @@ import (
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"go.uber.org/goleak"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func Test(t *testing.T) {
@@ -103,3 +111,144 @@ func (s *testClientDialOptionSuite) TestGRPCDialOption(c *C) {
 	c.Assert(err, NotNil)
 	c.Assert(time.Since(start), Greater, 500*time.Millisecond)
 }
+
+var _ = Suite(&testScatterRegionsSuite{})
+
+type testScatterRegionsSuite struct{}
+
+func (s *testScatterRegionsSuite) TestScatterRegions(c *C) {
+	var input scatterRegionsInput
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.Assert(r.Method, Equals, http.MethodPost)
+		c.Assert(r.URL.Path, Equals, scatterRegionsPath)
+		c.Assert(json.NewDecoder(r.Body).Decode(&input), IsNil)
+		if input.Group == "" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"finished_percentage":50,"failures":{"2":"region 2 has no leader"}}`))
+	}))
+	defer server.Close()
+
+	cli := &client{
+		baseClient: &baseClient{checkLeaderCh: make(chan struct{}, 1)},
+		httpClient: &http.Client{},
+	}
+	defer cli.httpClient.CloseIdleConnections()
+	cli.connMu.leader = server.URL
+
+	failures, err := cli.ScatterRegions(context.Background(), []uint64{1, 2}, "test")
+	c.Assert(err, IsNil)
+	c.Assert(input.RegionsID, DeepEquals, []uint64{1, 2})
+	c.Assert(input.Group, Equals, "test")
+	c.Assert(failures, DeepEquals, map[uint64]string{2: "region 2 has no leader"})
+
+	_, err = cli.ScatterRegions(context.Background(), []uint64{1}, "")
+	c.Assert(err, NotNil)
+}
+
+var _ = Suite(&testLocalTSOSuite{})
+
+type testLocalTSOSuite struct{}
+
+// mockLocalTSOServer serves the local TSO of a data center if it is the
+// leader, and records the count of each request.
+type mockLocalTSOServer struct {
+	pdpb.PDServer
+	dcLocation string
+
+	mu      sync.Mutex
+	logical int64
+	counts  []uint32
+}
+
+func (s *mockLocalTSOServer) Tso(stream pdpb.PD_TsoServer) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if values := md.Get(dcLocationMetadataKey); len(values) == 0 || values[0] != s.dcLocation {
+		return status.Error(codes.Unavailable, "not the leader of the local tso")
+	}
+	for {
+		req, err := stream.Recv()
+		if err != nil {
+			return nil
+		}
+		s.mu.Lock()
+		s.logical += int64(req.GetCount())
+		s.counts = append(s.counts, req.GetCount())
+		resp := &pdpb.TsoResponse{
+			Count:     req.GetCount(),
+			Timestamp: &pdpb.Timestamp{Physical: 1, Logical: s.logical},
+		}
+		s.mu.Unlock()
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *testLocalTSOSuite) TestGetLocalTS(c *C) {
+	var urls []string
+	var leader *mockLocalTSOServer
+	for _, dcLocation := range []string{"dc-2", "dc-3", "dc-1"} {
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		c.Assert(err, IsNil)
+		server := &mockLocalTSOServer{dcLocation: dcLocation}
+		if dcLocation == "dc-1" {
+			leader = server
+		}
+		grpcServer := grpc.NewServer()
+		pdpb.RegisterPDServer(grpcServer, server)
+		go grpcServer.Serve(lis)
+		defer grpcServer.Stop()
+		urls = append(urls, "http://"+lis.Addr().String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cli := &client{
+		baseClient: &baseClient{
+			urls:                    urls,
+			checkLeaderCh:           make(chan struct{}, 1),
+			ctx:                     ctx,
+			cancel:                  cancel,
+			maxTSOBatchWaitInterval: 100 * time.Millisecond,
+		},
+		tsoRequests: make(chan *tsoRequest, maxMergeTSORequests),
+		httpClient:  &http.Client{},
+	}
+	cli.connMu.clientConns = make(map[string]*grpc.ClientConn)
+	cli.localTSOMu.dispatchers = make(map[string]*tsoDispatcher)
+	defer cli.Close()
+
+	// The concurrent requests are batched and served by the leader of the
+	// local TSO.
+	const count = 10
+	var wg sync.WaitGroup
+	logicals := make([]int64, count)
+	errs := make([]error, count)
+	for i := 0; i < count; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, logicals[i], errs[i] = cli.GetLocalTS(context.Background(), "dc-1")
+		}(i)
+	}
+	wg.Wait()
+	seen := make(map[int64]struct{})
+	for i := 0; i < count; i++ {
+		c.Assert(errs[i], IsNil)
+		seen[logicals[i]] = struct{}{}
+	}
+	c.Assert(seen, HasLen, count)
+	addr, ok := cli.localTSOAddrs.Load("dc-1")
+	c.Assert(ok, IsTrue)
+	c.Assert(addr, Equals, urls[2])
+	leader.mu.Lock()
+	// The first request probes the stream.
+	c.Assert(leader.logical, Equals, int64(count+1))
+	c.Assert(len(leader.counts), Less, count+1)
+	leader.mu.Unlock()
+
+	// The data center has no leader of the local TSO.
+	_, _, err := cli.GetLocalTS(context.Background(), "dc-4")
+	c.Assert(err, NotNil)
+}
@@ -13,7 +13,11 @@
 
 package pd
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
 
 var (
 	cmdDuration = prometheus.NewHistogramVec(
@@ -52,6 +56,24 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(1, 2, 13),
 		})
 
+	tsoBatchWaitDuration = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "tso_batch_wait_duration_seconds",
+			Help:      "Bucketed histogram of the time (s) the first request of a tso batch waits before sent.",
+			Buckets:   prometheus.ExponentialBuckets(0.00001, 2, 16),
+		})
+
+	tsoBatchRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "pd_client",
+			Subsystem: "request",
+			Name:      "tso_batch_duration_seconds",
+			Help:      "Bucketed histogram of processing time (s) of tso batches by the batch size.",
+			Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 13),
+		}, []string{"batch_size"})
+
 	configCmdDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Namespace: "config_client",
@@ -75,6 +97,7 @@ var (
 	// WithLabelValues is a heavy operation, define variable to avoid call it every time.
 	cmdDurationWait                     = cmdDuration.WithLabelValues("wait")
 	cmdDurationTSO                      = cmdDuration.WithLabelValues("tso")
+	cmdDurationLocalTSO                 = cmdDuration.WithLabelValues("local_tso")
 	cmdDurationTSOAsyncWait             = cmdDuration.WithLabelValues("tso_async_wait")
 	cmdDurationGetRegion                = cmdDuration.WithLabelValues("get_region")
 	cmdDurationGetPrevRegion            = cmdDuration.WithLabelValues("get_prev_region")
@@ -85,10 +108,12 @@ var (
 	cmdDurationUpdateGCSafePoint        = cmdDuration.WithLabelValues("update_gc_safe_point")
 	cmdDurationUpdateServiceGCSafePoint = cmdDuration.WithLabelValues("update_service_gc_safe_point")
 	cmdDurationScatterRegion            = cmdDuration.WithLabelValues("scatter_region")
+	cmdDurationScatterRegions           = cmdDuration.WithLabelValues("scatter_regions")
 	cmdDurationGetOperator              = cmdDuration.WithLabelValues("get_operator")
 
 	cmdFailDurationGetRegion                  = cmdFailedDuration.WithLabelValues("get_region")
 	cmdFailDurationTSO                        = cmdFailedDuration.WithLabelValues("tso")
+	cmdFailDurationLocalTSO                   = cmdFailedDuration.WithLabelValues("local_tso")
 	cmdFailDurationGetPrevRegion              = cmdFailedDuration.WithLabelValues("get_prev_region")
 	cmdFailedDurationGetRegionByID            = cmdFailedDuration.WithLabelValues("get_region_byid")
 	cmdFailedDurationScanRegions              = cmdFailedDuration.WithLabelValues("scan_regions")
@@ -112,11 +137,23 @@ var (
 	configCmdFailDurationDelete = configCmdFailedDuration.WithLabelValues("delete")
 )
 
+// batchSizeLabel returns the label of the tso batch size, the sizes are
+// rounded up to the powers of 4 to limit the number of labels.
+func batchSizeLabel(size int) string {
+	upper := 1
+	for upper < size {
+		upper *= 4
+	}
+	return strconv.Itoa(upper)
+}
+
 func init() {
 	prometheus.MustRegister(cmdDuration)
 	prometheus.MustRegister(cmdFailedDuration)
 	prometheus.MustRegister(requestDuration)
 	prometheus.MustRegister(tsoBatchSize)
+	prometheus.MustRegister(tsoBatchWaitDuration)
+	prometheus.MustRegister(tsoBatchRequestDuration)
 
 	// config
 	prometheus.MustRegister(configCmdDuration)
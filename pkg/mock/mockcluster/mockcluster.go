@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"go.uber.org/zap"
@@ -38,13 +39,16 @@ type Cluster struct {
 	*placement.RuleManager
 	*statistics.HotCache
 	*statistics.StoresStats
-	ID uint64
+	ID            uint64
+	regionLabeler *labeler.RegionLabeler
 }
 
 // NewCluster creates a new Cluster
 func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
-	ruleManager := placement.NewRuleManager(core.NewStorage(kv.NewMemoryKV()))
-	ruleManager.Initialize(opt.MaxReplicas, opt.GetLocationLabels())
+	storage := core.NewStorage(kv.NewMemoryKV())
+	ruleManager := placement.NewRuleManager(storage)
+	ruleManager.Initialize(opt.MaxReplicas, opt.GetLocationLabels(), opt.GetIsolationLevel())
+	regionLabeler, _ := labeler.NewRegionLabeler(storage)
 	return &Cluster{
 		BasicCluster:    core.NewBasicCluster(),
 		IDAllocator:     mockid.NewIDAllocator(),
@@ -52,6 +56,7 @@ func NewCluster(opt *mockoption.ScheduleOptions) *Cluster {
 		RuleManager:     ruleManager,
 		HotCache:        statistics.NewHotCache(),
 		StoresStats:     statistics.NewStoresStats(),
+		regionLabeler:   regionLabeler,
 	}
 }
 
@@ -125,6 +130,11 @@ func (mc *Cluster) AllocPeer(storeID uint64) (*metapb.Peer, error) {
 	return peer, nil
 }
 
+// GetRegionLabeler returns the region labeler.
+func (mc *Cluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return mc.regionLabeler
+}
+
 // FitRegion fits a region to the rules it matches.
 func (mc *Cluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
 	return mc.RuleManager.FitRegion(mc.BasicCluster, region)
@@ -274,6 +284,25 @@ func (mc *Cluster) AddLeaderRegionWithRange(regionID uint64, startKey string, en
 	mc.PutRegion(r)
 }
 
+// AddLeaderRegionWithWitnesses adds region with specified leader, followers
+// and witnesses. The witness stores should be a subset of the follower stores.
+func (mc *Cluster) AddLeaderRegionWithWitnesses(regionID uint64, leaderID uint64, witnessIDs []uint64, followerIds ...uint64) *core.RegionInfo {
+	origin := mc.newMockRegionInfo(regionID, leaderID, followerIds...)
+	var witnesses []*metapb.Peer
+	for _, id := range witnessIDs {
+		if p := origin.GetStorePeer(id); p != nil {
+			witnesses = append(witnesses, p)
+		}
+	}
+	region := origin.Clone(
+		core.WithWitnesses(witnesses),
+		core.SetApproximateSize(10),
+		core.SetApproximateKeys(10),
+	)
+	mc.PutRegion(region)
+	return region
+}
+
 // AddLeaderRegionWithReadInfo adds region with specified leader, followers and read info.
 func (mc *Cluster) AddLeaderRegionWithReadInfo(
 	regionID uint64, leaderID uint64,
@@ -334,6 +363,13 @@ func (mc *Cluster) UpdateStoreLeaderSize(storeID uint64, size int64) {
 	mc.PutStore(newStore)
 }
 
+// UpdateStoreLeaderLoad updates store leader load.
+func (mc *Cluster) UpdateStoreLeaderLoad(storeID uint64, load float64) {
+	store := mc.GetStore(storeID)
+	newStore := store.Clone(core.SetLeaderLoad(load))
+	mc.PutStore(newStore)
+}
+
 // UpdateStoreRegionSize updates store region size.
 func (mc *Cluster) UpdateStoreRegionSize(storeID uint64, size int64) {
 	store := mc.GetStore(storeID)
@@ -421,6 +457,31 @@ func (mc *Cluster) UpdateStorageReadStats(storeID, bytesWritten, keysWritten uin
 	mc.PutStore(newStore)
 }
 
+// UpdateStoreCPUUsage updates the cpu usage of the store, it should be called
+// after the read or written stats are updated.
+func (mc *Cluster) UpdateStoreCPUUsage(storeID, usage uint64) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.CpuUsages = []*pdpb.RecordPair{{Key: "grpc", Value: usage}}
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.Set(storeID, newStats)
+	mc.PutStore(newStore)
+}
+
+// ObserveStoreSlowStats records a heartbeat of the store with the latency of
+// operations and whether it is busy.
+func (mc *Cluster) ObserveStoreSlowStats(storeID, latency uint64, isBusy bool) {
+	store := mc.GetStore(storeID)
+	newStats := proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	newStats.OpLatencies = []*pdpb.RecordPair{{Key: "apply", Value: latency}}
+	newStats.IsBusy = isBusy
+	now := time.Now().Second()
+	newStats.Interval = &pdpb.TimeInterval{StartTimestamp: uint64(now - statistics.StoreHeartBeatReportInterval), EndTimestamp: uint64(now)}
+	newStore := store.Clone(core.SetStoreStats(newStats))
+	mc.Observe(storeID, newStats)
+	mc.PutStore(newStore)
+}
+
 // UpdateStorageWrittenBytes updates store written bytes.
 func (mc *Cluster) UpdateStorageWrittenBytes(storeID uint64, bytesWritten uint64) {
 	store := mc.GetStore(storeID)
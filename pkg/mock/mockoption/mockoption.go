@@ -66,6 +66,7 @@ type ScheduleOptions struct {
 	MaxStoreDownTime             time.Duration
 	MaxReplicas                  int
 	LocationLabels               []string
+	IsolationLevel               string
 	StrictlyMatchLabel           bool
 	HotRegionCacheHitsThreshold  int
 	TolerantSizeRatio            float64
@@ -85,6 +86,13 @@ type ScheduleOptions struct {
 	DisableLocationReplacement   bool
 	LeaderSchedulePolicy         string
 	LabelProperties              map[string][]*metapb.StoreLabel
+	SchedulerQuotas              map[string]SchedulerQuota
+}
+
+// SchedulerQuota mocks the quota of the waiting operators of a scheduler.
+type SchedulerQuota struct {
+	MaxWaitingOperator uint64
+	Weight             float64
 }
 
 // NewScheduleOptions creates a mock schedule option.
@@ -100,6 +108,7 @@ func NewScheduleOptions() *ScheduleOptions {
 	mso.MaxMergeRegionSize = defaultMaxMergeRegionSize
 	mso.MaxMergeRegionKeys = defaultMaxMergeRegionKeys
 	mso.SchedulerMaxWaitingOperator = defaultSchedulerMaxWaitingOperator
+	mso.SchedulerQuotas = make(map[string]SchedulerQuota)
 	mso.SplitMergeInterval = defaultSplitMergeInterval
 	mso.MaxStoreDownTime = defaultMaxStoreDownTime
 	mso.MaxReplicas = defaultMaxReplicas
@@ -200,6 +209,11 @@ func (mso *ScheduleOptions) GetLocationLabels() []string {
 	return mso.LocationLabels
 }
 
+// GetIsolationLevel mocks method
+func (mso *ScheduleOptions) GetIsolationLevel() string {
+	return mso.IsolationLevel
+}
+
 // GetStrictlyMatchLabel mocks method
 func (mso *ScheduleOptions) GetStrictlyMatchLabel() bool {
 	return mso.StrictlyMatchLabel
@@ -235,6 +249,20 @@ func (mso *ScheduleOptions) GetSchedulerMaxWaitingOperator() uint64 {
 	return mso.SchedulerMaxWaitingOperator
 }
 
+// GetSchedulerQuota mocks method.
+func (mso *ScheduleOptions) GetSchedulerQuota(desc string) (uint64, float64) {
+	maxWaitingOperator, weight := mso.SchedulerMaxWaitingOperator, 1.0
+	if quota, ok := mso.SchedulerQuotas[desc]; ok {
+		if quota.MaxWaitingOperator > 0 {
+			maxWaitingOperator = quota.MaxWaitingOperator
+		}
+		if quota.Weight > 0 {
+			weight = quota.Weight
+		}
+	}
+	return maxWaitingOperator, weight
+}
+
 // SetMaxReplicas mocks method
 func (mso *ScheduleOptions) SetMaxReplicas(replicas int) {
 	mso.MaxReplicas = replicas
@@ -20,6 +20,26 @@ const (
 	logicalBits       = (1 << physicalShiftBits) - 1
 )
 
+// MaxSuffixBits is the max bits of the suffix of the logical part.
+const MaxSuffixBits = 10
+
+// ComposeTS composes the ts from the physical and logical parts.
+func ComposeTS(physical, logical int64) uint64 {
+	return uint64(physical<<physicalShiftBits + logical)
+}
+
+// ComposeLogical composes the logical part from the counter and the suffix,
+// the suffix takes the lowest suffixBits bits.
+func ComposeLogical(counter, suffix int64, suffixBits int) int64 {
+	return counter<<uint(suffixBits) | suffix
+}
+
+// ParseLogical parses the logical part to (counter,suffix), the suffix takes
+// the lowest suffixBits bits.
+func ParseLogical(logical int64, suffixBits int) (int64, int64) {
+	return logical >> uint(suffixBits), logical & (1<<uint(suffixBits) - 1)
+}
+
 // ParseTS parses the ts to (physical,logical).
 func ParseTS(ts uint64) (time.Time, uint64) {
 	logical := ts & logicalBits
@@ -0,0 +1,48 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tsoutil
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+)
+
+func TestTSOUtil(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testTSOSuite{})
+
+type testTSOSuite struct{}
+
+func (s *testTSOSuite) TestComposeAndParse(c *C) {
+	physical := time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC).UnixNano() / int64(time.Millisecond)
+	logical := ComposeLogical(5, 3, 2)
+	c.Assert(logical, Equals, int64(23))
+	counter, suffix := ParseLogical(logical, 2)
+	c.Assert(counter, Equals, int64(5))
+	c.Assert(suffix, Equals, int64(3))
+
+	ts := ComposeTS(physical, logical)
+	physicalTime, l := ParseTS(ts)
+	c.Assert(physicalTime.UnixNano()/int64(time.Millisecond), Equals, physical)
+	c.Assert(l, Equals, uint64(logical))
+
+	// No suffix.
+	counter, suffix = ParseLogical(logical, 0)
+	c.Assert(counter, Equals, logical)
+	c.Assert(suffix, Equals, int64(0))
+}
@@ -0,0 +1,117 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/unrolled/render"
+)
+
+type dryRunHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newDryRunHandler(svr *server.Server, rd *render.Render) *dryRunHandler {
+	return &dryRunHandler{
+		svr: svr,
+		rd:  rd,
+	}
+}
+
+type dryRunInput struct {
+	Name   string          `json:"name"`
+	Args   []string        `json:"args"`
+	Rounds int             `json:"rounds"`
+	Config json.RawMessage `json:"config"` // overwrites items of the current schedule config
+}
+
+// getScheduleConfig returns the schedule config used in the dry run, which
+// is the current schedule config overwritten by the input.
+func (h *dryRunHandler) getScheduleConfig(input *dryRunInput) (*config.ScheduleConfig, error) {
+	cfg := h.svr.GetScheduleConfig().Clone()
+	if len(input.Config) == 0 {
+		return cfg, nil
+	}
+	if err := json.Unmarshal(input.Config, cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// @Tags dry-run
+// @Summary Run a scheduler on a snapshot of the cluster without dispatching any operator.
+// @Accept json
+// @Param body body object true "json params, contains name, args, rounds and config"
+// @Produce json
+// @Success 200 {object} schedule.DryRunResult
+// @Failure 400 {string} string "The input is invalid."
+// @Router /dry-run/scheduler [post]
+func (h *dryRunHandler) Scheduler(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	input := dryRunInput{Rounds: 1}
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.Name == "" {
+		h.rd.JSON(w, http.StatusBadRequest, "missing scheduler name")
+		return
+	}
+	cfg, err := h.getScheduleConfig(&input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	result, err := cluster.DryRunScheduler(input.Name, input.Args, cfg, input.Rounds)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, result)
+}
+
+// @Tags dry-run
+// @Summary Run the checkers on a snapshot of the cluster without dispatching any operator.
+// @Accept json
+// @Param body body object true "json params, contains rounds and config"
+// @Produce json
+// @Success 200 {object} schedule.DryRunResult
+// @Failure 400 {string} string "The input is invalid."
+// @Router /dry-run/checker [post]
+func (h *dryRunHandler) Checker(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	input := dryRunInput{Rounds: 1}
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	cfg, err := h.getScheduleConfig(&input)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	result, err := cluster.DryRunCheckers(cfg, input.Rounds)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, result)
+}
@@ -16,11 +16,14 @@ package api
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
@@ -106,6 +109,84 @@ func (h *operatorHandler) List(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, results)
 }
 
+// defaultOperatorRecordLimit is the default max number of operator records returned.
+const defaultOperatorRecordLimit = 1000
+
+// @Tags operator
+// @Summary Query the persisted finished operators by the order of finish time.
+// @Param region_id query integer false "The region involved."
+// @Param store_id query integer false "The store involved."
+// @Param kind query string false "The kinds the operator contains, concat by ','." example(leader,balance)
+// @Param status query string false "The final status." Enums(success, canceled, replaced, expired, timeout)
+// @Param start query integer false "The start Unix timestamp of the finish time, inclusive."
+// @Param end query integer false "The end Unix timestamp of the finish time, exclusive."
+// @Param limit query integer false "The max number of records returned." default(1000)
+// @Produce json
+// @Success 200 {array} schedule.OperatorRecord
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /operators/records [get]
+func (h *operatorHandler) Records(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseOperatorRecordFilter(r)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	records, err := h.GetOperatorRecords(filter)
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, records)
+}
+
+func parseOperatorRecordFilter(r *http.Request) (*schedule.OperatorRecordFilter, error) {
+	query := r.URL.Query()
+	filter := &schedule.OperatorRecordFilter{
+		Status: query.Get("status"),
+		Limit:  defaultOperatorRecordLimit,
+	}
+	var err error
+	if str := query.Get("region_id"); str != "" {
+		if filter.RegionID, err = strconv.ParseUint(str, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if str := query.Get("store_id"); str != "" {
+		if filter.StoreID, err = strconv.ParseUint(str, 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	if str := query.Get("kind"); str != "" {
+		if filter.Kind, err = operator.ParseOperatorKind(str); err != nil {
+			return nil, err
+		}
+	}
+	if str := query.Get("start"); str != "" {
+		start, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		filter.Start = time.Unix(start, 0)
+	}
+	if str := query.Get("end"); str != "" {
+		end, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		filter.End = time.Unix(end, 0)
+	}
+	if str := query.Get("limit"); str != "" {
+		if filter.Limit, err = strconv.Atoi(str); err != nil {
+			return nil, err
+		}
+		if filter.Limit <= 0 {
+			return nil, errors.New("limit should be positive")
+		}
+	}
+	return filter, nil
+}
+
 // FIXME: details of input json body params
 // @Tags operator
 // @Summary Create an operator.
@@ -23,10 +23,12 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
 )
 
 var _ = Suite(&testOperatorSuite{})
@@ -135,6 +137,32 @@ func (s *testOperatorSuite) TestMergeRegionOperator(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testOperatorSuite) TestOperatorRecords(c *C) {
+	mustPutStore(c, s.svr, 1, metapb.StoreState_Up, nil)
+	mustPutStore(c, s.svr, 2, metapb.StoreState_Up, nil)
+	r := newTestRegionInfo(40, 1, []byte("x"), []byte("y"), core.SetRegionVersion(10))
+	mustRegionHeartbeat(c, s.svr, r)
+
+	err := postJSON(testDialClient, fmt.Sprintf("%s/operators", s.urlPrefix), []byte(`{"name":"add-learner", "region_id": 40, "store_id": 2}`))
+	c.Assert(err, IsNil)
+	_, err = doDelete(testDialClient, fmt.Sprintf("%s/operators/40", s.urlPrefix))
+	c.Assert(err, IsNil)
+
+	url := fmt.Sprintf("%s/operators/records?region_id=40&store_id=2&kind=admin,region&status=canceled", s.urlPrefix)
+	var records []*schedule.OperatorRecord
+	testutil.WaitUntil(c, func(c *C) bool {
+		c.Assert(readJSON(testDialClient, url, &records), IsNil)
+		return len(records) == 1
+	})
+	c.Assert(records[0].Desc, Equals, "admin-add-learner")
+	c.Assert(records[0].Stores, DeepEquals, []uint64{2})
+
+	c.Assert(readJSON(testDialClient, fmt.Sprintf("%s/operators/records?region_id=40&status=success", s.urlPrefix), &records), IsNil)
+	c.Assert(records, HasLen, 0)
+	c.Assert(readJSON(testDialClient, fmt.Sprintf("%s/operators/records?kind=unknown", s.urlPrefix), &records), NotNil)
+	c.Assert(readJSON(testDialClient, fmt.Sprintf("%s/operators/records?limit=0", s.urlPrefix), &records), NotNil)
+}
+
 func mustPutStore(c *C, svr *server.Server, id uint64, state metapb.StoreState, labels []*metapb.StoreLabel) {
 	_, err := svr.PutStore(context.Background(), &pdpb.PutStoreRequest{
 		Header: &pdpb.RequestHeader{ClusterId: svr.ClusterID()},
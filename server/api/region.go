@@ -24,6 +24,7 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/kvproto/pkg/replication_modepb"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/unrolled/render"
@@ -489,6 +490,79 @@ func (h *regionsHandler) GetRegionSiblings(w http.ResponseWriter, r *http.Reques
 	h.rd.JSON(w, http.StatusOK, regionsInfo)
 }
 
+type splitRegionsInput struct {
+	StartKey  string   `json:"start_key"`
+	EndKey    string   `json:"end_key"`
+	SplitKeys []string `json:"split_keys"`
+	Count     int      `json:"count"`
+	Limit     int      `json:"limit"`
+}
+
+// @Tags region
+// @Summary Split the regions in a key range at the split keys or into the count of regions, the keys are in hex format.
+// @Accept json
+// @Param body body object true "json params"
+// @Produce json
+// @Success 200 {object} schedule.SplitRegionsResult
+// @Failure 400 {string} string "The input is invalid."
+// @Router /regions/split [post]
+func (h *regionsHandler) SplitRegions(w http.ResponseWriter, r *http.Request) {
+	var input splitRegionsInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if input.Limit < 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "limit should not be negative")
+		return
+	}
+	result, err := h.svr.GetHandler().SplitRegions(r.Context(), input.StartKey, input.EndKey, input.SplitKeys, input.Count, input.Limit)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, result)
+}
+
+type scatterRegionsInput struct {
+	RegionsID []uint64 `json:"regions_id"`
+	Group     string   `json:"group"`
+}
+
+// ScatterRegionsOutput is the result of scattering regions.
+type ScatterRegionsOutput struct {
+	FinishedPercentage int               `json:"finished_percentage"`
+	Failures           map[uint64]string `json:"failures"`
+}
+
+// @Tags region
+// @Summary Scatter the regions in the same group, so their peers and leaders are spread evenly.
+// @Accept json
+// @Param body body object true "json params"
+// @Produce json
+// @Success 200 {object} ScatterRegionsOutput
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /regions/scatter [post]
+func (h *regionsHandler) ScatterRegions(w http.ResponseWriter, r *http.Request) {
+	var input scatterRegionsInput
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &input); err != nil {
+		return
+	}
+	if len(input.RegionsID) == 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "missing region ids")
+		return
+	}
+	failures, err := h.svr.GetHandler().ScatterRegions(input.RegionsID, input.Group)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, &ScatterRegionsOutput{
+		FinishedPercentage: 100 * (len(input.RegionsID) - len(failures)) / len(input.RegionsID),
+		Failures:           failures,
+	})
+}
+
 const (
 	defaultRegionLimit     = 16
 	maxRegionLimit         = 10240
@@ -0,0 +1,165 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/pkg/apiutil"
+	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+	"github.com/unrolled/render"
+)
+
+type regionLabelHandler struct {
+	svr *server.Server
+	rd  *render.Render
+}
+
+func newRegionLabelHandler(s *server.Server, rd *render.Render) *regionLabelHandler {
+	return &regionLabelHandler{
+		svr: s,
+		rd:  rd,
+	}
+}
+
+// @Tags region_label
+// @Summary List all label rules of cluster.
+// @Produce json
+// @Success 200 {array} labeler.LabelRule
+// @Router /config/region-label/rules [get]
+func (h *regionLabelHandler) GetAllRules(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	rules := cluster.GetRegionLabeler().GetAllLabelRules()
+	h.rd.JSON(w, http.StatusOK, rules)
+}
+
+// @Tags region_label
+// @Summary Get label rule of cluster by id.
+// @Param id path string true "Rule Id"
+// @Produce json
+// @Success 200 {object} labeler.LabelRule
+// @Failure 404 {string} string "The rule does not exist."
+// @Router /config/region-label/rule/{id} [get]
+func (h *regionLabelHandler) GetRule(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id := mux.Vars(r)["id"]
+	rule := cluster.GetRegionLabeler().GetLabelRule(id)
+	if rule == nil {
+		h.rd.JSON(w, http.StatusNotFound, nil)
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, rule)
+}
+
+// @Tags region_label
+// @Summary Update label rule of cluster.
+// @Accept json
+// @Param rule body labeler.LabelRule true "Parameters of label rule"
+// @Produce json
+// @Success 200 {string} string "Update rule success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/region-label/rule [post]
+func (h *regionLabelHandler) SetRule(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	var rule labeler.LabelRule
+	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &rule); err != nil {
+		return
+	}
+	if err := cluster.GetRegionLabeler().SetLabelRule(&rule); err != nil {
+		if labeler.IsInvalidRuleError(err) {
+			h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		} else {
+			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags region_label
+// @Summary Delete label rule of cluster by id.
+// @Param id path string true "Rule Id"
+// @Produce json
+// @Success 200 {string} string "Delete rule success."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /config/region-label/rule/{id} [delete]
+func (h *regionLabelHandler) DeleteRule(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	id := mux.Vars(r)["id"]
+	if err := cluster.GetRegionLabeler().DeleteLabelRule(id); err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.rd.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags region_label
+// @Summary Get labels of a region.
+// @Param id path integer true "Region Id"
+// @Produce json
+// @Success 200 {array} labeler.RegionLabel
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The region does not exist."
+// @Router /region/id/{id}/labels [get]
+func (h *regionLabelHandler) GetRegionLabels(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	region, ok := h.getRegion(w, r)
+	if !ok {
+		return
+	}
+	labels := cluster.GetRegionLabeler().GetRegionLabels(region)
+	if labels == nil {
+		labels = []*labeler.RegionLabel{}
+	}
+	h.rd.JSON(w, http.StatusOK, labels)
+}
+
+// @Tags region_label
+// @Summary Get label of a region by key.
+// @Param id path integer true "Region Id"
+// @Param key path string true "Label key"
+// @Produce json
+// @Success 200 {string} string
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The region does not exist."
+// @Router /region/id/{id}/label/{key} [get]
+func (h *regionLabelHandler) GetRegionLabel(w http.ResponseWriter, r *http.Request) {
+	cluster := getCluster(r.Context())
+	region, ok := h.getRegion(w, r)
+	if !ok {
+		return
+	}
+	key := mux.Vars(r)["key"]
+	h.rd.JSON(w, http.StatusOK, cluster.GetRegionLabeler().GetRegionLabel(region, key))
+}
+
+func (h *regionLabelHandler) getRegion(w http.ResponseWriter, r *http.Request) (*core.RegionInfo, bool) {
+	cluster := getCluster(r.Context())
+	regionID, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, "invalid region id")
+		return nil, false
+	}
+	region := cluster.GetRegion(regionID)
+	if region == nil {
+		h.rd.JSON(w, http.StatusNotFound, server.ErrRegionNotFound(regionID).Error())
+		return nil, false
+	}
+	return region, true
+}
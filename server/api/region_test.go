@@ -15,6 +15,7 @@ package api
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"math/rand"
 	"net/url"
@@ -190,6 +191,27 @@ func (s *testRegionSuite) TestRegions(c *C) {
 	}
 }
 
+func (s *testRegionSuite) TestScatterRegions(c *C) {
+	r := newTestRegionInfo(2, 1, []byte("a"), []byte("b"))
+	mustRegionHeartbeat(c, s.svr, r)
+	url := fmt.Sprintf("%s/regions/scatter", s.urlPrefix)
+
+	err := postJSON(testDialClient, url, []byte(`{"group":"test"}`))
+	c.Assert(err, NotNil)
+
+	// Region 2 has only one peer and region 1000 does not exist.
+	body := []byte(`{"regions_id":[2,1000],"group":"test"}`)
+	err = postJSON(testDialClient, url, body, func(res []byte, code int) {
+		output := &ScatterRegionsOutput{}
+		c.Assert(json.Unmarshal(res, output), IsNil)
+		c.Assert(output.FinishedPercentage, Equals, 0)
+		c.Assert(output.Failures, HasLen, 2)
+		c.Assert(output.Failures[2], Matches, ".*not fully replicated.*")
+		c.Assert(output.Failures[1000], Matches, ".*not found.*")
+	})
+	c.Assert(err, IsNil)
+}
+
 func (s *testRegionSuite) TestStoreRegions(c *C) {
 	r1 := newTestRegionInfo(2, 1, []byte("a"), []byte("b"))
 	r2 := newTestRegionInfo(3, 1, []byte("b"), []byte("c"))
@@ -59,6 +59,7 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) *mux.R
 	operatorHandler := newOperatorHandler(handler, rd)
 	apiRouter.HandleFunc("/operators", operatorHandler.List).Methods("GET")
 	apiRouter.HandleFunc("/operators", operatorHandler.Post).Methods("POST")
+	apiRouter.HandleFunc("/operators/records", operatorHandler.Records).Methods("GET")
 	apiRouter.HandleFunc("/operators/{region_id}", operatorHandler.Get).Methods("GET")
 	apiRouter.HandleFunc("/operators/{region_id}", operatorHandler.Delete).Methods("DELETE")
 
@@ -67,6 +68,15 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) *mux.R
 	apiRouter.HandleFunc("/schedulers", schedulerHandler.Post).Methods("POST")
 	apiRouter.HandleFunc("/schedulers/{name}", schedulerHandler.Delete).Methods("DELETE")
 	apiRouter.HandleFunc("/schedulers/{name}", schedulerHandler.PauseOrResume).Methods("POST")
+	apiRouter.HandleFunc("/schedulers/{name}/diagnostic", schedulerHandler.GetDiagnostic).Methods("GET")
+	apiRouter.HandleFunc("/schedulers/{name}/diagnostic", schedulerHandler.SetDiagnostic).Methods("POST")
+	apiRouter.HandleFunc("/schedulers/{name}/active-window", schedulerHandler.GetActiveWindow).Methods("GET")
+	apiRouter.HandleFunc("/schedulers/{name}/active-window", schedulerHandler.SetActiveWindow).Methods("POST")
+	apiRouter.HandleFunc("/schedulers/{name}/active-window", schedulerHandler.DeleteActiveWindow).Methods("DELETE")
+	apiRouter.HandleFunc("/checkers/merge-checker/candidates", schedulerHandler.GetMergeCandidates).Methods("GET")
+	apiRouter.HandleFunc("/checkers/{name}/active-window", schedulerHandler.GetActiveWindow).Methods("GET")
+	apiRouter.HandleFunc("/checkers/{name}/active-window", schedulerHandler.SetActiveWindow).Methods("POST")
+	apiRouter.HandleFunc("/checkers/{name}/active-window", schedulerHandler.DeleteActiveWindow).Methods("DELETE")
 	schedulerConfigHandler := newSchedulerConfigHandler(svr, rd)
 	rootRouter.PathPrefix(server.SchedulerConfigHandlerPath).Handler(schedulerConfigHandler)
 
@@ -94,10 +104,28 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) *mux.R
 	clusterRouter.HandleFunc("/config/rules/group/{group}", rulesHandler.GetAllByGroup).Methods("GET")
 	clusterRouter.HandleFunc("/config/rules/region/{region}", rulesHandler.GetAllByRegion).Methods("GET")
 	clusterRouter.HandleFunc("/config/rules/key/{key}", rulesHandler.GetAllByKey).Methods("GET")
+	clusterRouter.HandleFunc("/config/rules/batch", rulesHandler.Batch).Methods("POST")
 	clusterRouter.HandleFunc("/config/rule/{group}/{id}", rulesHandler.Get).Methods("GET")
 	clusterRouter.HandleFunc("/config/rule", rulesHandler.Set).Methods("POST")
 	clusterRouter.HandleFunc("/config/rule/{group}/{id}", rulesHandler.Delete).Methods("DELETE")
 
+	clusterRouter.HandleFunc("/config/rule_group/{id}", rulesHandler.GetGroupConfig).Methods("GET")
+	clusterRouter.HandleFunc("/config/rule_group", rulesHandler.SetGroupConfig).Methods("POST")
+	clusterRouter.HandleFunc("/config/rule_group/{id}", rulesHandler.DeleteGroupConfig).Methods("DELETE")
+	clusterRouter.HandleFunc("/config/rule_groups", rulesHandler.GetAllGroupConfigs).Methods("GET")
+
+	regionLabelHandler := newRegionLabelHandler(svr, rd)
+	clusterRouter.HandleFunc("/config/region-label/rules", regionLabelHandler.GetAllRules).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/rule/{id}", regionLabelHandler.GetRule).Methods("GET")
+	clusterRouter.HandleFunc("/config/region-label/rule", regionLabelHandler.SetRule).Methods("POST")
+	clusterRouter.HandleFunc("/config/region-label/rule/{id}", regionLabelHandler.DeleteRule).Methods("DELETE")
+	clusterRouter.HandleFunc("/region/id/{id}/labels", regionLabelHandler.GetRegionLabels).Methods("GET")
+	clusterRouter.HandleFunc("/region/id/{id}/label/{key}", regionLabelHandler.GetRegionLabel).Methods("GET")
+
+	dryRunHandler := newDryRunHandler(svr, rd)
+	clusterRouter.HandleFunc("/dry-run/scheduler", dryRunHandler.Scheduler).Methods("POST")
+	clusterRouter.HandleFunc("/dry-run/checker", dryRunHandler.Checker).Methods("POST")
+
 	storeHandler := newStoreHandler(handler, rd)
 	clusterRouter.HandleFunc("/store/{id}", storeHandler.Get).Methods("GET")
 	clusterRouter.HandleFunc("/store/{id}", storeHandler.Delete).Methods("DELETE")
@@ -148,6 +176,8 @@ func createRouter(ctx context.Context, prefix string, svr *server.Server) *mux.R
 	clusterRouter.HandleFunc("/regions/check/hist-size", regionsHandler.GetSizeHistogram).Methods("GET")
 	clusterRouter.HandleFunc("/regions/check/hist-keys", regionsHandler.GetKeysHistogram).Methods("GET")
 	clusterRouter.HandleFunc("/regions/sibling/{id}", regionsHandler.GetRegionSiblings).Methods("GET")
+	clusterRouter.HandleFunc("/regions/scatter", regionsHandler.ScatterRegions).Methods("POST")
+	clusterRouter.HandleFunc("/regions/split", regionsHandler.SplitRegions).Methods("POST")
 
 	apiRouter.Handle("/version", newVersionHandler(rd)).Methods("GET")
 	apiRouter.Handle("/status", newStatusHandler(svr, rd)).Methods("GET")
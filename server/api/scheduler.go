@@ -17,12 +17,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/gorilla/mux"
 	"github.com/pingcap/pd/v4/pkg/apiutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pingcap/pd/v4/server/schedulers"
+	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 )
 
@@ -42,8 +46,16 @@ func newSchedulerHandler(svr *server.Server, r *render.Render) *schedulerHandler
 	}
 }
 
+// schedulerStatus is the state of a running scheduler.
+type schedulerStatus struct {
+	Name         string                       `json:"name"`
+	Paused       bool                         `json:"paused"`
+	ActiveWindow *schedule.ActiveWindowStatus `json:"active-window"`
+}
+
 // @Tags scheduler
 // @Summary List running schedulers.
+// @Param detail query bool false "Whether to show the pause and active window state of schedulers."
 // @Produce json
 // @Success 200 {array} string
 // @Failure 500 {string} string "PD server failed to proceed the request."
@@ -54,7 +66,24 @@ func (h *schedulerHandler) List(w http.ResponseWriter, r *http.Request) {
 		h.r.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
-	h.r.JSON(w, http.StatusOK, schedulers)
+	if r.URL.Query().Get("detail") != "true" {
+		h.r.JSON(w, http.StatusOK, schedulers)
+		return
+	}
+	statuses := make([]*schedulerStatus, 0, len(schedulers))
+	for _, name := range schedulers {
+		paused, err := h.IsSchedulerPaused(name)
+		if err != nil {
+			// The scheduler is removed concurrently.
+			continue
+		}
+		window, err := h.GetActiveWindowStatus(name)
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, &schedulerStatus{Name: name, Paused: paused, ActiveWindow: window})
+	}
+	h.r.JSON(w, http.StatusOK, statuses)
 }
 
 // FIXME: details of input json body params
@@ -95,6 +124,11 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+	case schedulers.BalanceLearnerName:
+		if err := h.AddBalanceLearnerScheduler(); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	case schedulers.LabelName:
 		if err := h.AddLabelScheduler(); err != nil {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
@@ -146,9 +180,14 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusBadRequest, "missing store id")
 			return
 		}
-		err := h.AddGrantLeaderScheduler(uint64(storeID))
+		args, err := collectStoreLeaderOptions(input)
+		if err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		err = h.AddGrantLeaderScheduler(uint64(storeID), args...)
 		if err == schedulers.ErrSchedulerExisted {
-			if err := h.redirectSchedulerUpdate(schedulers.GrantLeaderName, storeID); err != nil {
+			if err := h.redirectSchedulerUpdate(schedulers.GrantLeaderName, input); err != nil {
 				h.r.JSON(w, http.StatusInternalServerError, err.Error())
 				return
 			}
@@ -163,9 +202,14 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusBadRequest, "missing store id")
 			return
 		}
-		err := h.AddEvictLeaderScheduler(uint64(storeID))
+		args, err := collectStoreLeaderOptions(input)
+		if err != nil {
+			h.r.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		err = h.AddEvictLeaderScheduler(uint64(storeID), args...)
 		if err == schedulers.ErrSchedulerExisted {
-			if err := h.redirectSchedulerUpdate(schedulers.EvictLeaderName, storeID); err != nil {
+			if err := h.redirectSchedulerUpdate(schedulers.EvictLeaderName, input); err != nil {
 				h.r.JSON(w, http.StatusInternalServerError, err.Error())
 				return
 			}
@@ -174,6 +218,11 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
 			return
 		}
+	case schedulers.EvictSlowStoreName:
+		if err := h.AddEvictSlowStoreScheduler(); err != nil {
+			h.r.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
 	case schedulers.ShuffleLeaderName:
 		if err := h.AddShuffleLeaderScheduler(); err != nil {
 			h.r.JSON(w, http.StatusInternalServerError, err.Error())
@@ -207,10 +256,36 @@ func (h *schedulerHandler) Post(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, nil)
 }
 
-func (h *schedulerHandler) redirectSchedulerUpdate(name string, storeID float64) error {
-	input := make(map[string]interface{})
-	input["name"] = name
-	input["store_id"] = storeID
+// collectStoreLeaderOptions collects the leader percent and key ranges of
+// evict-leader-scheduler and grant-leader-scheduler as args.
+func collectStoreLeaderOptions(input map[string]interface{}) ([]string, error) {
+	var args []string
+	if v, ok := input["leader_percent"]; ok {
+		percent, ok := v.(float64)
+		if !ok {
+			return nil, errors.New("leader_percent should be a number")
+		}
+		args = append(args, strconv.Itoa(int(percent))+"%")
+	}
+	if v, ok := input["ranges"]; ok {
+		ranges, ok := v.([]interface{})
+		if !ok {
+			return nil, errors.New("ranges should be a list of keys")
+		}
+		for _, r := range ranges {
+			key, ok := r.(string)
+			if !ok {
+				return nil, errors.New("the keys of ranges should be strings")
+			}
+			args = append(args, key)
+		}
+	}
+	return args, nil
+}
+
+// redirectSchedulerUpdate adds the store in the input to the existing
+// evict-leader-scheduler or grant-leader-scheduler.
+func (h *schedulerHandler) redirectSchedulerUpdate(name string, input map[string]interface{}) error {
 	updateURL := fmt.Sprintf("%s/%s/%s/config", h.GetAddr(), schedulerConfigPrefix, name)
 	body, err := json.Marshal(input)
 	if err != nil {
@@ -249,10 +324,149 @@ func (h *schedulerHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	h.r.JSON(w, http.StatusOK, nil)
 }
 
+// @Tags scheduler
+// @Summary Get the plans of the last rounds recorded by a scheduler.
+// @Param name path string true "The name of the scheduler."
+// @Produce json
+// @Success 200 {object} plan.Diagnostic
+// @Failure 400 {string} string "The scheduler does not support diagnostic."
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/diagnostic [get]
+func (h *schedulerHandler) GetDiagnostic(w http.ResponseWriter, r *http.Request) {
+	recorder, err := h.GetSchedulerPlanRecorder(mux.Vars(r)["name"])
+	if err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.r.JSON(w, http.StatusOK, recorder.GetDiagnostic())
+}
+
+// @Tags scheduler
+// @Summary Enable or disable recording the plans of a scheduler.
+// @Accept json
+// @Param name path string true "The name of the scheduler."
+// @Param body body object true "json params, contains enable and rounds"
+// @Produce json
+// @Success 200 {string} string "Set the diagnostic of the scheduler success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/diagnostic [post]
+func (h *schedulerHandler) SetDiagnostic(w http.ResponseWriter, r *http.Request) {
+	input := struct {
+		Enable bool `json:"enable"`
+		Rounds int  `json:"rounds"`
+	}{Rounds: plan.DefaultRecordRounds}
+	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	recorder, err := h.GetSchedulerPlanRecorder(mux.Vars(r)["name"])
+	if err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	if !input.Enable {
+		recorder.Disable()
+		h.r.JSON(w, http.StatusOK, nil)
+		return
+	}
+	if err := recorder.Enable(input.Rounds); err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags scheduler
+// @Summary List the regions small enough to merge but skipped by the merge checker, with the reasons.
+// @Param range query string false "The ID of the label rule deciding the merge policy, or the table like table-{id}."
+// @Produce json
+// @Success 200 {array} checker.MergeCandidate
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /checkers/merge-checker/candidates [get]
+func (h *schedulerHandler) GetMergeCandidates(w http.ResponseWriter, r *http.Request) {
+	candidates, err := h.Handler.GetMergeCandidates(r.URL.Query().Get("range"))
+	if err != nil {
+		h.r.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	h.r.JSON(w, http.StatusOK, candidates)
+}
+
+// @Tags scheduler
+// @Summary Get the active window of a scheduler or a checker.
+// @Param name path string true "The name of the scheduler or the checker."
+// @Produce json
+// @Success 200 {object} schedule.ActiveWindowStatus
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/active-window [get]
+// @Router /checkers/{name}/active-window [get]
+func (h *schedulerHandler) GetActiveWindow(w http.ResponseWriter, r *http.Request) {
+	status, err := h.GetActiveWindowStatus(mux.Vars(r)["name"])
+	if err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.r.JSON(w, http.StatusOK, status)
+}
+
+// @Tags scheduler
+// @Summary Set the active window of a scheduler or a checker, such as "only 01:00-06:00" or "except Fri 18:00-20:00" in UTC.
+// @Accept json
+// @Param name path string true "The name of the scheduler or the checker."
+// @Param body body object true "json params, contains spec"
+// @Produce json
+// @Success 200 {string} string "Set the active window success."
+// @Failure 400 {string} string "The input is invalid."
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/active-window [post]
+// @Router /checkers/{name}/active-window [post]
+func (h *schedulerHandler) SetActiveWindow(w http.ResponseWriter, r *http.Request) {
+	var input struct {
+		Spec string `json:"spec"`
+	}
+	if err := apiutil.ReadJSONRespondError(h.r, w, r.Body, &input); err != nil {
+		return
+	}
+	window, err := schedule.ParseActiveWindow(input.Spec)
+	if err != nil {
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.Handler.SetActiveWindow(mux.Vars(r)["name"], window); err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
+// @Tags scheduler
+// @Summary Remove the active window of a scheduler or a checker, it is always active then.
+// @Param name path string true "The name of the scheduler or the checker."
+// @Produce json
+// @Success 200 {string} string "Remove the active window success."
+// @Failure 404 {string} string "The scheduler is not found."
+// @Failure 500 {string} string "PD server failed to proceed the request."
+// @Router /schedulers/{name}/active-window [delete]
+// @Router /checkers/{name}/active-window [delete]
+func (h *schedulerHandler) DeleteActiveWindow(w http.ResponseWriter, r *http.Request) {
+	if err := h.Handler.SetActiveWindow(mux.Vars(r)["name"], nil); err != nil {
+		h.handleErr(w, err)
+		return
+	}
+	h.r.JSON(w, http.StatusOK, nil)
+}
+
 func (h *schedulerHandler) handleErr(w http.ResponseWriter, err error) {
-	if err == schedulers.ErrSchedulerNotFound {
+	switch err {
+	case schedulers.ErrSchedulerNotFound:
 		h.r.JSON(w, http.StatusNotFound, err.Error())
-	} else {
+	case server.ErrSchedulerNotDiagnosable:
+		h.r.JSON(w, http.StatusBadRequest, err.Error())
+	default:
 		h.r.JSON(w, http.StatusInternalServerError, err.Error())
 	}
 }
@@ -16,11 +16,15 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	_ "github.com/pingcap/pd/v4/server/schedulers"
 )
 
@@ -98,7 +102,62 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 		args          []arg
 		extraTestFunc func(name string, c *C)
 	}{
-		{name: "balance-leader-scheduler"},
+		{
+			name: "balance-leader-scheduler",
+			extraTestFunc: func(name string, c *C) {
+				resp := make(map[string]interface{})
+				listURL := fmt.Sprintf("%s%s%s/%s/list", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				c.Assert(readJSON(testDialClient, listURL, &resp), IsNil)
+				c.Assert(resp["batch"], Equals, 1.0)
+				c.Assert(resp["operator-limit"], Equals, 0.0)
+				c.Assert(resp["ranges"], HasLen, 1)
+
+				updateURL := fmt.Sprintf("%s%s%s/%s/config", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				body, err := json.Marshal(map[string]interface{}{"batch": 3, "operator-limit": 8})
+				c.Assert(err, IsNil)
+				c.Assert(postJSON(testDialClient, updateURL, body), IsNil)
+				resp = make(map[string]interface{})
+				c.Assert(readJSON(testDialClient, listURL, &resp), IsNil)
+				c.Assert(resp["batch"], Equals, 3.0)
+				c.Assert(resp["operator-limit"], Equals, 8.0)
+				err = postJSON(testDialClient, updateURL, body, func(res []byte, code int) {
+					c.Assert(string(res), Equals, "no changed")
+					c.Assert(code, Equals, 200)
+				})
+				c.Assert(err, IsNil)
+
+				// The invalid config is rejected and the config is unchanged.
+				for _, data := range []map[string]interface{}{
+					{"batch": 11},
+					{"tolerant-size-ratio": -1},
+					{"unknown": 1},
+					{"name": "other"},
+					{"ranges": []interface{}{}},
+				} {
+					body, err = json.Marshal(data)
+					c.Assert(err, IsNil)
+					c.Assert(postJSON(testDialClient, updateURL, body), NotNil)
+				}
+				resp = make(map[string]interface{})
+				c.Assert(readJSON(testDialClient, listURL, &resp), IsNil)
+				c.Assert(resp["batch"], Equals, 3.0)
+				c.Assert(resp["name"], Equals, name)
+				c.Assert(resp["ranges"], HasLen, 1)
+
+				// The ranges can be given as escaped keys.
+				body, err = json.Marshal(map[string]interface{}{"ranges": []string{url.QueryEscape("a\x00"), "c", "e", ""}})
+				c.Assert(err, IsNil)
+				c.Assert(postJSON(testDialClient, updateURL, body), IsNil)
+				var conf struct {
+					Ranges []core.KeyRange `json:"ranges"`
+				}
+				c.Assert(readJSON(testDialClient, listURL, &conf), IsNil)
+				c.Assert(conf.Ranges, DeepEquals, []core.KeyRange{core.NewKeyRange("a\x00", "c"), core.NewKeyRange("e", "")})
+				body, err = json.Marshal(map[string]interface{}{"ranges": []string{"a", "c", "e"}})
+				c.Assert(err, IsNil)
+				c.Assert(postJSON(testDialClient, updateURL, body), NotNil)
+			},
+		},
 		{
 			name: "balance-hot-region-scheduler",
 			extraTestFunc: func(name string, c *C) {
@@ -140,7 +199,19 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 			},
 		},
 		{name: "balance-region-scheduler"},
+		{name: "balance-learner-scheduler"},
 		{name: "shuffle-leader-scheduler"},
+		{
+			name:        "evict-slow-store-scheduler",
+			createdName: "evict-slow-store-scheduler",
+			extraTestFunc: func(name string, c *C) {
+				resp := make(map[string]interface{})
+				listURL := fmt.Sprintf("%s%s%s/%s/list", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				c.Assert(readJSON(testDialClient, listURL, &resp), IsNil)
+				c.Assert(resp["evict-store"], Equals, 0.0)
+				c.Assert(resp["slow-scores"], NotNil)
+			},
+		},
 		{name: "shuffle-region-scheduler"},
 		{
 			name:        "grant-leader-scheduler",
@@ -222,6 +293,7 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 				input := make(map[string]interface{})
 				input["name"] = "evict-leader-scheduler"
 				input["store_id"] = 2
+				input["leader_percent"] = 20
 				updateURL := fmt.Sprintf("%s%s%s/%s/config", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
 				body, err := json.Marshal(input)
 				c.Assert(err, IsNil)
@@ -230,6 +302,20 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 				c.Assert(readJSON(testDialClient, listURL, &resp), IsNil)
 				exceptMap["2"] = []interface{}{map[string]interface{}{"end-key": "", "start-key": ""}}
 				c.Assert(resp["store-id-ranges"], DeepEquals, exceptMap)
+				c.Assert(resp["store-id-leader-percent"], DeepEquals, map[string]interface{}{"2": 20.0})
+
+				// the leader percent is invalid
+				input["leader_percent"] = 120
+				body, err = json.Marshal(input)
+				c.Assert(err, IsNil)
+				c.Assert(postJSON(testDialClient, updateURL, body), NotNil)
+
+				// using /pd/v1/schedule-config/evict-leader-scheduler/progress to get the progress
+				progress := make(map[string]interface{})
+				progressURL := fmt.Sprintf("%s%s%s/%s/progress", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name)
+				c.Assert(readJSON(testDialClient, progressURL, &progress), IsNil)
+				c.Assert(progress, HasLen, 2)
+				c.Assert(progress["2"].(map[string]interface{})["target"], Equals, 0.0)
 
 				// using /pd/v1/schedule-config/evict-leader-scheduler/config to delete exist store from evict-leader-scheduler
 				deleteURL := fmt.Sprintf("%s%s%s/%s/delete/%s", s.svr.GetAddr(), apiPrefix, server.SchedulerConfigHandlerPath, name, "2")
@@ -336,6 +422,97 @@ func (s *testScheduleSuite) TestAPI(c *C) {
 
 }
 
+func (s *testScheduleSuite) TestDiagnostic(c *C) {
+	body, err := json.Marshal(map[string]interface{}{"name": "balance-region-scheduler"})
+	c.Assert(err, IsNil)
+	s.addScheduler("balance-region-scheduler", "", body, nil, c)
+	defer s.deleteScheduler("balance-region-scheduler", c)
+
+	url := fmt.Sprintf("%s/%s/diagnostic", s.urlPrefix, "balance-region-scheduler")
+	var d plan.Diagnostic
+	c.Assert(readJSON(testDialClient, url, &d), IsNil)
+	c.Assert(d.Enabled, IsFalse)
+
+	body, err = json.Marshal(map[string]interface{}{"enable": true, "rounds": 5})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(testDialClient, url, body), IsNil)
+	c.Assert(readJSON(testDialClient, url, &d), IsNil)
+	c.Assert(d.Enabled, IsTrue)
+	c.Assert(d.Rounds, Equals, 5)
+
+	body, err = json.Marshal(map[string]interface{}{"enable": true, "rounds": plan.MaxRecordRounds + 1})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(testDialClient, url, body), NotNil)
+
+	body, err = json.Marshal(map[string]interface{}{"enable": false})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(testDialClient, url, body), IsNil)
+	c.Assert(readJSON(testDialClient, url, &d), IsNil)
+	c.Assert(d.Enabled, IsFalse)
+
+	// The scheduler does not exist.
+	c.Assert(readJSON(testDialClient, fmt.Sprintf("%s/%s/diagnostic", s.urlPrefix, "balance-leader-scheduler"), &d), NotNil)
+}
+
+func (s *testScheduleSuite) TestActiveWindow(c *C) {
+	body, err := json.Marshal(map[string]interface{}{"name": "balance-region-scheduler"})
+	c.Assert(err, IsNil)
+	s.addScheduler("balance-region-scheduler", "", body, nil, c)
+	defer s.deleteScheduler("balance-region-scheduler", c)
+
+	url := fmt.Sprintf("%s/%s/active-window", s.urlPrefix, "balance-region-scheduler")
+	var status schedule.ActiveWindowStatus
+	c.Assert(readJSON(testDialClient, url, &status), IsNil)
+	c.Assert(status.Spec, Equals, "")
+	c.Assert(status.Active, IsTrue)
+	c.Assert(status.NextTransition, IsNil)
+
+	body, err = json.Marshal(map[string]interface{}{"spec": "except 00:00-24:00"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(testDialClient, url, body), IsNil)
+	c.Assert(readJSON(testDialClient, url, &status), IsNil)
+	c.Assert(status.Spec, Equals, "except 00:00-24:00")
+	c.Assert(status.Active, IsFalse)
+	// The active window is not a scheduler config.
+	c.Assert(s.svr.GetConfig().Schedule.SchedulersPayload, Not(HasKey), "balance-region-scheduler/active-window")
+
+	var statuses []*schedulerStatus
+	c.Assert(readJSON(testDialClient, s.urlPrefix+"?detail=true", &statuses), IsNil)
+	found := false
+	for _, st := range statuses {
+		if st.Name == "balance-region-scheduler" {
+			found = true
+			c.Assert(st.Paused, IsFalse)
+			c.Assert(st.ActiveWindow.Active, IsFalse)
+		}
+	}
+	c.Assert(found, IsTrue)
+
+	// The window is invalid.
+	body, err = json.Marshal(map[string]interface{}{"spec": "only 25:00-26:00"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(testDialClient, url, body), NotNil)
+
+	_, err = doDelete(testDialClient, url)
+	c.Assert(err, IsNil)
+	c.Assert(readJSON(testDialClient, url, &status), IsNil)
+	c.Assert(status.Active, IsTrue)
+
+	// The checkers also support the active window.
+	checkerURL := fmt.Sprintf("%s%s/api/v1/checkers/%s/active-window", s.svr.GetAddr(), apiPrefix, schedule.MergeCheckerName)
+	body, err = json.Marshal(map[string]interface{}{"spec": "only Sat,Sun 00:00-24:00"})
+	c.Assert(err, IsNil)
+	c.Assert(postJSON(testDialClient, checkerURL, body), IsNil)
+	c.Assert(readJSON(testDialClient, checkerURL, &status), IsNil)
+	c.Assert(status.Spec, Equals, "only Sat,Sun 00:00-24:00")
+	c.Assert(status.NextTransition, NotNil)
+	_, err = doDelete(testDialClient, checkerURL)
+	c.Assert(err, IsNil)
+
+	// The scheduler does not exist.
+	c.Assert(readJSON(testDialClient, fmt.Sprintf("%s/%s/active-window", s.urlPrefix, "balance-leader-scheduler"), &status), NotNil)
+}
+
 func (s *testScheduleSuite) addScheduler(name, createdName string, body []byte, extraTest func(string, *C), c *C) {
 	if createdName == "" {
 		createdName = name
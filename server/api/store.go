@@ -373,7 +373,7 @@ func (h *storeHandler) SetLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.SetStoreLimit(storeID, rate/schedule.StoreBalanceBaseTime, typeValue); err != nil {
+	if err := h.SetStoreLimit(storeID, limitRateFromInput(rate, typeValue), typeValue); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -443,7 +443,7 @@ func (h *storesHandler) SetAllLimit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.SetAllStoresLimit(rate/schedule.StoreBalanceBaseTime, typeValue); err != nil {
+	if err := h.SetAllStoresLimit(limitRateFromInput(rate, typeValue), typeValue); err != nil {
 		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -477,7 +477,7 @@ func (h *storesHandler) GetAllLimit(w http.ResponseWriter, r *http.Request) {
 	resp := make(map[uint64]*LimitResp)
 	for s, l := range limits {
 		resp[s] = &LimitResp{
-			Rate: l.Rate() * schedule.StoreBalanceBaseTime,
+			Rate: limitRateToOutput(l.Rate(), typeValue),
 			Mode: l.Mode().String(),
 		}
 	}
@@ -501,6 +501,10 @@ func (h *storesHandler) SetStoreLimitScene(w http.ResponseWriter, r *http.Reques
 		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if typeValue.IsByteType() {
+		h.rd.JSON(w, http.StatusBadRequest, "scene is not supported by the byte limit")
+		return
+	}
 	scene := h.Handler.GetStoreLimitScene(typeValue)
 	if err := apiutil.ReadJSONRespondError(h.rd, w, r.Body, &scene); err != nil {
 		return
@@ -521,6 +525,10 @@ func (h *storesHandler) GetStoreLimitScene(w http.ResponseWriter, r *http.Reques
 		h.rd.JSON(w, http.StatusBadRequest, err.Error())
 		return
 	}
+	if typeValue.IsByteType() {
+		h.rd.JSON(w, http.StatusBadRequest, "scene is not supported by the byte limit")
+		return
+	}
 	scene := h.Handler.GetStoreLimitScene(typeValue)
 	h.rd.JSON(w, http.StatusOK, scene)
 }
@@ -634,3 +642,20 @@ func parseStoreLimitType(typeName string) (storelimit.Type, error) {
 	}
 	return typeValue, err
 }
+
+// limitRateFromInput converts the rate of the API to the rate of the store
+// limit. The rate of the operator count limits is per minute, and the rate of
+// the byte limits is in MB/s.
+func limitRateFromInput(rate float64, typeValue storelimit.Type) float64 {
+	if typeValue.IsByteType() {
+		return rate
+	}
+	return rate / schedule.StoreBalanceBaseTime
+}
+
+func limitRateToOutput(rate float64, typeValue storelimit.Type) float64 {
+	if typeValue.IsByteType() {
+		return rate
+	}
+	return rate * schedule.StoreBalanceBaseTime
+}
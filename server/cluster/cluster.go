@@ -39,6 +39,7 @@ import (
 	"github.com/pingcap/pd/v4/server/replication"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/schedule/storelimit"
@@ -105,14 +106,18 @@ type RaftCluster struct {
 	quit         chan struct{}
 	regionSyncer *syncer.RegionSyncer
 
-	ruleManager *placement.RuleManager
-	etcdClient  *clientv3.Client
-	httpClient  *http.Client
+	ruleManager   *placement.RuleManager
+	regionLabeler *labeler.RegionLabeler
+	etcdClient    *clientv3.Client
+	httpClient    *http.Client
 
 	replicationMode *replication.ModeManager
 
 	// It's used to manage components.
 	componentManager *component.Manager
+
+	// dryRunning is set while a dry run is running.
+	dryRunning int32
 }
 
 // Status saves some state information.
@@ -216,12 +221,17 @@ func (c *RaftCluster) Start(s Server) error {
 
 	c.ruleManager = placement.NewRuleManager(c.storage)
 	if c.IsPlacementRulesEnabled() {
-		err = c.ruleManager.Initialize(c.opt.GetMaxReplicas(), c.opt.GetLocationLabels())
+		err = c.ruleManager.Initialize(c.opt.GetMaxReplicas(), c.opt.GetLocationLabels(), c.opt.GetIsolationLevel())
 		if err != nil {
 			return err
 		}
 	}
 
+	c.regionLabeler, err = labeler.NewRegionLabeler(c.storage)
+	if err != nil {
+		return err
+	}
+
 	c.componentManager = component.NewManager(c.storage)
 	_, err = c.storage.LoadComponent(&c.componentManager)
 	if err != nil {
@@ -234,7 +244,7 @@ func (c *RaftCluster) Start(s Server) error {
 	}
 
 	c.coordinator = newCoordinator(c.ctx, cluster, s.GetHBStreams())
-	c.regionStats = statistics.NewRegionStatistics(c.opt)
+	c.regionStats = statistics.NewRegionStatistics(c.opt, c.ruleManager, c.core)
 	c.limiter = NewStoreLimiter(c.coordinator.opController)
 	c.quit = make(chan struct{})
 
@@ -305,6 +315,7 @@ func (c *RaftCluster) runBackgroundJobs(interval time.Duration) {
 			c.checkStores()
 			c.collectMetrics()
 			c.coordinator.opController.PruneHistory()
+			c.GetRegionLabeler().CheckAndClearExpiredRules()
 		}
 	}
 }
@@ -370,6 +381,13 @@ func (c *RaftCluster) GetRegionScatter() *schedule.RegionScatterer {
 	return c.coordinator.regionScatterer
 }
 
+// GetRegionSplitter returns the region splitter.
+func (c *RaftCluster) GetRegionSplitter() *schedule.RegionSplitter {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.regionSplitter
+}
+
 // GetHeartbeatStreams returns the heartbeat streams.
 func (c *RaftCluster) GetHeartbeatStreams() opt.HeartbeatStreams {
 	c.RLock()
@@ -422,7 +440,16 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 	if store == nil {
 		return core.NewStoreNotFoundErr(storeID)
 	}
-	newStore := store.Clone(core.SetStoreStats(stats), core.SetLastHeartbeatTS(time.Now()))
+	c.storesStats.Observe(storeID, stats)
+	// The reads are served by the leaders, so the read rate of the store
+	// averaged over time is used as the load of its leaders. The writes are
+	// excluded, the written keys of a store are counted when they are applied
+	// by all the peers, so transferring leaders doesn't move them.
+	newStore := store.Clone(
+		core.SetStoreStats(stats),
+		core.SetLastHeartbeatTS(time.Now()),
+		core.SetLeaderLoad(c.storesStats.GetStoreKeysReadAvgRate(storeID)),
+	)
 	if newStore.IsLowSpace(c.GetLowSpaceRatio()) {
 		log.Warn("store does not have enough disk space",
 			zap.Uint64("store-id", newStore.GetID()),
@@ -437,7 +464,6 @@ func (c *RaftCluster) HandleStoreHeartbeat(stats *pdpb.StoreStats) error {
 		}
 	}
 	c.core.PutStore(newStore)
-	c.storesStats.Observe(newStore.GetID(), newStore.GetStoreStats())
 	c.storesStats.UpdateTotalBytesRate(c.core.GetStores)
 	c.storesStats.UpdateTotalKeysRate(c.core.GetStores)
 
@@ -769,6 +795,22 @@ func (c *RaftCluster) GetStoreRegionCount(storeID uint64) int {
 	return c.core.GetStoreRegionCount(storeID)
 }
 
+// GetStoreLeaderCount returns the number of leaders for a given store.
+func (c *RaftCluster) GetStoreLeaderCount(storeID uint64) int {
+	return c.core.GetStoreLeaderCount(storeID)
+}
+
+// GetStoreLearnerCount returns the number of learners for a given store.
+func (c *RaftCluster) GetStoreLearnerCount(storeID uint64) int {
+	return c.core.GetStoreLearnerCount(storeID)
+}
+
+// GetStoreLearnerRegionSize returns the total size of learner regions for a
+// given store.
+func (c *RaftCluster) GetStoreLearnerRegionSize(storeID uint64) int64 {
+	return c.core.GetStoreLearnerRegionSize(storeID)
+}
+
 // GetAverageRegionSize returns the average region approximate size.
 func (c *RaftCluster) GetAverageRegionSize() int64 {
 	return c.core.GetAverageRegionSize()
@@ -959,6 +1001,7 @@ func (c *RaftCluster) RemoveStore(storeID uint64) error {
 	if err == nil {
 		// set the remove peer limit of the store to unlimited
 		c.coordinator.opController.SetStoreLimit(store.GetID(), storelimit.Unlimited, storelimit.Manual, storelimit.RegionRemove)
+		c.coordinator.opController.SetStoreLimit(store.GetID(), storelimit.Unlimited, storelimit.Manual, storelimit.RegionRemoveBytes)
 	}
 	return err
 }
@@ -1213,11 +1256,25 @@ func (c *RaftCluster) GetRegionStatsByType(typ statistics.RegionStatisticType) [
 	return c.regionStats.GetRegionStatsByType(typ)
 }
 
+// updateRegionsLabelLevelStats updates the label level statistics and the
+// isolation statistics of the regions. The isolation is checked before taking
+// the lock since it may fit the regions to the placement rules.
 func (c *RaftCluster) updateRegionsLabelLevelStats(regions []*core.RegionInfo) {
+	var underIsolation []bool
+	if c.regionStats != nil {
+		underIsolation = make([]bool, len(regions))
+		for i, region := range regions {
+			underIsolation[i] = c.regionStats.IsUnderIsolation(region, c.GetRegionStores(region))
+		}
+	}
+
 	c.Lock()
 	defer c.Unlock()
-	for _, region := range regions {
+	for i, region := range regions {
 		c.labelLevelStats.Observe(region, c.takeRegionStoresLocked(region), c.GetLocationLabels())
+		if underIsolation != nil {
+			c.regionStats.ObserveIsolation(region, underIsolation[i])
+		}
 	}
 }
 
@@ -1376,6 +1433,12 @@ func (c *RaftCluster) GetSchedulerMaxWaitingOperator() uint64 {
 	return c.opt.GetSchedulerMaxWaitingOperator()
 }
 
+// GetSchedulerQuota returns the max waiting operators and the promotion weight
+// of the scheduler creating the operators with the description.
+func (c *RaftCluster) GetSchedulerQuota(desc string) (uint64, float64) {
+	return c.opt.GetSchedulerQuota(desc)
+}
+
 // GetMaxSnapshotCount returns the number of the max snapshot which is allowed to send.
 func (c *RaftCluster) GetMaxSnapshotCount() uint64 {
 	return c.opt.GetMaxSnapshotCount()
@@ -1431,6 +1494,11 @@ func (c *RaftCluster) GetLocationLabels() []string {
 	return c.opt.GetLocationLabels()
 }
 
+// GetIsolationLevel returns the isolation level of regions.
+func (c *RaftCluster) GetIsolationLevel() string {
+	return c.opt.GetIsolationLevel()
+}
+
 // GetStrictlyMatchLabel returns if the strictly label check is enabled.
 func (c *RaftCluster) GetStrictlyMatchLabel() bool {
 	return c.opt.GetStrictlyMatchLabel()
@@ -1570,6 +1638,14 @@ func (c *RaftCluster) GetRuleManager() *placement.RuleManager {
 	return c.ruleManager
 }
 
+// GetRegionLabeler returns the region labeler. It is called by the checkers
+// and schedulers which may already hold the cluster lock, so it does not take
+// the lock. The labeler is set in Start before the cluster runs, and it is
+// never changed while the cluster is running.
+func (c *RaftCluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return c.regionLabeler
+}
+
 // FitRegion tries to fit the region with placement rules.
 func (c *RaftCluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
 	return c.GetRuleManager().FitRegion(c, region)
@@ -1663,6 +1739,20 @@ func (c *RaftCluster) PauseOrResumeScheduler(name string, t int64) error {
 	return c.coordinator.pauseOrResumeScheduler(name, t)
 }
 
+// SetActiveWindow sets the active window of a scheduler or a checker.
+func (c *RaftCluster) SetActiveWindow(name string, w *schedule.ActiveWindow) error {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.setActiveWindow(name, w)
+}
+
+// GetActiveWindow returns the active window of a scheduler or a checker.
+func (c *RaftCluster) GetActiveWindow(name string) (*schedule.ActiveWindow, error) {
+	c.RLock()
+	defer c.RUnlock()
+	return c.coordinator.getActiveWindow(name)
+}
+
 // GetStoreLimiter returns the dynamic adjusting limiter
 func (c *RaftCluster) GetStoreLimiter() *StoreLimiter {
 	return c.limiter
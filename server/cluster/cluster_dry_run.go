@@ -0,0 +1,79 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"sync/atomic"
+
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pkg/errors"
+)
+
+// newSandboxCluster creates a sandbox on the snapshot of the cluster. If cfg
+// is nil, the current schedule config is used. Taking the snapshot copies
+// all the regions with the read lock of the cluster held, which delays the
+// region heartbeats of a large cluster for a while.
+func (c *RaftCluster) newSandboxCluster(cfg *config.ScheduleConfig) *schedule.SandboxCluster {
+	if cfg == nil {
+		cfg = c.opt.GetScheduleConfig().Clone()
+	}
+	// The merge checker in the sandbox is always newly created, ignore the
+	// split merge interval or it never merges any region.
+	cfg.SplitMergeInterval = typeutil.NewDuration(0)
+	opts := config.NewPersistOptions(&config.Config{
+		Schedule:        *cfg,
+		Replication:     *c.opt.GetReplicationConfig(),
+		PDServerCfg:     *c.opt.GetPDServerConfig(),
+		ReplicationMode: *c.opt.GetReplicationModeConfig(),
+		LabelProperty:   c.opt.GetLabelPropertyConfig(),
+		ClusterVersion:  *c.opt.GetClusterVersion(),
+	})
+	return schedule.NewSandboxCluster(c, c.core.Clone(), c.GetRuleManager(), opts)
+}
+
+// startDryRun returns an error if there is another dry run, only one dry run
+// is allowed at a time to bound the cost of the snapshots. The returned
+// function should be called when the dry run is finished.
+func (c *RaftCluster) startDryRun() (func(), error) {
+	if !atomic.CompareAndSwapInt32(&c.dryRunning, 0, 1) {
+		return nil, errors.New("another dry run is running")
+	}
+	return func() { atomic.StoreInt32(&c.dryRunning, 0) }, nil
+}
+
+// DryRunScheduler runs the scheduler with the type and args on a snapshot of
+// the cluster for several rounds, and returns the operators it would create.
+// If cfg is not nil, it is used instead of the current schedule config.
+func (c *RaftCluster) DryRunScheduler(typ string, args []string, cfg *config.ScheduleConfig, rounds int) (*schedule.DryRunResult, error) {
+	finish, err := c.startDryRun()
+	if err != nil {
+		return nil, err
+	}
+	defer finish()
+	return schedule.DryRunScheduler(c.ctx, c.newSandboxCluster(cfg), typ, args, rounds)
+}
+
+// DryRunCheckers runs the checkers on a snapshot of the cluster for several
+// rounds, and returns the operators they would create. If cfg is not nil, it
+// is used instead of the current schedule config.
+func (c *RaftCluster) DryRunCheckers(cfg *config.ScheduleConfig, rounds int) (*schedule.DryRunResult, error) {
+	finish, err := c.startDryRun()
+	if err != nil {
+		return nil, err
+	}
+	defer finish()
+	return schedule.DryRunCheckers(c.ctx, c.newSandboxCluster(cfg), c.GetRuleManager(), rounds)
+}
@@ -197,6 +197,7 @@ func (c *RaftCluster) HandleReportSplit(request *pdpb.ReportSplitRequest) (*pdpb
 	log.Info("region split, generate new region",
 		zap.Uint64("region-id", originRegion.GetId()),
 		zap.Stringer("region-meta", core.RegionToHexMeta(left)))
+	c.notifySplit(originRegion.GetId(), []uint64{left.GetId()})
 	return &pdpb.ReportSplitResponse{}, nil
 }
 
@@ -219,5 +220,21 @@ func (c *RaftCluster) HandleBatchReportSplit(request *pdpb.ReportBatchSplitReque
 		zap.Uint64("region-id", originRegion.GetId()),
 		zap.Stringer("origin", hrm),
 		zap.Int("total", last))
+	newRegionIDs := make([]uint64, 0, last)
+	for _, region := range regions[:last] {
+		newRegionIDs = append(newRegionIDs, region.GetId())
+	}
+	c.notifySplit(originRegion.GetId(), newRegionIDs)
 	return &pdpb.ReportBatchSplitResponse{}, nil
 }
+
+// notifySplit notifies the region splitter of the regions generated by the
+// split.
+func (c *RaftCluster) notifySplit(regionID uint64, newRegionIDs []uint64) {
+	c.RLock()
+	co := c.coordinator
+	c.RUnlock()
+	if co != nil {
+		co.regionSplitter.OnSplitReported(regionID, newRegionIDs)
+	}
+}
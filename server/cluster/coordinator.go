@@ -59,6 +59,7 @@ type coordinator struct {
 	cluster         *RaftCluster
 	checkers        *schedule.CheckerController
 	regionScatterer *schedule.RegionScatterer
+	regionSplitter  *schedule.RegionSplitter
 	schedulers      map[string]*scheduleController
 	opController    *schedule.OperatorController
 	hbStreams       opt.HeartbeatStreams
@@ -69,12 +70,16 @@ type coordinator struct {
 func newCoordinator(ctx context.Context, cluster *RaftCluster, hbStreams opt.HeartbeatStreams) *coordinator {
 	ctx, cancel := context.WithCancel(ctx)
 	opController := schedule.NewOperatorController(ctx, cluster, hbStreams)
+	if cluster.storage != nil {
+		opController.SetOperatorRecordStorage(schedule.NewOperatorRecordStorage(ctx, cluster.storage))
+	}
 	return &coordinator{
 		ctx:             ctx,
 		cancel:          cancel,
 		cluster:         cluster,
 		checkers:        schedule.NewCheckerController(ctx, cluster, cluster.ruleManager, opController),
 		regionScatterer: schedule.NewRegionScatterer(cluster),
+		regionSplitter:  schedule.NewRegionSplitter(cluster, opController),
 		schedulers:      make(map[string]*scheduleController),
 		opController:    opController,
 		hbStreams:       hbStreams,
@@ -126,7 +131,7 @@ func (c *coordinator) patrolRegions() {
 				c.opController.AddWaitingOperator(ops...)
 			}
 		}
-		// Updates the label level isolation statistics.
+		// Updates the label level and the region isolation statistics.
 		c.cluster.updateRegionsLabelLevelStats(regions)
 		if len(key) == 0 {
 			patrolCheckRegionsHistogram.Observe(time.Since(start).Seconds())
@@ -251,6 +256,15 @@ func (c *coordinator) run() {
 		log.Error("cannot persist schedule config", zap.Error(err))
 	}
 
+	for _, name := range schedule.CheckerNames {
+		w, err := schedule.LoadActiveWindow(c.cluster.storage, name)
+		if err != nil {
+			log.Error("can not load the active window of checker", zap.String("checker-name", name), zap.Error(err))
+			continue
+		}
+		c.checkers.SetActiveWindow(name, w)
+	}
+
 	c.wg.Add(2)
 	// Starts to patrol regions.
 	go c.patrolRegions()
@@ -464,6 +478,11 @@ func (c *coordinator) addScheduler(scheduler schedule.Scheduler, args ...string)
 	}
 
 	s := newScheduleController(c, scheduler)
+	w, err := schedule.LoadActiveWindow(c.cluster.storage, s.GetName())
+	if err != nil {
+		log.Error("can not load the active window of scheduler", zap.String("scheduler-name", s.GetName()), zap.Error(err))
+	}
+	s.setActiveWindow(w)
 	if err := s.Prepare(c.cluster); err != nil {
 		return err
 	}
@@ -500,6 +519,8 @@ func (c *coordinator) removeScheduler(name string) error {
 		err = c.cluster.storage.RemoveScheduleConfig(name)
 		if err != nil {
 			log.Error("can not remove the scheduler config", zap.Error(err))
+		} else if err = schedule.SaveActiveWindow(c.cluster.storage, name, nil); err != nil {
+			log.Error("can not remove the active window of scheduler", zap.Error(err))
 		}
 	}
 	return err
@@ -534,6 +555,46 @@ func (c *coordinator) pauseOrResumeScheduler(name string, t int64) error {
 	return err
 }
 
+// setActiveWindow sets the active window of a scheduler or a checker, a nil
+// window means it is always active.
+func (c *coordinator) setActiveWindow(name string, w *schedule.ActiveWindow) error {
+	c.Lock()
+	defer c.Unlock()
+	if c.cluster == nil {
+		return ErrNotBootstrapped
+	}
+	sc, ok := c.schedulers[name]
+	if !ok && !schedule.IsCheckerName(name) {
+		return schedulers.ErrSchedulerNotFound
+	}
+	if err := schedule.SaveActiveWindow(c.cluster.storage, name, w); err != nil {
+		return err
+	}
+	if ok {
+		sc.setActiveWindow(w)
+	} else {
+		c.checkers.SetActiveWindow(name, w)
+	}
+	log.Info("set the active window", zap.String("name", name), zap.Stringer("active-window", w))
+	return nil
+}
+
+// getActiveWindow returns the active window of a scheduler or a checker.
+func (c *coordinator) getActiveWindow(name string) (*schedule.ActiveWindow, error) {
+	c.RLock()
+	defer c.RUnlock()
+	if c.cluster == nil {
+		return nil, ErrNotBootstrapped
+	}
+	if sc, ok := c.schedulers[name]; ok {
+		return sc.GetActiveWindow(), nil
+	}
+	if schedule.IsCheckerName(name) {
+		return c.checkers.GetActiveWindow(name), nil
+	}
+	return nil, schedulers.ErrSchedulerNotFound
+}
+
 func (c *coordinator) runScheduler(s *scheduleController) {
 	defer logutil.LogPanic()
 	defer c.wg.Done()
@@ -542,16 +603,32 @@ func (c *coordinator) runScheduler(s *scheduleController) {
 	timer := time.NewTimer(s.GetInterval())
 	defer timer.Stop()
 
+	active := true
 	for {
 		select {
 		case <-timer.C:
 			timer.Reset(s.GetInterval())
+			if isActive := s.IsActive(); isActive != active {
+				active = isActive
+				log.Info("scheduler active window changes",
+					zap.String("scheduler-name", s.GetName()),
+					zap.Bool("active", active),
+					zap.Stringer("active-window", s.GetActiveWindow()))
+			}
 			if !s.AllowSchedule() {
 				continue
 			}
 			if op := s.Schedule(); op != nil {
 				added := c.opController.AddWaitingOperator(op...)
 				log.Debug("add operator", zap.Int("added", added), zap.Int("total", len(op)), zap.String("scheduler", s.GetName()))
+				for i := 1; i < s.GetBatch() && s.AllowSchedule(); i++ {
+					op = s.Scheduler.Schedule(c.cluster)
+					if op == nil {
+						break
+					}
+					added = c.opController.AddWaitingOperator(op...)
+					log.Debug("add operator in batch", zap.Int("added", added), zap.Int("total", len(op)), zap.String("scheduler", s.GetName()))
+				}
 			}
 
 		case <-s.Ctx().Done():
@@ -572,6 +649,7 @@ type scheduleController struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 	delayUntil   int64
+	activeWindow atomic.Value // *schedule.ActiveWindow
 }
 
 // newScheduleController creates a new scheduleController.
@@ -607,6 +685,14 @@ func (s *scheduleController) Schedule() []*operator.Operator {
 	return nil
 }
 
+// GetBatch returns the max times a scheduler schedules in a round.
+func (s *scheduleController) GetBatch() int {
+	if bs, ok := s.Scheduler.(schedule.BatchScheduler); ok {
+		return bs.GetBatch()
+	}
+	return 1
+}
+
 // GetInterval returns the interval of scheduling for a scheduler.
 func (s *scheduleController) GetInterval() time.Duration {
 	return s.nextInterval
@@ -614,7 +700,23 @@ func (s *scheduleController) GetInterval() time.Duration {
 
 // AllowSchedule returns if a scheduler is allowed to schedule.
 func (s *scheduleController) AllowSchedule() bool {
-	return s.Scheduler.IsScheduleAllowed(s.cluster) && !s.IsPaused()
+	return s.Scheduler.IsScheduleAllowed(s.cluster) && !s.IsPaused() && s.IsActive()
+}
+
+func (s *scheduleController) setActiveWindow(w *schedule.ActiveWindow) {
+	s.activeWindow.Store(w)
+}
+
+// GetActiveWindow returns the active window of the scheduler, nil means the
+// scheduler is always active.
+func (s *scheduleController) GetActiveWindow() *schedule.ActiveWindow {
+	w, _ := s.activeWindow.Load().(*schedule.ActiveWindow)
+	return w
+}
+
+// IsActive returns if the scheduler is in its active window now.
+func (s *scheduleController) IsActive() bool {
+	return s.GetActiveWindow().IsActive(time.Now())
 }
 
 // isPaused returns if a schedueler is paused.
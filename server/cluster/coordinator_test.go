@@ -240,7 +240,7 @@ func dispatchHeartbeat(co *coordinator, region *core.RegionInfo, stream opt.Hear
 
 func (s *testCoordinatorSuite) TestCollectMetrics(c *C) {
 	tc, co, cleanup := prepare(nil, func(tc *testCluster) {
-		tc.regionStats = statistics.NewRegionStatistics(tc.GetOpt())
+		tc.regionStats = statistics.NewRegionStatistics(tc.GetOpt(), nil, nil)
 	}, func(co *coordinator) { co.run() }, c)
 	defer cleanup()
 
@@ -775,6 +775,58 @@ func (s *testCoordinatorSuite) TestRemoveScheduler(c *C) {
 	co.wg.Wait()
 }
 
+func (s *testCoordinatorSuite) TestActiveWindow(c *C) {
+	tc, co, cleanup := prepare(nil, nil, func(co *coordinator) { co.run() }, c)
+	hbStreams := co.hbStreams
+	defer cleanup()
+
+	c.Assert(tc.addRegionStore(4, 4), IsNil)
+	c.Assert(tc.addRegionStore(3, 3), IsNil)
+	c.Assert(tc.addRegionStore(2, 2), IsNil)
+	c.Assert(tc.addRegionStore(1, 1), IsNil)
+	c.Assert(tc.addLeaderRegion(1, 2, 3), IsNil)
+
+	never, err := schedule.ParseActiveWindow("except 00:00-24:00")
+	c.Assert(err, IsNil)
+	c.Assert(co.setActiveWindow("unknown-scheduler", never), Equals, schedulers.ErrSchedulerNotFound)
+
+	// The scheduler is not allowed to schedule out of the window.
+	sc := co.schedulers[schedulers.BalanceRegionName]
+	c.Assert(sc.IsActive(), IsTrue)
+	c.Assert(co.setActiveWindow(schedulers.BalanceRegionName, never), IsNil)
+	c.Assert(sc.IsActive(), IsFalse)
+	c.Assert(sc.AllowSchedule(), IsFalse)
+	w, err := co.getActiveWindow(schedulers.BalanceRegionName)
+	c.Assert(err, IsNil)
+	c.Assert(w.String(), Equals, "except 00:00-24:00")
+
+	// The checker does not check regions out of the window.
+	c.Assert(co.setActiveWindow(schedule.ReplicaCheckerName, never), IsNil)
+	s.checkRegion(c, tc, co, 1, false, 0)
+	c.Assert(co.setActiveWindow(schedule.ReplicaCheckerName, nil), IsNil)
+	s.checkRegion(c, tc, co, 1, false, 1)
+	c.Assert(co.setActiveWindow(schedule.MergeCheckerName, never), IsNil)
+	co.stop()
+	co.wg.Wait()
+
+	// The windows are loaded after restart.
+	co = newCoordinator(s.ctx, tc.RaftCluster, hbStreams)
+	co.run()
+	c.Assert(co.schedulers, HasLen, 4)
+	c.Assert(co.schedulers[schedulers.BalanceRegionName].IsActive(), IsFalse)
+	c.Assert(co.schedulers[schedulers.BalanceLeaderName].IsActive(), IsTrue)
+	c.Assert(co.checkers.IsCheckerActive(schedule.MergeCheckerName, time.Now()), IsFalse)
+	c.Assert(co.checkers.IsCheckerActive(schedule.ReplicaCheckerName, time.Now()), IsTrue)
+
+	// The window is removed along with the scheduler.
+	c.Assert(co.removeScheduler(schedulers.BalanceRegionName), IsNil)
+	w, err = schedule.LoadActiveWindow(tc.storage, schedulers.BalanceRegionName)
+	c.Assert(err, IsNil)
+	c.Assert(w, IsNil)
+	co.stop()
+	co.wg.Wait()
+}
+
 func (s *testCoordinatorSuite) TestRestart(c *C) {
 	tc, co, cleanup := prepare(func(cfg *config.ScheduleConfig) {
 		// Turn off balance, we test add replica only.
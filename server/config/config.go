@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/pkg/grpcutil"
 	"github.com/pingcap/pd/v4/pkg/metricutil"
+	"github.com/pingcap/pd/v4/pkg/tsoutil"
 	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pkg/errors"
@@ -80,6 +81,23 @@ type Config struct {
 	// TsoSaveInterval is the interval to save timestamp.
 	TsoSaveInterval typeutil.Duration `toml:"tso-save-interval" json:"tso-save-interval"`
 
+	// DCLocation is the data center of the PD member. The members in the same
+	// data center elect a leader to serve the local TSO of the data center.
+	// The local TSO is disabled if it is empty.
+	DCLocation string `toml:"dc-location" json:"dc-location"`
+
+	// EnableTSOFollowerProxy allows the member to proxy the TSO requests to
+	// the leader when it is a follower. The clients need to enable the follower
+	// proxy too.
+	EnableTSOFollowerProxy bool `toml:"enable-tso-follower-proxy" json:"enable-tso-follower-proxy"`
+
+	// TSOSuffixBits is the number of the lowest bits of the logical part of
+	// the timestamps reserved for TSOSuffix, which makes the timestamps of
+	// the clusters with different suffixes distinguishable. All the members
+	// of a cluster should have the same suffix.
+	TSOSuffixBits int   `toml:"tso-suffix-bits" json:"tso-suffix-bits"`
+	TSOSuffix     int64 `toml:"tso-suffix" json:"tso-suffix"`
+
 	Metric metricutil.MetricConfig `toml:"metric" json:"metric"`
 
 	Schedule ScheduleConfig `toml:"schedule" json:"schedule"`
@@ -327,6 +345,15 @@ func (c *Config) Validate() error {
 	if !strings.HasPrefix(rel, "..") {
 		return errors.New("log directory shouldn't be the subdirectory of data directory")
 	}
+	if c.DCLocation == "global" || strings.Contains(c.DCLocation, "/") {
+		return errors.Errorf("invalid dc-location %q", c.DCLocation)
+	}
+	if c.TSOSuffixBits < 0 || c.TSOSuffixBits > tsoutil.MaxSuffixBits {
+		return errors.Errorf("tso-suffix-bits should be in [0, %d]", tsoutil.MaxSuffixBits)
+	}
+	if c.TSOSuffix < 0 || c.TSOSuffix >= 1<<uint(c.TSOSuffixBits) {
+		return errors.Errorf("tso-suffix should be in [0, %d)", 1<<uint(c.TSOSuffixBits))
+	}
 
 	return nil
 }
@@ -519,7 +546,7 @@ type ScheduleConfig struct {
 	MaxStoreDownTime typeutil.Duration `toml:"max-store-down-time" json:"max-store-down-time"`
 	// LeaderScheduleLimit is the max coexist leader schedules.
 	LeaderScheduleLimit uint64 `toml:"leader-schedule-limit" json:"leader-schedule-limit"`
-	// LeaderSchedulePolicy is the option to balance leader, there are some policies supported: ["count", "size"], default: "count"
+	// LeaderSchedulePolicy is the option to balance leader, there are some policies supported: ["count", "size", "load"], default: "count"
 	LeaderSchedulePolicy string `toml:"leader-schedule-policy" json:"leader-schedule-policy"`
 	// RegionScheduleLimit is the max coexist region schedules.
 	RegionScheduleLimit uint64 `toml:"region-schedule-limit" json:"region-schedule-limit"`
@@ -551,6 +578,10 @@ type ScheduleConfig struct {
 	HighSpaceRatio float64 `toml:"high-space-ratio" json:"high-space-ratio"`
 	// SchedulerMaxWaitingOperator is the max coexist operators for each scheduler.
 	SchedulerMaxWaitingOperator uint64 `toml:"scheduler-max-waiting-operator" json:"scheduler-max-waiting-operator"`
+	// SchedulerQuotas is the quotas of the waiting operators of the schedulers
+	// and checkers. The key is the description of the operators they create,
+	// such as "balance-region" and "replace-offline-replica".
+	SchedulerQuotas map[string]SchedulerQuota `toml:"scheduler-quotas" json:"scheduler-quotas"`
 	// WARN: DisableLearner is deprecated.
 	// DisableLearner is the option to disable using AddLearnerNode instead of AddNode.
 	DisableLearner bool `toml:"disable-raft-learner" json:"disable-raft-learner,string,omitempty"`
@@ -607,6 +638,13 @@ type ScheduleConfig struct {
 func (c *ScheduleConfig) Clone() *ScheduleConfig {
 	schedulers := make(SchedulerConfigs, len(c.Schedulers))
 	copy(schedulers, c.Schedulers)
+	var quotas map[string]SchedulerQuota
+	if c.SchedulerQuotas != nil {
+		quotas = make(map[string]SchedulerQuota, len(c.SchedulerQuotas))
+		for desc, quota := range c.SchedulerQuotas {
+			quotas[desc] = quota
+		}
+	}
 	return &ScheduleConfig{
 		MaxSnapshotCount:             c.MaxSnapshotCount,
 		MaxPendingPeerCount:          c.MaxPendingPeerCount,
@@ -629,6 +667,7 @@ func (c *ScheduleConfig) Clone() *ScheduleConfig {
 		LowSpaceRatio:                c.LowSpaceRatio,
 		HighSpaceRatio:               c.HighSpaceRatio,
 		SchedulerMaxWaitingOperator:  c.SchedulerMaxWaitingOperator,
+		SchedulerQuotas:              quotas,
 		DisableLearner:               c.DisableLearner,
 		DisableRemoveDownReplica:     c.DisableRemoveDownReplica,
 		DisableReplaceOfflineReplica: c.DisableReplaceOfflineReplica,
@@ -788,6 +827,11 @@ func (c *ScheduleConfig) Validate() error {
 	if c.LowSpaceRatio <= c.HighSpaceRatio {
 		return errors.New("low-space-ratio should be larger than high-space-ratio")
 	}
+	for desc, quota := range c.SchedulerQuotas {
+		if quota.Weight < 0 {
+			return errors.Errorf("weight of scheduler quota %s should be nonnegative", desc)
+		}
+	}
 	for _, scheduleConfig := range c.Schedulers {
 		if !schedule.IsSchedulerRegistered(scheduleConfig.Type) {
 			return errors.Errorf("create func of %v is not registered, maybe misspelled", scheduleConfig.Type)
@@ -848,6 +892,16 @@ type SchedulerConfig struct {
 	ArgsPayload string   `toml:"args-payload" json:"args-payload"`
 }
 
+// SchedulerQuota is the quota of the waiting operators of a scheduler.
+type SchedulerQuota struct {
+	// MaxWaitingOperator replaces scheduler-max-waiting-operator for the
+	// scheduler if it is not 0.
+	MaxWaitingOperator uint64 `toml:"max-waiting-operator" json:"max-waiting-operator"`
+	// Weight is the share of the scheduler when the waiting operators of the
+	// same priority are promoted, it is 1 if not set.
+	Weight float64 `toml:"weight" json:"weight"`
+}
+
 var defaultSchedulers = SchedulerConfigs{
 	{Type: "balance-region"},
 	{Type: "balance-leader"},
@@ -877,6 +931,15 @@ type ReplicationConfig struct {
 	LocationLabels typeutil.StringSlice `toml:"location-labels" json:"location-labels"`
 	// StrictlyMatchLabel strictly checks if the label of TiKV is matched with LocationLabels.
 	StrictlyMatchLabel bool `toml:"strictly-match-label" json:"strictly-match-label,string"`
+	// IsolationLevel is used to isolate replicas explicitly and forcibly if it's not empty.
+	// Its value must be empty or one of LocationLabels.
+	// Example:
+	// location-labels = ["zone", "rack", "host"]
+	// isolation-level = "zone"
+	// With configuration like above, PD ensure that all replicas be placed in different zones.
+	// Even if a zone is down, PD will not try to make up replicas in other zone
+	// because other zones already have replicas on it.
+	IsolationLevel string `toml:"isolation-level" json:"isolation-level"`
 
 	// When PlacementRules feature is enabled. MaxReplicas and LocationLabels are not uesd any more.
 	EnablePlacementRules bool `toml:"enable-placement-rules" json:"enable-placement-rules,string"`
@@ -889,17 +952,26 @@ func (c *ReplicationConfig) clone() *ReplicationConfig {
 		MaxReplicas:          c.MaxReplicas,
 		LocationLabels:       locationLabels,
 		StrictlyMatchLabel:   c.StrictlyMatchLabel,
+		IsolationLevel:       c.IsolationLevel,
 		EnablePlacementRules: c.EnablePlacementRules,
 	}
 }
 
 // Validate is used to validate if some replication configurations are right.
 func (c *ReplicationConfig) Validate() error {
+	foundIsolationLevel := false
 	for _, label := range c.LocationLabels {
 		err := ValidateLabels([]*metapb.StoreLabel{{Key: label}})
 		if err != nil {
 			return err
 		}
+		// IsolationLevel should be empty or one of LocationLabels
+		if !foundIsolationLevel && label == c.IsolationLevel {
+			foundIsolationLevel = true
+		}
+	}
+	if c.IsolationLevel != "" && !foundIsolationLevel {
+		return errors.New("isolation-level must be one of location-labels or empty")
 	}
 	return nil
 }
@@ -99,6 +99,12 @@ func (s *testConfigSuite) TestValidation(c *C) {
 	c.Assert(cfg.Schedule.Validate(), IsNil)
 	cfg.Schedule.TolerantSizeRatio = -0.6
 	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.TolerantSizeRatio = 0
+	cfg.Schedule.SchedulerQuotas = map[string]SchedulerQuota{"balance-region": {Weight: -1}}
+	c.Assert(cfg.Schedule.Validate(), NotNil)
+	cfg.Schedule.SchedulerQuotas["balance-region"] = SchedulerQuota{MaxWaitingOperator: 10, Weight: 2}
+	c.Assert(cfg.Schedule.Validate(), IsNil)
+	c.Assert(cfg.Schedule.Clone().SchedulerQuotas, DeepEquals, cfg.Schedule.SchedulerQuotas)
 	// check quota
 	c.Assert(cfg.QuotaBackendBytes, Equals, defaultQuotaBackendBytes)
 }
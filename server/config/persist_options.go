@@ -121,6 +121,11 @@ func (o *PersistOptions) GetLocationLabels() []string {
 	return o.GetReplicationConfig().LocationLabels
 }
 
+// GetIsolationLevel returns the isolation label for each region.
+func (o *PersistOptions) GetIsolationLevel() string {
+	return o.GetReplicationConfig().IsolationLevel
+}
+
 // IsPlacementRulesEnabled returns if the placement rules is enabled.
 func (o *PersistOptions) IsPlacementRulesEnabled() bool {
 	return o.GetReplicationConfig().EnablePlacementRules
@@ -250,6 +255,22 @@ func (o *PersistOptions) GetSchedulerMaxWaitingOperator() uint64 {
 	return o.GetScheduleConfig().SchedulerMaxWaitingOperator
 }
 
+// GetSchedulerQuota returns the max waiting operators and the promotion weight
+// of the scheduler creating the operators with the description.
+func (o *PersistOptions) GetSchedulerQuota(desc string) (maxWaitingOperator uint64, weight float64) {
+	cfg := o.GetScheduleConfig()
+	maxWaitingOperator, weight = cfg.SchedulerMaxWaitingOperator, 1
+	if quota, ok := cfg.SchedulerQuotas[desc]; ok {
+		if quota.MaxWaitingOperator > 0 {
+			maxWaitingOperator = quota.MaxWaitingOperator
+		}
+		if quota.Weight > 0 {
+			weight = quota.Weight
+		}
+	}
+	return
+}
+
 // GetLeaderSchedulePolicy is to get leader schedule policy.
 func (o *PersistOptions) GetLeaderSchedulePolicy() core.SchedulePolicy {
 	return core.StringToSchedulePolicy(o.GetScheduleConfig().LeaderSchedulePolicy)
@@ -38,6 +38,22 @@ func NewBasicCluster() *BasicCluster {
 	}
 }
 
+// Clone creates a snapshot of the BasicCluster. StoreInfo and RegionInfo are
+// shared since they are not modified in place, so updating the snapshot does
+// not affect the origin one.
+func (bc *BasicCluster) Clone() *BasicCluster {
+	bc.RLock()
+	defer bc.RUnlock()
+	snapshot := NewBasicCluster()
+	for _, store := range bc.Stores.GetStores() {
+		snapshot.Stores.SetStore(store)
+	}
+	for _, region := range bc.Regions.GetRegions() {
+		snapshot.Regions.SetRegion(region)
+	}
+	return snapshot
+}
+
 // GetStores returns all Stores in the cluster.
 func (bc *BasicCluster) GetStores() []*StoreInfo {
 	bc.RLock()
@@ -215,11 +231,11 @@ func (bc *BasicCluster) GetStoreCount() int {
 	return bc.Stores.GetStoreCount()
 }
 
-// GetStoreRegionCount gets the total count of a store's leader and follower RegionInfo by storeID.
+// GetStoreRegionCount gets the total count of a store's leader, follower, learner and witness RegionInfo by storeID.
 func (bc *BasicCluster) GetStoreRegionCount(storeID uint64) int {
 	bc.RLock()
 	defer bc.RUnlock()
-	return bc.Regions.GetStoreLeaderCount(storeID) + bc.Regions.GetStoreFollowerCount(storeID) + bc.Regions.GetStoreLearnerCount(storeID)
+	return bc.Regions.GetStoreRegionCount(storeID)
 }
 
 // GetStoreLeaderCount get the total count of a store's leader RegionInfo.
@@ -236,6 +252,20 @@ func (bc *BasicCluster) GetStoreFollowerCount(storeID uint64) int {
 	return bc.Regions.GetStoreFollowerCount(storeID)
 }
 
+// GetStoreLearnerCount get the total count of a store's learner RegionInfo.
+func (bc *BasicCluster) GetStoreLearnerCount(storeID uint64) int {
+	bc.RLock()
+	defer bc.RUnlock()
+	return bc.Regions.GetStoreLearnerCount(storeID)
+}
+
+// GetStoreLearnerRegionSize get total size of store's learner regions.
+func (bc *BasicCluster) GetStoreLearnerRegionSize(storeID uint64) int64 {
+	bc.RLock()
+	defer bc.RUnlock()
+	return bc.Regions.GetStoreLearnerRegionSize(storeID)
+}
+
 // GetStorePendingPeerCount gets the total count of a store's region that includes pending peer.
 func (bc *BasicCluster) GetStorePendingPeerCount(storeID uint64) int {
 	bc.RLock()
@@ -250,11 +280,11 @@ func (bc *BasicCluster) GetStoreLeaderRegionSize(storeID uint64) int64 {
 	return bc.Regions.GetStoreLeaderRegionSize(storeID)
 }
 
-// GetStoreRegionSize get total size of store's regions.
+// GetStoreRegionSize get total size of store's regions, witness regions are not counted.
 func (bc *BasicCluster) GetStoreRegionSize(storeID uint64) int64 {
 	bc.RLock()
 	defer bc.RUnlock()
-	return bc.Regions.GetStoreLeaderRegionSize(storeID) + bc.Regions.GetStoreFollowerRegionSize(storeID) + bc.Regions.GetStoreLearnerRegionSize(storeID)
+	return bc.Regions.GetStoreRegionSize(storeID)
 }
 
 // GetAverageRegionSize returns the average region approximate size.
@@ -371,6 +401,9 @@ type RegionSetInformer interface {
 	RandPendingRegion(storeID uint64, ranges []KeyRange, opts ...RegionOption) *RegionInfo
 	GetAverageRegionSize() int64
 	GetStoreRegionCount(storeID uint64) int
+	GetStoreLeaderCount(storeID uint64) int
+	GetStoreLearnerCount(storeID uint64) int
+	GetStoreLearnerRegionSize(storeID uint64) int64
 	GetRegion(id uint64) *RegionInfo
 	GetAdjacentRegions(region *RegionInfo) (*RegionInfo, *RegionInfo)
 	ScanRegions(startKey, endKey []byte, limit int) []*RegionInfo
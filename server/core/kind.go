@@ -21,8 +21,26 @@ const (
 	LowPriority PriorityLevel = iota
 	NormalPriority
 	HighPriority
+	// UrgentPriority is used by the operators repairing replicas, they can
+	// preempt the store limit of the lower priority operators.
+	UrgentPriority
 )
 
+// String implements fmt.Stringer.
+func (l PriorityLevel) String() string {
+	switch l {
+	case LowPriority:
+		return "low"
+	case NormalPriority:
+		return "normal"
+	case HighPriority:
+		return "high"
+	case UrgentPriority:
+		return "urgent"
+	}
+	return "unknown"
+}
+
 // ScheduleKind distinguishes resources and schedule policy.
 type ScheduleKind struct {
 	Resource ResourceKind
@@ -66,6 +84,8 @@ const (
 	ByCount SchedulePolicy = iota
 	// BySize indicates that balance by size
 	BySize
+	// ByLoad indicates that balance by the read load of leaders
+	ByLoad
 )
 
 func (k SchedulePolicy) String() string {
@@ -74,6 +94,8 @@ func (k SchedulePolicy) String() string {
 		return "count"
 	case BySize:
 		return "size"
+	case ByLoad:
+		return "load"
 	default:
 		return "unknown"
 	}
@@ -86,6 +108,8 @@ func StringToSchedulePolicy(input string) SchedulePolicy {
 		return BySize
 	case ByCount.String():
 		return ByCount
+	case ByLoad.String():
+		return ByLoad
 	default:
 		panic("invalid schedule policy: " + input)
 	}
@@ -34,6 +34,7 @@ type RegionInfo struct {
 	meta              *metapb.Region
 	learners          []*metapb.Peer
 	voters            []*metapb.Peer
+	witnesses         map[uint64]struct{} // IDs of voters that store no data and never become leader
 	leader            *metapb.Peer
 	downPeers         []*pdpb.PeerStats
 	pendingPeers      []*metapb.Peer
@@ -122,6 +123,7 @@ func (r *RegionInfo) Clone(opts ...RegionCreateOption) *RegionInfo {
 	region := &RegionInfo{
 		meta:              proto.Clone(r.meta).(*metapb.Region),
 		leader:            proto.Clone(r.leader).(*metapb.Peer),
+		witnesses:         r.witnesses,
 		downPeers:         downPeers,
 		pendingPeers:      pendingPeers,
 		writtenBytes:      r.writtenBytes,
@@ -151,6 +153,34 @@ func (r *RegionInfo) GetVoters() []*metapb.Peer {
 	return r.voters
 }
 
+// GetWitnesses returns the witnesses, they are voters that take part in
+// quorum but store no data and never become leader.
+func (r *RegionInfo) GetWitnesses() []*metapb.Peer {
+	if len(r.witnesses) == 0 {
+		return nil
+	}
+	witnesses := make([]*metapb.Peer, 0, len(r.witnesses))
+	for _, peer := range r.voters {
+		if _, ok := r.witnesses[peer.GetId()]; ok {
+			witnesses = append(witnesses, peer)
+		}
+	}
+	return witnesses
+}
+
+// IsWitness returns if the peer with specified peer id is a witness.
+func (r *RegionInfo) IsWitness(peerID uint64) bool {
+	if _, ok := r.witnesses[peerID]; !ok {
+		return false
+	}
+	for _, peer := range r.voters {
+		if peer.GetId() == peerID {
+			return true
+		}
+	}
+	return false
+}
+
 // GetPeer returns the peer with specified peer id.
 func (r *RegionInfo) GetPeer(peerID uint64) *metapb.Peer {
 	for _, peer := range r.meta.GetPeers() {
@@ -251,6 +281,26 @@ func (r *RegionInfo) GetStoreLearner(storeID uint64) *metapb.Peer {
 	return nil
 }
 
+// GetStoreWitness returns the witness peer in specified store.
+func (r *RegionInfo) GetStoreWitness(storeID uint64) *metapb.Peer {
+	for _, peer := range r.GetWitnesses() {
+		if peer.GetStoreId() == storeID {
+			return peer
+		}
+	}
+	return nil
+}
+
+// GetWitnessStoreIds returns a map indicate the stores of the witnesses.
+func (r *RegionInfo) GetWitnessStoreIds() map[uint64]struct{} {
+	witnesses := r.GetWitnesses()
+	stores := make(map[uint64]struct{}, len(witnesses))
+	for _, peer := range witnesses {
+		stores[peer.GetStoreId()] = struct{}{}
+	}
+	return stores
+}
+
 // GetStoreIds returns a map indicate the region distributed.
 func (r *RegionInfo) GetStoreIds() map[uint64]struct{} {
 	peers := r.meta.GetPeers()
@@ -262,11 +312,12 @@ func (r *RegionInfo) GetStoreIds() map[uint64]struct{} {
 }
 
 // GetFollowers returns a map indicate the follow peers distributed.
+// Witnesses are not included since they can never become leader.
 func (r *RegionInfo) GetFollowers() map[uint64]*metapb.Peer {
 	peers := r.GetVoters()
 	followers := make(map[uint64]*metapb.Peer, len(peers))
 	for _, peer := range peers {
-		if r.leader == nil || r.leader.GetId() != peer.GetId() {
+		if r.isFollower(peer) {
 			followers[peer.GetStoreId()] = peer
 		}
 	}
@@ -276,13 +327,21 @@ func (r *RegionInfo) GetFollowers() map[uint64]*metapb.Peer {
 // GetFollower randomly returns a follow peer.
 func (r *RegionInfo) GetFollower() *metapb.Peer {
 	for _, peer := range r.GetVoters() {
-		if r.leader == nil || r.leader.GetId() != peer.GetId() {
+		if r.isFollower(peer) {
 			return peer
 		}
 	}
 	return nil
 }
 
+func (r *RegionInfo) isFollower(peer *metapb.Peer) bool {
+	if r.leader != nil && r.leader.GetId() == peer.GetId() {
+		return false
+	}
+	_, isWitness := r.witnesses[peer.GetId()]
+	return !isWitness
+}
+
 // GetDiffFollowers returns the followers which is not located in the same
 // store as any other followers of the another specified region.
 func (r *RegionInfo) GetDiffFollowers(other *RegionInfo) []*metapb.Peer {
@@ -373,6 +432,16 @@ func (r *RegionInfo) GetKeysRead() uint64 {
 	return r.readKeys
 }
 
+// GetKeysReadRate returns the read keys per second of the region in the
+// last heartbeat interval.
+func (r *RegionInfo) GetKeysReadRate() float64 {
+	interval := r.interval.GetEndTimestamp() - r.interval.GetStartTimestamp()
+	if interval == 0 {
+		return 0
+	}
+	return float64(r.readKeys) / float64(interval)
+}
+
 // GetLeader returns the leader of the region.
 func (r *RegionInfo) GetLeader() *metapb.Peer {
 	return r.leader
@@ -553,6 +622,7 @@ type RegionsInfo struct {
 	leaders      map[uint64]*regionSubTree // storeID -> regionSubTree
 	followers    map[uint64]*regionSubTree // storeID -> regionSubTree
 	learners     map[uint64]*regionSubTree // storeID -> regionSubTree
+	witnesses    map[uint64]*regionSubTree // storeID -> regionSubTree
 	pendingPeers map[uint64]*regionSubTree // storeID -> regionSubTree
 }
 
@@ -564,6 +634,7 @@ func NewRegionsInfo() *RegionsInfo {
 		leaders:      make(map[uint64]*regionSubTree),
 		followers:    make(map[uint64]*regionSubTree),
 		learners:     make(map[uint64]*regionSubTree),
+		witnesses:    make(map[uint64]*regionSubTree),
 		pendingPeers: make(map[uint64]*regionSubTree),
 	}
 }
@@ -624,6 +695,14 @@ func (r *RegionsInfo) AddRegion(region *RegionInfo) []*RegionInfo {
 				r.leaders[storeID] = store
 			}
 			store.update(region)
+		} else if region.IsWitness(peer.GetId()) {
+			// Add witness peer to witnesses.
+			store, ok := r.witnesses[storeID]
+			if !ok {
+				store = newRegionSubTree()
+				r.witnesses[storeID] = store
+			}
+			store.update(region)
 		} else {
 			// Add follower peer to followers.
 			store, ok := r.followers[storeID]
@@ -682,6 +761,7 @@ func (r *RegionsInfo) removeRegionFromSubTree(region *RegionInfo) {
 		r.leaders[storeID].remove(region)
 		r.followers[storeID].remove(region)
 		r.learners[storeID].remove(region)
+		r.witnesses[storeID].remove(region)
 		r.pendingPeers[storeID].remove(region)
 	}
 }
@@ -719,6 +799,7 @@ func (r *RegionsInfo) shouldRemoveFromSubTree(region *RegionInfo, origin *Region
 	return origin.leader.GetId() != region.leader.GetId() ||
 		checkPeersChange(origin.GetVoters(), region.GetVoters()) ||
 		checkPeersChange(origin.GetLearners(), region.GetLearners()) ||
+		checkPeersChange(origin.GetWitnesses(), region.GetWitnesses()) ||
 		checkPeersChange(origin.GetPendingPeers(), region.GetPendingPeers())
 }
 
@@ -776,7 +857,13 @@ func (r *RegionsInfo) GetStoreLearnerRegionSize(storeID uint64) int64 {
 	return r.learners[storeID].TotalSize()
 }
 
-// GetStoreRegionSize get total size of store's regions
+// GetStoreWitnessRegionSize get total size of store's witness regions
+func (r *RegionsInfo) GetStoreWitnessRegionSize(storeID uint64) int64 {
+	return r.witnesses[storeID].TotalSize()
+}
+
+// GetStoreRegionSize get total size of store's regions. Witness regions are
+// not counted since witnesses store no data.
 func (r *RegionsInfo) GetStoreRegionSize(storeID uint64) int64 {
 	return r.GetStoreLeaderRegionSize(storeID) + r.GetStoreFollowerRegionSize(storeID) + r.GetStoreLearnerRegionSize(storeID)
 }
@@ -797,7 +884,7 @@ func (r *RegionsInfo) GetRegionCount() int {
 
 // GetStoreRegionCount gets the total count of a store's leader and follower RegionInfo by storeID
 func (r *RegionsInfo) GetStoreRegionCount(storeID uint64) int {
-	return r.GetStoreLeaderCount(storeID) + r.GetStoreFollowerCount(storeID) + r.GetStoreLearnerCount(storeID)
+	return r.GetStoreLeaderCount(storeID) + r.GetStoreFollowerCount(storeID) + r.GetStoreLearnerCount(storeID) + r.GetStoreWitnessCount(storeID)
 }
 
 // GetStorePendingPeerCount gets the total count of a store's region that includes pending peer
@@ -820,6 +907,11 @@ func (r *RegionsInfo) GetStoreLearnerCount(storeID uint64) int {
 	return r.learners[storeID].length()
 }
 
+// GetStoreWitnessCount get the total count of a store's witness RegionInfo
+func (r *RegionsInfo) GetStoreWitnessCount(storeID uint64) int {
+	return r.witnesses[storeID].length()
+}
+
 // RandPendingRegion randomly gets a store's region with a pending peer.
 func (r *RegionsInfo) RandPendingRegion(storeID uint64, ranges []KeyRange) *RegionInfo {
 	return r.pendingPeers[storeID].RandomRegion(ranges)
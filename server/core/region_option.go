@@ -54,6 +54,21 @@ func WithLearners(learners []*metapb.Peer) RegionCreateOption {
 	}
 }
 
+// WithWitnesses sets the witnesses for the region, the witnesses should be
+// voters of the region which are reported as witnesses by TiKV.
+func WithWitnesses(witnesses []*metapb.Peer) RegionCreateOption {
+	return func(region *RegionInfo) {
+		if len(witnesses) == 0 {
+			region.witnesses = nil
+			return
+		}
+		region.witnesses = make(map[uint64]struct{}, len(witnesses))
+		for _, w := range witnesses {
+			region.witnesses[w.GetId()] = struct{}{}
+		}
+	}
+}
+
 // WithLeader sets the leader for the region.
 func WithLeader(leader *metapb.Peer) RegionCreateOption {
 	return func(region *RegionInfo) {
@@ -221,10 +221,52 @@ func (*testRegionKey) TestShouldRemoveFromSubTree(c *C) {
 	c.Assert(regions.shouldRemoveFromSubTree(region, origin), Equals, true)
 }
 
+func (*testRegionKey) TestWitness(c *C) {
+	regions := NewRegionsInfo()
+	peers := []*metapb.Peer{
+		{Id: 1, StoreId: 1},
+		{Id: 2, StoreId: 2},
+		{Id: 3, StoreId: 3},
+	}
+	region := NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0], SetApproximateSize(10))
+	regions.AddRegion(region)
+	c.Assert(regions.GetStoreRegionSize(3), Equals, int64(10))
+	c.Assert(regions.GetStoreFollowerCount(3), Equals, 1)
+
+	region = region.Clone(WithWitnesses([]*metapb.Peer{peers[2]}))
+	c.Assert(region.IsWitness(3), IsTrue)
+	c.Assert(region.IsWitness(2), IsFalse)
+	c.Assert(region.GetStoreWitness(3), DeepEquals, peers[2])
+	c.Assert(region.GetFollowers(), HasLen, 1)
+	c.Assert(region.GetFollower().GetStoreId(), Equals, uint64(2))
+	c.Assert(regions.shouldRemoveFromSubTree(region, regions.GetRegion(1)), IsTrue)
+
+	regions.SetRegion(region)
+	checkRegions(c, regions)
+	c.Assert(regions.GetStoreRegionSize(3), Equals, int64(0))
+	c.Assert(regions.GetStoreRegionCount(3), Equals, 1)
+	c.Assert(regions.GetStoreFollowerCount(3), Equals, 0)
+	c.Assert(regions.GetStoreWitnessCount(3), Equals, 1)
+	c.Assert(regions.GetStoreWitnessRegionSize(3), Equals, int64(10))
+	c.Assert(regions.GetStoreRegionSize(2), Equals, int64(10))
+	c.Assert(regions.RandFollowerRegion(3, nil), IsNil)
+
+	// Witnesses are kept when the region is cloned.
+	region = region.Clone(SetApproximateSize(20))
+	c.Assert(region.GetWitnesses(), HasLen, 1)
+	region = region.Clone(WithWitnesses(nil))
+	c.Assert(region.GetWitnesses(), HasLen, 0)
+	regions.SetRegion(region)
+	checkRegions(c, regions)
+	c.Assert(regions.GetStoreWitnessCount(3), Equals, 0)
+	c.Assert(regions.GetStoreRegionSize(3), Equals, int64(20))
+}
+
 func checkRegions(c *C, regions *RegionsInfo) {
 	leaderMap := make(map[uint64]uint64)
 	followerMap := make(map[uint64]uint64)
 	learnerMap := make(map[uint64]uint64)
+	witnessMap := make(map[uint64]uint64)
 	pendingPeerMap := make(map[uint64]uint64)
 	for _, item := range regions.GetRegions() {
 		if leaderCount, ok := leaderMap[item.leader.StoreId]; ok {
@@ -246,6 +288,9 @@ func checkRegions(c *C, regions *RegionsInfo) {
 				learnerMap[learner.StoreId] = 1
 			}
 		}
+		for _, witness := range item.GetWitnesses() {
+			witnessMap[witness.StoreId]++
+		}
 		for _, pendingPeer := range item.GetPendingPeers() {
 			if pendingPeerCount, ok := pendingPeerMap[pendingPeer.StoreId]; ok {
 				pendingPeerMap[pendingPeer.StoreId] = pendingPeerCount + 1
@@ -263,6 +308,9 @@ func checkRegions(c *C, regions *RegionsInfo) {
 	for key, value := range regions.learners {
 		c.Assert(value.length(), Equals, int(learnerMap[key]))
 	}
+	for key, value := range regions.witnesses {
+		c.Assert(value.length(), Equals, int(witnessMap[key]))
+	}
 	for key, value := range regions.pendingPeers {
 		c.Assert(value.length(), Equals, int(pendingPeerMap[key]))
 	}
@@ -37,9 +37,13 @@ const (
 	schedulePath             = "schedule"
 	gcPath                   = "gc"
 	rulesPath                = "rules"
+	ruleGroupPath            = "rule_group"
+	regionLabelPath          = "region_label"
 	replicationPath          = "replication_mode"
 	componentPath            = "component"
 	customScheduleConfigPath = "scheduler_config"
+	operatorRecordPath       = "operator_record"
+	activeWindowPath         = "active_window"
 )
 
 const (
@@ -123,6 +127,21 @@ func (s *Storage) LoadScheduleConfig(scheduleName string) (string, error) {
 	return s.Load(configPath)
 }
 
+// SaveActiveWindow saves the active window of a scheduler or a checker.
+func (s *Storage) SaveActiveWindow(name string, data []byte) error {
+	return s.Save(path.Join(activeWindowPath, name), string(data))
+}
+
+// RemoveActiveWindow removes the active window of a scheduler or a checker.
+func (s *Storage) RemoveActiveWindow(name string) error {
+	return s.Remove(path.Join(activeWindowPath, name))
+}
+
+// LoadActiveWindow loads the active window of a scheduler or a checker.
+func (s *Storage) LoadActiveWindow(name string) (string, error) {
+	return s.Load(path.Join(activeWindowPath, name))
+}
+
 // LoadMeta loads cluster meta from storage.
 func (s *Storage) LoadMeta(meta *metapb.Cluster) (bool, error) {
 	return loadProto(s.Base, clusterPath, meta)
@@ -235,12 +254,157 @@ func (s *Storage) DeleteRule(ruleKey string) error {
 	return s.Base.Remove(path.Join(rulesPath, ruleKey))
 }
 
+// BatchUpdateRules saves and removes rules in a single transaction.
+func (s *Storage) BatchUpdateRules(toSave map[string]interface{}, toDelete []string) error {
+	ops := make([]kv.Op, 0, len(toSave)+len(toDelete))
+	for ruleKey, rule := range toSave {
+		value, err := json.Marshal(rule)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ops = append(ops, kv.OpSave(path.Join(rulesPath, ruleKey), string(value)))
+	}
+	for _, ruleKey := range toDelete {
+		ops = append(ops, kv.OpRemove(path.Join(rulesPath, ruleKey)))
+	}
+	return s.Batch(ops)
+}
+
 // LoadRules loads placement rules from storage.
 func (s *Storage) LoadRules(f func(k, v string)) (bool, error) {
-	// Range is ['rule/\x00', 'rule0'). 'rule0' is the upper bound of all rules because '0' is next char of '/' in
-	// ascii order.
-	nextKey := path.Join(rulesPath, "\x00")
-	endKey := rulesPath + "0"
+	return s.loadRangeByPrefix(rulesPath+"/", f)
+}
+
+// SaveRuleGroup stores a rule group config to storage.
+func (s *Storage) SaveRuleGroup(groupID string, group interface{}) error {
+	value, err := json.Marshal(group)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(ruleGroupPath, groupID), string(value))
+}
+
+// DeleteRuleGroup removes a rule group from storage.
+func (s *Storage) DeleteRuleGroup(groupID string) error {
+	return s.Remove(path.Join(ruleGroupPath, groupID))
+}
+
+// LoadRuleGroups loads all rule groups from storage.
+func (s *Storage) LoadRuleGroups(f func(k, v string)) (bool, error) {
+	return s.loadRangeByPrefix(ruleGroupPath+"/", f)
+}
+
+// SaveRegionRule saves a region label rule to storage.
+func (s *Storage) SaveRegionRule(ruleKey string, rule interface{}) error {
+	value, err := json.Marshal(rule)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return s.Save(path.Join(regionLabelPath, ruleKey), string(value))
+}
+
+// DeleteRegionRule removes a region label rule from storage.
+func (s *Storage) DeleteRegionRule(ruleKey string) error {
+	return s.Remove(path.Join(regionLabelPath, ruleKey))
+}
+
+// LoadRegionRules loads region label rules from storage.
+func (s *Storage) LoadRegionRules(f func(k, v string)) (bool, error) {
+	return s.loadRangeByPrefix(regionLabelPath+"/", f)
+}
+
+// OperatorRecordKey returns the key of a finished operator. The keys are
+// ordered by the finish time.
+func OperatorRecordKey(finishTime time.Time, regionID uint64) string {
+	return fmt.Sprintf("%020d/%020d", finishTime.UnixNano(), regionID)
+}
+
+// SaveOperatorRecords saves finished operators in a single transaction. The
+// keys should be generated by OperatorRecordKey.
+func (s *Storage) SaveOperatorRecords(records map[string]interface{}) error {
+	ops := make([]kv.Op, 0, len(records))
+	for key, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ops = append(ops, kv.OpSave(path.Join(operatorRecordPath, key), string(value)))
+	}
+	return s.Batch(ops)
+}
+
+// DeleteOperatorRecords removes finished operators in a single transaction.
+func (s *Storage) DeleteOperatorRecords(keys []string) error {
+	ops := make([]kv.Op, 0, len(keys))
+	for _, key := range keys {
+		ops = append(ops, kv.OpRemove(path.Join(operatorRecordPath, key)))
+	}
+	return s.Batch(ops)
+}
+
+// LoadOperatorRecords iterates finished operators whose finish time is in
+// [start, end) by the order of finish time, until f returns false. A zero
+// start or end means no lower or upper bound.
+func (s *Storage) LoadOperatorRecords(start, end time.Time, f func(k, v string) bool) error {
+	prefix := operatorRecordPath + "/"
+	nextKey, endKey := operatorRecordRange(start, end)
+	for {
+		keys, values, err := s.LoadRange(nextKey, endKey, minKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for i := range keys {
+			if !f(strings.TrimPrefix(keys[i], prefix), values[i]) {
+				return nil
+			}
+		}
+		if len(keys) < minKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+// LoadOperatorRecordKeys is like LoadOperatorRecords but only loads the keys.
+func (s *Storage) LoadOperatorRecordKeys(start, end time.Time, f func(k string) bool) error {
+	prefix := operatorRecordPath + "/"
+	nextKey, endKey := operatorRecordRange(start, end)
+	for {
+		keys, err := s.LoadRangeKeys(nextKey, endKey, minKVRangeLimit)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			if !f(strings.TrimPrefix(key, prefix)) {
+				return nil
+			}
+		}
+		if len(keys) < minKVRangeLimit {
+			return nil
+		}
+		nextKey = keys[len(keys)-1] + "\x00"
+	}
+}
+
+// operatorRecordRange returns the key range of the operator records whose
+// finish time is in [start, end).
+func operatorRecordRange(start, end time.Time) (string, string) {
+	prefix := operatorRecordPath + "/"
+	startKey := prefix
+	if !start.IsZero() {
+		startKey = prefix + fmt.Sprintf("%020d", start.UnixNano())
+	}
+	endKey := clientv3.GetPrefixRangeEnd(prefix)
+	if !end.IsZero() {
+		endKey = prefix + fmt.Sprintf("%020d", end.UnixNano())
+	}
+	return startKey, endKey
+}
+
+// loadRangeByPrefix iterates all key-value pairs in the storage that has the prefix.
+func (s *Storage) loadRangeByPrefix(prefix string, f func(k, v string)) (bool, error) {
+	nextKey := prefix
+	endKey := clientv3.GetPrefixRangeEnd(prefix)
 	for {
 		keys, values, err := s.LoadRange(nextKey, endKey, minKVRangeLimit)
 		if err != nil {
@@ -250,7 +414,7 @@ func (s *Storage) LoadRules(f func(k, v string)) (bool, error) {
 			return false, nil
 		}
 		for i := range keys {
-			f(strings.TrimPrefix(keys[i], rulesPath+"/"), values[i])
+			f(strings.TrimPrefix(keys[i], prefix), values[i])
 		}
 		if len(keys) < minKVRangeLimit {
 			return true, nil
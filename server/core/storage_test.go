@@ -182,6 +182,42 @@ func (s *testKVSuite) TestLoadRegionsExceedRangeLimit(c *C) {
 	}
 }
 
+func (s *testKVSuite) TestOperatorRecords(c *C) {
+	storage := NewStorage(kv.NewMemoryKV())
+	base := time.Now()
+	n := minKVRangeLimit + 50
+	records := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		records[OperatorRecordKey(base.Add(time.Duration(i)*time.Second), uint64(i))] = i
+	}
+	c.Assert(storage.SaveOperatorRecords(records), IsNil)
+
+	load := func(start, end time.Time, limit int) []string {
+		var keys []string
+		c.Assert(storage.LoadOperatorRecords(start, end, func(k, v string) bool {
+			keys = append(keys, k)
+			return limit == 0 || len(keys) < limit
+		}), IsNil)
+		return keys
+	}
+	keys := load(time.Time{}, time.Time{}, 0)
+	c.Assert(keys, HasLen, n)
+	for i, key := range keys {
+		c.Assert(key, Equals, OperatorRecordKey(base.Add(time.Duration(i)*time.Second), uint64(i)))
+	}
+	c.Assert(load(base.Add(10*time.Second), base.Add(20*time.Second), 0), DeepEquals, keys[10:20])
+	c.Assert(load(base.Add(10*time.Second), time.Time{}, 5), DeepEquals, keys[10:15])
+	var onlyKeys []string
+	c.Assert(storage.LoadOperatorRecordKeys(time.Time{}, base.Add(time.Duration(n-5)*time.Second), func(k string) bool {
+		onlyKeys = append(onlyKeys, k)
+		return true
+	}), IsNil)
+	c.Assert(onlyKeys, DeepEquals, keys[:n-5])
+
+	c.Assert(storage.DeleteOperatorRecords(keys[:n-10]), IsNil)
+	c.Assert(load(time.Time{}, time.Time{}, 0), DeepEquals, keys[n-10:])
+}
+
 func (s *testKVSuite) TestLoadGCSafePoint(c *C) {
 	storage := NewStorage(kv.NewMemoryKV())
 	testData := []uint64{0, 1, 2, 233, 2333, 23333333333, math.MaxUint64}
@@ -46,6 +46,7 @@ type StoreInfo struct {
 	regionCount      int
 	leaderSize       int64
 	regionSize       int64
+	leaderLoad       float64
 	pendingPeerCount int
 	lastPersistTime  time.Time
 	leaderWeight     float64
@@ -78,6 +79,7 @@ func (s *StoreInfo) Clone(opts ...StoreCreateOption) *StoreInfo {
 		regionCount:      s.regionCount,
 		leaderSize:       s.leaderSize,
 		regionSize:       s.regionSize,
+		leaderLoad:       s.leaderLoad,
 		pendingPeerCount: s.pendingPeerCount,
 		lastPersistTime:  s.lastPersistTime,
 		leaderWeight:     s.leaderWeight,
@@ -101,6 +103,7 @@ func (s *StoreInfo) ShallowClone(opts ...StoreCreateOption) *StoreInfo {
 		regionCount:      s.regionCount,
 		leaderSize:       s.leaderSize,
 		regionSize:       s.regionSize,
+		leaderLoad:       s.leaderLoad,
 		pendingPeerCount: s.pendingPeerCount,
 		lastPersistTime:  s.lastPersistTime,
 		leaderWeight:     s.leaderWeight,
@@ -252,6 +255,12 @@ func (s *StoreInfo) GetLeaderSize() int64 {
 	return s.leaderSize
 }
 
+// GetLeaderLoad returns the smoothed read keys rate served by the leaders
+// of the store.
+func (s *StoreInfo) GetLeaderLoad() float64 {
+	return s.leaderLoad
+}
+
 // GetRegionSize returns the Region size of the store.
 func (s *StoreInfo) GetRegionSize() int64 {
 	return s.regionSize
@@ -292,6 +301,8 @@ func (s *StoreInfo) LeaderScore(policy SchedulePolicy, delta int64) float64 {
 		return float64(s.GetLeaderSize()+delta) / math.Max(s.GetLeaderWeight(), minWeight)
 	case ByCount:
 		return float64(int64(s.GetLeaderCount())+delta) / math.Max(s.GetLeaderWeight(), minWeight)
+	case ByLoad:
+		return (s.GetLeaderLoad() + float64(delta)) / math.Max(s.GetLeaderWeight(), minWeight)
 	default:
 		return 0
 	}
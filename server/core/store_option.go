@@ -115,6 +115,13 @@ func SetLeaderSize(leaderSize int64) StoreCreateOption {
 	}
 }
 
+// SetLeaderLoad sets the leader load for the store.
+func SetLeaderLoad(leaderLoad float64) StoreCreateOption {
+	return func(store *StoreInfo) {
+		store.leaderLoad = leaderLoad
+	}
+}
+
 // SetRegionSize sets the Region size for the store.
 func SetRegionSize(regionSize int64) StoreCreateOption {
 	return func(store *StoreInfo) {
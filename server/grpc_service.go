@@ -26,13 +26,31 @@ import (
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/cluster"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/tso"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-const slowThreshold = 5 * time.Millisecond
+const (
+	slowThreshold = 5 * time.Millisecond
+	// dcLocationMetadataKey is the key of the gRPC metadata to request the
+	// local TSO of a data center.
+	dcLocationMetadataKey = "pd-dc-location"
+	// followerHandleMetadataKey is the key of the gRPC metadata to allow a
+	// follower to handle the region and store reads with its synced data,
+	// which may be stale.
+	followerHandleMetadataKey = "pd-allow-follower-handle"
+	// tsoSuffixBitsMetadataKey is the key of the gRPC header metadata of the
+	// TSO stream to tell the client the tso-suffix-bits of the server.
+	tsoSuffixBitsMetadataKey = "pd-tso-suffix-bits"
+	// maxFollowerSyncLag is the max time since a follower synced the regions
+	// from the leader to handle the reads.
+	maxFollowerSyncLag = 30 * time.Second
+)
 
 // gRPC errors
 var (
@@ -70,7 +88,30 @@ func (s *Server) GetMembers(context.Context, *pdpb.GetMembersRequest) (*pdpb.Get
 }
 
 // Tso implements gRPC PDServer.
+// The timestamps are allocated by the local TSO of the data center specified
+// by the "pd-dc-location" metadata of the stream, or the global TSO if there
+// is no such metadata. If the stream has the "pd-tso-follower-proxy"
+// metadata, a follower forwards the requests of the global TSO to the leader.
 func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
+	var (
+		dcLocation string
+		proxy      bool
+		syncLocal  bool
+	)
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok {
+		if values := md.Get(dcLocationMetadataKey); len(values) > 0 {
+			dcLocation = values[0]
+		}
+		proxy = len(md.Get(tsoProxyMetadataKey)) > 0
+		syncLocal = len(md.Get(tsoSyncMetadataKey)) > 0
+	}
+	if proxy && !s.cfg.EnableTSOFollowerProxy {
+		return status.Errorf(codes.Unavailable, "tso follower proxy is disabled")
+	}
+	// The client learns the step of the logical part from the header.
+	if err := stream.SetHeader(metadata.Pairs(tsoSuffixBitsMetadataKey, strconv.Itoa(s.cfg.TSOSuffixBits))); err != nil {
+		return errors.WithStack(err)
+	}
 	for {
 		request, err := stream.Recv()
 		if err == io.EOF {
@@ -88,7 +129,21 @@ func (s *Server) Tso(stream pdpb.PD_TsoServer) error {
 			return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, request.GetHeader().GetClusterId())
 		}
 		count := request.GetCount()
-		ts, err := s.tso.GetRespTS(count)
+		var ts pdpb.Timestamp
+		if proxy && dcLocation == "" && !s.member.IsLeader() {
+			if count == 0 {
+				return status.Errorf(codes.Unknown, "tso count should be positive")
+			}
+			ts, err = s.tsoProxy.GetTS(stream.Context(), count)
+		} else if syncLocal {
+			// The global TSO gets the timestamp of the local TSO.
+			ts, err = s.tsoAllocatorManager.SyncLocalTSO(dcLocation)
+		} else {
+			ts, err = s.tsoAllocatorManager.GetTSO(dcLocation, count)
+		}
+		if cause := errors.Cause(err); cause == tso.ErrNotLocalTSOLeader || cause == tso.ErrLocalTSONotSynced {
+			return status.Errorf(codes.Unavailable, err.Error())
+		}
 		if err != nil {
 			return status.Errorf(codes.Unknown, err.Error())
 		}
@@ -168,6 +223,9 @@ func (s *Server) AllocID(ctx context.Context, request *pdpb.AllocIDRequest) (*pd
 
 // GetStore implements gRPC PDServer.
 func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*pdpb.GetStoreResponse, error) {
+	if s.isFollowerHandle(ctx) {
+		return s.getStoreByFollower(request)
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -189,6 +247,27 @@ func (s *Server) GetStore(ctx context.Context, request *pdpb.GetStoreRequest) (*
 	}, nil
 }
 
+// getStoreByFollower gets the store from the storage, the stats of the store
+// are only known by the leader.
+func (s *Server) getStoreByFollower(request *pdpb.GetStoreRequest) (*pdpb.GetStoreResponse, error) {
+	if err := s.validateFollowerRequest(request.GetHeader()); err != nil {
+		return nil, err
+	}
+	storeID := request.GetStoreId()
+	store := &metapb.Store{}
+	ok, err := s.storage.LoadStore(storeID, store)
+	if err != nil {
+		return nil, status.Errorf(codes.Unknown, err.Error())
+	}
+	if !ok {
+		return nil, status.Errorf(codes.Unknown, "invalid store ID %d, not found", storeID)
+	}
+	return &pdpb.GetStoreResponse{
+		Header: s.header(),
+		Store:  store,
+	}, nil
+}
+
 // checkStore returns an error response if the store exists and is in tombstone state.
 // It returns nil if it can't get the store.
 func checkStore(rc *cluster.RaftCluster, storeID uint64) *pdpb.Error {
@@ -408,6 +487,12 @@ func (s *Server) RegionHeartbeat(stream pdpb.PD_RegionHeartbeatServer) error {
 
 // GetRegion implements gRPC PDServer.
 func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if s.isFollowerHandle(ctx) {
+		if err := s.validateFollowerRequest(request.GetHeader()); err != nil {
+			return nil, err
+		}
+		return s.regionResponse(s.basicCluster.SearchRegion(request.GetRegionKey())), nil
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -426,6 +511,12 @@ func (s *Server) GetRegion(ctx context.Context, request *pdpb.GetRegionRequest)
 
 // GetPrevRegion implements gRPC PDServer
 func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionRequest) (*pdpb.GetRegionResponse, error) {
+	if s.isFollowerHandle(ctx) {
+		if err := s.validateFollowerRequest(request.GetHeader()); err != nil {
+			return nil, err
+		}
+		return s.regionResponse(s.basicCluster.SearchPrevRegion(request.GetRegionKey())), nil
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -445,6 +536,12 @@ func (s *Server) GetPrevRegion(ctx context.Context, request *pdpb.GetRegionReque
 
 // GetRegionByID implements gRPC PDServer.
 func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDRequest) (*pdpb.GetRegionResponse, error) {
+	if s.isFollowerHandle(ctx) {
+		if err := s.validateFollowerRequest(request.GetHeader()); err != nil {
+			return nil, err
+		}
+		return s.regionResponse(s.basicCluster.GetRegion(request.GetRegionId())), nil
+	}
 	if err := s.validateRequest(request.GetHeader()); err != nil {
 		return nil, err
 	}
@@ -464,15 +561,22 @@ func (s *Server) GetRegionByID(ctx context.Context, request *pdpb.GetRegionByIDR
 
 // ScanRegions implements gRPC PDServer.
 func (s *Server) ScanRegions(ctx context.Context, request *pdpb.ScanRegionsRequest) (*pdpb.ScanRegionsResponse, error) {
-	if err := s.validateRequest(request.GetHeader()); err != nil {
-		return nil, err
-	}
-
-	rc := s.GetRaftCluster()
-	if rc == nil {
-		return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+	var regions []*core.RegionInfo
+	if s.isFollowerHandle(ctx) {
+		if err := s.validateFollowerRequest(request.GetHeader()); err != nil {
+			return nil, err
+		}
+		regions = s.basicCluster.ScanRange(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
+	} else {
+		if err := s.validateRequest(request.GetHeader()); err != nil {
+			return nil, err
+		}
+		rc := s.GetRaftCluster()
+		if rc == nil {
+			return &pdpb.ScanRegionsResponse{Header: s.notBootstrappedHeader()}, nil
+		}
+		regions = rc.ScanRegions(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
 	}
-	regions := rc.ScanRegions(request.GetStartKey(), request.GetEndKey(), int(request.GetLimit()))
 	resp := &pdpb.ScanRegionsResponse{Header: s.header()}
 	for _, r := range regions {
 		leader := r.GetLeader()
@@ -647,13 +751,12 @@ func (s *Server) ScatterRegion(ctx context.Context, request *pdpb.ScatterRegionR
 		return nil, errors.Errorf("region %d is a hot region", region.GetID())
 	}
 
-	op, err := rc.GetRegionScatter().Scatter(region)
+	err := rc.GetRegionScatter().Scatter(region, "", func(op *operator.Operator) bool {
+		return rc.GetOperatorController().AddOperator(op)
+	})
 	if err != nil {
 		return nil, err
 	}
-	if op != nil {
-		rc.GetOperatorController().AddOperator(op)
-	}
 
 	return &pdpb.ScatterRegionResponse{
 		Header: s.header(),
@@ -829,6 +932,42 @@ func (s *Server) validateRequest(header *pdpb.RequestHeader) error {
 	return nil
 }
 
+// isFollowerHandle returns whether the server is a follower and the request
+// allows a follower to handle it.
+func (s *Server) isFollowerHandle(ctx context.Context) bool {
+	if s.member.IsLeader() {
+		return false
+	}
+	md, ok := metadata.FromIncomingContext(ctx)
+	return ok && len(md.Get(followerHandleMetadataKey)) > 0
+}
+
+// validateFollowerRequest checks if clusterID is matched and the follower
+// synced the regions from the leader recently.
+func (s *Server) validateFollowerRequest(header *pdpb.RequestHeader) error {
+	if s.IsClosed() {
+		return errors.WithStack(ErrNotStarted)
+	}
+	if header.GetClusterId() != s.clusterID {
+		return status.Errorf(codes.FailedPrecondition, "mismatch cluster id, need %d but got %d", s.clusterID, header.GetClusterId())
+	}
+	lastSync := s.cluster.GetRegionSyncer().GetLastSyncTime()
+	if lastSync.IsZero() || time.Since(lastSync) > maxFollowerSyncLag {
+		return status.Errorf(codes.Unavailable, "regions of the follower are not synced with the leader")
+	}
+	return nil
+}
+
+// regionResponse returns the response of the region read by a follower. The
+// leader of the region is not synced, so it may be missing.
+func (s *Server) regionResponse(region *core.RegionInfo) *pdpb.GetRegionResponse {
+	resp := &pdpb.GetRegionResponse{Header: s.header()}
+	if region != nil {
+		resp.Region, resp.Leader = region.GetMeta(), region.GetLeader()
+	}
+	return resp
+}
+
 func (s *Server) header() *pdpb.ResponseHeader {
 	return &pdpb.ResponseHeader{ClusterId: s.clusterID}
 }
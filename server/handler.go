@@ -15,6 +15,7 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"net/http"
 	"path"
@@ -31,8 +32,10 @@ import (
 	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/checker"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 	"github.com/pingcap/pd/v4/server/schedulers"
 	"github.com/pingcap/pd/v4/server/statistics"
@@ -64,6 +67,8 @@ var (
 	ErrStoreNotFound = func(storeID uint64) error {
 		return errors.Errorf("store %v not found", storeID)
 	}
+	// ErrSchedulerNotDiagnosable is error info for scheduler does not support diagnostic.
+	ErrSchedulerNotDiagnosable = errors.New("scheduler does not support diagnostic")
 	// ErrPluginNotFound is error info for plugin not found.
 	ErrPluginNotFound = func(pluginPath string) error {
 		return errors.Errorf("plugin is not found: %s", pluginPath)
@@ -113,6 +118,23 @@ func (h *Handler) IsSchedulerPaused(name string) (bool, error) {
 	return sc.IsPaused(), nil
 }
 
+// GetSchedulerPlanRecorder returns the plan recorder of the scheduler.
+func (h *Handler) GetSchedulerPlanRecorder(name string) (*plan.Recorder, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	sc, ok := c.GetSchedulers()[name]
+	if !ok {
+		return nil, schedulers.ErrSchedulerNotFound
+	}
+	s, ok := sc.Scheduler.(schedule.DiagnosableScheduler)
+	if !ok {
+		return nil, ErrSchedulerNotDiagnosable
+	}
+	return s.GetPlanRecorder(), nil
+}
+
 // GetScheduleConfig returns ScheduleConfig.
 func (h *Handler) GetScheduleConfig() *config.ScheduleConfig {
 	return h.s.GetScheduleConfig()
@@ -254,6 +276,43 @@ func (h *Handler) PauseOrResumeScheduler(name string, t int64) error {
 	return err
 }
 
+// SetActiveWindow sets the active window of a scheduler or a checker, a nil
+// window means it is always active.
+func (h *Handler) SetActiveWindow(name string, w *schedule.ActiveWindow) error {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return err
+	}
+	if err = c.SetActiveWindow(name, w); err != nil {
+		log.Error("can not set the active window", zap.String("name", name), zap.Stringer("active-window", w), zap.Error(err))
+	}
+	return err
+}
+
+// GetActiveWindowStatus returns the state of the active window of a
+// scheduler or a checker.
+func (h *Handler) GetActiveWindowStatus(name string) (*schedule.ActiveWindowStatus, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	w, err := c.GetActiveWindow(name)
+	if err != nil {
+		return nil, err
+	}
+	return w.Status(time.Now()), nil
+}
+
+// GetMergeCandidates returns the regions small enough to merge but skipped by
+// the merge checker recently. An empty rangeName means all ranges.
+func (h *Handler) GetMergeCandidates(rangeName string) ([]*checker.MergeCandidate, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetMergeChecker().GetMergeCandidates(rangeName), nil
+}
+
 // AddBalanceLeaderScheduler adds a balance-leader-scheduler.
 func (h *Handler) AddBalanceLeaderScheduler() error {
 	return h.AddScheduler(schedulers.BalanceLeaderType)
@@ -264,6 +323,11 @@ func (h *Handler) AddBalanceRegionScheduler() error {
 	return h.AddScheduler(schedulers.BalanceRegionType)
 }
 
+// AddBalanceLearnerScheduler adds a balance-learner-scheduler.
+func (h *Handler) AddBalanceLearnerScheduler() error {
+	return h.AddScheduler(schedulers.BalanceLearnerType)
+}
+
 // AddBalanceHotRegionScheduler adds a balance-hot-region-scheduler.
 func (h *Handler) AddBalanceHotRegionScheduler() error {
 	return h.AddScheduler(schedulers.HotRegionType)
@@ -284,14 +348,21 @@ func (h *Handler) AddAdjacentRegionScheduler(args ...string) error {
 	return h.AddScheduler(schedulers.AdjacentRegionType, args...)
 }
 
-// AddGrantLeaderScheduler adds a grant-leader-scheduler.
-func (h *Handler) AddGrantLeaderScheduler(storeID uint64) error {
-	return h.AddScheduler(schedulers.GrantLeaderType, strconv.FormatUint(storeID, 10))
+// AddGrantLeaderScheduler adds a grant-leader-scheduler. The args are the
+// optional leader percent and key ranges, such as "50%", "a", "b".
+func (h *Handler) AddGrantLeaderScheduler(storeID uint64, args ...string) error {
+	return h.AddScheduler(schedulers.GrantLeaderType, append([]string{strconv.FormatUint(storeID, 10)}, args...)...)
+}
+
+// AddEvictLeaderScheduler adds an evict-leader-scheduler. The args are the
+// optional leader percent and key ranges, such as "50%", "a", "b".
+func (h *Handler) AddEvictLeaderScheduler(storeID uint64, args ...string) error {
+	return h.AddScheduler(schedulers.EvictLeaderType, append([]string{strconv.FormatUint(storeID, 10)}, args...)...)
 }
 
-// AddEvictLeaderScheduler adds an evict-leader-scheduler.
-func (h *Handler) AddEvictLeaderScheduler(storeID uint64) error {
-	return h.AddScheduler(schedulers.EvictLeaderType, strconv.FormatUint(storeID, 10))
+// AddEvictSlowStoreScheduler adds an evict-slow-store-scheduler.
+func (h *Handler) AddEvictSlowStoreScheduler() error {
+	return h.AddScheduler(schedulers.EvictSlowStoreType)
 }
 
 // AddShuffleLeaderScheduler adds a shuffle-leader-scheduler.
@@ -417,6 +488,15 @@ func (h *Handler) GetHistory(start time.Time) ([]operator.OpHistory, error) {
 	return c.GetHistory(start), nil
 }
 
+// GetOperatorRecords returns the persisted finished operators matching the filter.
+func (h *Handler) GetOperatorRecords(filter *schedule.OperatorRecordFilter) ([]*schedule.OperatorRecord, error) {
+	c, err := h.GetOperatorController()
+	if err != nil {
+		return nil, err
+	}
+	return c.GetOperatorRecords(filter)
+}
+
 // SetAllStoresLimit is used to set limit of all stores.
 func (h *Handler) SetAllStoresLimit(rate float64, limitType storelimit.Type) error {
 	c, err := h.GetOperatorController()
@@ -732,6 +812,44 @@ func (h *Handler) AddSplitRegionOperator(regionID uint64, policyStr string, keys
 	return nil
 }
 
+// SplitRegions splits the regions in the key range at the split keys, or
+// until there are count regions in the range if no split key is given. The
+// keys are in hex format. It returns after the splits are reported.
+func (h *Handler) SplitRegions(ctx context.Context, startKey, endKey string, keys []string, count, limit int) (*schedule.SplitRegionsResult, error) {
+	c, err := h.GetRaftCluster()
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := hex.DecodeString(startKey)
+	if err != nil {
+		return nil, errors.Errorf("start key %s is not in hex format", startKey)
+	}
+	end, err := hex.DecodeString(endKey)
+	if err != nil {
+		return nil, errors.Errorf("end key %s is not in hex format", endKey)
+	}
+	if len(end) > 0 && bytes.Compare(start, end) >= 0 {
+		return nil, errors.New("start key should be less than end key")
+	}
+
+	if len(keys) == 0 {
+		if count <= 0 {
+			return nil, errors.New("either split keys or a positive count is required")
+		}
+		return c.GetRegionSplitter().SplitRegionsByCount(ctx, start, end, count, limit), nil
+	}
+	splitKeys := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		k, err := hex.DecodeString(key)
+		if err != nil {
+			return nil, errors.Errorf("split key %s is not in hex format", key)
+		}
+		splitKeys = append(splitKeys, k)
+	}
+	return c.GetRegionSplitter().SplitRegionsAtKeys(ctx, start, end, splitKeys, limit), nil
+}
+
 // AddScatterRegionOperator adds an operator to scatter a region.
 func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 	c, err := h.GetRaftCluster()
@@ -748,18 +866,43 @@ func (h *Handler) AddScatterRegionOperator(regionID uint64) error {
 		return errors.Errorf("region %d is a hot region", regionID)
 	}
 
-	op, err := c.GetRegionScatter().Scatter(region)
+	return c.GetRegionScatter().Scatter(region, "", func(op *operator.Operator) bool {
+		return c.GetOperatorController().AddOperator(op)
+	})
+}
+
+// ScatterRegions scatters the regions in the same group, so the peers and
+// leaders of them are spread evenly. It returns the reasons of the regions
+// failed to be scattered.
+func (h *Handler) ScatterRegions(regionIDs []uint64, group string) (map[uint64]string, error) {
+	c, err := h.GetRaftCluster()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	if op == nil {
-		return nil
+	failures := make(map[uint64]string)
+	regions := make([]*core.RegionInfo, 0, len(regionIDs))
+	for _, id := range regionIDs {
+		region := c.GetRegion(id)
+		if region == nil {
+			failures[id] = ErrRegionNotFound(id).Error()
+			continue
+		}
+		if c.IsRegionHot(region) {
+			failures[id] = errors.Errorf("region %d is a hot region", id).Error()
+			continue
+		}
+		regions = append(regions, region)
 	}
-	if ok := c.GetOperatorController().AddOperator(op); !ok {
-		return errors.WithStack(ErrAddOperator)
+
+	scatterFailures := make(map[uint64]error)
+	c.GetRegionScatter().ScatterRegions(regions, group, func(op *operator.Operator) bool {
+		return c.GetOperatorController().AddOperator(op)
+	}, scatterFailures)
+	for id, err := range scatterFailures {
+		failures[id] = err.Error()
 	}
-	return nil
+	return failures, nil
 }
 
 // GetDownPeerRegions gets the region with down peer.
@@ -82,6 +82,23 @@ func (kv *etcdKVBase) LoadRange(key, endKey string, limit int) ([]string, []stri
 	return keys, values, nil
 }
 
+func (kv *etcdKVBase) LoadRangeKeys(key, endKey string, limit int) ([]string, error) {
+	key = path.Join(kv.rootPath, key)
+	endKey = path.Join(kv.rootPath, endKey)
+
+	withRange := clientv3.WithRange(endKey)
+	withLimit := clientv3.WithLimit(int64(limit))
+	resp, err := etcdutil.EtcdKVGet(kv.client, key, withRange, withLimit, clientv3.WithKeysOnly())
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(resp.Kvs))
+	for _, item := range resp.Kvs {
+		keys = append(keys, strings.TrimPrefix(strings.TrimPrefix(string(item.Key), kv.rootPath), "/"))
+	}
+	return keys, nil
+}
+
 func (kv *etcdKVBase) Save(key, value string) error {
 	key = path.Join(kv.rootPath, key)
 
@@ -112,6 +129,33 @@ func (kv *etcdKVBase) Remove(key string) error {
 	return nil
 }
 
+func (kv *etcdKVBase) Batch(ops []Op) error {
+	if len(ops) > MaxBatchOps {
+		return errors.Errorf("too many operations in a batch: %d > %d", len(ops), MaxBatchOps)
+	}
+	etcdOps := make([]clientv3.Op, 0, len(ops))
+	for _, op := range ops {
+		key := path.Join(kv.rootPath, op.Key)
+		switch op.Type {
+		case OpTypeSave:
+			etcdOps = append(etcdOps, clientv3.OpPut(key, op.Value))
+		case OpTypeRemove:
+			etcdOps = append(etcdOps, clientv3.OpDelete(key))
+		}
+	}
+
+	txn := NewSlowLogTxn(kv.client)
+	resp, err := txn.Then(etcdOps...).Commit()
+	if err != nil {
+		log.Error("batch update etcd meet error", zap.Error(err))
+		return errors.WithStack(err)
+	}
+	if !resp.Succeeded {
+		return errors.WithStack(errTxnFailed)
+	}
+	return nil
+}
+
 // SlowLogTxn wraps etcd transaction and log slow one.
 type SlowLogTxn struct {
 	clientv3.Txn
@@ -78,6 +78,9 @@ func (s *testEtcdKVSuite) TestEtcdKV(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(ks, DeepEquals, keys[:3])
 	c.Assert(vs, DeepEquals, vals[:3])
+	ks, err = kv.LoadRangeKeys(keys[0], "test/zzz", 3)
+	c.Assert(err, IsNil)
+	c.Assert(ks, DeepEquals, keys[:3])
 
 	v, err = kv.Load(keys[1])
 	c.Assert(err, IsNil)
@@ -87,6 +90,18 @@ func (s *testEtcdKVSuite) TestEtcdKV(c *C) {
 	c.Assert(err, IsNil)
 	c.Assert(v, Equals, "")
 
+	err = kv.Batch([]Op{OpSave(keys[1], "new2"), OpSave(keys[2], "new3"), OpRemove(keys[3])})
+	c.Assert(err, IsNil)
+	ks, vs, err = kv.LoadRange(keys[0], "test/zzz", 100)
+	c.Assert(err, IsNil)
+	c.Assert(ks, DeepEquals, []string{keys[0], keys[1], keys[2], keys[4]})
+	c.Assert(vs, DeepEquals, []string{vals[0], "new2", "new3", vals[4]})
+	ops := make([]Op, 0, MaxBatchOps+1)
+	for i := 0; i <= MaxBatchOps; i++ {
+		ops = append(ops, OpRemove(keys[0]))
+	}
+	c.Assert(kv.Batch(ops), NotNil)
+
 	etcd.Close()
 	cleanConfig(cfg)
 }
@@ -17,6 +17,42 @@ package kv
 type Base interface {
 	Load(key string) (string, error)
 	LoadRange(key, endKey string, limit int) (keys []string, values []string, err error)
+	// LoadRangeKeys is like LoadRange but only loads the keys.
+	LoadRangeKeys(key, endKey string, limit int) (keys []string, err error)
 	Save(key, value string) error
 	Remove(key string) error
+	// Batch applies all operations atomically. There should be at most
+	// MaxBatchOps operations.
+	Batch(ops []Op) error
+}
+
+// MaxBatchOps is the max number of operations in a batch, which is the
+// default limit of the operations in an etcd transaction.
+const MaxBatchOps = 128
+
+// OpType is the type of an Op.
+type OpType int
+
+const (
+	// OpTypeSave stores a key-value pair.
+	OpTypeSave OpType = iota
+	// OpTypeRemove deletes a key.
+	OpTypeRemove
+)
+
+// Op is a write operation that is applied in a batch.
+type Op struct {
+	Type  OpType
+	Key   string
+	Value string
+}
+
+// OpSave returns an Op that stores a key-value pair.
+func OpSave(key, value string) Op {
+	return Op{Type: OpTypeSave, Key: key, Value: value}
+}
+
+// OpRemove returns an Op that deletes a key.
+func OpRemove(key string) Op {
+	return Op{Type: OpTypeRemove, Key: key}
 }
@@ -62,6 +62,20 @@ func (kv *LeveldbKV) LoadRange(startKey, endKey string, limit int) ([]string, []
 	return keys, values, nil
 }
 
+// LoadRangeKeys gets a range of keys for a given key range.
+func (kv *LeveldbKV) LoadRangeKeys(startKey, endKey string, limit int) ([]string, error) {
+	iter := kv.NewIterator(&util.Range{Start: []byte(startKey), Limit: []byte(endKey)}, nil)
+	keys := make([]string, 0, limit)
+	for iter.Next() {
+		if limit > 0 && len(keys) >= limit {
+			break
+		}
+		keys = append(keys, string(iter.Key()))
+	}
+	iter.Release()
+	return keys, nil
+}
+
 // Save stores a key-value pair.
 func (kv *LeveldbKV) Save(key, value string) error {
 	return errors.WithStack(kv.Put([]byte(key), []byte(value), nil))
@@ -72,6 +86,20 @@ func (kv *LeveldbKV) Remove(key string) error {
 	return errors.WithStack(kv.Delete([]byte(key), nil))
 }
 
+// Batch applies all operations atomically.
+func (kv *LeveldbKV) Batch(ops []Op) error {
+	batch := new(leveldb.Batch)
+	for _, op := range ops {
+		switch op.Type {
+		case OpTypeSave:
+			batch.Put([]byte(op.Key), []byte(op.Value))
+		case OpTypeRemove:
+			batch.Delete([]byte(op.Key))
+		}
+	}
+	return errors.WithStack(kv.Write(batch, nil))
+}
+
 // SaveRegions stores some regions.
 func (kv *LeveldbKV) SaveRegions(regions map[string]*metapb.Region) error {
 	batch := new(leveldb.Batch)
@@ -65,6 +65,20 @@ func (kv *memoryKV) LoadRange(key, endKey string, limit int) ([]string, []string
 	return keys, values, nil
 }
 
+func (kv *memoryKV) LoadRangeKeys(key, endKey string, limit int) ([]string, error) {
+	kv.RLock()
+	defer kv.RUnlock()
+	keys := make([]string, 0, limit)
+	kv.tree.AscendRange(memoryKVItem{key, ""}, memoryKVItem{endKey, ""}, func(item btree.Item) bool {
+		keys = append(keys, item.(memoryKVItem).key)
+		if limit > 0 {
+			return len(keys) < limit
+		}
+		return true
+	})
+	return keys, nil
+}
+
 func (kv *memoryKV) Save(key, value string) error {
 	kv.Lock()
 	defer kv.Unlock()
@@ -79,3 +93,18 @@ func (kv *memoryKV) Remove(key string) error {
 	kv.tree.Delete(memoryKVItem{key, ""})
 	return nil
 }
+
+func (kv *memoryKV) Batch(ops []Op) error {
+	kv.Lock()
+	defer kv.Unlock()
+
+	for _, op := range ops {
+		switch op.Type {
+		case OpTypeSave:
+			kv.tree.ReplaceOrInsert(memoryKVItem{op.Key, op.Value})
+		case OpTypeRemove:
+			kv.tree.Delete(memoryKVItem{op.Key, ""})
+		}
+	}
+	return nil
+}
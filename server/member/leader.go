@@ -430,6 +430,55 @@ func (m *Member) SetMemberGitHash(id uint64, gitHash string) error {
 	return nil
 }
 
+func (m *Member) getMemberTSOSuffixPath(id uint64) string {
+	return path.Join(m.rootPath, fmt.Sprintf("member/%d/tso_suffix", id))
+}
+
+func formatTSOSuffix(bits int, suffix int64) string {
+	return fmt.Sprintf("%d/%d", bits, suffix)
+}
+
+// SetMemberTSOSuffix saves a member's tso-suffix-bits and tso-suffix.
+func (m *Member) SetMemberTSOSuffix(id uint64, bits int, suffix int64) error {
+	key := m.getMemberTSOSuffixPath(id)
+	txn := kv.NewSlowLogTxn(m.client)
+	res, err := txn.Then(clientv3.OpPut(key, formatTSOSuffix(bits, suffix))).Commit()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !res.Succeeded {
+		return errors.New("failed to save tso suffix")
+	}
+	return nil
+}
+
+// CheckTSOSuffix checks the tso-suffix-bits and tso-suffix are the same as
+// the ones of the current leader, since a follower may proxy the TSO requests
+// to the leader and the clients learn the bits from any member. It passes if
+// there is no leader, or the leader doesn't save them, which is the case of
+// a leader of an older version.
+func (m *Member) CheckTSOSuffix(bits int, suffix int64) error {
+	leader, _, err := getLeader(m.client, m.GetLeaderPath())
+	if err != nil {
+		return err
+	}
+	if leader == nil || m.isSameLeader(leader) {
+		return nil
+	}
+	res, err := etcdutil.EtcdKVGet(m.client, m.getMemberTSOSuffixPath(leader.GetMemberId()))
+	if err != nil {
+		return err
+	}
+	if len(res.Kvs) == 0 {
+		return nil
+	}
+	if expected := string(res.Kvs[0].Value); expected != formatTSOSuffix(bits, suffix) {
+		return errors.Errorf("tso-suffix-bits/tso-suffix %s mismatches %s of the leader %s",
+			formatTSOSuffix(bits, suffix), expected, leader.GetName())
+	}
+	return nil
+}
+
 func (m *Member) deleteLeaderKey() error {
 	// delete leader itself and let others start a new election again.
 	leaderKey := m.GetLeaderPath()
@@ -15,6 +15,7 @@ package syncer
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/pdpb"
@@ -45,6 +46,18 @@ func (s *RegionSyncer) StopSyncWithLeader() {
 	s.wg.Wait()
 }
 
+// GetLastSyncTime returns the last time the server received the regions or the
+// keepalive from the leader. It returns the zero time if the server has never
+// synced with the leader. The leader sends the keepalive every 10 seconds, so
+// the regions are stale if the time is much earlier than that.
+func (s *RegionSyncer) GetLastSyncTime() time.Time {
+	last := atomic.LoadInt64(&s.lastSyncTime)
+	if last == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, last)
+}
+
 func (s *RegionSyncer) reset() {
 	s.Lock()
 	defer s.Unlock()
@@ -206,6 +219,7 @@ func (s *RegionSyncer) StartSyncWithLeader(addr string) {
 						s.history.Record(region)
 					}
 				}
+				atomic.StoreInt64(&s.lastSyncTime, time.Now().UnixNano())
 			}
 		}
 	}()
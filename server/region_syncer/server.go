@@ -76,6 +76,9 @@ type RegionSyncer struct {
 	history            *historyBuffer
 	limit              *ratelimit.Bucket
 	securityConfig     *grpcutil.SecurityConfig
+	// lastSyncTime is the last time, in unix nanoseconds, the server received
+	// the regions or the keepalive from the leader.
+	lastSyncTime int64
 }
 
 // NewRegionSyncer returns a region syncer.
@@ -0,0 +1,292 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ActiveWindowOnly means the scheduler only works in the periods.
+	ActiveWindowOnly = "only"
+	// ActiveWindowExcept means the scheduler works except in the periods.
+	ActiveWindowExcept = "except"
+
+	minutesPerDay = 24 * 60
+	allWeekdays   = 1<<7 - 1
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// activePeriod is a daily period in UTC on some weekdays. The period crosses
+// midnight if the end is not after the start, and belongs to the weekday it
+// starts in.
+type activePeriod struct {
+	weekdays uint8 // bit i is set if the period is on time.Weekday(i)
+	start    int   // minutes from midnight
+	end      int   // minutes from midnight, 24:00 is allowed
+}
+
+func (p activePeriod) onWeekday(d time.Weekday) bool {
+	return p.weekdays&(1<<uint(d)) != 0
+}
+
+func (p activePeriod) contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	d := t.Weekday()
+	if p.start < p.end {
+		return p.onWeekday(d) && m >= p.start && m < p.end
+	}
+	return (p.onWeekday(d) && m >= p.start) || (p.onWeekday((d+6)%7) && m < p.end)
+}
+
+// ActiveWindow limits the time a scheduler or a checker works. It is
+// described by a spec like "only 01:00-06:00" or "except Fri 18:00-20:00".
+// Multiple periods are separated by ";", such as
+// "only Mon-Fri 01:00-06:00; Sat,Sun 00:00-24:00". All times are in UTC.
+type ActiveWindow struct {
+	spec    string
+	except  bool
+	periods []activePeriod
+}
+
+// ParseActiveWindow parses the spec of an ActiveWindow.
+func ParseActiveWindow(spec string) (*ActiveWindow, error) {
+	spec = strings.TrimSpace(spec)
+	fields := strings.Fields(spec)
+	if len(fields) < 2 {
+		return nil, errors.Errorf("invalid active window %q", spec)
+	}
+	w := &ActiveWindow{spec: strings.Join(fields, " ")}
+	switch strings.ToLower(fields[0]) {
+	case ActiveWindowOnly:
+	case ActiveWindowExcept:
+		w.except = true
+	default:
+		return nil, errors.Errorf("invalid active window %q, it should start with %q or %q", spec, ActiveWindowOnly, ActiveWindowExcept)
+	}
+	for _, s := range strings.Split(strings.Join(fields[1:], " "), ";") {
+		p, err := parseActivePeriod(s)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "invalid active window %q", spec)
+		}
+		w.periods = append(w.periods, p)
+	}
+	return w, nil
+}
+
+// parseActivePeriod parses a period like "01:00-06:00" or "Mon-Fri,Sun 22:00-02:00".
+func parseActivePeriod(s string) (activePeriod, error) {
+	fields := strings.Fields(s)
+	p := activePeriod{weekdays: allWeekdays}
+	var err error
+	switch len(fields) {
+	case 1:
+	case 2:
+		if p.weekdays, err = parseWeekdays(fields[0]); err != nil {
+			return p, err
+		}
+	default:
+		return p, errors.Errorf("invalid period %q", strings.TrimSpace(s))
+	}
+	times := strings.Split(fields[len(fields)-1], "-")
+	if len(times) != 2 {
+		return p, errors.Errorf("invalid period %q", strings.TrimSpace(s))
+	}
+	if p.start, err = parseMinutes(times[0]); err != nil {
+		return p, err
+	}
+	if p.end, err = parseMinutes(times[1]); err != nil {
+		return p, err
+	}
+	if p.start == p.end || p.start == minutesPerDay {
+		return p, errors.Errorf("invalid period %q", strings.TrimSpace(s))
+	}
+	return p, nil
+}
+
+func parseWeekdays(s string) (uint8, error) {
+	var weekdays uint8
+	for _, item := range strings.Split(s, ",") {
+		bounds := strings.Split(item, "-")
+		if len(bounds) > 2 {
+			return 0, errors.Errorf("invalid weekdays %q", s)
+		}
+		first, ok := weekdayNames[strings.ToLower(bounds[0])]
+		if !ok {
+			return 0, errors.Errorf("invalid weekday %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdayNames[strings.ToLower(bounds[1])]; !ok {
+				return 0, errors.Errorf("invalid weekday %q", bounds[1])
+			}
+		}
+		for d := first; ; d = (d + 1) % 7 {
+			weekdays |= 1 << uint(d)
+			if d == last {
+				break
+			}
+		}
+	}
+	return weekdays, nil
+}
+
+// parseMinutes parses "HH:MM" to the minutes from midnight.
+func parseMinutes(s string) (int, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, errors.Errorf("invalid time %q", s)
+	}
+	h, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q", s)
+	}
+	m, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, errors.Errorf("invalid time %q", s)
+	}
+	if h < 0 || m < 0 || m >= 60 || h*60+m > minutesPerDay {
+		return 0, errors.Errorf("invalid time %q", s)
+	}
+	return h*60 + m, nil
+}
+
+// String returns the spec of the window.
+func (w *ActiveWindow) String() string {
+	if w == nil {
+		return ""
+	}
+	return w.spec
+}
+
+// IsActive returns if the time is in the window. A nil window is always
+// active.
+func (w *ActiveWindow) IsActive(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	t = t.UTC()
+	for _, p := range w.periods {
+		if p.contains(t) {
+			return !w.except
+		}
+	}
+	return w.except
+}
+
+// NextTransition returns the first time after t at which the window turns
+// from active to inactive or vice versa. It returns the zero time if the
+// state never changes.
+func (w *ActiveWindow) NextTransition(t time.Time) time.Time {
+	if w == nil {
+		return time.Time{}
+	}
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	var boundaries []time.Time
+	// The boundaries of the periods starting from yesterday to a week later
+	// cover all the transitions in the next week.
+	for i := -1; i <= 7; i++ {
+		d := day.AddDate(0, 0, i)
+		for _, p := range w.periods {
+			if !p.onWeekday(d.Weekday()) {
+				continue
+			}
+			end := p.end
+			if p.end <= p.start {
+				end += minutesPerDay
+			}
+			for _, m := range []int{p.start, end} {
+				if b := d.Add(time.Duration(m) * time.Minute); b.After(t) {
+					boundaries = append(boundaries, b)
+				}
+			}
+		}
+	}
+	sort.Slice(boundaries, func(i, j int) bool { return boundaries[i].Before(boundaries[j]) })
+	active := w.IsActive(t)
+	for _, b := range boundaries {
+		if w.IsActive(b) != active {
+			return b
+		}
+	}
+	return time.Time{}
+}
+
+// ActiveWindowStatus is the state of the active window of a scheduler or a
+// checker.
+type ActiveWindowStatus struct {
+	Spec           string     `json:"spec,omitempty"`
+	Active         bool       `json:"active"`
+	NextTransition *time.Time `json:"next-transition,omitempty"`
+}
+
+// Status returns the state of the window at the time.
+func (w *ActiveWindow) Status(t time.Time) *ActiveWindowStatus {
+	status := &ActiveWindowStatus{
+		Spec:   w.String(),
+		Active: w.IsActive(t),
+	}
+	if next := w.NextTransition(t); !next.IsZero() {
+		status.NextTransition = &next
+	}
+	return status
+}
+
+type activeWindowConfig struct {
+	Spec string `json:"spec"`
+}
+
+// SaveActiveWindow persists the active window of the scheduler or the
+// checker, a nil window removes the persisted one.
+func SaveActiveWindow(storage *core.Storage, name string, w *ActiveWindow) error {
+	if w == nil {
+		return storage.RemoveActiveWindow(name)
+	}
+	data, err := json.Marshal(&activeWindowConfig{Spec: w.String()})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return storage.SaveActiveWindow(name, data)
+}
+
+// LoadActiveWindow loads the persisted active window of the scheduler or the
+// checker, it returns nil if there is no active window.
+func LoadActiveWindow(storage *core.Storage, name string) (*ActiveWindow, error) {
+	data, err := storage.LoadActiveWindow(name)
+	if err != nil || data == "" {
+		return nil, err
+	}
+	cfg := &activeWindowConfig{}
+	if err := json.Unmarshal([]byte(data), cfg); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ParseActiveWindow(cfg.Spec)
+}
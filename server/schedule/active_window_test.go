@@ -0,0 +1,142 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+)
+
+var _ = Suite(&testActiveWindowSuite{})
+
+type testActiveWindowSuite struct{}
+
+// utc returns the time of 2020-06-01 (Monday) plus the days, hours and minutes.
+func utc(days, hours, minutes int) time.Time {
+	return time.Date(2020, 6, 1+days, hours, minutes, 0, 0, time.UTC)
+}
+
+func (s *testActiveWindowSuite) TestParse(c *C) {
+	for _, spec := range []string{
+		"only 01:00-06:00",
+		"except Fri 18:00-20:00",
+		"ONLY mon-fri,sun 22:00-02:00",
+		"only Sat,Sun 00:00-24:00; Mon-Fri 01:00-06:00",
+		"except Fri-Mon 23:30-00:30",
+	} {
+		w, err := ParseActiveWindow(spec)
+		c.Assert(err, IsNil, Commentf("spec: %s", spec))
+		c.Assert(w.String(), Equals, spec)
+	}
+	for _, spec := range []string{
+		"",
+		"only",
+		"always 01:00-06:00",
+		"only 01:00",
+		"only 01:00-01:00",
+		"only 24:00-01:00",
+		"only 01:00-25:00",
+		"only 01:60-02:00",
+		"only Fri-Sat-Sun 01:00-02:00",
+		"only Fri Sat 01:00-02:00",
+		"only Friday 01:00-02:00",
+		"only 01:00-02:00;",
+	} {
+		_, err := ParseActiveWindow(spec)
+		c.Assert(err, NotNil, Commentf("spec: %s", spec))
+	}
+}
+
+func (s *testActiveWindowSuite) TestIsActive(c *C) {
+	var nilWindow *ActiveWindow
+	c.Assert(nilWindow.IsActive(utc(0, 0, 0)), IsTrue)
+	c.Assert(nilWindow.NextTransition(utc(0, 0, 0)).IsZero(), IsTrue)
+
+	w, err := ParseActiveWindow("only 01:00-06:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.IsActive(utc(0, 0, 59)), IsFalse)
+	c.Assert(w.IsActive(utc(0, 1, 0)), IsTrue)
+	c.Assert(w.IsActive(utc(3, 5, 59)), IsTrue)
+	c.Assert(w.IsActive(utc(3, 6, 0)), IsFalse)
+	// Other time zones are converted to UTC.
+	c.Assert(w.IsActive(utc(0, 2, 0).In(time.FixedZone("UTC+8", 8*3600))), IsTrue)
+
+	w, err = ParseActiveWindow("except Fri 18:00-20:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.IsActive(utc(3, 19, 0)), IsTrue)
+	c.Assert(w.IsActive(utc(4, 19, 0)), IsFalse)
+	c.Assert(w.IsActive(utc(4, 20, 0)), IsTrue)
+
+	// The period crossing midnight belongs to the weekday it starts in.
+	w, err = ParseActiveWindow("only Sun 22:00-02:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.IsActive(utc(-1, 23, 0)), IsTrue)
+	c.Assert(w.IsActive(utc(0, 1, 0)), IsTrue)
+	c.Assert(w.IsActive(utc(0, 23, 0)), IsFalse)
+	c.Assert(w.IsActive(utc(1, 1, 0)), IsFalse)
+}
+
+func (s *testActiveWindowSuite) TestNextTransition(c *C) {
+	w, err := ParseActiveWindow("only 01:00-06:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.NextTransition(utc(0, 0, 30)), Equals, utc(0, 1, 0))
+	c.Assert(w.NextTransition(utc(0, 1, 0)), Equals, utc(0, 6, 0))
+	c.Assert(w.NextTransition(utc(0, 7, 0)), Equals, utc(1, 1, 0))
+
+	w, err = ParseActiveWindow("except Fri 18:00-20:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.NextTransition(utc(0, 0, 0)), Equals, utc(4, 18, 0))
+	c.Assert(w.NextTransition(utc(4, 18, 30)), Equals, utc(4, 20, 0))
+	c.Assert(w.NextTransition(utc(4, 21, 0)), Equals, utc(11, 18, 0))
+
+	// The adjacent periods are merged.
+	w, err = ParseActiveWindow("only Mon 20:00-24:00; Tue 00:00-02:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.NextTransition(utc(0, 21, 0)), Equals, utc(1, 2, 0))
+
+	// The state never changes.
+	w, err = ParseActiveWindow("only 00:00-24:00")
+	c.Assert(err, IsNil)
+	c.Assert(w.IsActive(utc(2, 12, 0)), IsTrue)
+	c.Assert(w.NextTransition(utc(2, 12, 0)).IsZero(), IsTrue)
+	status := w.Status(utc(2, 12, 0))
+	c.Assert(status.Active, IsTrue)
+	c.Assert(status.NextTransition, IsNil)
+}
+
+func (s *testActiveWindowSuite) TestPersist(c *C) {
+	storage := core.NewStorage(kv.NewMemoryKV())
+	w, err := LoadActiveWindow(storage, "balance-region-scheduler")
+	c.Assert(err, IsNil)
+	c.Assert(w, IsNil)
+
+	w, err = ParseActiveWindow("except Fri 18:00-20:00")
+	c.Assert(err, IsNil)
+	c.Assert(SaveActiveWindow(storage, "balance-region-scheduler", w), IsNil)
+	names, _, err := storage.LoadAllScheduleConfig()
+	c.Assert(err, IsNil)
+	// The active windows are not mixed with the scheduler configs.
+	c.Assert(names, HasLen, 0)
+	loaded, err := LoadActiveWindow(storage, "balance-region-scheduler")
+	c.Assert(err, IsNil)
+	c.Assert(loaded.String(), Equals, w.String())
+
+	c.Assert(SaveActiveWindow(storage, "balance-region-scheduler", nil), IsNil)
+	loaded, err = LoadActiveWindow(storage, "balance-region-scheduler")
+	c.Assert(err, IsNil)
+	c.Assert(loaded, IsNil)
+}
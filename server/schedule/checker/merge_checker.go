@@ -16,23 +16,72 @@ package checker
 import (
 	"bytes"
 	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/pkg/cache"
 	"github.com/pingcap/pd/v4/pkg/codec"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"go.uber.org/zap"
 )
 
+// mergeCandidateTTL is the time to keep a skipped merge candidate if the
+// region is not checked again.
+var mergeCandidateTTL = 10 * time.Minute
+
+// MergeCandidate is a region small enough to be merged but skipped by the
+// merge checker.
+type MergeCandidate struct {
+	RegionID uint64 `json:"region_id"`
+	// Range is the ID of the label rule deciding the merge policy of the
+	// region, or the table of the region if there is no such rule.
+	Range      string    `json:"range"`
+	Size       int64     `json:"approximate_size"`
+	Keys       int64     `json:"approximate_keys"`
+	Reason     string    `json:"reason"`
+	UpdateTime time.Time `json:"update_time"`
+}
+
+// mergePolicy is the merge policy of a region decided by the label rules
+// covering it.
+type mergePolicy struct {
+	rangeName string
+	// ruleID is the ID of the label rule deciding the policy, it is empty if
+	// there is no such rule.
+	ruleID      string
+	deny        bool
+	maxSize     uint64
+	maxKeys     uint64
+	sizeLimited bool
+	keysLimited bool
+}
+
+// metricLabel returns the label of the policy in the metrics. The table IDs
+// are not used to keep the cardinality bounded by the label rules.
+func (p *mergePolicy) metricLabel() string {
+	if p.ruleID == "" {
+		return "default"
+	}
+	return p.ruleID
+}
+
 // MergeChecker ensures region to merge with adjacent region when size is small
 type MergeChecker struct {
 	cluster    opt.Cluster
 	splitCache *cache.TTLUint64
 	startTime  time.Time // it's used to judge whether server recently start.
+
+	mu         sync.Mutex
+	candidates map[uint64]*MergeCandidate
+	lastPrune  time.Time
 }
 
 // NewMergeChecker creates a merge checker.
@@ -42,6 +91,8 @@ func NewMergeChecker(ctx context.Context, cluster opt.Cluster) *MergeChecker {
 		cluster:    cluster,
 		splitCache: splitCache,
 		startTime:  time.Now(),
+		candidates: make(map[uint64]*MergeCandidate),
+		lastPrune:  time.Now(),
 	}
 }
 
@@ -77,27 +128,35 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 		return nil
 	}
 
+	policy := m.getMergePolicy(region)
 	// region is not small enough
-	if region.GetApproximateSize() > int64(m.cluster.GetMaxMergeRegionSize()) ||
-		region.GetApproximateKeys() > int64(m.cluster.GetMaxMergeRegionKeys()) {
+	if region.GetApproximateSize() > int64(policy.maxSize) ||
+		region.GetApproximateKeys() > int64(policy.maxKeys) {
 		checkerCounter.WithLabelValues("merge_checker", "no-need").Inc()
+		m.removeCandidate(region.GetID())
 		return nil
 	}
 
 	// skip region has down peers or pending peers or learner peers
 	if !opt.IsRegionHealthy(m.cluster, region) {
-		checkerCounter.WithLabelValues("merge_checker", "special-peer").Inc()
+		m.skipCandidate(region, policy, "special-peer")
 		return nil
 	}
 
 	if !opt.IsRegionReplicated(m.cluster, region) {
-		checkerCounter.WithLabelValues("merge_checker", "abnormal-replica").Inc()
+		m.skipCandidate(region, policy, "abnormal-replica")
 		return nil
 	}
 
 	// skip hot region
 	if m.cluster.IsRegionHot(region) {
-		checkerCounter.WithLabelValues("merge_checker", "hot-region").Inc()
+		m.skipCandidate(region, policy, "hot-region")
+		return nil
+	}
+
+	// skip region which is labeled to deny merge
+	if policy.deny {
+		m.skipCandidate(region, policy, "merge-option-deny")
 		return nil
 	}
 
@@ -114,7 +173,7 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 	}
 
 	if target == nil {
-		checkerCounter.WithLabelValues("merge_checker", "no-target").Inc()
+		m.skipCandidate(region, policy, "no-target")
 		return nil
 	}
 
@@ -125,6 +184,7 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 		return nil
 	}
 	checkerCounter.WithLabelValues("merge_checker", "new-operator").Inc()
+	m.removeCandidate(region.GetID())
 	if region.GetApproximateSize() > target.GetApproximateSize() ||
 		region.GetApproximateKeys() > target.GetApproximateKeys() {
 		checkerCounter.WithLabelValues("merge_checker", "larger-source").Inc()
@@ -132,9 +192,117 @@ func (m *MergeChecker) Check(region *core.RegionInfo) []*operator.Operator {
 	return ops
 }
 
+// getMergePolicy returns the merge policy of the region. The labels of the
+// rules covering the region override the global config.
+func (m *MergeChecker) getMergePolicy(region *core.RegionInfo) *mergePolicy {
+	policy := &mergePolicy{
+		maxSize: m.cluster.GetMaxMergeRegionSize(),
+		maxKeys: m.cluster.GetMaxMergeRegionKeys(),
+	}
+	if l := m.cluster.GetRegionLabeler(); l != nil {
+		// The rule with the greatest ID wins.
+		for _, rule := range l.GetRegionLabelRules(region) {
+			for _, label := range rule.Labels {
+				switch label.Key {
+				case labeler.MergeOptionLabel:
+					policy.deny = label.Value == labeler.MergeOptionValueDeny
+				case labeler.MaxMergeRegionSizeLabel:
+					policy.maxSize, _ = strconv.ParseUint(label.Value, 10, 64)
+					policy.sizeLimited = true
+				case labeler.MaxMergeRegionKeysLabel:
+					policy.maxKeys, _ = strconv.ParseUint(label.Value, 10, 64)
+					policy.keysLimited = true
+				default:
+					continue
+				}
+				policy.ruleID = rule.ID
+			}
+		}
+	}
+	policy.rangeName = policy.ruleID
+	if policy.rangeName == "" {
+		if tableID := codec.Key(region.GetStartKey()).TableID(); tableID != 0 {
+			policy.rangeName = fmt.Sprintf("table-%d", tableID)
+		} else {
+			policy.rangeName = "default"
+		}
+	}
+	return policy
+}
+
+func (m *MergeChecker) skipCandidate(region *core.RegionInfo, policy *mergePolicy, reason string) {
+	checkerCounter.WithLabelValues("merge_checker", reason).Inc()
+	mergeSkipCounter.WithLabelValues(policy.metricLabel(), reason).Inc()
+	now := time.Now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.candidates[region.GetID()] = &MergeCandidate{
+		RegionID:   region.GetID(),
+		Range:      policy.rangeName,
+		Size:       region.GetApproximateSize(),
+		Keys:       region.GetApproximateKeys(),
+		Reason:     reason,
+		UpdateTime: now,
+	}
+	if now.Sub(m.lastPrune) > mergeCandidateTTL {
+		m.pruneCandidatesLocked(now)
+	}
+}
+
+func (m *MergeChecker) removeCandidate(regionID uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.candidates, regionID)
+}
+
+func (m *MergeChecker) pruneCandidatesLocked(now time.Time) {
+	for id, c := range m.candidates {
+		if now.Sub(c.UpdateTime) > mergeCandidateTTL {
+			delete(m.candidates, id)
+		}
+	}
+	m.lastPrune = now
+}
+
+// GetMergeCandidates returns the regions skipped by the merge checker recently
+// sorted by the range and the region ID. An empty rangeName means all ranges.
+func (m *MergeChecker) GetMergeCandidates(rangeName string) []*MergeCandidate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pruneCandidatesLocked(time.Now())
+	candidates := make([]*MergeCandidate, 0, len(m.candidates))
+	for _, c := range m.candidates {
+		if rangeName == "" || c.Range == rangeName {
+			cc := *c
+			candidates = append(candidates, &cc)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Range != candidates[j].Range {
+			return candidates[i].Range < candidates[j].Range
+		}
+		return candidates[i].RegionID < candidates[j].RegionID
+	})
+	return candidates
+}
+
 func (m *MergeChecker) checkTarget(region, adjacent *core.RegionInfo) bool {
 	return adjacent != nil && !m.cluster.IsRegionHot(adjacent) && AllowMerge(m.cluster, region, adjacent) &&
-		opt.IsRegionHealthy(m.cluster, adjacent) && opt.IsRegionReplicated(m.cluster, adjacent)
+		opt.IsRegionHealthy(m.cluster, adjacent) && opt.IsRegionReplicated(m.cluster, adjacent) &&
+		m.checkTargetLimit(region, adjacent)
+}
+
+// checkTargetLimit returns false if the merged region exceeds the max merge
+// size or keys which the label rules of the target override.
+func (m *MergeChecker) checkTargetLimit(region, target *core.RegionInfo) bool {
+	policy := m.getMergePolicy(target)
+	if policy.sizeLimited && region.GetApproximateSize()+target.GetApproximateSize() > int64(policy.maxSize) {
+		return false
+	}
+	if policy.keysLimited && region.GetApproximateKeys()+target.GetApproximateKeys() > int64(policy.maxKeys) {
+		return false
+	}
+	return true
 }
 
 // AllowMerge returns true if two regions can be merged according to the key type.
@@ -147,6 +315,9 @@ func AllowMerge(cluster opt.Cluster, region *core.RegionInfo, adjacent *core.Reg
 	} else {
 		return false
 	}
+	if isMergeDenied(cluster, region) || isMergeDenied(cluster, adjacent) {
+		return false
+	}
 	if cluster.IsPlacementRulesEnabled() {
 		type withRuleManager interface {
 			GetRuleManager() *placement.RuleManager
@@ -172,6 +343,12 @@ func AllowMerge(cluster opt.Cluster, region *core.RegionInfo, adjacent *core.Reg
 	}
 }
 
+// isMergeDenied returns if the region is labeled with merge_option=deny.
+func isMergeDenied(cluster opt.Cluster, region *core.RegionInfo) bool {
+	l := cluster.GetRegionLabeler()
+	return l != nil && l.GetRegionLabel(region, labeler.MergeOptionLabel) == labeler.MergeOptionValueDeny
+}
+
 func isTableIDSame(region *core.RegionInfo, adjacent *core.RegionInfo) bool {
 	return codec.Key(region.GetStartKey()).TableID() == codec.Key(adjacent.GetStartKey()).TableID()
 }
@@ -25,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
@@ -185,6 +186,23 @@ func (s *testMergeCheckerSuite) TestBasic(c *C) {
 	c.Assert(ops[1].RegionID(), Equals, s.regions[1].GetID())
 	s.cluster.RuleManager.DeleteRule("test", "test")
 
+	// merge cannot happen if the region or the target is labeled to deny merge.
+	labelRule := &labeler.LabelRule{
+		ID:     "test",
+		Labels: []labeler.RegionLabel{{Key: labeler.MergeOptionLabel, Value: labeler.MergeOptionValueDeny}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("a")), EndKeyHex: hex.EncodeToString([]byte("t"))}},
+	}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(labelRule), IsNil)
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, IsNil)
+	labelRule.Ranges = []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("t")), EndKeyHex: hex.EncodeToString([]byte("x"))}}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(labelRule), IsNil)
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, IsNil)
+	c.Assert(s.cluster.GetRegionLabeler().DeleteLabelRule("test"), IsNil)
+	ops = s.mc.Check(s.regions[2])
+	c.Assert(ops, NotNil)
+
 	// Skip recently split regions.
 	s.cluster.ScheduleOptions.SplitMergeInterval = time.Hour
 	s.mc.RecordRegionSplit([]uint64{s.regions[2].GetID()})
@@ -194,6 +212,87 @@ func (s *testMergeCheckerSuite) TestBasic(c *C) {
 	c.Assert(ops, IsNil)
 }
 
+func (s *testMergeCheckerSuite) TestMergePolicy(c *C) {
+	s.cluster.ScheduleOptions.SplitMergeInterval = 0
+
+	// Region 2 is too large by the global config.
+	c.Assert(s.mc.Check(s.regions[1]), IsNil)
+	largeRule := &labeler.LabelRule{
+		ID: "large",
+		Labels: []labeler.RegionLabel{
+			{Key: labeler.MaxMergeRegionSizeLabel, Value: "300"},
+			{Key: labeler.MaxMergeRegionKeysLabel, Value: "300"},
+		},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("a")), EndKeyHex: hex.EncodeToString([]byte("t"))}},
+	}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(largeRule), IsNil)
+	ops := s.mc.Check(s.regions[1])
+	c.Assert(ops, NotNil)
+	c.Assert(ops[0].RegionID(), Equals, s.regions[1].GetID())
+	c.Assert(ops[1].RegionID(), Equals, s.regions[2].GetID())
+
+	denyRule := &labeler.LabelRule{
+		ID:     "deny",
+		Labels: []labeler.RegionLabel{{Key: labeler.MergeOptionLabel, Value: labeler.MergeOptionValueDeny}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("t")), EndKeyHex: hex.EncodeToString([]byte("x"))}},
+	}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(denyRule), IsNil)
+	c.Assert(s.mc.Check(s.regions[2]), IsNil)
+	// Region 1 has only 2 peers.
+	c.Assert(s.mc.Check(s.regions[0]), IsNil)
+
+	candidates := s.mc.GetMergeCandidates("")
+	c.Assert(candidates, HasLen, 2)
+	c.Assert(candidates[0].RegionID, Equals, uint64(1))
+	c.Assert(candidates[0].Range, Equals, "default")
+	c.Assert(candidates[0].Reason, Equals, "abnormal-replica")
+	c.Assert(candidates[1].RegionID, Equals, uint64(3))
+	c.Assert(candidates[1].Range, Equals, "deny")
+	c.Assert(candidates[1].Reason, Equals, "merge-option-deny")
+	candidates = s.mc.GetMergeCandidates("deny")
+	c.Assert(candidates, HasLen, 1)
+	c.Assert(candidates[0].RegionID, Equals, uint64(3))
+
+	// The candidate is removed once it is merged.
+	c.Assert(s.cluster.GetRegionLabeler().DeleteLabelRule("deny"), IsNil)
+	c.Assert(s.mc.Check(s.regions[2]), NotNil)
+	candidates = s.mc.GetMergeCandidates("")
+	c.Assert(candidates, HasLen, 1)
+	c.Assert(candidates[0].RegionID, Equals, uint64(1))
+}
+
+func (s *testMergeCheckerSuite) TestMergeTargetLimit(c *C) {
+	s.cluster.ScheduleOptions.SplitMergeInterval = 0
+
+	largeRule := &labeler.LabelRule{
+		ID:     "large",
+		Labels: []labeler.RegionLabel{{Key: labeler.MaxMergeRegionSizeLabel, Value: "300"}, {Key: labeler.MaxMergeRegionKeysLabel, Value: "300"}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("a")), EndKeyHex: hex.EncodeToString([]byte("t"))}},
+	}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(largeRule), IsNil)
+	// The merged region is larger than the size override of the target.
+	smallRule := &labeler.LabelRule{
+		ID:     "small",
+		Labels: []labeler.RegionLabel{{Key: labeler.MaxMergeRegionSizeLabel, Value: "100"}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("t")), EndKeyHex: hex.EncodeToString([]byte("x"))}},
+	}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(smallRule), IsNil)
+	c.Assert(s.mc.Check(s.regions[1]), IsNil)
+	candidates := s.mc.GetMergeCandidates("large")
+	c.Assert(candidates, HasLen, 1)
+	c.Assert(candidates[0].Reason, Equals, "no-target")
+
+	smallRule = &labeler.LabelRule{
+		ID:     "small",
+		Labels: []labeler.RegionLabel{{Key: labeler.MaxMergeRegionSizeLabel, Value: "1000"}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("t")), EndKeyHex: hex.EncodeToString([]byte("x"))}},
+	}
+	c.Assert(s.cluster.GetRegionLabeler().SetLabelRule(smallRule), IsNil)
+	ops := s.mc.Check(s.regions[1])
+	c.Assert(ops, NotNil)
+	c.Assert(ops[1].RegionID(), Equals, s.regions[2].GetID())
+}
+
 func (s *testMergeCheckerSuite) checkSteps(c *C, op *operator.Operator, steps []operator.OpStep) {
 	c.Assert(op.Kind()&operator.OpMerge, Not(Equals), 0)
 	c.Assert(steps, NotNil)
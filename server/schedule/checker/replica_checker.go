@@ -72,12 +72,12 @@ func (r *ReplicaChecker) Check(region *core.RegionInfo) *operator.Operator {
 	checkerCounter.WithLabelValues("replica_checker", "check").Inc()
 	if op := r.checkDownPeer(region); op != nil {
 		checkerCounter.WithLabelValues("replica_checker", "new-operator").Inc()
-		op.SetPriorityLevel(core.HighPriority)
+		op.SetPriorityLevel(core.UrgentPriority)
 		return op
 	}
 	if op := r.checkOfflinePeer(region); op != nil {
 		checkerCounter.WithLabelValues("replica_checker", "new-operator").Inc()
-		op.SetPriorityLevel(core.HighPriority)
+		op.SetPriorityLevel(core.UrgentPriority)
 		return op
 	}
 
@@ -145,6 +145,7 @@ func (r *ReplicaChecker) selectBestStoreToAddReplica(region *core.RegionInfo, fi
 	filters = append(filters, r.filters...)
 	filters = append(filters, newFilters...)
 	regionStores := r.cluster.GetRegionStores(region)
+	filters = append(filters, filter.NewIsolationFilter(r.name, r.cluster.GetIsolationLevel(), r.cluster.GetLocationLabels(), regionStores))
 	s := selector.NewReplicaSelector(regionStores, r.cluster.GetLocationLabels(), r.filters...)
 	target := s.SelectTarget(r.cluster, r.cluster.GetStores(), filters...)
 	if target == nil {
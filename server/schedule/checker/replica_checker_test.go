@@ -138,3 +138,12 @@ func (s *testReplicaCheckerSuite) TestOfflineWithOneReplica(c *C) {
 	c.Assert(op, NotNil)
 	c.Assert(op.Desc(), Equals, "replace-offline-replica")
 }
+
+func (s *testReplicaCheckerSuite) TestAddReplicaWithoutLocationLabels(c *C) {
+	s.cluster.IsolationLevel = "zone"
+	s.cluster.AddLeaderRegion(2, 3)
+	op := s.rc.Check(s.cluster.GetRegion(2))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "make-up-replica")
+	c.Assert(op.Step(0), FitsTypeOf, operator.AddLearner{})
+}
@@ -118,6 +118,9 @@ func (c *RuleChecker) addRulePeer(region *core.RegionInfo, rf *placement.RuleFit
 		return nil, errors.New("no store to add peer")
 	}
 	peer := &metapb.Peer{StoreId: store.GetID(), IsLearner: rf.Rule.Role == placement.Learner}
+	if rf.Rule.Role == placement.Witness {
+		return operator.CreateAddWitnessOperator("add-rule-peer", c.cluster, region, peer, operator.OpReplica)
+	}
 	return operator.CreateAddPeerOperator("add-rule-peer", c.cluster, region, peer, operator.OpReplica)
 }
 
@@ -128,6 +131,9 @@ func (c *RuleChecker) replaceRulePeer(region *core.RegionInfo, fit *placement.Re
 		return nil, errors.New("no store to replace peer")
 	}
 	newPeer := &metapb.Peer{StoreId: store.GetID(), IsLearner: rf.Rule.Role == placement.Learner}
+	if rf.Rule.Role == placement.Witness {
+		return operator.CreateMoveWitnessOperator("replace-rule-"+status+"-peer", c.cluster, region, operator.OpReplica, peer.StoreId, newPeer)
+	}
 	return operator.CreateMovePeerOperator("replace-rule-"+status+"-peer", c.cluster, region, operator.OpReplica, peer.StoreId, newPeer)
 }
 
@@ -136,10 +142,10 @@ func (c *RuleChecker) fixLooseMatchPeer(region *core.RegionInfo, fit *placement.
 		checkerCounter.WithLabelValues("rule_checker", "fix-peer-role").Inc()
 		return operator.CreatePromoteLearnerOperator("fix-peer-role", c.cluster, region, peer)
 	}
-	if region.GetLeader().GetId() == peer.GetId() && rf.Rule.Role == placement.Follower {
+	if region.GetLeader().GetId() == peer.GetId() && (rf.Rule.Role == placement.Follower || rf.Rule.Role == placement.Witness) {
 		checkerCounter.WithLabelValues("rule_checker", "fix-leader-role").Inc()
 		for _, p := range region.GetPeers() {
-			if c.allowLeader(fit, p) {
+			if c.allowLeader(region, fit, p) {
 				return operator.CreateTransferLeaderOperator("fix-peer-role", c.cluster, region, peer.GetStoreId(), p.GetStoreId(), 0)
 			}
 		}
@@ -149,8 +155,8 @@ func (c *RuleChecker) fixLooseMatchPeer(region *core.RegionInfo, fit *placement.
 	return nil, nil
 }
 
-func (c *RuleChecker) allowLeader(fit *placement.RegionFit, peer *metapb.Peer) bool {
-	if peer.GetIsLearner() {
+func (c *RuleChecker) allowLeader(region *core.RegionInfo, fit *placement.RegionFit, peer *metapb.Peer) bool {
+	if peer.GetIsLearner() || region.IsWitness(peer.GetId()) {
 		return false
 	}
 	s := c.cluster.GetStore(peer.GetStoreId())
@@ -195,6 +201,9 @@ func (c *RuleChecker) fixBetterLocation(region *core.RegionInfo, fit *placement.
 	}
 	checkerCounter.WithLabelValues("rule_checker", "move-to-better-location").Inc()
 	newPeer := &metapb.Peer{StoreId: newPeerStore.GetID(), IsLearner: oldPeer.IsLearner}
+	if rf.Rule.Role == placement.Witness {
+		return operator.CreateMoveWitnessOperator("move-to-better-location", c.cluster, region, operator.OpReplica, oldPeer.GetStoreId(), newPeer)
+	}
 	return operator.CreateMovePeerOperator("move-to-better-location", c.cluster, region, operator.OpReplica, oldPeer.GetStoreId(), newPeer)
 }
 
@@ -247,8 +256,10 @@ func SelectStoreToAddPeerByRule(scope string, cluster opt.Cluster, region *core.
 		filter.NewExcludedFilter(scope, nil, region.GetStoreIds()),
 		filter.NewSpecialUseFilter(scope),
 	}
+	fitStores := getRuleFitStores(cluster, rf)
+	fs = append(fs, filter.NewIsolationFilter(scope, rf.Rule.IsolationLevel, rf.Rule.LocationLabels, fitStores))
 	fs = append(fs, filters...)
-	store := selector.NewReplicaSelector(getRuleFitStores(cluster, rf), rf.Rule.LocationLabels).
+	store := selector.NewReplicaSelector(fitStores, rf.Rule.LocationLabels).
 		SelectTarget(cluster, cluster.GetStores(), fs...)
 	return store
 }
@@ -198,3 +198,94 @@ func (s *testRuleCheckerSuite) TestNoBetterReplacement(c *C) {
 	op := s.rc.Check(s.cluster.GetRegion(1))
 	c.Assert(op, IsNil)
 }
+
+func (s *testRuleCheckerSuite) TestIsolationLevel(c *C) {
+	s.cluster.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "host": "h1"})
+	s.cluster.AddLabelsStore(2, 1, map[string]string{"zone": "z1", "host": "h2"})
+	s.cluster.AddLabelsStore(3, 1, map[string]string{"zone": "z1", "host": "h3"})
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID:        "pd",
+		ID:             "default",
+		Role:           placement.Voter,
+		Count:          2,
+		LocationLabels: []string{"zone", "host"},
+		IsolationLevel: "zone",
+	})
+	// No store in another zone.
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, IsNil)
+
+	s.cluster.AddLabelsStore(4, 1, map[string]string{"zone": "z2", "host": "h1"})
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(4))
+
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2)
+	op = s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "move-to-better-location")
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(4))
+}
+
+func (s *testRuleCheckerSuite) TestIsolationLevelWithoutLocationLabels(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1)
+	err := s.ruleManager.SetRule(&placement.Rule{
+		GroupID:        "pd",
+		ID:             "default",
+		Role:           placement.Voter,
+		Count:          2,
+		IsolationLevel: "zone",
+	})
+	c.Assert(err, NotNil)
+	// The default rule is kept, and peers are added without isolation.
+	c.Assert(s.ruleManager.GetRule("pd", "default").IsolationLevel, Equals, "")
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(2))
+}
+
+func (s *testRuleCheckerSuite) TestWitness(c *C) {
+	s.cluster.AddLeaderStore(1, 1)
+	s.cluster.AddLeaderStore(2, 1)
+	s.cluster.AddLeaderStore(3, 1)
+	s.cluster.AddLeaderRegionWithRange(1, "", "", 1, 2)
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID: "pd",
+		ID:      "default",
+		Role:    placement.Voter,
+		Count:   2,
+	})
+	s.ruleManager.SetRule(&placement.Rule{
+		GroupID: "pd",
+		ID:      "witness",
+		Index:   100,
+		Role:    placement.Witness,
+		Count:   1,
+	})
+	op := s.rc.Check(s.cluster.GetRegion(1))
+	c.Assert(op, NotNil)
+	c.Assert(op.Desc(), Equals, "add-rule-peer")
+	c.Assert(op.Step(0).(operator.AddLearner).ToStore, Equals, uint64(3))
+	c.Assert(op.Step(1).(operator.PromoteLearner).ToStore, Equals, uint64(3))
+
+	// The peer is an ordinary voter until TiKV reports it as a witness.
+	s.cluster.AddLeaderRegionWithWitnesses(1, 1, nil, 2, 3)
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+	c.Assert(s.cluster.GetStoreRegionSize(3), Equals, int64(10))
+
+	s.cluster.AddLeaderRegionWithWitnesses(1, 1, []uint64{3}, 2, 3)
+	c.Assert(s.rc.Check(s.cluster.GetRegion(1)), IsNil)
+	c.Assert(s.cluster.GetStoreRegionSize(3), Equals, int64(0))
+	c.Assert(s.cluster.GetStoreRegionSize(2), Equals, int64(10))
+
+	// The reported witness never becomes leader.
+	region := s.cluster.GetRegion(1)
+	fit := s.ruleManager.FitRegion(s.cluster, region)
+	c.Assert(s.rc.allowLeader(region, fit, region.GetStorePeer(3)), IsFalse)
+	c.Assert(s.rc.allowLeader(region, fit, region.GetStorePeer(2)), IsTrue)
+}
@@ -15,6 +15,8 @@ package schedule
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
@@ -23,6 +25,17 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
+// The names of checkers.
+const (
+	LearnerCheckerName = "learner-checker"
+	ReplicaCheckerName = "replica-checker"
+	RuleCheckerName    = "rule-checker"
+	MergeCheckerName   = "merge-checker"
+)
+
+// CheckerNames are the names of all checkers.
+var CheckerNames = []string{LearnerCheckerName, ReplicaCheckerName, RuleCheckerName, MergeCheckerName}
+
 // CheckerController is used to manage all checkers.
 type CheckerController struct {
 	cluster        opt.Cluster
@@ -31,6 +44,9 @@ type CheckerController struct {
 	replicaChecker *checker.ReplicaChecker
 	ruleChecker    *checker.RuleChecker
 	mergeChecker   *checker.MergeChecker
+
+	windowMu      sync.RWMutex
+	activeWindows map[string]*ActiveWindow
 }
 
 // NewCheckerController create a new CheckerController.
@@ -43,6 +59,7 @@ func NewCheckerController(ctx context.Context, cluster opt.Cluster, ruleManager
 		replicaChecker: checker.NewReplicaChecker(cluster),
 		ruleChecker:    checker.NewRuleChecker(cluster, ruleManager),
 		mergeChecker:   checker.NewMergeChecker(ctx, cluster),
+		activeWindows:  make(map[string]*ActiveWindow),
 	}
 }
 
@@ -52,18 +69,21 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 	// Don't check isRaftLearnerEnabled cause it maybe disable learner feature but there are still some learners to promote.
 	opController := c.opController
 	checkerIsBusy := true
+	now := time.Now()
 	if c.cluster.IsPlacementRulesEnabled() {
-		if opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
+		if c.IsCheckerActive(RuleCheckerName, now) && opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
 			checkerIsBusy = false
 			if op := c.ruleChecker.Check(region); op != nil {
 				return checkerIsBusy, []*operator.Operator{op}
 			}
 		}
 	} else {
-		if op := c.learnerChecker.Check(region); op != nil {
-			return false, []*operator.Operator{op}
+		if c.IsCheckerActive(LearnerCheckerName, now) {
+			if op := c.learnerChecker.Check(region); op != nil {
+				return false, []*operator.Operator{op}
+			}
 		}
-		if opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
+		if c.IsCheckerActive(ReplicaCheckerName, now) && opController.OperatorCount(operator.OpReplica) < c.cluster.GetReplicaScheduleLimit() {
 			checkerIsBusy = false
 			if op := c.replicaChecker.Check(region); op != nil {
 				return checkerIsBusy, []*operator.Operator{op}
@@ -71,7 +91,7 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 		}
 	}
 
-	if c.mergeChecker != nil && opController.OperatorCount(operator.OpMerge) < c.cluster.GetMergeScheduleLimit() {
+	if c.mergeChecker != nil && c.IsCheckerActive(MergeCheckerName, now) && opController.OperatorCount(operator.OpMerge) < c.cluster.GetMergeScheduleLimit() {
 		checkerIsBusy = false
 		if ops := c.mergeChecker.Check(region); ops != nil {
 			// It makes sure that two operators can be added successfully altogether.
@@ -85,3 +105,37 @@ func (c *CheckerController) CheckRegion(region *core.RegionInfo) (bool, []*opera
 func (c *CheckerController) GetMergeChecker() *checker.MergeChecker {
 	return c.mergeChecker
 }
+
+// IsCheckerName returns if the name is the name of a checker.
+func IsCheckerName(name string) bool {
+	for _, n := range CheckerNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// SetActiveWindow sets the active window of the checker, a nil window means
+// the checker is always active.
+func (c *CheckerController) SetActiveWindow(name string, w *ActiveWindow) {
+	c.windowMu.Lock()
+	defer c.windowMu.Unlock()
+	if w == nil {
+		delete(c.activeWindows, name)
+		return
+	}
+	c.activeWindows[name] = w
+}
+
+// GetActiveWindow returns the active window of the checker.
+func (c *CheckerController) GetActiveWindow(name string) *ActiveWindow {
+	c.windowMu.RLock()
+	defer c.windowMu.RUnlock()
+	return c.activeWindows[name]
+}
+
+// IsCheckerActive returns if the checker is in its active window at the time.
+func (c *CheckerController) IsCheckerActive(name string, t time.Time) bool {
+	return c.GetActiveWindow(name).IsActive(t)
+}
@@ -0,0 +1,175 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pkg/errors"
+)
+
+// MaxDryRunRounds is the max rounds of a dry run.
+const MaxDryRunRounds = 100
+
+// DryRunOperator is an operator created in a dry run.
+type DryRunOperator struct {
+	Round    int      `json:"round"`
+	RegionID uint64   `json:"region_id"`
+	Desc     string   `json:"desc"`
+	Kind     string   `json:"kind"`
+	Steps    []string `json:"steps"`
+}
+
+// DryRunStore is the predicted change of a store in a dry run.
+type DryRunStore struct {
+	StoreID           uint64  `json:"store_id"`
+	LeaderCountBefore int     `json:"leader_count_before"`
+	LeaderCountAfter  int     `json:"leader_count_after"`
+	RegionCountBefore int     `json:"region_count_before"`
+	RegionCountAfter  int     `json:"region_count_after"`
+	LeaderScoreBefore float64 `json:"leader_score_before"`
+	LeaderScoreAfter  float64 `json:"leader_score_after"`
+	RegionScoreBefore float64 `json:"region_score_before"`
+	RegionScoreAfter  float64 `json:"region_score_after"`
+}
+
+// DryRunResult is the result of a dry run, it contains the operators that
+// would be created and the predicted changes of stores.
+type DryRunResult struct {
+	Operators []*DryRunOperator `json:"operators"`
+	Stores    []*DryRunStore    `json:"stores"`
+}
+
+// DryRunScheduler creates a scheduler with the type and args, and runs it on
+// the sandbox for several rounds. Operators created in each round are applied
+// to the sandbox before the next round, but never dispatched.
+func DryRunScheduler(ctx context.Context, cluster *SandboxCluster, typ string, args []string, rounds int) (*DryRunResult, error) {
+	if err := checkDryRunRounds(rounds); err != nil {
+		return nil, err
+	}
+	if _, ok := schedulerMap[typ]; !ok {
+		typ = FindSchedulerTypeByName(typ)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opController := NewOperatorController(ctx, cluster, nil)
+	s, err := CreateScheduler(typ, opController, core.NewStorage(kv.NewMemoryKV()), ConfigSliceDecoder(typ, args))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Prepare(cluster); err != nil {
+		return nil, err
+	}
+	defer s.Cleanup(cluster)
+
+	result := newDryRunResult(cluster)
+	for round := 1; round <= rounds; round++ {
+		for _, op := range s.Schedule(cluster) {
+			result.addOperator(round, op)
+			cluster.ApplyOperator(op)
+		}
+	}
+	result.collectStores(cluster)
+	return result, nil
+}
+
+// DryRunCheckers runs the checkers on all regions of the sandbox for several
+// rounds. Operators are applied to the sandbox once they are created, but
+// never dispatched.
+func DryRunCheckers(ctx context.Context, cluster *SandboxCluster, ruleManager *placement.RuleManager, rounds int) (*DryRunResult, error) {
+	if err := checkDryRunRounds(rounds); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	opController := NewOperatorController(ctx, cluster, nil)
+	checkers := NewCheckerController(ctx, cluster, ruleManager, opController)
+
+	result := newDryRunResult(cluster)
+	for round := 1; round <= rounds; round++ {
+		for _, r := range cluster.GetRegions() {
+			// The region may be changed by operators of other regions.
+			region := cluster.GetRegion(r.GetID())
+			if region == nil {
+				continue
+			}
+			_, ops := checkers.CheckRegion(region)
+			for _, op := range ops {
+				result.addOperator(round, op)
+				cluster.ApplyOperator(op)
+			}
+		}
+	}
+	result.collectStores(cluster)
+	return result, nil
+}
+
+func checkDryRunRounds(rounds int) error {
+	if rounds <= 0 || rounds > MaxDryRunRounds {
+		return errors.Errorf("rounds should be in [1, %d]", MaxDryRunRounds)
+	}
+	return nil
+}
+
+func newDryRunResult(cluster *SandboxCluster) *DryRunResult {
+	result := &DryRunResult{Operators: []*DryRunOperator{}}
+	for _, s := range cluster.GetStores() {
+		if s.IsTombstone() {
+			continue
+		}
+		result.Stores = append(result.Stores, &DryRunStore{
+			StoreID:           s.GetID(),
+			LeaderCountBefore: s.GetLeaderCount(),
+			RegionCountBefore: s.GetRegionCount(),
+			LeaderScoreBefore: s.LeaderScore(cluster.GetLeaderSchedulePolicy(), 0),
+			RegionScoreBefore: s.RegionScore(cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), 0),
+		})
+	}
+	sort.Slice(result.Stores, func(i, j int) bool {
+		return result.Stores[i].StoreID < result.Stores[j].StoreID
+	})
+	return result
+}
+
+func (r *DryRunResult) addOperator(round int, op *operator.Operator) {
+	steps := make([]string, 0, op.Len())
+	for i := 0; i < op.Len(); i++ {
+		steps = append(steps, op.Step(i).String())
+	}
+	r.Operators = append(r.Operators, &DryRunOperator{
+		Round:    round,
+		RegionID: op.RegionID(),
+		Desc:     op.Desc(),
+		Kind:     op.Kind().String(),
+		Steps:    steps,
+	})
+}
+
+func (r *DryRunResult) collectStores(cluster *SandboxCluster) {
+	for _, store := range r.Stores {
+		s := cluster.GetStore(store.StoreID)
+		if s == nil {
+			continue
+		}
+		store.LeaderCountAfter = s.GetLeaderCount()
+		store.RegionCountAfter = s.GetRegionCount()
+		store.LeaderScoreAfter = s.LeaderScore(cluster.GetLeaderSchedulePolicy(), 0)
+		store.RegionScoreAfter = s.RegionScore(cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), 0)
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pingcap/pd/v4/server/schedule/storelimit"
 )
 
@@ -39,6 +40,53 @@ func SelectTargetStores(stores []*core.StoreInfo, filters []Filter, opt opt.Opti
 	})
 }
 
+// SelectSourceStoresWithPlan is the same as SelectSourceStores, and records
+// the filter which rejects each store into the plan.
+func SelectSourceStoresWithPlan(stores []*core.StoreInfo, filters []Filter, opt opt.Options, p *plan.Plan) []*core.StoreInfo {
+	return filterStoresBy(stores, func(s *core.StoreInfo) bool {
+		for _, f := range filters {
+			if !f.Source(opt, s) {
+				p.AddFiltered(s.GetID(), 0, plan.SourceRole, f.Type(), rejectReason(f, opt, s, true))
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// SelectTargetStoresWithPlan is the same as SelectTargetStores, and records
+// the filter which rejects each store into the plan. The regionID is the
+// region to schedule, or 0 if the stores are selected without any region.
+func SelectTargetStoresWithPlan(stores []*core.StoreInfo, filters []Filter, opt opt.Options, regionID uint64, p *plan.Plan) []*core.StoreInfo {
+	return filterStoresBy(stores, func(s *core.StoreInfo) bool {
+		for _, f := range filters {
+			if !f.Target(opt, s) {
+				p.AddFiltered(s.GetID(), regionID, plan.TargetRole, f.Type(), rejectReason(f, opt, s, false))
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// reasoner is implemented by the filters which can explain why a store is
+// rejected.
+type reasoner interface {
+	sourceReason(opt opt.Options, store *core.StoreInfo) string
+	targetReason(opt opt.Options, store *core.StoreInfo) string
+}
+
+func rejectReason(f Filter, opt opt.Options, store *core.StoreInfo, isSource bool) string {
+	r, ok := f.(reasoner)
+	if !ok {
+		return ""
+	}
+	if isSource {
+		return r.sourceReason(opt, store)
+	}
+	return r.targetReason(opt, store)
+}
+
 func filterStoresBy(stores []*core.StoreInfo, keepPred func(*core.StoreInfo) bool) (selected []*core.StoreInfo) {
 	for _, s := range stores {
 		if keepPred(s) {
@@ -134,11 +182,11 @@ func (f *storeLimitFilter) Type() string {
 }
 
 func (f *storeLimitFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
-	return store.IsAvailable(storelimit.RegionRemove)
+	return store.IsAvailable(storelimit.RegionRemove) && store.IsAvailable(storelimit.RegionRemoveBytes)
 }
 
 func (f *storeLimitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
-	return store.IsAvailable(storelimit.RegionAdd)
+	return store.IsAvailable(storelimit.RegionAdd) && store.IsAvailable(storelimit.RegionAddBytes)
 }
 
 type stateFilter struct{ scope string }
@@ -322,6 +370,76 @@ func (f *distinctScoreFilter) Target(opt opt.Options, store *core.StoreInfo) boo
 	return core.DistinctScore(f.labels, f.stores, store) >= f.safeScore
 }
 
+// isolationFilter ensures that the target store does not share the labels
+// of the isolation level with any store of the region.
+type isolationFilter struct {
+	scope          string
+	locationLabels []string
+	constraintSet  [][]string
+}
+
+// NewIsolationFilter creates a filter that filters out stores whose labels,
+// from the topmost location label down to the isolation level, are the same
+// as any of the given region stores. It places no constraint if the
+// isolation level is empty or is not one of the location labels.
+func NewIsolationFilter(scope, isolationLevel string, locationLabels []string, regionStores []*core.StoreInfo) Filter {
+	isolationFilter := &isolationFilter{
+		scope:          scope,
+		locationLabels: locationLabels,
+	}
+	// Get which idx this isolationLevel at according to locationLabels
+	isolationLevelIdx := -1
+	for level, label := range locationLabels {
+		if label == isolationLevel {
+			isolationLevelIdx = level
+			break
+		}
+	}
+	if isolationLevel == "" || isolationLevelIdx == -1 {
+		return isolationFilter
+	}
+	// Collect all constraints for given isolationLevel
+	isolationFilter.constraintSet = make([][]string, 0, len(regionStores))
+	for _, regionStore := range regionStores {
+		var constraintList []string
+		for i := 0; i <= isolationLevelIdx; i++ {
+			constraintList = append(constraintList, regionStore.GetLabelValue(locationLabels[i]))
+		}
+		isolationFilter.constraintSet = append(isolationFilter.constraintSet, constraintList)
+	}
+	return isolationFilter
+}
+
+func (f *isolationFilter) Scope() string {
+	return f.scope
+}
+
+func (f *isolationFilter) Type() string {
+	return "isolation-filter"
+}
+
+func (f *isolationFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
+	return true
+}
+
+func (f *isolationFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
+	// No isolation constraint to fit
+	if len(f.constraintSet) == 0 {
+		return true
+	}
+	for _, constrainList := range f.constraintSet {
+		match := true
+		for idx, constraint := range constrainList {
+			// Check every constraint in constrainList
+			match = store.GetLabelValue(f.locationLabels[idx]) == constraint && match
+		}
+		if len(constrainList) > 0 && match {
+			return false
+		}
+	}
+	return true
+}
+
 // StoreStateFilter is used to determine whether a store can be selected as the
 // source or target of the schedule based on the store's state.
 type StoreStateFilter struct {
@@ -345,64 +463,95 @@ func (f StoreStateFilter) Type() string {
 // Source returns true when the store can be selected as the schedule
 // source.
 func (f StoreStateFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
-	if store.IsTombstone() ||
-		store.DownTime() > opt.GetMaxStoreDownTime() {
-		return false
+	return f.sourceReason(opt, store) == ""
+}
+
+// Target returns true when the store can be selected as the schedule
+// target.
+func (f StoreStateFilter) Target(opts opt.Options, store *core.StoreInfo) bool {
+	return f.targetReason(opts, store) == ""
+}
+
+// sourceReason returns why the store can not be selected as the schedule
+// source, or an empty string if it can be.
+func (f StoreStateFilter) sourceReason(opt opt.Options, store *core.StoreInfo) string {
+	if store.IsTombstone() {
+		return "tombstone"
 	}
-	if f.TransferLeader && (store.IsDisconnected() || store.IsBlocked()) {
-		return false
+	if store.DownTime() > opt.GetMaxStoreDownTime() {
+		return "down"
+	}
+	if f.TransferLeader {
+		if store.IsDisconnected() {
+			return "disconnected"
+		}
+		if store.IsBlocked() {
+			return "blocked"
+		}
 	}
 
-	if f.MoveRegion && !f.filterMoveRegion(opt, true, store) {
-		return false
+	if f.MoveRegion {
+		return f.moveRegionReason(opt, true, store)
 	}
-	return true
+	return ""
 }
 
-// Target returns true when the store can be selected as the schedule
-// target.
-func (f StoreStateFilter) Target(opts opt.Options, store *core.StoreInfo) bool {
-	if store.IsTombstone() ||
-		store.IsOffline() ||
-		store.DownTime() > opts.GetMaxStoreDownTime() {
-		return false
+// targetReason returns why the store can not be selected as the schedule
+// target, or an empty string if it can be.
+func (f StoreStateFilter) targetReason(opts opt.Options, store *core.StoreInfo) string {
+	if store.IsTombstone() {
+		return "tombstone"
 	}
-	if f.TransferLeader &&
-		(store.IsDisconnected() ||
-			store.IsBlocked() ||
-			store.IsBusy() ||
-			opts.CheckLabelProperty(opt.RejectLeader, store.GetLabels())) {
-		return false
+	if store.IsOffline() {
+		return "offline"
+	}
+	if store.DownTime() > opts.GetMaxStoreDownTime() {
+		return "down"
+	}
+	if f.TransferLeader {
+		if store.IsDisconnected() {
+			return "disconnected"
+		}
+		if store.IsBlocked() {
+			return "blocked"
+		}
+		if store.IsBusy() {
+			return "busy"
+		}
+		if opts.CheckLabelProperty(opt.RejectLeader, store.GetLabels()) {
+			return "reject-leader"
+		}
 	}
 
 	if f.MoveRegion {
 		// only target consider the pending peers because pending more means the disk is slower.
 		if opts.GetMaxPendingPeerCount() > 0 && store.GetPendingPeerCount() > int(opts.GetMaxPendingPeerCount()) {
-			return false
-		}
-
-		if !f.filterMoveRegion(opts, false, store) {
-			return false
+			return "too-many-pending-peers"
 		}
+		return f.moveRegionReason(opts, false, store)
 	}
-	return true
+	return ""
 }
 
-func (f StoreStateFilter) filterMoveRegion(opt opt.Options, isSource bool, store *core.StoreInfo) bool {
+func (f StoreStateFilter) moveRegionReason(opt opt.Options, isSource bool, store *core.StoreInfo) string {
 	if store.IsBusy() {
-		return false
+		return "busy"
 	}
 
 	if (isSource && !store.IsAvailable(storelimit.RegionRemove)) || (!isSource && !store.IsAvailable(storelimit.RegionAdd)) {
-		return false
+		return "exceed-store-limit"
+	}
+
+	if (isSource && !store.IsAvailable(storelimit.RegionRemoveBytes)) || (!isSource && !store.IsAvailable(storelimit.RegionAddBytes)) {
+		return "exceed-store-bytes-limit"
 	}
 
 	if uint64(store.GetSendingSnapCount()) > opt.GetMaxSnapshotCount() ||
 		uint64(store.GetReceivingSnapCount()) > opt.GetMaxSnapshotCount() ||
 		uint64(store.GetApplyingSnapCount()) > opt.GetMaxSnapshotCount() {
-		return false
+		return "too-many-snapshots"
 	}
-	return true
+	return ""
 }
 
 // BlacklistType the type of BlackListStore Filter.
@@ -540,6 +689,13 @@ func (f *ruleFitFilter) Source(opt opt.Options, store *core.StoreInfo) bool {
 func (f *ruleFitFilter) Target(opt opt.Options, store *core.StoreInfo) bool {
 	region := f.region.Clone(core.WithReplacePeerStore(f.oldStore, store.GetID()))
 	newFit := f.fitter.FitRegion(region)
+	// The isolation level required by rules is a hard constraint, so the
+	// placement which breaks it is never acceptable.
+	for i, rf := range newFit.RuleFits {
+		if !rf.IsIsolationSatisfied() && i < len(f.oldFit.RuleFits) && f.oldFit.RuleFits[i].IsIsolationSatisfied() {
+			return false
+		}
+	}
 	return placement.CompareRegionFit(f.oldFit, newFit) <= 0
 }
 
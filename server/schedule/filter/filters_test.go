@@ -13,6 +13,7 @@
 package filter
 
 import (
+	"encoding/hex"
 	"testing"
 
 	. "github.com/pingcap/check"
@@ -20,6 +21,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
 	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
@@ -80,3 +82,63 @@ func (s *testFiltersSuite) TestRuleFitFilter(c *C) {
 	c.Assert(filter.Target(tc, tc.GetStore(4)), IsFalse)
 	c.Assert(filter.Source(tc, tc.GetStore(4)), IsTrue)
 }
+
+func (s *testFiltersSuite) TestIsolationFilter(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	locationLabels := []string{"zone", "rack", "host"}
+	tc.AddLabelsStore(1, 1, map[string]string{"zone": "z1", "rack": "r1", "host": "h1"})
+	tc.AddLabelsStore(2, 1, map[string]string{"zone": "z1", "rack": "r1", "host": "h2"})
+	tc.AddLabelsStore(3, 1, map[string]string{"zone": "z1", "rack": "r2", "host": "h1"})
+	tc.AddLabelsStore(4, 1, map[string]string{"zone": "z2", "rack": "r1", "host": "h1"})
+	regionStores := []*core.StoreInfo{tc.GetStore(1)}
+
+	testCases := []struct {
+		isolationLevel string
+		targetRes      []bool
+	}{
+		{"", []bool{true, true, true, true}},
+		{"unknown", []bool{true, true, true, true}},
+		{"zone", []bool{false, false, false, true}},
+		{"rack", []bool{false, false, true, true}},
+		{"host", []bool{false, true, true, true}},
+	}
+	for _, tc0 := range testCases {
+		filter := NewIsolationFilter("", tc0.isolationLevel, locationLabels, regionStores)
+		for i := 0; i < 4; i++ {
+			c.Assert(filter.Source(tc, tc.GetStore(uint64(i+1))), IsTrue)
+			c.Assert(filter.Target(tc, tc.GetStore(uint64(i+1))), Equals, tc0.targetRes[i])
+		}
+	}
+
+	// No constraint without location labels.
+	for _, isolationLevel := range []string{"", "zone"} {
+		filter := NewIsolationFilter("", isolationLevel, nil, regionStores)
+		for i := 0; i < 4; i++ {
+			c.Assert(filter.Target(tc, tc.GetStore(uint64(i+1))), IsTrue)
+		}
+	}
+}
+
+func (s *testFiltersSuite) TestRegionScheduleAllowed(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, StartKey: []byte("b"), EndKey: []byte("c")}, nil)
+	allowed := RegionScheduleAllowed(tc)
+	c.Assert(allowed(region), IsTrue)
+
+	rule := &labeler.LabelRule{
+		ID:     "deny",
+		Labels: []labeler.RegionLabel{{Key: labeler.ScheduleOptionLabel, Value: labeler.ScheduleOptionValueDeny}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: hex.EncodeToString([]byte("a")), EndKeyHex: hex.EncodeToString([]byte("d"))}},
+	}
+	c.Assert(tc.GetRegionLabeler().SetLabelRule(rule), IsNil)
+	c.Assert(RegionLabelMatch(tc, labeler.ScheduleOptionLabel, labeler.ScheduleOptionValueDeny)(region), IsTrue)
+	c.Assert(allowed(region), IsFalse)
+	// The region is not covered by the rule.
+	other := core.NewRegionInfo(&metapb.Region{Id: 2, StartKey: []byte("c"), EndKey: []byte("e")}, nil)
+	c.Assert(allowed(other), IsTrue)
+
+	c.Assert(tc.GetRegionLabeler().DeleteLabelRule("deny"), IsNil)
+	c.Assert(allowed(region), IsTrue)
+}
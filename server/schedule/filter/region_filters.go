@@ -0,0 +1,37 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filter
+
+import (
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+)
+
+// RegionLabelMatch returns a function that checks if a region is labeled with
+// the key and value by the region label rules.
+func RegionLabelMatch(cluster opt.Cluster, key, value string) func(*core.RegionInfo) bool {
+	return func(region *core.RegionInfo) bool {
+		l := cluster.GetRegionLabeler()
+		return l != nil && l.GetRegionLabel(region, key) == value
+	}
+}
+
+// RegionScheduleAllowed returns a function that checks if a region can be
+// picked by the balance and hot region schedulers, which requires the region
+// is not labeled with schedule=deny.
+func RegionScheduleAllowed(cluster opt.Cluster) func(*core.RegionInfo) bool {
+	denied := RegionLabelMatch(cluster, labeler.ScheduleOptionLabel, labeler.ScheduleOptionValueDeny)
+	return func(region *core.RegionInfo) bool { return !denied(region) }
+}
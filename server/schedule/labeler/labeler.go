@@ -0,0 +1,201 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"go.uber.org/zap"
+)
+
+// RegionLabeler is utility to label regions by key ranges.
+// It is threadsafe.
+type RegionLabeler struct {
+	storage *core.Storage
+	sync.RWMutex
+	labelRules map[string]*LabelRule
+}
+
+// NewRegionLabeler creates a RegionLabeler instance and loads the rules from
+// storage.
+func NewRegionLabeler(storage *core.Storage) (*RegionLabeler, error) {
+	l := &RegionLabeler{
+		storage:    storage,
+		labelRules: make(map[string]*LabelRule),
+	}
+	if err := l.loadRules(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+func (l *RegionLabeler) loadRules() error {
+	var toDelete []string
+	now := time.Now()
+	_, err := l.storage.LoadRegionRules(func(k, v string) {
+		var r LabelRule
+		if err := json.Unmarshal([]byte(v), &r); err != nil {
+			log.Error("failed to unmarshal label rule value", zap.String("rule-key", k), zap.String("rule-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		if err := r.checkAndAdjust(now); err != nil {
+			log.Error("label rule is in bad format", zap.Error(err), zap.String("rule-key", k), zap.String("rule-value", v))
+			toDelete = append(toDelete, k)
+			return
+		}
+		if r.expired(now) {
+			toDelete = append(toDelete, k)
+			return
+		}
+		l.labelRules[r.ID] = &r
+	})
+	if err != nil {
+		return err
+	}
+	for _, d := range toDelete {
+		if err = l.storage.DeleteRegionRule(d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckAndClearExpiredRules removes the expired rules from memory and
+// storage.
+func (l *RegionLabeler) CheckAndClearExpiredRules() {
+	l.Lock()
+	defer l.Unlock()
+	now := time.Now()
+	for id, rule := range l.labelRules {
+		if !rule.expired(now) {
+			continue
+		}
+		if err := l.storage.DeleteRegionRule(id); err != nil {
+			log.Error("failed to delete expired label rule", zap.String("rule-id", id), zap.Error(err))
+			continue
+		}
+		delete(l.labelRules, id)
+		log.Info("label rule expired", zap.Stringer("rule", rule))
+	}
+}
+
+// GetAllLabelRules returns all the rules sorted by ID.
+func (l *RegionLabeler) GetAllLabelRules() []*LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	now := time.Now()
+	rules := make([]*LabelRule, 0, len(l.labelRules))
+	for _, rule := range l.labelRules {
+		if !rule.expired(now) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// GetLabelRule returns the rule of the ID, or nil if it does not exist or
+// has expired.
+func (l *RegionLabeler) GetLabelRule(id string) *LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	rule, ok := l.labelRules[id]
+	if !ok || rule.expired(time.Now()) {
+		return nil
+	}
+	return rule
+}
+
+// SetLabelRule inserts or updates a LabelRule. The TTL of the rule starts
+// from now.
+func (l *RegionLabeler) SetLabelRule(rule *LabelRule) error {
+	rule.StartAt = ""
+	if err := rule.checkAndAdjust(time.Now()); err != nil {
+		return invalidRuleError{err}
+	}
+	l.Lock()
+	defer l.Unlock()
+	if err := l.storage.SaveRegionRule(rule.ID, rule); err != nil {
+		return err
+	}
+	l.labelRules[rule.ID] = rule
+	log.Info("label rule updated", zap.Stringer("rule", rule))
+	return nil
+}
+
+// DeleteLabelRule removes a LabelRule.
+func (l *RegionLabeler) DeleteLabelRule(id string) error {
+	l.Lock()
+	defer l.Unlock()
+	if _, ok := l.labelRules[id]; !ok {
+		return nil
+	}
+	if err := l.storage.DeleteRegionRule(id); err != nil {
+		return err
+	}
+	delete(l.labelRules, id)
+	log.Info("label rule removed", zap.String("rule-id", id))
+	return nil
+}
+
+// GetRegionLabel returns the value of the region's label with the key. It
+// returns empty if the region does not have such label.
+func (l *RegionLabeler) GetRegionLabel(region *core.RegionInfo, key string) string {
+	for _, label := range l.GetRegionLabels(region) {
+		if label.Key == key {
+			return label.Value
+		}
+	}
+	return ""
+}
+
+// GetRegionLabelRules returns the rules covering the region sorted by ID.
+func (l *RegionLabeler) GetRegionLabelRules(region *core.RegionInfo) []*LabelRule {
+	l.RLock()
+	defer l.RUnlock()
+	now := time.Now()
+	var rules []*LabelRule
+	for _, rule := range l.labelRules {
+		if !rule.expired(now) && rule.matchRange(region.GetStartKey(), region.GetEndKey()) {
+			rules = append(rules, rule)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
+
+// GetRegionLabels returns the labels of the region. If multiple rules
+// assign the same key to the region, the one with the greatest ID wins.
+func (l *RegionLabeler) GetRegionLabels(region *core.RegionInfo) []*RegionLabel {
+	var result []*RegionLabel
+	index := make(map[string]int)
+	for _, rule := range l.GetRegionLabelRules(region) {
+		for _, label := range rule.Labels {
+			label := label
+			if i, ok := index[label.Key]; ok {
+				result[i] = &label
+				continue
+			}
+			index[label.Key] = len(result)
+			result = append(result, &label)
+		}
+	}
+	return result
+}
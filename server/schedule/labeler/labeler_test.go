@@ -0,0 +1,167 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/codec"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testLabelerSuite{})
+
+type testLabelerSuite struct {
+	store   *core.Storage
+	labeler *RegionLabeler
+}
+
+func (s *testLabelerSuite) SetUpTest(c *C) {
+	s.store = core.NewStorage(kv.NewMemoryKV())
+	var err error
+	s.labeler, err = NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+}
+
+func (s *testLabelerSuite) TestAdjustRule(c *C) {
+	rules := []LabelRule{
+		{ID: "foo", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}},
+		{ID: "", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}},
+		{ID: "foo", Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}},
+		{ID: "foo", Labels: []RegionLabel{{Key: "", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}},
+		{ID: "foo", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}},
+		{ID: "foo", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "12abc", EndKeyHex: "34cdef"}}},
+		{ID: "foo", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "34cdef", EndKeyHex: "12abcd"}}},
+		{ID: "foo", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}, TTL: "1x"},
+		{ID: "foo", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}, TTL: "-1h"},
+		{ID: "foo", Labels: []RegionLabel{{Key: MaxMergeRegionSizeLabel, Value: "-1"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}},
+		{ID: "foo", Labels: []RegionLabel{{Key: MaxMergeRegionKeysLabel, Value: "x"}}, Ranges: []KeyRange{{StartKeyHex: "12abcd", EndKeyHex: "34cdef"}}},
+	}
+	c.Assert(rules[0].checkAndAdjust(time.Now()), IsNil)
+	c.Assert(rules[0].Ranges[0].StartKey, DeepEquals, []byte{0x12, 0xab, 0xcd})
+	c.Assert(rules[0].Ranges[0].EndKey, DeepEquals, []byte{0x34, 0xcd, 0xef})
+	for i := 1; i < len(rules); i++ {
+		c.Assert(rules[i].checkAndAdjust(time.Now()), NotNil)
+	}
+}
+
+func (s *testLabelerSuite) TestTableIDs(c *C) {
+	rule := &LabelRule{ID: "foo", Labels: []RegionLabel{{Key: MaxMergeRegionSizeLabel, Value: "100"}}, TableIDs: []int64{10}}
+	c.Assert(rule.checkAndAdjust(time.Now()), IsNil)
+	c.Assert(rule.TableIDs, IsNil)
+	c.Assert(rule.Ranges, HasLen, 1)
+	c.Assert(rule.Ranges[0].StartKey, DeepEquals, []byte(codec.EncodeBytes(codec.GenerateTableKey(10))))
+	c.Assert(rule.Ranges[0].EndKey, DeepEquals, []byte(codec.EncodeBytes(codec.GenerateTableKey(11))))
+
+	c.Assert(s.labeler.SetLabelRule(rule), IsNil)
+	region := core.NewRegionInfo(&metapb.Region{
+		Id:       1,
+		StartKey: codec.EncodeBytes(codec.GenerateRowKey(10, 1)),
+		EndKey:   codec.EncodeBytes(codec.GenerateRowKey(10, 100)),
+	}, nil)
+	c.Assert(s.labeler.GetRegionLabel(region, MaxMergeRegionSizeLabel), Equals, "100")
+	c.Assert(s.labeler.GetRegionLabelRules(region), DeepEquals, []*LabelRule{rule})
+}
+
+func (s *testLabelerSuite) TestGetSetRule(c *C) {
+	rules := []*LabelRule{
+		{ID: "rule1", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "1234", EndKeyHex: "5678"}}},
+		{ID: "rule2", Labels: []RegionLabel{{Key: "k2", Value: "v2"}}, Ranges: []KeyRange{{StartKeyHex: "ab12", EndKeyHex: "cd12"}}},
+		{ID: "rule3", Labels: []RegionLabel{{Key: "k3", Value: "v3"}}, Ranges: []KeyRange{{StartKeyHex: "abcd", EndKeyHex: ""}}},
+	}
+	for _, r := range rules {
+		c.Assert(s.labeler.SetLabelRule(r), IsNil)
+	}
+	c.Assert(s.labeler.GetLabelRule("rule2"), DeepEquals, rules[1])
+	c.Assert(s.labeler.GetLabelRule("rule4"), IsNil)
+	c.Assert(s.labeler.GetAllLabelRules(), DeepEquals, rules)
+
+	// reload from storage
+	labeler, err := NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(labeler.GetAllLabelRules(), DeepEquals, rules)
+
+	c.Assert(s.labeler.DeleteLabelRule("rule2"), IsNil)
+	c.Assert(s.labeler.DeleteLabelRule("rule4"), IsNil)
+	c.Assert(s.labeler.GetAllLabelRules(), DeepEquals, []*LabelRule{rules[0], rules[2]})
+	labeler, err = NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(labeler.GetAllLabelRules(), DeepEquals, []*LabelRule{rules[0], rules[2]})
+}
+
+func (s *testLabelerSuite) TestGetRegionLabel(c *C) {
+	rules := []*LabelRule{
+		{ID: "rule1", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "1234", EndKeyHex: "5678"}}},
+		{ID: "rule2", Labels: []RegionLabel{{Key: "k1", Value: "v2"}, {Key: "k2", Value: "v2"}}, Ranges: []KeyRange{{StartKeyHex: "3456", EndKeyHex: "4567"}}},
+		{ID: "rule3", Labels: []RegionLabel{{Key: "k3", Value: "v3"}}, Ranges: []KeyRange{{StartKeyHex: "abcd", EndKeyHex: ""}, {StartKeyHex: "", EndKeyHex: "1234"}}},
+	}
+	for _, r := range rules {
+		c.Assert(s.labeler.SetLabelRule(r), IsNil)
+	}
+	testCases := []struct {
+		start, end string
+		labels     map[string]string
+	}{
+		{"1234", "5678", map[string]string{"k1": "v1"}},
+		{"1234", "", map[string]string{}},
+		{"3456", "4567", map[string]string{"k1": "v2", "k2": "v2"}},
+		{"3456", "4568", map[string]string{"k1": "v1"}},
+		{"abcd", "", map[string]string{"k3": "v3"}},
+		{"", "1234", map[string]string{"k3": "v3"}},
+		{"", "", map[string]string{}},
+	}
+	for _, t := range testCases {
+		start, end := s.decodeHex(c, t.start), s.decodeHex(c, t.end)
+		region := core.NewRegionInfo(&metapb.Region{StartKey: start, EndKey: end}, nil)
+		labels := s.labeler.GetRegionLabels(region)
+		c.Assert(labels, HasLen, len(t.labels))
+		for _, l := range labels {
+			c.Assert(l.Value, Equals, t.labels[l.Key])
+			c.Assert(s.labeler.GetRegionLabel(region, l.Key), Equals, l.Value)
+		}
+	}
+}
+
+func (s *testLabelerSuite) TestExpire(c *C) {
+	rule := &LabelRule{ID: "rule1", Labels: []RegionLabel{{Key: "k1", Value: "v1"}}, Ranges: []KeyRange{{StartKeyHex: "", EndKeyHex: ""}}, TTL: "10ms"}
+	c.Assert(s.labeler.SetLabelRule(rule), IsNil)
+	c.Assert(rule.StartAt, Not(Equals), "")
+	region := core.NewRegionInfo(&metapb.Region{}, nil)
+	c.Assert(s.labeler.GetRegionLabel(region, "k1"), Equals, "v1")
+
+	// Simulate the rule has expired.
+	expire := time.Now().Add(-time.Second)
+	rule.expire = &expire
+	c.Assert(s.labeler.GetLabelRule("rule1"), IsNil)
+	c.Assert(s.labeler.GetRegionLabel(region, "k1"), Equals, "")
+	s.labeler.CheckAndClearExpiredRules()
+	labeler, err := NewRegionLabeler(s.store)
+	c.Assert(err, IsNil)
+	c.Assert(labeler.GetAllLabelRules(), HasLen, 0)
+}
+
+func (s *testLabelerSuite) decodeHex(c *C, str string) []byte {
+	b, err := hex.DecodeString(str)
+	c.Assert(err, IsNil)
+	return b
+}
@@ -0,0 +1,199 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package labeler
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/pd/v4/pkg/codec"
+	"github.com/pkg/errors"
+)
+
+// Labels that are recognized by PD.
+const (
+	// MergeOptionLabel is the label key to control whether a region can be
+	// merged by the merge checker.
+	MergeOptionLabel = "merge_option"
+	// MergeOptionValueDeny is the value of MergeOptionLabel that forbids
+	// the region from being merged.
+	MergeOptionValueDeny = "deny"
+	// MaxMergeRegionSizeLabel is the label key to override the max size in
+	// MB of a region to be merged by the merge checker.
+	MaxMergeRegionSizeLabel = "max_merge_region_size"
+	// MaxMergeRegionKeysLabel is the label key to override the max key count
+	// of a region to be merged by the merge checker.
+	MaxMergeRegionKeysLabel = "max_merge_region_keys"
+	// ScheduleOptionLabel is the label key to control whether a region can be
+	// picked by the balance and hot region schedulers.
+	ScheduleOptionLabel = "schedule"
+	// ScheduleOptionValueDeny is the value of ScheduleOptionLabel that
+	// excludes the region from the balance and hot region scheduling.
+	ScheduleOptionValueDeny = "deny"
+)
+
+// invalidRuleError indicates the label rule is in bad format.
+type invalidRuleError struct{ error }
+
+// IsInvalidRuleError returns if the error is caused by a label rule in bad
+// format.
+func IsInvalidRuleError(err error) bool {
+	_, ok := errors.Cause(err).(invalidRuleError)
+	return ok
+}
+
+// RegionLabel is the label of a region.
+type RegionLabel struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+func (l RegionLabel) String() string {
+	return fmt.Sprintf("%s=%s", l.Key, l.Value)
+}
+
+// KeyRange is a range of keys that a label rule is applied to.
+type KeyRange struct {
+	StartKeyHex string `json:"start_key"` // hex format start key, for marshal/unmarshal
+	EndKeyHex   string `json:"end_key"`   // hex format end key, for marshal/unmarshal
+	StartKey    []byte `json:"-"`         // always decoded from StartKeyHex
+	EndKey      []byte `json:"-"`         // always decoded from EndKeyHex
+}
+
+// contains returns if the range [startKey, endKey) is covered by the KeyRange.
+func (r *KeyRange) contains(startKey, endKey []byte) bool {
+	if bytes.Compare(startKey, r.StartKey) < 0 {
+		return false
+	}
+	if len(r.EndKey) == 0 {
+		return true
+	}
+	return len(endKey) > 0 && bytes.Compare(endKey, r.EndKey) <= 0
+}
+
+// LabelRule is the rule to assign labels to regions whose key range is
+// covered by one of the rule's ranges.
+type LabelRule struct {
+	ID     string        `json:"id"`
+	Labels []RegionLabel `json:"labels"`
+	Ranges []KeyRange    `json:"ranges"`
+	// TableIDs are the tables the rule is applied to. They are converted
+	// to Ranges by PD.
+	TableIDs []int64 `json:"table_ids,omitempty"`
+	// TTL is a duration string such as "1h30m". The rule expires after TTL
+	// since it is set, empty means the rule never expires.
+	TTL string `json:"ttl,omitempty"`
+	// StartAt is the time when the TTL starts, it is filled by PD.
+	StartAt string     `json:"start_at,omitempty"`
+	expire  *time.Time // always calculated from TTL and StartAt
+}
+
+func (rule *LabelRule) String() string {
+	return fmt.Sprintf("%s%v", rule.ID, rule.Labels)
+}
+
+// GetLabel returns the value of the label with the key, or empty if the
+// rule does not have such label.
+func (rule *LabelRule) GetLabel(key string) string {
+	for _, l := range rule.Labels {
+		if l.Key == key {
+			return l.Value
+		}
+	}
+	return ""
+}
+
+// checkAndAdjust validates the rule and decodes the fields for internal use.
+func (rule *LabelRule) checkAndAdjust(now time.Time) error {
+	if rule.ID == "" {
+		return errors.New("empty rule id")
+	}
+	if len(rule.Labels) == 0 {
+		return errors.New("no labels")
+	}
+	for _, l := range rule.Labels {
+		if l.Key == "" || l.Value == "" {
+			return errors.Errorf("invalid label %s", l)
+		}
+		if l.Key == MaxMergeRegionSizeLabel || l.Key == MaxMergeRegionKeysLabel {
+			if _, err := strconv.ParseUint(l.Value, 10, 64); err != nil {
+				return errors.Errorf("invalid label %s, the value should be an unsigned integer", l)
+			}
+		}
+	}
+	for _, tableID := range rule.TableIDs {
+		rule.Ranges = append(rule.Ranges, KeyRange{
+			StartKeyHex: hex.EncodeToString(codec.EncodeBytes(codec.GenerateTableKey(tableID))),
+			EndKeyHex:   hex.EncodeToString(codec.EncodeBytes(codec.GenerateTableKey(tableID + 1))),
+		})
+	}
+	rule.TableIDs = nil
+	if len(rule.Ranges) == 0 {
+		return errors.New("no key ranges")
+	}
+	for i := range rule.Ranges {
+		r := &rule.Ranges[i]
+		var err error
+		r.StartKey, err = hex.DecodeString(r.StartKeyHex)
+		if err != nil {
+			return errors.Wrap(err, "start key is not in hex format")
+		}
+		r.EndKey, err = hex.DecodeString(r.EndKeyHex)
+		if err != nil {
+			return errors.Wrap(err, "end key is not hex format")
+		}
+		if len(r.EndKey) > 0 && bytes.Compare(r.EndKey, r.StartKey) <= 0 {
+			return errors.New("endKey should be greater than startKey")
+		}
+	}
+	rule.expire = nil
+	if rule.TTL == "" {
+		rule.StartAt = ""
+		return nil
+	}
+	ttl, err := time.ParseDuration(rule.TTL)
+	if err != nil {
+		return errors.Wrap(err, "invalid ttl")
+	}
+	if ttl <= 0 {
+		return errors.Errorf("ttl %s should be positive", rule.TTL)
+	}
+	startAt := now
+	if rule.StartAt != "" {
+		if startAt, err = time.Parse(time.RFC3339, rule.StartAt); err != nil {
+			return errors.Wrap(err, "invalid start_at")
+		}
+	}
+	rule.StartAt = startAt.Format(time.RFC3339)
+	expire := startAt.Add(ttl)
+	rule.expire = &expire
+	return nil
+}
+
+func (rule *LabelRule) expired(now time.Time) bool {
+	return rule.expire != nil && !now.Before(*rule.expire)
+}
+
+// matchRange returns if the range [startKey, endKey) is covered by the rule.
+func (rule *LabelRule) matchRange(startKey, endKey []byte) bool {
+	for i := range rule.Ranges {
+		if rule.Ranges[i].contains(startKey, endKey) {
+			return true
+		}
+	}
+	return false
+}
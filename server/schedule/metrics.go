@@ -50,6 +50,22 @@ var (
 			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 16),
 		}, []string{"type"})
 
+	waitingOperatorGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "waiting_operators",
+			Help:      "Number of waiting operators of each priority.",
+		}, []string{"priority"})
+
+	operatorPreemptCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "operators_preempted_count",
+			Help:      "Counter of operators canceled to give store limit to urgent operators.",
+		}, []string{"type", "by"})
+
 	storeLimitGauge = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Namespace: "pd",
@@ -57,6 +73,14 @@ var (
 			Name:      "store_limit",
 			Help:      "Limit of store.",
 		}, []string{"store", "type", "limit_type"})
+
+	operatorRecordCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "pd",
+			Subsystem: "schedule",
+			Name:      "operator_records_count",
+			Help:      "Counter of persisted operator records.",
+		}, []string{"event"})
 )
 
 func init() {
@@ -65,4 +89,7 @@ func init() {
 	prometheus.MustRegister(operatorWaitDuration)
 	prometheus.MustRegister(storeLimitGauge)
 	prometheus.MustRegister(operatorWaitCounter)
+	prometheus.MustRegister(waitingOperatorGauge)
+	prometheus.MustRegister(operatorPreemptCounter)
+	prometheus.MustRegister(operatorRecordCounter)
 }
@@ -39,6 +39,7 @@ type Builder struct {
 	regionID    uint64
 	regionEpoch *metapb.RegionEpoch
 	rules       []*placement.Rule
+	witnesses   map[uint64]struct{} // stores of the witnesses reported by TiKV, they never become leader
 
 	// operation record
 	originPeers  peersMap
@@ -70,6 +71,7 @@ func NewBuilder(desc string, cluster Cluster, region *core.RegionInfo) *Builder
 	}
 
 	var rules []*placement.Rule
+	witnesses := region.GetWitnessStoreIds()
 	if cluster.IsPlacementRulesEnabled() {
 		fit := cluster.FitRegion(region)
 		for _, rf := range fit.RuleFits {
@@ -86,6 +88,7 @@ func NewBuilder(desc string, cluster Cluster, region *core.RegionInfo) *Builder
 		regionID:     region.GetID(),
 		regionEpoch:  region.GetRegionEpoch(),
 		rules:        rules,
+		witnesses:    witnesses,
 		originPeers:  originPeers,
 		originLeader: region.GetLeader().GetStoreId(),
 		targetPeers:  originPeers.Copy(),
@@ -107,6 +110,21 @@ func (b *Builder) AddPeer(p *metapb.Peer) *Builder {
 	return b
 }
 
+// AddWitness records an add witness peer operation in Builder. The peer meta
+// in the current kvproto can not carry a witness flag, so the witness is
+// added as an ordinary voter which stores a full replica, and it is treated
+// as a witness only after TiKV reports it as one.
+func (b *Builder) AddWitness(p *metapb.Peer) *Builder {
+	if b.err != nil {
+		return b
+	}
+	if p.GetIsLearner() {
+		b.err = errors.Errorf("cannot add witness %s: witness should be voter", p)
+		return b
+	}
+	return b.AddPeer(p)
+}
+
 // RemovePeer records a remove peer operation in Builder.
 func (b *Builder) RemovePeer(storeID uint64) *Builder {
 	if b.err != nil {
@@ -148,6 +166,8 @@ func (b *Builder) SetLeader(storeID uint64) *Builder {
 		b.err = errors.Errorf("cannot transfer leader to %d: not found", storeID)
 	} else if p.GetIsLearner() {
 		b.err = errors.Errorf("cannot transfer leader to %d: not voter", storeID)
+	} else if _, ok := b.witnesses[storeID]; ok {
+		b.err = errors.Errorf("cannot transfer leader to %d: witness", storeID)
 	} else {
 		b.targetLeader = storeID
 	}
@@ -347,6 +367,9 @@ func (b *Builder) allowLeader(peer *metapb.Peer) bool {
 	if peer.GetIsLearner() {
 		return false
 	}
+	if _, ok := b.witnesses[peer.GetStoreId()]; ok {
+		return false
+	}
 	store := b.cluster.GetStore(peer.GetStoreId())
 	if store == nil {
 		return false
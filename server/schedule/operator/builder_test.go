@@ -100,6 +100,37 @@ func (s *testBuilderSuite) TestRecord(c *C) {
 	c.Assert(builder.isLigthWeight, IsTrue)
 }
 
+func (s *testBuilderSuite) TestWitness(c *C) {
+	c.Assert(s.newBuilder().AddWitness(&metapb.Peer{StoreId: 4, IsLearner: true}).err, NotNil)
+	c.Assert(s.newBuilder().AddWitness(&metapb.Peer{StoreId: 1}).err, NotNil)
+
+	// witness is added as an ordinary voter until TiKV reports it.
+	op, err := s.newBuilder().AddWitness(&metapb.Peer{StoreId: 4}).Build(0)
+	c.Assert(err, IsNil)
+	c.Assert(op.Step(0), DeepEquals, AddLearner{ToStore: 4, PeerID: op.Step(0).(AddLearner).PeerID})
+	c.Assert(op.Step(1).(PromoteLearner).ToStore, Equals, uint64(4))
+	c.Assert(s.newBuilder().AddWitness(&metapb.Peer{StoreId: 4}).SetLeader(4).err, IsNil)
+
+	// reported witness never becomes leader.
+	peers := []*metapb.Peer{
+		{Id: 11, StoreId: 1},
+		{Id: 12, StoreId: 2},
+		{Id: 13, StoreId: 3},
+	}
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0])
+	op, err = NewBuilder("test", s.cluster, region).RemovePeer(1).Build(0)
+	c.Assert(err, IsNil)
+	c.Assert(op.Step(0), FitsTypeOf, TransferLeader{})
+	region = region.Clone(core.WithWitnesses([]*metapb.Peer{peers[1]}))
+	op, err = NewBuilder("test", s.cluster, region).RemovePeer(1).Build(0)
+	c.Assert(err, IsNil)
+	c.Assert(op.Step(0).(TransferLeader).ToStore, Equals, uint64(3))
+	region = region.Clone(core.WithWitnesses([]*metapb.Peer{peers[1], peers[2]}))
+	_, err = NewBuilder("test", s.cluster, region).RemovePeer(1).Build(0)
+	c.Assert(err, NotNil)
+	c.Assert(NewBuilder("test", s.cluster, region).SetLeader(2).err, NotNil)
+}
+
 func (s *testBuilderSuite) TestPrepareBuild(c *C) {
 	// no voter.
 	_, err := s.newBuilder().SetPeers(map[uint64]*metapb.Peer{4: {StoreId: 4, IsLearner: true}}).prepareBuild()
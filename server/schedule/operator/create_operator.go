@@ -30,6 +30,13 @@ func CreateAddPeerOperator(desc string, cluster Cluster, region *core.RegionInfo
 		Build(kind)
 }
 
+// CreateAddWitnessOperator creates an operator that adds a new witness peer.
+func CreateAddWitnessOperator(desc string, cluster Cluster, region *core.RegionInfo, peer *metapb.Peer, kind OpKind) (*Operator, error) {
+	return NewBuilder(desc, cluster, region).
+		AddWitness(peer).
+		Build(kind)
+}
+
 // CreatePromoteLearnerOperator creates an operator that promotes a learner.
 func CreatePromoteLearnerOperator(desc string, cluster Cluster, region *core.RegionInfo, peer *metapb.Peer) (*Operator, error) {
 	return NewBuilder(desc, cluster, region).
@@ -66,6 +73,14 @@ func CreateMovePeerOperator(desc string, cluster Cluster, region *core.RegionInf
 		Build(kind)
 }
 
+// CreateMoveWitnessOperator creates an operator that replaces an old peer with a new witness peer.
+func CreateMoveWitnessOperator(desc string, cluster Cluster, region *core.RegionInfo, kind OpKind, oldStore uint64, peer *metapb.Peer) (*Operator, error) {
+	return NewBuilder(desc, cluster, region).
+		RemovePeer(oldStore).
+		AddWitness(peer).
+		Build(kind)
+}
+
 // CreateMoveLeaderOperator creates an operator that replaces an old leader with a new leader.
 func CreateMoveLeaderOperator(desc string, cluster Cluster, region *core.RegionInfo, kind OpKind, oldStore uint64, peer *metapb.Peer) (*Operator, error) {
 	return NewBuilder(desc, cluster, region).
@@ -147,15 +162,19 @@ func isRegionMatch(a, b *core.RegionInfo) bool {
 }
 
 // CreateScatterRegionOperator creates an operator that scatters the specified region.
-func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.RegionInfo, targetPeers map[uint64]*metapb.Peer) (*Operator, error) {
-	// randomly pick a leader.
-	var ids []uint64
-	for id := range targetPeers {
-		ids = append(ids, id)
-	}
-	var leader uint64
-	if len(ids) > 0 {
-		leader = ids[rand.Intn(len(ids))]
+// The leader is picked randomly if targetLeader is 0.
+func CreateScatterRegionOperator(desc string, cluster Cluster, origin *core.RegionInfo, targetPeers map[uint64]*metapb.Peer, targetLeader uint64) (*Operator, error) {
+	leader := targetLeader
+	if leader == 0 {
+		var ids []uint64
+		for id, peer := range targetPeers {
+			if !peer.GetIsLearner() {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			leader = ids[rand.Intn(len(ids))]
+		}
 	}
 	return NewBuilder(desc, cluster, origin).
 		SetPeers(targetPeers).
@@ -39,6 +39,7 @@ type StoreInfluence struct {
 	RegionCount int64
 	LeaderSize  int64
 	LeaderCount int64
+	LeaderLoad  int64
 	StepCost    map[storelimit.Type]int64
 }
 
@@ -51,6 +52,8 @@ func (s StoreInfluence) ResourceProperty(kind core.ScheduleKind) int64 {
 			return s.LeaderCount
 		case core.BySize:
 			return s.LeaderSize
+		case core.ByLoad:
+			return s.LeaderLoad
 		default:
 			return 0
 		}
@@ -78,6 +81,12 @@ func (s *StoreInfluence) addStepCost(limitType storelimit.Type, cost int64) {
 
 // AdjustStepCost adjusts the step cost of specific type store limit according to region size
 func (s *StoreInfluence) AdjustStepCost(limitType storelimit.Type, regionSize int64) {
+	if limitType.IsByteType() {
+		if regionSize > core.EmptyRegionApproximateSize {
+			s.addStepCost(limitType, regionSize*storelimit.RegionInfluence[limitType])
+		}
+		return
+	}
 	if regionSize > storelimit.SmallRegionThreshold {
 		s.addStepCost(limitType, storelimit.RegionInfluence[limitType])
 	} else if regionSize <= storelimit.SmallRegionThreshold && regionSize > core.EmptyRegionApproximateSize {
@@ -65,7 +65,9 @@ type Operator struct {
 // NewOperator creates a new operator.
 func NewOperator(desc, brief string, regionID uint64, regionEpoch *metapb.RegionEpoch, kind OpKind, steps ...OpStep) *Operator {
 	level := core.NormalPriority
-	if kind&OpAdmin != 0 {
+	if kind&OpReplica != 0 {
+		level = core.UrgentPriority
+	} else if kind&OpAdmin != 0 {
 		level = core.HighPriority
 	}
 	return &Operator{
@@ -105,6 +107,11 @@ func (o *Operator) Desc() string {
 	return o.desc
 }
 
+// Brief returns the operator's brief.
+func (o *Operator) Brief() string {
+	return o.brief
+}
+
 // SetDesc sets the description for the operator.
 func (o *Operator) SetDesc(desc string) {
 	o.desc = desc
@@ -59,8 +59,10 @@ func (tl TransferLeader) Influence(opInfluence OpInfluence, region *core.RegionI
 
 	from.LeaderSize -= region.GetApproximateSize()
 	from.LeaderCount--
+	from.LeaderLoad -= int64(region.GetKeysReadRate())
 	to.LeaderSize += region.GetApproximateSize()
 	to.LeaderCount++
+	to.LeaderLoad += int64(region.GetKeysReadRate())
 }
 
 // AddPeer is an OpStep that adds a region peer.
@@ -99,6 +101,7 @@ func (ap AddPeer) Influence(opInfluence OpInfluence, region *core.RegionInfo) {
 	to.RegionSize += regionSize
 	to.RegionCount++
 	to.AdjustStepCost(storelimit.RegionAdd, regionSize)
+	to.AdjustStepCost(storelimit.RegionAddBytes, regionSize)
 }
 
 // AddLearner is an OpStep that adds a region learner peer.
@@ -138,6 +141,7 @@ func (al AddLearner) Influence(opInfluence OpInfluence, region *core.RegionInfo)
 	to.RegionSize += regionSize
 	to.RegionCount++
 	to.AdjustStepCost(storelimit.RegionAdd, regionSize)
+	to.AdjustStepCost(storelimit.RegionAddBytes, regionSize)
 }
 
 // PromoteLearner is an OpStep that promotes a region learner peer to normal voter.
@@ -198,6 +202,7 @@ func (rp RemovePeer) Influence(opInfluence OpInfluence, region *core.RegionInfo)
 	from.RegionSize -= regionSize
 	from.RegionCount--
 	from.AdjustStepCost(storelimit.RegionRemove, regionSize)
+	from.AdjustStepCost(storelimit.RegionRemoveBytes, regionSize)
 }
 
 // MergeRegion is an OpStep that merge two regions.
@@ -18,6 +18,7 @@ import (
 	"container/list"
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
@@ -32,6 +33,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/storelimit"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -62,6 +64,7 @@ type OperatorController struct {
 	histories       *list.List
 	counts          map[operator.OpKind]uint64
 	opRecords       *OperatorRecords
+	recordStorage   *OperatorRecordStorage
 	storesLimit     map[uint64]map[storelimit.Type]*storelimit.StoreLimit
 	wop             WaitingOperator
 	wopStatus       *WaitingOperatorStatus
@@ -70,7 +73,7 @@ type OperatorController struct {
 
 // NewOperatorController creates a OperatorController.
 func NewOperatorController(ctx context.Context, cluster opt.Cluster, hbStreams opt.HeartbeatStreams) *OperatorController {
-	return &OperatorController{
+	oc := &OperatorController{
 		ctx:             ctx,
 		cluster:         cluster,
 		operators:       make(map[uint64]*operator.Operator),
@@ -79,10 +82,14 @@ func NewOperatorController(ctx context.Context, cluster opt.Cluster, hbStreams o
 		counts:          make(map[operator.OpKind]uint64),
 		opRecords:       NewOperatorRecords(ctx),
 		storesLimit:     make(map[uint64]map[storelimit.Type]*storelimit.StoreLimit),
-		wop:             NewRandBuckets(),
 		wopStatus:       NewWaitingOperatorStatus(),
 		opNotifierQueue: make(operatorQueue, 0),
 	}
+	oc.wop = NewPriorityQueues(func(desc string) float64 {
+		_, weight := cluster.GetSchedulerQuota(desc)
+		return weight
+	})
+	return oc
 }
 
 // Ctx returns a context which will be canceled once RaftCluster is stopped.
@@ -301,7 +308,7 @@ func (oc *OperatorController) AddOperator(ops ...*operator.Operator) bool {
 	oc.Lock()
 	defer oc.Unlock()
 
-	if oc.exceedStoreLimit(ops...) || !oc.checkAddOperator(ops...) {
+	if !oc.checkAddOperator(ops...) || (oc.exceedStoreLimit(ops...) && !oc.preemptStoreLimit(ops...)) {
 		for _, op := range ops {
 			operatorCounter.WithLabelValues(op.Desc(), "cancel").Inc()
 			_ = op.Cancel()
@@ -330,7 +337,7 @@ func (oc *OperatorController) PromoteWaitingOperator() {
 		}
 		operatorWaitCounter.WithLabelValues(ops[0].Desc(), "get").Inc()
 
-		if oc.exceedStoreLimit(ops...) || !oc.checkAddOperator(ops...) {
+		if !oc.checkAddOperator(ops...) || (oc.exceedStoreLimit(ops...) && !oc.preemptStoreLimit(ops...)) {
 			for _, op := range ops {
 				operatorWaitCounter.WithLabelValues(op.Desc(), "promote_canceled").Inc()
 				_ = op.Cancel()
@@ -393,8 +400,8 @@ func (oc *OperatorController) checkAddOperator(ops ...*operator.Operator) bool {
 			operatorWaitCounter.WithLabelValues(op.Desc(), "add_canceled").Inc()
 			return false
 		}
-		if oc.wopStatus.ops[op.Desc()] >= oc.cluster.GetSchedulerMaxWaitingOperator() {
-			log.Debug("exceed_max return false", zap.Uint64("waiting", oc.wopStatus.ops[op.Desc()]), zap.String("desc", op.Desc()), zap.Uint64("max", oc.cluster.GetSchedulerMaxWaitingOperator()))
+		if maxWaiting, _ := oc.cluster.GetSchedulerQuota(op.Desc()); oc.wopStatus.ops[op.Desc()] >= maxWaiting {
+			log.Debug("exceed_max return false", zap.Uint64("waiting", oc.wopStatus.ops[op.Desc()]), zap.String("desc", op.Desc()), zap.Uint64("max", maxWaiting))
 			operatorWaitCounter.WithLabelValues(op.Desc(), "exceed_max").Inc()
 			return false
 		}
@@ -554,6 +561,27 @@ func (oc *OperatorController) buryOperator(op *operator.Operator) {
 	}
 
 	oc.opRecords.Put(op)
+	if oc.recordStorage != nil {
+		oc.recordStorage.Put(op)
+	}
+}
+
+// SetOperatorRecordStorage sets the storage to persist the finished operators.
+func (oc *OperatorController) SetOperatorRecordStorage(s *OperatorRecordStorage) {
+	oc.Lock()
+	defer oc.Unlock()
+	oc.recordStorage = s
+}
+
+// GetOperatorRecords returns the persisted finished operators matching the filter.
+func (oc *OperatorController) GetOperatorRecords(filter *OperatorRecordFilter) ([]*OperatorRecord, error) {
+	oc.RLock()
+	s := oc.recordStorage
+	oc.RUnlock()
+	if s == nil {
+		return nil, errors.New("operator records are not persisted")
+	}
+	return s.Query(filter)
 }
 
 // GetOperatorStatus gets the operator and its status with the specify id.
@@ -869,9 +897,13 @@ func (oc *OperatorController) exceedStoreLimit(ops ...*operator.Operator) bool {
 			if stepCost == 0 {
 				continue
 			}
-			available := oc.getOrCreateStoreLimit(storeID, v).Available()
+			limit := oc.getOrCreateStoreLimit(storeID, v)
+			if limit == nil {
+				continue
+			}
+			available := limit.Available()
 			storeLimitGauge.WithLabelValues(strconv.FormatUint(storeID, 10), "available", n).Set(float64(available) / float64(storelimit.RegionInfluence[v]))
-			if available < stepCost {
+			if available < v.RequiredTokens(stepCost) {
 				return true
 			}
 		}
@@ -879,6 +911,113 @@ func (oc *OperatorController) exceedStoreLimit(ops ...*operator.Operator) bool {
 	return false
 }
 
+// preemptStoreLimit cancels the running operators with lower priority to make
+// room in the store limit for the urgent operators. The tokens of the
+// unfinished steps of the canceled operators are refunded to the store limits,
+// and then taken by the urgent ones when they are added. Nothing is canceled
+// if there are not enough operators to preempt. It returns true if the
+// operators can be added.
+func (oc *OperatorController) preemptStoreLimit(ops ...*operator.Operator) bool {
+	level := ops[0].GetPriorityLevel()
+	if level < core.UrgentPriority {
+		return false
+	}
+	regions := make(map[uint64]struct{}, len(ops))
+	for _, op := range ops {
+		regions[op.RegionID()] = struct{}{}
+	}
+	var (
+		candidates  []*operator.Operator
+		influences  = make(map[uint64]operator.OpInfluence)
+		preempted   []*operator.Operator
+		isPreempted = make(map[uint64]struct{})
+	)
+	for _, op := range oc.operators {
+		if _, ok := regions[op.RegionID()]; ok || op.GetPriorityLevel() >= level {
+			continue
+		}
+		region := oc.cluster.GetRegion(op.RegionID())
+		if region == nil {
+			continue
+		}
+		influence := operator.OpInfluence{StoresInfluence: make(map[uint64]*operator.StoreInfluence)}
+		op.UnfinishedInfluence(influence, region)
+		candidates = append(candidates, op)
+		influences[op.RegionID()] = influence
+	}
+	// Preempt the lowest priority first, and the latest started one loses the
+	// least progress.
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].GetPriorityLevel() != candidates[j].GetPriorityLevel() {
+			return candidates[i].GetPriorityLevel() < candidates[j].GetPriorityLevel()
+		}
+		if !candidates[i].GetStartTime().Equal(candidates[j].GetStartTime()) {
+			return candidates[i].GetStartTime().After(candidates[j].GetStartTime())
+		}
+		return candidates[i].RegionID() > candidates[j].RegionID()
+	})
+
+	opInfluence := NewTotalOpInfluence(ops, oc.cluster)
+	for storeID := range opInfluence.StoresInfluence {
+		for _, v := range storelimit.TypeNameValue {
+			stepCost := opInfluence.GetStoreInfluence(storeID).GetStepCost(v)
+			limit := oc.storesLimit[storeID][v]
+			if stepCost == 0 || limit == nil {
+				continue
+			}
+			lack := v.RequiredTokens(stepCost) - limit.Available()
+			for _, op := range preempted {
+				lack -= influences[op.RegionID()].GetStoreInfluence(storeID).GetStepCost(v)
+			}
+			for _, op := range candidates {
+				if lack <= 0 {
+					break
+				}
+				if _, ok := isPreempted[op.RegionID()]; ok {
+					continue
+				}
+				if cost := influences[op.RegionID()].GetStoreInfluence(storeID).GetStepCost(v); cost > 0 {
+					lack -= cost
+					preempted = append(preempted, op)
+					isPreempted[op.RegionID()] = struct{}{}
+				}
+			}
+			if lack > 0 {
+				return false
+			}
+		}
+	}
+
+	for _, op := range preempted {
+		if !oc.removeOperatorLocked(op) {
+			continue
+		}
+		if op.Cancel() {
+			log.Info("operator preempted",
+				zap.Uint64("region-id", op.RegionID()),
+				zap.String("by", ops[0].Desc()),
+				zap.Reflect("operator", op))
+		}
+		operatorPreemptCounter.WithLabelValues(op.Desc(), ops[0].Desc()).Inc()
+		oc.buryOperator(op)
+		oc.refundStoreLimit(influences[op.RegionID()])
+	}
+	return true
+}
+
+// refundStoreLimit gives back the tokens of the unfinished influence of a
+// canceled operator.
+func (oc *OperatorController) refundStoreLimit(influence operator.OpInfluence) {
+	for storeID, storeInfluence := range influence.StoresInfluence {
+		for _, v := range storelimit.TypeNameValue {
+			limit := oc.storesLimit[storeID][v]
+			if cost := storeInfluence.GetStepCost(v); cost > 0 && limit != nil {
+				limit.Refund(cost)
+			}
+		}
+	}
+}
+
 // SetAllStoresLimit is used to set limit of all stores.
 func (oc *OperatorController) SetAllStoresLimit(rate float64, mode storelimit.Mode, limitType storelimit.Type) {
 	oc.Lock()
@@ -901,10 +1040,7 @@ func (oc *OperatorController) SetAllStoresLimitAuto(rate float64, limitType stor
 				continue
 			}
 		}
-		if oc.storesLimit[sid] == nil {
-			oc.storesLimit[sid] = make(map[storelimit.Type]*storelimit.StoreLimit)
-		}
-		oc.storesLimit[sid][limitType] = storelimit.NewStoreLimit(rate, storelimit.Auto, storelimit.RegionInfluence[limitType])
+		oc.newStoreLimit(sid, rate, storelimit.Auto, limitType)
 	}
 }
 
@@ -920,26 +1056,34 @@ func (oc *OperatorController) newStoreLimit(storeID uint64, rate float64, mode s
 	if oc.storesLimit[storeID] == nil {
 		oc.storesLimit[storeID] = make(map[storelimit.Type]*storelimit.StoreLimit)
 	}
+	if oc.storesLimit[storeID][limitType] == nil {
+		oc.attachAvailableFunc(storeID, limitType)
+	}
 	oc.storesLimit[storeID][limitType] = storelimit.NewStoreLimit(rate, mode, storelimit.RegionInfluence[limitType])
 }
 
-// getOrCreateStoreLimit is used to get or create the limit of a store.
+// getOrCreateStoreLimit is used to get or create the limit of a store. The
+// byte limits are never created automatically, it returns nil if they are
+// not set.
 func (oc *OperatorController) getOrCreateStoreLimit(storeID uint64, limitType storelimit.Type) *storelimit.StoreLimit {
-	if oc.storesLimit[storeID][limitType] == nil {
+	if oc.storesLimit[storeID][limitType] == nil && !limitType.IsByteType() {
 		rate := oc.cluster.GetStoreBalanceRate() / StoreBalanceBaseTime
 		oc.newStoreLimit(storeID, rate, storelimit.Auto, limitType)
-		oc.cluster.AttachAvailableFunc(storeID, limitType, func() bool {
-			oc.RLock()
-			defer oc.RUnlock()
-			if oc.storesLimit[storeID][limitType] == nil {
-				return true
-			}
-			return oc.storesLimit[storeID][limitType].Available() >= storelimit.RegionInfluence[limitType]
-		})
 	}
 	return oc.storesLimit[storeID][limitType]
 }
 
+func (oc *OperatorController) attachAvailableFunc(storeID uint64, limitType storelimit.Type) {
+	oc.cluster.AttachAvailableFunc(storeID, limitType, func() bool {
+		oc.RLock()
+		defer oc.RUnlock()
+		if oc.storesLimit[storeID][limitType] == nil {
+			return true
+		}
+		return oc.storesLimit[storeID][limitType].Available() >= storelimit.RegionInfluence[limitType]
+	})
+}
+
 // GetAllStoresLimit is used to get limit of all stores.
 func (oc *OperatorController) GetAllStoresLimit(limitType storelimit.Type) map[uint64]*storelimit.StoreLimit {
 	oc.RLock()
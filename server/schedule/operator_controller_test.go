@@ -368,6 +368,89 @@ func (t *testOperatorControllerSuite) TestStoreLimit(c *C) {
 	c.Assert(oc.RemoveOperator(op), IsFalse)
 }
 
+func (t *testOperatorControllerSuite) TestStoreLimitBytes(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderRegion(1, 1)
+	region := tc.GetRegion(1).Clone(core.SetApproximateSize(100))
+	tc.PutRegion(region)
+	// Leave room in the count limit so that only the byte limit blocks.
+	oc.SetStoreLimit(2, 100, storelimit.Manual, storelimit.RegionAdd)
+
+	// The byte limit is not created automatically.
+	op := operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 2})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	checkRemoveOperatorSuccess(c, oc, op)
+	c.Assert(oc.GetAllStoresLimit(storelimit.RegionAddBytes), HasLen, 0)
+
+	// A region larger than the capacity can still be scheduled, and the
+	// following ones wait until the debt is paid off.
+	oc.SetStoreLimit(2, 10, storelimit.Manual, storelimit.RegionAddBytes)
+	c.Assert(oc.GetAllStoresLimit(storelimit.RegionAddBytes)[2].Rate(), Equals, float64(10))
+	op = operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 2})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	checkRemoveOperatorSuccess(c, oc, op)
+	c.Assert(tc.GetStore(2).IsAvailable(storelimit.RegionAddBytes), IsFalse)
+	op = operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 2})
+	c.Assert(oc.AddOperator(op), IsFalse)
+
+	// The count limit is not affected.
+	c.Assert(tc.GetStore(2).IsAvailable(storelimit.RegionAdd), IsTrue)
+	oc.SetStoreLimit(2, storelimit.Unlimited, storelimit.Manual, storelimit.RegionAddBytes)
+	op = operator.NewOperator("test", "test", 1, region.GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 2})
+	c.Assert(oc.AddOperator(op), IsTrue)
+	checkRemoveOperatorSuccess(c, oc, op)
+}
+
+func (t *testOperatorControllerSuite) TestPreemptStoreLimit(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := NewOperatorController(t.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	for i := uint64(1); i <= 10; i++ {
+		tc.PutRegion(tc.AddLeaderRegion(i, 1).Clone(core.SetApproximateSize(100)))
+	}
+	// Each operator costs a whole region influence.
+	oc.SetStoreLimit(2, 2, storelimit.Manual, storelimit.RegionAdd)
+	var balanceOps []*operator.Operator
+	for i := uint64(1); i <= 2; i++ {
+		op := operator.NewOperator("balance-region", "test", i, tc.GetRegion(i).GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: i + 100})
+		c.Assert(op.GetPriorityLevel(), Equals, core.NormalPriority)
+		c.Assert(oc.AddOperator(op), IsTrue)
+		balanceOps = append(balanceOps, op)
+	}
+	// The store limit is exhausted by the balance operators.
+	op := operator.NewOperator("balance-region", "test", 3, tc.GetRegion(3).GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 103})
+	c.Assert(oc.AddOperator(op), IsFalse)
+
+	// The high priority operator can not preempt.
+	op = operator.NewOperator("hot-region", "test", 3, tc.GetRegion(3).GetRegionEpoch(), operator.OpRegion, operator.AddPeer{ToStore: 2, PeerID: 103})
+	op.SetPriorityLevel(core.HighPriority)
+	c.Assert(oc.AddOperator(op), IsFalse)
+
+	// The replica operator preempts the latest started balance operator.
+	op = operator.NewOperator("replace-down-replica", "test", 3, tc.GetRegion(3).GetRegionEpoch(), operator.OpRegion|operator.OpReplica, operator.AddPeer{ToStore: 2, PeerID: 103})
+	c.Assert(op.GetPriorityLevel(), Equals, core.UrgentPriority)
+	c.Assert(oc.AddOperator(op), IsTrue)
+	c.Assert(oc.GetOperator(3), Equals, op)
+	c.Assert(balanceOps[1].Status(), Equals, operator.CANCELED)
+	c.Assert(oc.GetOperator(2), IsNil)
+	c.Assert(balanceOps[0].Status(), Equals, operator.STARTED)
+	// The tokens of the canceled operator are refunded, so the limit is not
+	// overdrawn.
+	c.Assert(oc.getOrCreateStoreLimit(2, storelimit.RegionAdd).Available() >= 0, IsTrue)
+
+	// Nothing is preempted if it is not enough.
+	op = operator.NewOperator("replace-down-replica", "test", 4, tc.GetRegion(4).GetRegionEpoch(), operator.OpRegion|operator.OpReplica, operator.AddPeer{ToStore: 2, PeerID: 104})
+	op2 := operator.NewOperator("replace-down-replica", "test", 5, tc.GetRegion(5).GetRegionEpoch(), operator.OpRegion|operator.OpReplica, operator.AddPeer{ToStore: 2, PeerID: 105})
+	c.Assert(oc.AddOperator(op, op2), IsFalse)
+	c.Assert(balanceOps[0].Status(), Equals, operator.STARTED)
+}
+
 // #1652
 func (t *testOperatorControllerSuite) TestDispatchOutdatedRegion(c *C) {
 	cluster := mockcluster.NewCluster(mockoption.NewScheduleOptions())
@@ -704,3 +787,21 @@ func (t *testOperatorControllerSuite) TestAddWaitingOperator(c *C) {
 	// no space left, new operator can not be added.
 	c.Assert(controller.AddWaitingOperator(addPeerOp(0)), Equals, 0)
 }
+
+func (t *testOperatorControllerSuite) TestSchedulerQuota(c *C) {
+	opts := mockoption.NewScheduleOptions()
+	cluster := mockcluster.NewCluster(opts)
+	controller := NewOperatorController(t.ctx, cluster, mockhbstream.NewHeartbeatStream())
+	cluster.AddLeaderStore(1, 0)
+	cluster.AddLeaderStore(2, 0)
+	cluster.AddLeaderRegion(1, 1)
+	op := operator.NewOperator("balance-leader", "test", 1, cluster.GetRegion(1).GetRegionEpoch(), operator.OpLeader, operator.TransferLeader{FromStore: 1, ToStore: 2})
+
+	controller.wopStatus.ops["balance-leader"] = opts.SchedulerMaxWaitingOperator
+	c.Assert(controller.checkAddOperator(op), IsFalse)
+	// The quota of the scheduler replaces the max waiting operators.
+	opts.SchedulerQuotas["balance-leader"] = mockoption.SchedulerQuota{MaxWaitingOperator: opts.SchedulerMaxWaitingOperator + 1}
+	c.Assert(controller.checkAddOperator(op), IsTrue)
+	opts.SchedulerQuotas["balance-leader"] = mockoption.SchedulerQuota{Weight: 2}
+	c.Assert(controller.checkAddOperator(op), IsFalse)
+}
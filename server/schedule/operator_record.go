@@ -0,0 +1,287 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"go.uber.org/zap"
+)
+
+var (
+	// operatorRecordKeepTime is the time to keep the finished operators in
+	// the storage.
+	operatorRecordKeepTime = 7 * 24 * time.Hour
+	// maxOperatorRecordCount is the max number of finished operators kept
+	// in the storage.
+	maxOperatorRecordCount = 10000
+	// operatorRecordPruneInterval is the interval to remove the expired
+	// operator records.
+	operatorRecordPruneInterval = 10 * time.Minute
+)
+
+const (
+	// maxOperatorRecordBatch is the max number of records to save or delete
+	// in one transaction.
+	maxOperatorRecordBatch = 64
+	// operatorRecordChanSize is the number of records waiting to be saved,
+	// records are dropped if the channel is full.
+	operatorRecordChanSize = 1024
+)
+
+// OperatorRecord is a finished operator persisted in the storage.
+type OperatorRecord struct {
+	RegionID uint64 `json:"region_id"`
+	// Desc is the scheduler or checker which creates the operator.
+	Desc       string    `json:"desc"`
+	Brief      string    `json:"brief"`
+	Kind       string    `json:"kind"`
+	Stores     []uint64  `json:"stores"`
+	Steps      []string  `json:"steps"`
+	Status     string    `json:"status"`
+	CreateTime time.Time `json:"create_time"`
+	StartTime  time.Time `json:"start_time"`
+	FinishTime time.Time `json:"finish_time"`
+}
+
+// NewOperatorRecord creates a record from a finished operator.
+func NewOperatorRecord(op *operator.Operator) *OperatorRecord {
+	steps := make([]string, 0, op.Len())
+	stores := make([]uint64, 0, op.Len())
+	addStore := func(id uint64) {
+		for _, s := range stores {
+			if s == id {
+				return
+			}
+		}
+		stores = append(stores, id)
+	}
+	for i := 0; i < op.Len(); i++ {
+		step := op.Step(i)
+		steps = append(steps, step.String())
+		switch s := step.(type) {
+		case operator.TransferLeader:
+			addStore(s.FromStore)
+			addStore(s.ToStore)
+		case operator.AddPeer:
+			addStore(s.ToStore)
+		case operator.AddLightPeer:
+			addStore(s.ToStore)
+		case operator.AddLearner:
+			addStore(s.ToStore)
+		case operator.AddLightLearner:
+			addStore(s.ToStore)
+		case operator.PromoteLearner:
+			addStore(s.ToStore)
+		case operator.RemovePeer:
+			addStore(s.FromStore)
+		}
+	}
+	return &OperatorRecord{
+		RegionID:   op.RegionID(),
+		Desc:       op.Desc(),
+		Brief:      op.Brief(),
+		Kind:       op.Kind().String(),
+		Stores:     stores,
+		Steps:      steps,
+		Status:     operator.OpStatusToString(op.Status()),
+		CreateTime: op.GetCreateTime(),
+		StartTime:  op.GetStartTime(),
+		FinishTime: op.GetReachTimeOf(op.Status()),
+	}
+}
+
+// OperatorRecordFilter is the condition to query operator records. The zero
+// value of each field means no limitation.
+type OperatorRecordFilter struct {
+	RegionID uint64
+	StoreID  uint64
+	// Kind is the kinds the operator should contain all of.
+	Kind   operator.OpKind
+	Status string
+	// Start and End limit the finish time in [Start, End).
+	Start time.Time
+	End   time.Time
+	Limit int
+}
+
+func (f *OperatorRecordFilter) match(r *OperatorRecord) bool {
+	if f.RegionID != 0 && r.RegionID != f.RegionID {
+		return false
+	}
+	if f.StoreID != 0 {
+		found := false
+		for _, id := range r.Stores {
+			if id == f.StoreID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.Kind != 0 {
+		kind, err := operator.ParseOperatorKind(r.Kind)
+		if err != nil || kind&f.Kind != f.Kind {
+			return false
+		}
+	}
+	if f.Status != "" && !strings.EqualFold(r.Status, f.Status) {
+		return false
+	}
+	return true
+}
+
+// OperatorRecordStorage persists the finished operators, so they are still
+// available after the PD leader changes. The records are saved
+// asynchronously, and the ones beyond the keep time or the max count are
+// removed periodically.
+type OperatorRecordStorage struct {
+	ctx     context.Context
+	storage *core.Storage
+	ch      chan *OperatorRecord
+}
+
+// NewOperatorRecordStorage creates an OperatorRecordStorage and starts to
+// save records in the background until the context is done.
+func NewOperatorRecordStorage(ctx context.Context, storage *core.Storage) *OperatorRecordStorage {
+	s := &OperatorRecordStorage{
+		ctx:     ctx,
+		storage: storage,
+		ch:      make(chan *OperatorRecord, operatorRecordChanSize),
+	}
+	go s.run()
+	return s
+}
+
+// Put records a finished operator. It never blocks, the record is dropped if
+// there are too many records waiting to be saved.
+func (s *OperatorRecordStorage) Put(op *operator.Operator) {
+	select {
+	case s.ch <- NewOperatorRecord(op):
+	default:
+		operatorRecordCounter.WithLabelValues("drop").Inc()
+	}
+}
+
+// Query returns the records matching the filter by the order of finish time.
+func (s *OperatorRecordStorage) Query(filter *OperatorRecordFilter) ([]*OperatorRecord, error) {
+	var (
+		records = make([]*OperatorRecord, 0)
+		err     error
+	)
+	loadErr := s.storage.LoadOperatorRecords(filter.Start, filter.End, func(k, v string) bool {
+		r := &OperatorRecord{}
+		if err = json.Unmarshal([]byte(v), r); err != nil {
+			return false
+		}
+		if filter.match(r) {
+			records = append(records, r)
+		}
+		return filter.Limit == 0 || len(records) < filter.Limit
+	})
+	if loadErr != nil {
+		return nil, loadErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (s *OperatorRecordStorage) run() {
+	ticker := time.NewTicker(operatorRecordPruneInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case r := <-s.ch:
+			batch := []*OperatorRecord{r}
+		drain:
+			for len(batch) < maxOperatorRecordBatch {
+				select {
+				case r := <-s.ch:
+					batch = append(batch, r)
+				default:
+					break drain
+				}
+			}
+			s.save(batch)
+		case <-ticker.C:
+			s.prune()
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *OperatorRecordStorage) save(batch []*OperatorRecord) {
+	records := make(map[string]interface{}, len(batch))
+	for _, r := range batch {
+		records[core.OperatorRecordKey(r.FinishTime, r.RegionID)] = r
+	}
+	if err := s.storage.SaveOperatorRecords(records); err != nil {
+		log.Error("failed to save operator records", zap.Int("count", len(batch)), zap.Error(err))
+		operatorRecordCounter.WithLabelValues("save-failed").Add(float64(len(batch)))
+		return
+	}
+	operatorRecordCounter.WithLabelValues("save").Add(float64(len(batch)))
+}
+
+// prune removes the records beyond the keep time or the max count. Only the
+// keys are loaded, the expired ones are found by the finish time in the keys.
+func (s *OperatorRecordStorage) prune() {
+	expireTime := time.Now().Add(-operatorRecordKeepTime)
+	if !s.remove(s.loadKeys(time.Time{}, expireTime)) {
+		return
+	}
+	keys := s.loadKeys(expireTime, time.Time{})
+	if len(keys) > maxOperatorRecordCount {
+		s.remove(keys[:len(keys)-maxOperatorRecordCount])
+	}
+}
+
+func (s *OperatorRecordStorage) loadKeys(start, end time.Time) []string {
+	var keys []string
+	if err := s.storage.LoadOperatorRecordKeys(start, end, func(k string) bool {
+		keys = append(keys, k)
+		return true
+	}); err != nil {
+		log.Error("failed to load operator records", zap.Error(err))
+		return nil
+	}
+	return keys
+}
+
+// remove removes the records in batches, it returns false if it fails.
+func (s *OperatorRecordStorage) remove(keys []string) bool {
+	for start := 0; start < len(keys); start += maxOperatorRecordBatch {
+		end := start + maxOperatorRecordBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		if err := s.storage.DeleteOperatorRecords(keys[start:end]); err != nil {
+			log.Error("failed to remove operator records", zap.Error(err))
+			return false
+		}
+		operatorRecordCounter.WithLabelValues("prune").Add(float64(end - start))
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+var _ = Suite(&testOperatorRecordSuite{})
+
+type testOperatorRecordSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testOperatorRecordSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *testOperatorRecordSuite) TearDownSuite(c *C) {
+	s.cancel()
+}
+
+func (s *testOperatorRecordSuite) TestOperatorRecordStorage(c *C) {
+	rs := NewOperatorRecordStorage(s.ctx, core.NewStorage(kv.NewMemoryKV()))
+
+	op1 := operator.NewOperator("balance-leader", "test", 1, &metapb.RegionEpoch{}, operator.OpLeader|operator.OpBalance,
+		operator.TransferLeader{FromStore: 1, ToStore: 2})
+	c.Assert(op1.Start(), IsTrue)
+	c.Assert(op1.Replace(), IsTrue)
+	time.Sleep(time.Millisecond)
+	op2 := operator.NewOperator("replica-checker", "test", 2, &metapb.RegionEpoch{}, operator.OpRegion|operator.OpReplica,
+		operator.AddPeer{ToStore: 3, PeerID: 3}, operator.RemovePeer{FromStore: 1})
+	c.Assert(op2.Cancel(), IsTrue)
+	rs.Put(op1)
+	rs.Put(op2)
+
+	query := func(filter *OperatorRecordFilter) []uint64 {
+		records, err := rs.Query(filter)
+		c.Assert(err, IsNil)
+		regionIDs := make([]uint64, 0, len(records))
+		for _, r := range records {
+			regionIDs = append(regionIDs, r.RegionID)
+		}
+		return regionIDs
+	}
+	testutil.WaitUntil(c, func(c *C) bool {
+		return len(query(&OperatorRecordFilter{})) == 2
+	})
+
+	records, err := rs.Query(&OperatorRecordFilter{RegionID: 2})
+	c.Assert(err, IsNil)
+	c.Assert(records, HasLen, 1)
+	c.Assert(records[0].Desc, Equals, "replica-checker")
+	c.Assert(records[0].Stores, DeepEquals, []uint64{3, 1})
+	c.Assert(records[0].Steps, HasLen, 2)
+	c.Assert(records[0].Status, Equals, "Canceled")
+	c.Assert(records[0].FinishTime.Equal(op2.GetReachTimeOf(operator.CANCELED)), IsTrue)
+
+	c.Assert(query(&OperatorRecordFilter{StoreID: 2}), DeepEquals, []uint64{1})
+	c.Assert(query(&OperatorRecordFilter{StoreID: 1}), DeepEquals, []uint64{1, 2})
+	c.Assert(query(&OperatorRecordFilter{Kind: operator.OpLeader}), DeepEquals, []uint64{1})
+	c.Assert(query(&OperatorRecordFilter{Kind: operator.OpRegion | operator.OpBalance}), HasLen, 0)
+	c.Assert(query(&OperatorRecordFilter{Status: "replaced"}), DeepEquals, []uint64{1})
+	c.Assert(query(&OperatorRecordFilter{Start: op2.GetReachTimeOf(operator.CANCELED)}), DeepEquals, []uint64{2})
+	c.Assert(query(&OperatorRecordFilter{End: op2.GetReachTimeOf(operator.CANCELED)}), DeepEquals, []uint64{1})
+	c.Assert(query(&OperatorRecordFilter{Limit: 1}), DeepEquals, []uint64{1})
+
+	// Only the newest one is kept.
+	defer func(count int) { maxOperatorRecordCount = count }(maxOperatorRecordCount)
+	maxOperatorRecordCount = 1
+	rs.prune()
+	c.Assert(query(&OperatorRecordFilter{}), DeepEquals, []uint64{2})
+
+	// The expired ones are removed.
+	defer func(keepTime time.Duration) { operatorRecordKeepTime = keepTime }(operatorRecordKeepTime)
+	operatorRecordKeepTime = 0
+	rs.prune()
+	c.Assert(query(&OperatorRecordFilter{}), HasLen, 0)
+}
@@ -19,12 +19,21 @@ import (
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/server/statistics"
 )
 
 // Options for schedulers.
 type Options interface {
+	Config
+
+	RemoveScheduler(name string) error
+}
+
+// Config is the configurations of schedulers, it is the Options without the
+// methods changing the schedulers.
+type Config interface {
 	GetLeaderScheduleLimit() uint64
 	GetRegionScheduleLimit() uint64
 	GetReplicaScheduleLimit() uint64
@@ -45,6 +54,7 @@ type Options interface {
 
 	GetMaxReplicas() int
 	GetLocationLabels() []string
+	GetIsolationLevel() string
 	GetStrictlyMatchLabel() bool
 	IsPlacementRulesEnabled() bool
 
@@ -53,6 +63,7 @@ type Options interface {
 	GetLowSpaceRatio() float64
 	GetHighSpaceRatio() float64
 	GetSchedulerMaxWaitingOperator() uint64
+	GetSchedulerQuota(desc string) (maxWaitingOperator uint64, weight float64)
 
 	IsRemoveDownReplicaEnabled() bool
 	IsReplaceOfflineReplicaEnabled() bool
@@ -63,8 +74,6 @@ type Options interface {
 	GetLeaderSchedulePolicy() core.SchedulePolicy
 	GetKeyType() core.KeyType
 
-	RemoveScheduler(name string) error
-
 	CheckLabelProperty(typ string, labels []*metapb.StoreLabel) bool
 }
 
@@ -87,6 +96,7 @@ type Cluster interface {
 
 	AllocID() (uint64, error)
 	FitRegion(*core.RegionInfo) *placement.RegionFit
+	GetRegionLabeler() *labeler.RegionLabeler
 }
 
 // HeartbeatStream is an interface.
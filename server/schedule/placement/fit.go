@@ -89,7 +89,13 @@ type RuleFit struct {
 
 // IsSatisfied returns if the rule is properly satisfied.
 func (f *RuleFit) IsSatisfied() bool {
-	return len(f.Peers) == f.Rule.Count && len(f.PeersWithDifferentRole) == 0
+	return len(f.Peers) == f.Rule.Count && len(f.PeersWithDifferentRole) == 0 && f.IsIsolationSatisfied()
+}
+
+// IsIsolationSatisfied returns if the peers are isolated at the level that
+// is required by the rule's IsolationLevel.
+func (f *RuleFit) IsIsolationSatisfied() bool {
+	return len(f.Peers) <= 1 || f.IsolationLevel >= f.Rule.requiredIsolationLevel()
 }
 
 func compareRuleFit(a, b *RuleFit) int {
@@ -111,7 +117,9 @@ func compareRuleFit(a, b *RuleFit) int {
 	}
 }
 
-// FitRegion tries to fit peers of a region to the rules.
+// FitRegion tries to fit peers of a region to the rules. Rules are fitted in
+// the apply order (see compareRule), so rules in groups with smaller index
+// take precedence when picking peers.
 func FitRegion(stores core.StoreSetInformer, region *core.RegionInfo, rules []*Rule) *RegionFit {
 	peers := prepareFitPeers(stores, region)
 
@@ -178,7 +186,7 @@ func (p *fitPeer) matchRoleStrict(role PeerRoleType) bool {
 		return !p.IsLearner
 	case Leader:
 		return p.isLeader
-	case Follower:
+	case Follower, Witness:
 		return !p.IsLearner && !p.isLeader
 	case Learner:
 		return p.IsLearner
@@ -162,3 +162,44 @@ func (s *testFitSuite) TestFitByLocation(c *C) {
 		c.Assert(ruleFit.IsolationLevel, Equals, cc.expectedIsolationLevel)
 	}
 }
+
+func (s *testFitSuite) TestIsolationSatisfied(c *C) {
+	stores := core.NewBasicCluster()
+	for i, labels := range []map[string]string{
+		{"zone": "z1", "rack": "r1", "host": "h1"},
+		{"zone": "z1", "rack": "r1", "host": "h2"},
+		{"zone": "z1", "rack": "r2", "host": "h1"},
+		{"zone": "z2", "rack": "r1", "host": "h1"},
+	} {
+		stores.PutStore(core.NewStoreInfoWithLabel(uint64(i+1), 0, labels))
+	}
+	makeRegion := func(storeIDs ...uint64) *core.RegionInfo {
+		var peers []*metapb.Peer
+		for _, id := range storeIDs {
+			peers = append(peers, &metapb.Peer{Id: id, StoreId: id})
+		}
+		return core.NewRegionInfo(&metapb.Region{Peers: peers}, peers[0])
+	}
+	rule := &Rule{GroupID: "pd", ID: "default", Role: Voter, Count: 2, LocationLabels: []string{"zone", "rack", "host"}}
+
+	testCases := []struct {
+		isolationLevel string
+		storeIDs       []uint64
+		satisfied      bool
+	}{
+		{"", []uint64{1, 2}, true},
+		{"zone", []uint64{1}, true},
+		{"zone", []uint64{1, 2}, false},
+		{"zone", []uint64{1, 3}, false},
+		{"zone", []uint64{1, 4}, true},
+		{"rack", []uint64{1, 2}, false},
+		{"rack", []uint64{1, 3}, true},
+		{"host", []uint64{1, 2}, true},
+	}
+	for _, t := range testCases {
+		rule.IsolationLevel = t.isolationLevel
+		fit := FitRegion(stores, makeRegion(t.storeIDs...), []*Rule{rule})
+		c.Assert(fit.RuleFits[0].IsIsolationSatisfied(), Equals, t.satisfied)
+		c.Assert(fit.RuleFits[0].IsSatisfied(), Equals, t.satisfied && len(t.storeIDs) == rule.Count)
+	}
+}
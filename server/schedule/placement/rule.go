@@ -31,10 +31,15 @@ const (
 	Follower PeerRoleType = "follower"
 	// Learner matches a learner.
 	Learner PeerRoleType = "learner"
+	// Witness matches a follower which takes part in quorum but stores no
+	// data and never becomes leader. The peer meta in the current kvproto has
+	// no witness flag, so the peers of witness rules are added as ordinary
+	// voters, and PD only treats the peers reported by TiKV as witnesses.
+	Witness PeerRoleType = "witness"
 )
 
 func validateRole(s PeerRoleType) bool {
-	return s == Voter || s == Leader || s == Follower || s == Learner
+	return s == Voter || s == Leader || s == Follower || s == Learner || s == Witness
 }
 
 // Rule is the placement rule that can be checked against a region. When
@@ -53,6 +58,9 @@ type Rule struct {
 	Count            int               `json:"count"`                       // expected count of the peers
 	LabelConstraints []LabelConstraint `json:"label_constraints,omitempty"` // used to select stores to place peers
 	LocationLabels   []string          `json:"location_labels,omitempty"`   // used to make peers isolated physically
+	IsolationLevel   string            `json:"isolation_level,omitempty"`   // used to isolate replicas explicitly and forcibly
+
+	group *RuleGroup // only set at runtime, no need to {,un}marshal or persist.
 }
 
 func (r Rule) String() string {
@@ -70,9 +78,43 @@ func (r Rule) StoreKey() string {
 	return hex.EncodeToString([]byte(r.GroupID)) + "-" + hex.EncodeToString([]byte(r.ID))
 }
 
-// Rules are ordered by (GroupID, Index, ID).
+// requiredIsolationLevel returns the minimum isolation level (see
+// RuleFit.IsolationLevel) that the peers must meet.
+func (r *Rule) requiredIsolationLevel() int {
+	for i, label := range r.LocationLabels {
+		if label == r.IsolationLevel {
+			return len(r.LocationLabels) - i
+		}
+	}
+	return 0
+}
+
+func (r *Rule) groupIndex() int {
+	if r.group != nil {
+		return r.group.Index
+	}
+	return 0
+}
+
+// RuleGroup defines properties of a rule group.
+type RuleGroup struct {
+	ID       string `json:"id,omitempty"`
+	Index    int    `json:"index,omitempty"`
+	Override bool   `json:"override,omitempty"`
+}
+
+func (g *RuleGroup) String() string {
+	b, _ := json.Marshal(g)
+	return string(b)
+}
+
+// Rules are ordered by (GroupIndex, GroupID, Index, ID).
 func compareRule(a, b *Rule) int {
 	switch {
+	case a.groupIndex() < b.groupIndex():
+		return -1
+	case a.groupIndex() > b.groupIndex():
+		return 1
 	case a.GroupID < b.GroupID:
 		return -1
 	case a.GroupID > b.GroupID:
@@ -100,7 +142,11 @@ func prepareRulesForApply(rules []*Rule) []*Rule {
 	var i, j int
 	for i = 1; i < len(rules); i++ {
 		if rules[j].GroupID != rules[i].GroupID {
-			res = append(res, rules[j:i]...)
+			if rules[i].group != nil && rules[i].group.Override {
+				res = res[:0] // override all previous groups
+			} else {
+				res = append(res, rules[j:i]...) // save rules belong to previous groups
+			}
 			j = i
 		}
 		if rules[i].Override {
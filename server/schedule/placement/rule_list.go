@@ -16,6 +16,8 @@ package placement
 import (
 	"bytes"
 	"sort"
+
+	"github.com/pkg/errors"
 )
 
 type splitPointType int
@@ -118,6 +120,46 @@ func buildRuleList(rules map[[2]string]*Rule) ruleList {
 	return rl
 }
 
+// checkRange checks the rules applied to the ranges overlapping [start, end).
+func (rl ruleList) checkRange(start, end []byte) error {
+	for i, rr := range rl.ranges {
+		if len(end) > 0 && bytes.Compare(rr.startKey, end) >= 0 {
+			break
+		}
+		if i+1 < len(rl.ranges) && bytes.Compare(rl.ranges[i+1].startKey, start) <= 0 {
+			continue
+		}
+		if len(rr.rules) == 0 {
+			continue
+		}
+		if err := checkApplyRules(rr.applyRules); err != nil {
+			return errors.Wrapf(err, "invalid rules for range starts with %x", rr.startKey)
+		}
+	}
+	return nil
+}
+
+// checkApplyRules checks if the rules applied to a range meet the raft
+// constraints: there is at most one leader and at least one voter.
+func checkApplyRules(rules []*Rule) error {
+	var leaderCount, voterCount int
+	for _, r := range rules {
+		switch r.Role {
+		case Leader:
+			leaderCount += r.Count
+		case Voter:
+			voterCount += r.Count
+		}
+	}
+	if leaderCount > 1 {
+		return errors.New("multiple leader replicas")
+	}
+	if leaderCount+voterCount < 1 {
+		return errors.New("needs at least one leader or voter")
+	}
+	return nil
+}
+
 func (rl ruleList) getSplitKeys(start, end []byte) [][]byte {
 	var keys [][]byte
 	i := sort.Search(len(rl.ranges), func(i int) bool {
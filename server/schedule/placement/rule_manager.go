@@ -17,10 +17,14 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"sort"
+	"strings"
 	"sync"
 
 	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/slice"
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -32,20 +36,22 @@ type RuleManager struct {
 	sync.RWMutex
 	initialized bool
 	rules       map[[2]string]*Rule
+	ruleGroups  map[string]*RuleGroup
 	ruleList    ruleList
 }
 
 // NewRuleManager creates a RuleManager instance.
 func NewRuleManager(store *core.Storage) *RuleManager {
 	return &RuleManager{
-		store: store,
-		rules: make(map[[2]string]*Rule),
+		store:      store,
+		rules:      make(map[[2]string]*Rule),
+		ruleGroups: make(map[string]*RuleGroup),
 	}
 }
 
 // Initialize loads rules from storage. If Placement Rules feature is never enabled, it creates default rule that is
 // compatible with previous configuration.
-func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error {
+func (m *RuleManager) Initialize(maxReplica int, locationLabels []string, isolationLevel string) error {
 	m.Lock()
 	defer m.Unlock()
 	if m.initialized {
@@ -55,6 +61,9 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 	if err := m.loadRules(); err != nil {
 		return err
 	}
+	if err := m.loadGroups(); err != nil {
+		return err
+	}
 	if len(m.rules) == 0 {
 		// migrate from old config.
 		defaultRule := &Rule{
@@ -63,13 +72,22 @@ func (m *RuleManager) Initialize(maxReplica int, locationLabels []string) error
 			Role:           Voter,
 			Count:          maxReplica,
 			LocationLabels: locationLabels,
+			IsolationLevel: isolationLevel,
 		}
 		if err := m.store.SaveRule(defaultRule.StoreKey(), defaultRule); err != nil {
 			return err
 		}
 		m.rules[defaultRule.Key()] = defaultRule
 	}
+	for _, r := range m.rules {
+		r.group = m.ruleGroups[r.GroupID]
+	}
 	m.ruleList = buildRuleList(m.rules)
+	// The persisted rules are kept even if they are invalid, so that they
+	// can be fixed by later updates.
+	if err := m.ruleList.checkRange(nil, nil); err != nil {
+		log.Warn("persisted placement rules are invalid", zap.Error(err))
+	}
 	m.initialized = true
 	return nil
 }
@@ -117,6 +135,18 @@ func (m *RuleManager) loadRules() error {
 	return nil
 }
 
+func (m *RuleManager) loadGroups() error {
+	_, err := m.store.LoadRuleGroups(func(k, v string) {
+		var g RuleGroup
+		if err := json.Unmarshal([]byte(v), &g); err != nil {
+			log.Error("failed to unmarshal rule group", zap.String("group-id", k), zap.String("group-value", v))
+			return
+		}
+		m.ruleGroups[g.ID] = &g
+	})
+	return err
+}
+
 // check and adjust rule from client or storage.
 func (m *RuleManager) adjustRule(r *Rule) error {
 	var err error
@@ -148,6 +178,9 @@ func (m *RuleManager) adjustRule(r *Rule) error {
 			return errors.Errorf("invalid op %s", c.Op)
 		}
 	}
+	if r.IsolationLevel != "" && slice.NoneOf(r.LocationLabels, func(i int) bool { return r.LocationLabels[i] == r.IsolationLevel }) {
+		return errors.Errorf("isolation level %s should be one of location labels", r.IsolationLevel)
+	}
 	return nil
 }
 
@@ -160,47 +193,125 @@ func (m *RuleManager) GetRule(group, id string) *Rule {
 
 // SetRule inserts or updates a Rule.
 func (m *RuleManager) SetRule(rule *Rule) error {
-	err := m.adjustRule(rule)
-	if err != nil {
-		return err
-	}
-	m.Lock()
-	defer m.Unlock()
-	old := m.rules[rule.Key()]
-	m.rules[rule.Key()] = rule
-
-	if err = m.store.SaveRule(rule.StoreKey(), rule); err != nil {
-		// restore
-		if old == nil {
-			delete(m.rules, rule.Key())
-		} else {
-			m.rules[old.Key()] = old
-		}
-		return err
-	}
-
-	log.Info("placement rule updated", zap.Stringer("rule", rule))
-	m.ruleList = buildRuleList(m.rules)
-	return nil
+	return m.Batch([]RuleOp{{Rule: rule, Action: RuleOpAdd}})
 }
 
 // DeleteRule removes a Rule.
 func (m *RuleManager) DeleteRule(group, id string) error {
+	return m.Batch([]RuleOp{{Rule: &Rule{GroupID: group, ID: id}, Action: RuleOpDel}})
+}
+
+// RuleOpType indicates the operation type of a RuleOp.
+type RuleOpType string
+
+const (
+	// RuleOpAdd inserts or updates a placement rule.
+	RuleOpAdd RuleOpType = "add"
+	// RuleOpDel removes a placement rule, only `GroupID` and `ID` of the rule
+	// need to be specified.
+	RuleOpDel RuleOpType = "del"
+)
+
+// RuleOp is for batching placement rule actions. The action type is
+// distinguished by the field `Action`.
+type RuleOp struct {
+	*Rule                       // information of the placement rule to add/delete
+	Action           RuleOpType `json:"action"`              // the operation type
+	DeleteByIDPrefix bool       `json:"delete_by_id_prefix"` // if action == del, delete all rules of the group that IDs have the prefix
+}
+
+func (r RuleOp) String() string {
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// Batch executes a series of actions at once. The ranges of the updated rules
+// are checked after all actions applied, and the rules are persisted
+// atomically, so at most kv.MaxBatchOps rules can be updated at once. If
+// anything fails, none of the actions takes effect.
+func (m *RuleManager) Batch(todo []RuleOp) error {
+	for _, t := range todo {
+		if t.Rule == nil {
+			return errors.New("rule should not be empty")
+		}
+		switch t.Action {
+		case RuleOpAdd:
+			if err := m.adjustRule(t.Rule); err != nil {
+				return err
+			}
+		case RuleOpDel:
+		default:
+			return errors.Errorf("invalid action %s", t.Action)
+		}
+	}
+
 	m.Lock()
 	defer m.Unlock()
-	key := [2]string{group, id}
-	old, ok := m.rules[key]
-	if !ok {
+
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for k, r := range m.rules {
+		rules[k] = r
+	}
+	dirty := make(map[[2]string]struct{})
+	for _, t := range todo {
+		switch t.Action {
+		case RuleOpAdd:
+			t.Rule.group = m.ruleGroups[t.GroupID]
+			rules[t.Key()] = t.Rule
+			dirty[t.Key()] = struct{}{}
+		case RuleOpDel:
+			if !t.DeleteByIDPrefix {
+				delete(rules, t.Key())
+				dirty[t.Key()] = struct{}{}
+				continue
+			}
+			for k, r := range rules {
+				if r.GroupID == t.GroupID && strings.HasPrefix(r.ID, t.ID) {
+					delete(rules, k)
+					dirty[k] = struct{}{}
+				}
+			}
+		}
+	}
+
+	rl := buildRuleList(rules)
+	toSave := make(map[string]interface{})
+	var toDelete []string
+	for k := range dirty {
+		// Only the ranges of the updated rules are checked, the invalid
+		// rules loaded from storage don't block the other updates.
+		if old, ok := m.rules[k]; ok {
+			if err := rl.checkRange(old.StartKey, old.EndKey); err != nil {
+				return err
+			}
+		}
+		if r, ok := rules[k]; ok {
+			if err := rl.checkRange(r.StartKey, r.EndKey); err != nil {
+				return err
+			}
+			toSave[r.StoreKey()] = r
+		} else if old, ok := m.rules[k]; ok {
+			toDelete = append(toDelete, old.StoreKey())
+		}
+	}
+	if len(toSave) == 0 && len(toDelete) == 0 {
 		return nil
 	}
-	delete(m.rules, [2]string{group, id})
-	if err := m.store.DeleteRule(old.StoreKey()); err != nil {
-		// restore
-		m.rules[key] = old
+	if n := len(toSave) + len(toDelete); n > kv.MaxBatchOps {
+		return errors.Errorf("too many rules to update at once: %d > %d", n, kv.MaxBatchOps)
+	}
+	if err := m.store.BatchUpdateRules(toSave, toDelete); err != nil {
 		return err
 	}
-	log.Info("placement rule removed", zap.Stringer("rule", old))
-	m.ruleList = buildRuleList(m.rules)
+
+	for k := range dirty {
+		if r, ok := rules[k]; ok {
+			log.Info("placement rule updated", zap.Stringer("rule", r))
+		} else if old, ok := m.rules[k]; ok {
+			log.Info("placement rule removed", zap.Stringer("rule", old))
+		}
+	}
+	m.rules, m.ruleList = rules, rl
 	return nil
 }
 
@@ -256,3 +367,94 @@ func (m *RuleManager) FitRegion(stores core.StoreSetInformer, region *core.Regio
 	rules := m.GetRulesForApplyRegion(region)
 	return FitRegion(stores, region, rules)
 }
+
+// GetRuleGroup returns a RuleGroup configuration.
+func (m *RuleManager) GetRuleGroup(id string) *RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	return m.ruleGroups[id]
+}
+
+// GetRuleGroups returns all RuleGroup configuration.
+func (m *RuleManager) GetRuleGroups() []*RuleGroup {
+	m.RLock()
+	defer m.RUnlock()
+	groups := make([]*RuleGroup, 0, len(m.ruleGroups))
+	for _, g := range m.ruleGroups {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Index != groups[j].Index {
+			return groups[i].Index < groups[j].Index
+		}
+		return groups[i].ID < groups[j].ID
+	})
+	return groups
+}
+
+// SetRuleGroup updates a RuleGroup.
+func (m *RuleManager) SetRuleGroup(group *RuleGroup) error {
+	if group.ID == "" {
+		return errors.New("group ID should not be empty")
+	}
+	m.Lock()
+	defer m.Unlock()
+	rules, rl, err := m.buildRulesWithGroup(group.ID, group)
+	if err != nil {
+		return err
+	}
+	if err := m.store.SaveRuleGroup(group.ID, group); err != nil {
+		return err
+	}
+	m.ruleGroups[group.ID] = group
+	m.rules, m.ruleList = rules, rl
+	log.Info("group config updated", zap.Stringer("group", group))
+	return nil
+}
+
+// DeleteRuleGroup removes a RuleGroup. Rules of the group will fallback to
+// the default group configuration.
+func (m *RuleManager) DeleteRuleGroup(id string) error {
+	m.Lock()
+	defer m.Unlock()
+	if _, ok := m.ruleGroups[id]; !ok {
+		return nil
+	}
+	rules, rl, err := m.buildRulesWithGroup(id, nil)
+	if err != nil {
+		return err
+	}
+	if err := m.store.DeleteRuleGroup(id); err != nil {
+		return err
+	}
+	delete(m.ruleGroups, id)
+	m.rules, m.ruleList = rules, rl
+	log.Info("group config reset", zap.String("group", id))
+	return nil
+}
+
+// buildRulesWithGroup attaches the group configuration to all rules of the
+// group and builds the rule list, the ranges of the group are checked. Rules
+// are copied rather than updated in place because they may be still
+// referenced by readers.
+func (m *RuleManager) buildRulesWithGroup(id string, group *RuleGroup) (map[[2]string]*Rule, ruleList, error) {
+	rules := make(map[[2]string]*Rule, len(m.rules))
+	for key, r := range m.rules {
+		if r.GroupID == id {
+			nr := *r
+			nr.group = group
+			r = &nr
+		}
+		rules[key] = r
+	}
+	rl := buildRuleList(rules)
+	for _, r := range rules {
+		if r.GroupID != id {
+			continue
+		}
+		if err := rl.checkRange(r.StartKey, r.EndKey); err != nil {
+			return nil, rl, err
+		}
+	}
+	return rules, rl, nil
+}
@@ -15,6 +15,7 @@ package placement
 
 import (
 	"encoding/hex"
+	"strconv"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -33,7 +34,7 @@ func (s *testManagerSuite) SetUpTest(c *C) {
 	s.store = core.NewStorage(kv.NewMemoryKV())
 	var err error
 	s.manager = NewRuleManager(s.store)
-	err = s.manager.Initialize(3, []string{"zone", "rack", "host"})
+	err = s.manager.Initialize(3, []string{"zone", "rack", "host"}, "")
 	c.Assert(err, IsNil)
 }
 
@@ -61,6 +62,7 @@ func (s *testManagerSuite) TestAdjustRule(c *C) {
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 0},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: -1},
 		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LabelConstraints: []LabelConstraint{{Op: "foo"}}},
+		{GroupID: "group", ID: "id", StartKeyHex: "123abc", EndKeyHex: "123abf", Role: "voter", Count: 3, LocationLabels: []string{"zone"}, IsolationLevel: "rack"},
 	}
 	c.Assert(s.manager.adjustRule(&rules[0]), IsNil)
 	c.Assert(rules[0].StartKey, DeepEquals, []byte{0x12, 0x3a, 0xbc})
@@ -80,7 +82,7 @@ func (s *testManagerSuite) TestSaveLoad(c *C) {
 		s.manager.SetRule(r)
 	}
 	m2 := NewRuleManager(s.store)
-	err := m2.Initialize(3, []string{"no", "labels"})
+	err := m2.Initialize(3, []string{"no", "labels"}, "")
 	c.Assert(err, IsNil)
 	c.Assert(m2.GetAllRules(), HasLen, 3)
 	c.Assert(m2.GetRule("pd", "default"), DeepEquals, rules[0])
@@ -161,6 +163,153 @@ func (s *testManagerSuite) TestKeys(c *C) {
 	}
 }
 
+func (s *testManagerSuite) TestBatch(c *C) {
+	testCases := []struct {
+		ops   []RuleOp
+		rules [][2]string
+	}{
+		{ // add rules and delete default rule at once.
+			ops: []RuleOp{
+				{Rule: &Rule{GroupID: "pd", ID: "voter", Role: "voter", Count: 3}, Action: RuleOpAdd},
+				{Rule: &Rule{GroupID: "pd", ID: "learner1", Role: "learner", Count: 1}, Action: RuleOpAdd},
+				{Rule: &Rule{GroupID: "pd", ID: "learner2", Role: "learner", Count: 1}, Action: RuleOpAdd},
+				{Rule: &Rule{GroupID: "pd", ID: "default"}, Action: RuleOpDel},
+			},
+			rules: [][2]string{{"pd", "learner1"}, {"pd", "learner2"}, {"pd", "voter"}},
+		},
+		{ // delete by id prefix.
+			ops: []RuleOp{
+				{Rule: &Rule{GroupID: "pd", ID: "learner"}, Action: RuleOpDel, DeleteByIDPrefix: true},
+			},
+			rules: [][2]string{{"pd", "voter"}},
+		},
+		{ // add and delete the same rule.
+			ops: []RuleOp{
+				{Rule: &Rule{GroupID: "pd", ID: "foo", Role: "learner", Count: 1}, Action: RuleOpAdd},
+				{Rule: &Rule{GroupID: "pd", ID: "foo"}, Action: RuleOpDel},
+			},
+			rules: [][2]string{{"pd", "voter"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		err := s.manager.Batch(tc.ops)
+		c.Assert(err, IsNil)
+		rules := s.manager.GetAllRules()
+		c.Assert(rules, HasLen, len(tc.rules))
+		for i := range rules {
+			c.Assert(rules[i].Key(), Equals, tc.rules[i])
+		}
+		// check persisted rules.
+		m2 := NewRuleManager(s.store)
+		err = m2.Initialize(3, []string{}, "")
+		c.Assert(err, IsNil)
+		c.Assert(m2.GetAllRules(), DeepEquals, rules)
+	}
+
+	// invalid batch does not take effect.
+	failCases := [][]RuleOp{
+		{ // no voter left.
+			{Rule: &Rule{GroupID: "pd", ID: "learner", Role: "learner", Count: 1}, Action: RuleOpAdd},
+			{Rule: &Rule{GroupID: "pd", ID: "voter"}, Action: RuleOpDel},
+		},
+		{ // multiple leaders.
+			{Rule: &Rule{GroupID: "pd", ID: "leader1", Role: "leader", Count: 1}, Action: RuleOpAdd},
+			{Rule: &Rule{GroupID: "pd", ID: "leader2", Role: "leader", Count: 1}, Action: RuleOpAdd},
+		},
+		{ // bad rule.
+			{Rule: &Rule{GroupID: "pd", ID: "foo", Role: "learner", Count: 1}, Action: RuleOpAdd},
+			{Rule: &Rule{GroupID: "pd", ID: "bar", Role: "voter", Count: 0}, Action: RuleOpAdd},
+		},
+		{ // bad action.
+			{Rule: &Rule{GroupID: "pd", ID: "foo", Role: "learner", Count: 1}, Action: "foo"},
+		},
+	}
+	// too many rules to persist at once.
+	var ops []RuleOp
+	for i := 0; i <= kv.MaxBatchOps; i++ {
+		ops = append(ops, RuleOp{Rule: &Rule{GroupID: "pd", ID: "learner" + strconv.Itoa(i), Role: "learner", Count: 1}, Action: RuleOpAdd})
+	}
+	failCases = append(failCases, ops)
+	for _, ops := range failCases {
+		c.Assert(s.manager.Batch(ops), NotNil)
+		rules := s.manager.GetAllRules()
+		c.Assert(rules, HasLen, 1)
+		c.Assert(rules[0].Key(), Equals, [2]string{"pd", "voter"})
+	}
+}
+
+func (s *testManagerSuite) TestLoadInvalidRules(c *C) {
+	// the persisted rules of range [11, 22) have multiple leaders.
+	rules := []*Rule{
+		{GroupID: "pd", ID: "default", Role: "voter", Count: 3},
+		{GroupID: "pd", ID: "leader1", StartKeyHex: "11", EndKeyHex: "22", Role: "leader", Count: 1},
+		{GroupID: "pd", ID: "leader2", StartKeyHex: "11", EndKeyHex: "22", Role: "leader", Count: 1},
+	}
+	for _, r := range rules {
+		c.Assert(s.manager.adjustRule(r), IsNil)
+		c.Assert(s.store.SaveRule(r.StoreKey(), r), IsNil)
+	}
+	m2 := NewRuleManager(s.store)
+	c.Assert(m2.Initialize(3, []string{}, ""), IsNil)
+	c.Assert(m2.GetAllRules(), HasLen, 3)
+
+	// the updates of other ranges are not blocked.
+	err := m2.SetRule(&Rule{GroupID: "pd", ID: "learner", StartKeyHex: "33", EndKeyHex: "44", Role: "learner", Count: 1})
+	c.Assert(err, IsNil)
+	// the updates of the invalid range are checked.
+	err = m2.SetRule(&Rule{GroupID: "pd", ID: "learner2", StartKeyHex: "11", EndKeyHex: "33", Role: "learner", Count: 1})
+	c.Assert(err, NotNil)
+	c.Assert(m2.DeleteRule("pd", "leader2"), IsNil)
+	c.Assert(m2.GetAllRules(), HasLen, 3)
+}
+
+func (s *testManagerSuite) TestGroupConfig(c *C) {
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 0)
+	c.Assert(s.manager.GetRuleGroup("pd"), IsNil)
+
+	// add a rule to a new group and configure the group.
+	err := s.manager.SetRule(&Rule{GroupID: "g1", ID: "r1", Role: "voter", Count: 1})
+	c.Assert(err, IsNil)
+	err = s.manager.SetRuleGroup(&RuleGroup{ID: "g1", Index: -1, Override: true})
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroups(), DeepEquals, []*RuleGroup{{ID: "g1", Index: -1, Override: true}})
+	c.Assert(s.manager.GetRuleGroup("g1"), DeepEquals, &RuleGroup{ID: "g1", Index: -1, Override: true})
+
+	// group with smaller index is applied first, so "pd" overrides nothing
+	// and the rules of both groups are applied.
+	rules := s.manager.GetAllRules()
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].Key(), Equals, [2]string{"g1", "r1"})
+	c.Assert(rules[1].Key(), Equals, [2]string{"pd", "default"})
+	region := core.NewRegionInfo(&metapb.Region{}, nil)
+	c.Assert(s.manager.GetRulesForApplyRegion(region), HasLen, 2)
+
+	// move group after "pd", it overrides the default rule.
+	err = s.manager.SetRuleGroup(&RuleGroup{ID: "g1", Index: 1, Override: true})
+	c.Assert(err, IsNil)
+	rules = s.manager.GetRulesForApplyRegion(region)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, [2]string{"g1", "r1"})
+
+	// config is persisted.
+	m2 := NewRuleManager(s.store)
+	err = m2.Initialize(3, []string{}, "")
+	c.Assert(err, IsNil)
+	c.Assert(m2.GetRuleGroup("g1"), DeepEquals, &RuleGroup{ID: "g1", Index: 1, Override: true})
+	rules = m2.GetRulesForApplyRegion(region)
+	c.Assert(rules, HasLen, 1)
+	c.Assert(rules[0].Key(), Equals, [2]string{"g1", "r1"})
+
+	// delete the group config, all rules are applied again.
+	err = s.manager.DeleteRuleGroup("g1")
+	c.Assert(err, IsNil)
+	c.Assert(s.manager.GetRuleGroups(), HasLen, 0)
+	c.Assert(s.manager.GetRulesForApplyRegion(region), HasLen, 2)
+
+	c.Assert(s.manager.SetRuleGroup(&RuleGroup{}), NotNil)
+}
+
 func (s *testManagerSuite) dhex(hk string) []byte {
 	k, err := hex.DecodeString(hk)
 	if err != nil {
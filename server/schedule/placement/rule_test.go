@@ -59,3 +59,45 @@ func (s *testRuleSuite) TestPrepareRulesForApply(c *C) {
 		c.Assert(rules[i].Key(), Equals, expected[i])
 	}
 }
+
+func (s *testRuleSuite) TestGroupProperties(c *C) {
+	testCases := []struct {
+		rules  []*Rule
+		expect [][2]string
+	}{
+		{ // test group index
+			rules: []*Rule{
+				{GroupID: "g1", ID: "id1", group: &RuleGroup{ID: "g1", Index: 2}},
+				{GroupID: "g2", ID: "id2", group: &RuleGroup{ID: "g2", Index: 1}},
+				{GroupID: "g3", ID: "id3"},
+			},
+			expect: [][2]string{{"g3", "id3"}, {"g2", "id2"}, {"g1", "id1"}},
+		},
+		{ // test group override
+			rules: []*Rule{
+				{GroupID: "g1", ID: "id1", group: &RuleGroup{ID: "g1", Index: 1}},
+				{GroupID: "g2", ID: "id2", group: &RuleGroup{ID: "g2", Index: 2, Override: true}},
+				{GroupID: "g3", ID: "id3", group: &RuleGroup{ID: "g3", Index: 3}},
+			},
+			expect: [][2]string{{"g2", "id2"}, {"g3", "id3"}},
+		},
+		{ // test override in both group and rule
+			rules: []*Rule{
+				{GroupID: "g1", ID: "id1"},
+				{GroupID: "g2", ID: "id2", Index: 1, group: &RuleGroup{ID: "g2", Index: 2, Override: true}},
+				{GroupID: "g2", ID: "id3", Index: 2, Override: true, group: &RuleGroup{ID: "g2", Index: 2, Override: true}},
+			},
+			expect: [][2]string{{"g2", "id3"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		rand.Shuffle(len(tc.rules), func(i, j int) { tc.rules[i], tc.rules[j] = tc.rules[j], tc.rules[i] })
+		sortRules(tc.rules)
+		rules := prepareRulesForApply(tc.rules)
+		c.Assert(rules, HasLen, len(tc.expect))
+		for i := range rules {
+			c.Assert(rules[i].Key(), Equals, tc.expect[i])
+		}
+	}
+}
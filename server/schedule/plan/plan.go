@@ -0,0 +1,132 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"time"
+)
+
+// Roles of a store in a plan.
+const (
+	SourceRole = "source"
+	TargetRole = "target"
+)
+
+// ResultNoOperator is the result of a plan which creates no operator.
+const ResultNoOperator = "no-operator"
+
+// StoreScore is a candidate store with its score.
+type StoreScore struct {
+	StoreID uint64  `json:"store_id"`
+	Score   float64 `json:"score"`
+}
+
+// FilterRecord records a store rejected by a filter.
+type FilterRecord struct {
+	StoreID  uint64 `json:"store_id"`
+	RegionID uint64 `json:"region_id,omitempty"`
+	Role     string `json:"role"`
+	Filter   string `json:"filter"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// StepRecord records a step which is given up, such as no region can be
+// selected from a store.
+type StepRecord struct {
+	StoreID  uint64 `json:"store_id"`
+	RegionID uint64 `json:"region_id,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+// ScoreRecord records the scores compared to decide whether to balance a
+// region between two stores.
+type ScoreRecord struct {
+	RegionID         uint64  `json:"region_id"`
+	SourceID         uint64  `json:"source_id"`
+	TargetID         uint64  `json:"target_id"`
+	SourceScore      float64 `json:"source_score"`
+	TargetScore      float64 `json:"target_score"`
+	SourceInfluence  int64   `json:"source_influence"`
+	TargetInfluence  int64   `json:"target_influence"`
+	TolerantResource int64   `json:"tolerant_resource"`
+	ShouldBalance    bool    `json:"should_balance"`
+}
+
+// Plan is the trace of one scheduling round. All methods can be called on a
+// nil Plan, which means the trace is not recorded.
+type Plan struct {
+	Round    uint64         `json:"round"`
+	Time     time.Time      `json:"time"`
+	Sources  []StoreScore   `json:"sources"`
+	Targets  []StoreScore   `json:"targets,omitempty"`
+	Filtered []FilterRecord `json:"filtered"`
+	Steps    []StepRecord   `json:"steps"`
+	Scores   []ScoreRecord  `json:"scores"`
+	Result   string         `json:"result"`
+}
+
+// AddSource records a candidate source store.
+func (p *Plan) AddSource(storeID uint64, score float64) {
+	if p == nil {
+		return
+	}
+	p.Sources = append(p.Sources, StoreScore{StoreID: storeID, Score: score})
+}
+
+// AddTarget records a candidate target store.
+func (p *Plan) AddTarget(storeID uint64, score float64) {
+	if p == nil {
+		return
+	}
+	p.Targets = append(p.Targets, StoreScore{StoreID: storeID, Score: score})
+}
+
+// AddFiltered records a store rejected by a filter. The regionID is 0 if the
+// store is filtered without any region.
+func (p *Plan) AddFiltered(storeID, regionID uint64, role, filter, reason string) {
+	if p == nil {
+		return
+	}
+	p.Filtered = append(p.Filtered, FilterRecord{
+		StoreID:  storeID,
+		RegionID: regionID,
+		Role:     role,
+		Filter:   filter,
+		Reason:   reason,
+	})
+}
+
+// AddStep records a step which is given up with the reason.
+func (p *Plan) AddStep(storeID, regionID uint64, reason string) {
+	if p == nil {
+		return
+	}
+	p.Steps = append(p.Steps, StepRecord{StoreID: storeID, RegionID: regionID, Reason: reason})
+}
+
+// AddScore records the scores compared.
+func (p *Plan) AddScore(record ScoreRecord) {
+	if p == nil {
+		return
+	}
+	p.Scores = append(p.Scores, record)
+}
+
+// SetResult sets the final outcome of the plan.
+func (p *Plan) SetResult(result string) {
+	if p == nil {
+		return
+	}
+	p.Result = result
+}
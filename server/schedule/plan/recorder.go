@@ -0,0 +1,116 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRecordRounds is the default number of rounds to keep.
+	DefaultRecordRounds = 10
+	// MaxRecordRounds is the max number of rounds to keep.
+	MaxRecordRounds = 100
+)
+
+// Diagnostic is the plans recorded by a Recorder.
+type Diagnostic struct {
+	Enabled bool    `json:"enabled"`
+	Rounds  int     `json:"rounds"`
+	Plans   []*Plan `json:"plans"`
+}
+
+// Recorder keeps the plans of the last several scheduling rounds. It is
+// disabled by default. It is threadsafe.
+type Recorder struct {
+	sync.RWMutex
+	rounds int // 0 means disabled
+	round  uint64
+	plans  []*Plan
+}
+
+// NewRecorder creates a disabled Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Enable starts to record the plans of the last rounds.
+func (r *Recorder) Enable(rounds int) error {
+	if rounds <= 0 || rounds > MaxRecordRounds {
+		return errors.Errorf("rounds should be in [1, %d]", MaxRecordRounds)
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.rounds = rounds
+	if len(r.plans) > rounds {
+		r.plans = r.plans[len(r.plans)-rounds:]
+	}
+	return nil
+}
+
+// Disable stops recording and drops all recorded plans.
+func (r *Recorder) Disable() {
+	r.Lock()
+	defer r.Unlock()
+	r.rounds = 0
+	r.plans = nil
+}
+
+// NewPlan creates a Plan for a new round. It returns nil if the recorder is
+// disabled, so the caller can always record into the returned plan.
+func (r *Recorder) NewPlan() *Plan {
+	r.Lock()
+	defer r.Unlock()
+	if r.rounds == 0 {
+		return nil
+	}
+	r.round++
+	return &Plan{Round: r.round, Time: time.Now()}
+}
+
+// Record saves a finished plan, the oldest plans beyond the rounds are
+// dropped.
+func (r *Recorder) Record(p *Plan) {
+	if p == nil {
+		return
+	}
+	if p.Result == "" {
+		p.Result = ResultNoOperator
+	}
+	r.Lock()
+	defer r.Unlock()
+	if r.rounds == 0 {
+		return
+	}
+	r.plans = append(r.plans, p)
+	if len(r.plans) > r.rounds {
+		r.plans = r.plans[len(r.plans)-r.rounds:]
+	}
+}
+
+// GetDiagnostic returns the recorded plans, from the oldest to the newest.
+func (r *Recorder) GetDiagnostic() *Diagnostic {
+	r.RLock()
+	defer r.RUnlock()
+	plans := make([]*Plan, len(r.plans))
+	copy(plans, r.plans)
+	return &Diagnostic{
+		Enabled: r.rounds > 0,
+		Rounds:  r.rounds,
+		Plans:   plans,
+	}
+}
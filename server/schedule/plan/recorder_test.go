@@ -0,0 +1,71 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plan
+
+import (
+	"testing"
+
+	. "github.com/pingcap/check"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+var _ = Suite(&testRecorderSuite{})
+
+type testRecorderSuite struct{}
+
+func (s *testRecorderSuite) TestRecorder(c *C) {
+	r := NewRecorder()
+	// Disabled by default, records into the nil plan are ignored.
+	p := r.NewPlan()
+	c.Assert(p, IsNil)
+	p.AddSource(1, 10)
+	p.AddFiltered(2, 0, SourceRole, "store-state-filter", "busy")
+	p.SetResult("test")
+	r.Record(p)
+	c.Assert(r.GetDiagnostic().Enabled, IsFalse)
+	c.Assert(r.GetDiagnostic().Plans, HasLen, 0)
+
+	c.Assert(r.Enable(0), NotNil)
+	c.Assert(r.Enable(MaxRecordRounds+1), NotNil)
+	c.Assert(r.Enable(2), IsNil)
+	for i := 0; i < 3; i++ {
+		p = r.NewPlan()
+		c.Assert(p, NotNil)
+		p.AddSource(uint64(i), float64(i))
+		r.Record(p)
+	}
+	d := r.GetDiagnostic()
+	c.Assert(d.Enabled, IsTrue)
+	c.Assert(d.Rounds, Equals, 2)
+	c.Assert(d.Plans, HasLen, 2)
+	c.Assert(d.Plans[0].Round, Equals, uint64(2))
+	c.Assert(d.Plans[1].Round, Equals, uint64(3))
+	c.Assert(d.Plans[1].Sources, DeepEquals, []StoreScore{{StoreID: 2, Score: 2}})
+	c.Assert(d.Plans[1].Result, Equals, ResultNoOperator)
+
+	// Shrink the rounds.
+	c.Assert(r.Enable(1), IsNil)
+	d = r.GetDiagnostic()
+	c.Assert(d.Plans, HasLen, 1)
+	c.Assert(d.Plans[0].Round, Equals, uint64(3))
+
+	r.Disable()
+	d = r.GetDiagnostic()
+	c.Assert(d.Enabled, IsFalse)
+	c.Assert(d.Plans, HasLen, 0)
+	c.Assert(r.NewPlan(), IsNil)
+}
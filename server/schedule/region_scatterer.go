@@ -16,6 +16,7 @@ package schedule
 import (
 	"math/rand"
 	"sync"
+	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
 	"github.com/pingcap/log"
@@ -23,55 +24,50 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/filter"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
 const regionScatterName = "region-scatter"
 
-type selectedStores struct {
-	mu     sync.Mutex
-	stores map[uint64]struct{}
-}
+// scatterGroupTTL is the time to keep the counters of a group after it is
+// last used.
+var scatterGroupTTL = 10 * time.Minute
 
-func newSelectedStores() *selectedStores {
-	return &selectedStores{
-		stores: make(map[uint64]struct{}),
-	}
+// scatterGroup counts the peers and leaders placed on each store by the
+// regions scattered in the same group.
+type scatterGroup struct {
+	peers    map[uint64]uint64
+	leaders  map[uint64]uint64
+	lastUsed time.Time
 }
 
-func (s *selectedStores) put(id uint64) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if _, ok := s.stores[id]; ok {
-		return false
+func newScatterGroup() *scatterGroup {
+	return &scatterGroup{
+		peers:   make(map[uint64]uint64),
+		leaders: make(map[uint64]uint64),
 	}
-	s.stores[id] = struct{}{}
-	return true
 }
 
-func (s *selectedStores) reset() {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.stores = make(map[uint64]struct{})
-}
-
-func (s *selectedStores) newFilter(scope string) filter.Filter {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	cloned := make(map[uint64]struct{})
-	for id := range s.stores {
-		cloned[id] = struct{}{}
+// record counts the peers and the leader of a region placed on the stores.
+func (g *scatterGroup) record(peers map[uint64]*metapb.Peer, leader uint64) {
+	for storeID := range peers {
+		g.peers[storeID]++
+	}
+	if leader != 0 {
+		g.leaders[leader]++
 	}
-	return filter.NewExcludedFilter(scope, nil, cloned)
 }
 
 // RegionScatterer scatters regions.
 type RegionScatterer struct {
-	name     string
-	cluster  opt.Cluster
-	filters  []filter.Filter
-	selected *selectedStores
+	name    string
+	cluster opt.Cluster
+	filters []filter.Filter
+
+	mu     sync.Mutex
+	groups map[string]*scatterGroup
 }
 
 // NewRegionScatterer creates a region scatterer.
@@ -83,104 +79,203 @@ func NewRegionScatterer(cluster opt.Cluster) *RegionScatterer {
 		filters: []filter.Filter{
 			filter.StoreStateFilter{ActionScope: regionScatterName},
 		},
-		selected: newSelectedStores(),
+		groups: make(map[string]*scatterGroup),
 	}
 }
 
-// Scatter relocates the region.
-func (r *RegionScatterer) Scatter(region *core.RegionInfo) (*operator.Operator, error) {
-	if !opt.IsRegionReplicated(r.cluster, region) {
-		return nil, errors.Errorf("region %d is not fully replicated", region.GetID())
+// Scatter relocates the region, and adds the operator by addOperator. The
+// peers and leaders of the regions in the same group are spread evenly across
+// the stores. The region is counted in the group only if the operator is added.
+func (r *RegionScatterer) Scatter(region *core.RegionInfo, group string, addOperator func(*operator.Operator) bool) error {
+	failures := make(map[uint64]error, 1)
+	r.ScatterRegions([]*core.RegionInfo{region}, group, addOperator, failures)
+	return failures[region.GetID()]
+}
+
+// ScatterRegions scatters the regions in the same group, and adds the
+// operators by addOperator. Only the operators which are added are counted in
+// the group. The failures are recorded with the region IDs.
+func (r *RegionScatterer) ScatterRegions(regions []*core.RegionInfo, group string, addOperator func(*operator.Operator) bool, failures map[uint64]error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	g := r.getGroup(group)
+	for _, region := range regions {
+		if err := r.checkRegion(region); err != nil {
+			failures[region.GetID()] = err
+			continue
+		}
+		op, targetPeers, leader := r.scatterRegion(region, g)
+		if op != nil && !addOperator(op) {
+			failures[region.GetID()] = errors.Errorf("failed to add operator for region %d", region.GetID())
+			continue
+		}
+		g.record(targetPeers, leader)
 	}
+}
 
+func (r *RegionScatterer) checkRegion(region *core.RegionInfo) error {
+	if !opt.IsRegionReplicated(r.cluster, region) {
+		return errors.Errorf("region %d is not fully replicated", region.GetID())
+	}
 	if region.GetLeader() == nil {
-		return nil, errors.Errorf("region %d has no leader", region.GetID())
+		return errors.Errorf("region %d has no leader", region.GetID())
 	}
+	return nil
+}
 
-	return r.scatterRegion(region), nil
+// getGroup returns the counters of the group, and removes the groups which
+// are not used for a long time.
+func (r *RegionScatterer) getGroup(name string) *scatterGroup {
+	now := time.Now()
+	for n, g := range r.groups {
+		if n != name && now.Sub(g.lastUsed) > scatterGroupTTL {
+			delete(r.groups, n)
+		}
+	}
+	g, ok := r.groups[name]
+	if !ok {
+		g = newScatterGroup()
+		r.groups[name] = g
+	}
+	g.lastUsed = now
+	return g
 }
 
-func (r *RegionScatterer) scatterRegion(region *core.RegionInfo) *operator.Operator {
-	stores := r.collectAvailableStores(region)
+// scatterRegion returns the operator to scatter the region, and the target
+// peers and leader store of the region. The operator is nil if the region is
+// unchanged.
+func (r *RegionScatterer) scatterRegion(region *core.RegionInfo, group *scatterGroup) (*operator.Operator, map[uint64]*metapb.Peer, uint64) {
+	var fit *placement.RegionFit
+	if r.cluster.IsPlacementRulesEnabled() {
+		fit = r.cluster.FitRegion(region)
+	}
 	targetPeers := make(map[uint64]*metapb.Peer)
+	selected := make(map[uint64]struct{})
 	for _, peer := range region.GetPeers() {
-		if len(stores) == 0 {
-			// Reset selected stores if we have no available stores.
-			r.selected.reset()
-			stores = r.collectAvailableStores(region)
-		}
-
-		if r.selected.put(peer.GetStoreId()) {
-			delete(stores, peer.GetStoreId())
-			targetPeers[peer.GetStoreId()] = peer
-			continue
-		}
-		newPeer := r.selectPeerToReplace(stores, region, peer)
-		if newPeer == nil {
-			targetPeers[peer.GetStoreId()] = peer
-			continue
-		}
-		// Remove it from stores and mark it as selected.
-		delete(stores, newPeer.GetStoreId())
-		r.selected.put(newPeer.GetStoreId())
+		newPeer := r.selectPeer(region, fit, peer, selected, group)
+		selected[newPeer.GetStoreId()] = struct{}{}
 		targetPeers[newPeer.GetStoreId()] = newPeer
 	}
-	op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers)
+	leader := r.selectLeader(region, targetPeers, group)
+
+	if leader == region.GetLeader().GetStoreId() && r.isUnchanged(region, targetPeers) {
+		return nil, targetPeers, leader
+	}
+	op, err := operator.CreateScatterRegionOperator("scatter-region", r.cluster, region, targetPeers, leader)
 	if err != nil {
 		log.Debug("fail to create scatter region operator", zap.Error(err))
-		return nil
+		// The region stays where it is.
+		currentPeers := make(map[uint64]*metapb.Peer, len(region.GetPeers()))
+		for _, peer := range region.GetPeers() {
+			currentPeers[peer.GetStoreId()] = peer
+		}
+		return nil, currentPeers, region.GetLeader().GetStoreId()
 	}
 	op.SetPriorityLevel(core.HighPriority)
-	return op
+	return op, targetPeers, leader
 }
 
-func (r *RegionScatterer) selectPeerToReplace(stores map[uint64]*core.StoreInfo, region *core.RegionInfo, oldPeer *metapb.Peer) *metapb.Peer {
-	// scoreGuard guarantees that the distinct score will not decrease.
-	regionStores := r.cluster.GetRegionStores(region)
+func (r *RegionScatterer) isUnchanged(region *core.RegionInfo, targetPeers map[uint64]*metapb.Peer) bool {
+	for storeID := range targetPeers {
+		if region.GetStorePeer(storeID) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// selectPeer selects the store with the fewest peers of the group for the
+// peer. The peer is kept on its store if the store is one of the best.
+func (r *RegionScatterer) selectPeer(region *core.RegionInfo, fit *placement.RegionFit, oldPeer *metapb.Peer, selected map[uint64]struct{}, group *scatterGroup) *metapb.Peer {
 	storeID := oldPeer.GetStoreId()
-	sourceStore := r.cluster.GetStore(storeID)
-	if sourceStore == nil {
-		log.Error("failed to get the store", zap.Uint64("store-id", storeID))
+	// The witness is kept since it can not be moved by the scatter operator.
+	if region.IsWitness(oldPeer.GetId()) {
+		return oldPeer
 	}
-	var scoreGuard filter.Filter
-	if r.cluster.IsPlacementRulesEnabled() {
-		scoreGuard = filter.NewRuleFitFilter(r.name, r.cluster, region, oldPeer.GetStoreId())
+
+	excluded := make(map[uint64]struct{}, len(selected)+len(region.GetPeers()))
+	for id := range region.GetStoreIds() {
+		excluded[id] = struct{}{}
+	}
+	for id := range selected {
+		excluded[id] = struct{}{}
+	}
+	filters := []filter.Filter{filter.NewExcludedFilter(r.name, nil, excluded)}
+	filters = append(filters, r.filters...)
+	if fit != nil {
+		rf := fit.GetRuleFit(oldPeer.GetId())
+		if rf == nil {
+			// The orphan peer is left to the rule checker.
+			return oldPeer
+		}
+		filters = append(filters,
+			filter.NewLabelConstaintFilter(r.name, rf.Rule.LabelConstraints),
+			filter.NewRuleFitFilter(r.name, r.cluster, region, storeID),
+		)
 	} else {
-		scoreGuard = filter.NewDistinctScoreFilter(r.name, r.cluster.GetLocationLabels(), regionStores, sourceStore)
+		sourceStore := r.cluster.GetStore(storeID)
+		if sourceStore == nil {
+			log.Error("failed to get the store", zap.Uint64("store-id", storeID))
+			return oldPeer
+		}
+		filters = append(filters, filter.NewDistinctScoreFilter(r.name, r.cluster.GetLocationLabels(), r.cluster.GetRegionStores(region), sourceStore))
 	}
 
-	candidates := make([]*core.StoreInfo, 0, len(stores))
-	for _, store := range stores {
-		if !scoreGuard.Target(r.cluster, store) {
+	candidates := []uint64{storeID}
+	minCount := group.peers[storeID]
+	for _, store := range r.cluster.GetStores() {
+		if store.IsBusy() || !filter.Target(r.cluster, store, filters) {
 			continue
 		}
-		candidates = append(candidates, store)
+		switch count := group.peers[store.GetID()]; {
+		case count < minCount:
+			candidates, minCount = []uint64{store.GetID()}, count
+		case count == minCount:
+			candidates = append(candidates, store.GetID())
+		}
 	}
-
-	if len(candidates) == 0 {
-		return nil
+	if candidates[0] == storeID {
+		return oldPeer
 	}
-
-	target := candidates[rand.Intn(len(candidates))]
 	return &metapb.Peer{
-		StoreId:   target.GetID(),
+		StoreId:   candidates[rand.Intn(len(candidates))],
 		IsLearner: oldPeer.GetIsLearner(),
 	}
 }
 
-func (r *RegionScatterer) collectAvailableStores(region *core.RegionInfo) map[uint64]*core.StoreInfo {
-	filters := []filter.Filter{
-		r.selected.newFilter(r.name),
-		filter.NewExcludedFilter(r.name, nil, region.GetStoreIds()),
+// selectLeader selects the store with the fewest leaders of the group among
+// the voters. The leader is kept if its store is one of the best.
+func (r *RegionScatterer) selectLeader(region *core.RegionInfo, targetPeers map[uint64]*metapb.Peer, group *scatterGroup) uint64 {
+	leader := region.GetLeader().GetStoreId()
+	witnesses := region.GetWitnessStoreIds()
+	var (
+		candidates []uint64
+		minCount   uint64
+	)
+	for storeID, peer := range targetPeers {
+		if peer.GetIsLearner() {
+			continue
+		}
+		if _, ok := witnesses[storeID]; ok {
+			continue
+		}
+		switch count := group.leaders[storeID]; {
+		case len(candidates) == 0 || count < minCount:
+			candidates, minCount = []uint64{storeID}, count
+		case count == minCount:
+			candidates = append(candidates, storeID)
+		}
 	}
-	filters = append(filters, r.filters...)
-
-	stores := r.cluster.GetStores()
-	targets := make(map[uint64]*core.StoreInfo, len(stores))
-	for _, store := range stores {
-		if filter.Target(r.cluster, store, filters) && !store.IsBusy() {
-			targets[store.GetID()] = store
+	if len(candidates) == 0 {
+		if _, ok := targetPeers[leader]; ok {
+			return leader
+		}
+		return 0
+	}
+	for _, id := range candidates {
+		if id == leader {
+			return leader
 		}
 	}
-	return targets
+	return candidates[rand.Intn(len(candidates))]
 }
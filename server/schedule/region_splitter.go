@@ -0,0 +1,287 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSplitRegionsLimit is the default number of regions splitting at
+	// the same time.
+	DefaultSplitRegionsLimit = 16
+	// maxSplitRegionsRounds is the max rounds to split the regions by size,
+	// each round can double the regions at most.
+	maxSplitRegionsRounds = 32
+)
+
+// splitRegionWaitTimeout is the max time to wait for the split of a region
+// reported.
+var splitRegionWaitTimeout = time.Minute
+
+// SplitRegionsResult is the result of splitting regions.
+type SplitRegionsResult struct {
+	// NewRegionIDs are the IDs of the regions generated by the split.
+	NewRegionIDs []uint64 `json:"new_region_ids"`
+	// Failures are the reasons of the regions failed to split.
+	Failures map[uint64]string `json:"failures,omitempty"`
+}
+
+func newSplitRegionsResult() *SplitRegionsResult {
+	return &SplitRegionsResult{
+		NewRegionIDs: make([]uint64, 0),
+		Failures:     make(map[uint64]string),
+	}
+}
+
+// RegionSplitter splits the regions in a key range at the keys or into a
+// count, and waits for the new regions reported by TiKV.
+type RegionSplitter struct {
+	cluster      opt.Cluster
+	opController *OperatorController
+
+	mu sync.Mutex
+	// waiters are the channels to receive the new region IDs, by the
+	// ID of the region to split.
+	waiters map[uint64][]chan []uint64
+}
+
+// NewRegionSplitter creates a region splitter.
+func NewRegionSplitter(cluster opt.Cluster, opController *OperatorController) *RegionSplitter {
+	return &RegionSplitter{
+		cluster:      cluster,
+		opController: opController,
+		waiters:      make(map[uint64][]chan []uint64),
+	}
+}
+
+// OnSplitReported notifies the splitter the region is split into the new
+// regions.
+func (r *RegionSplitter) OnSplitReported(regionID uint64, newRegionIDs []uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, ch := range r.waiters[regionID] {
+		ch <- newRegionIDs
+	}
+	delete(r.waiters, regionID)
+}
+
+func (r *RegionSplitter) addWaiter(regionID uint64) chan []uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ch := make(chan []uint64, 1)
+	r.waiters[regionID] = append(r.waiters[regionID], ch)
+	return ch
+}
+
+func (r *RegionSplitter) removeWaiter(regionID uint64, ch chan []uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	waiters := r.waiters[regionID]
+	for i := range waiters {
+		if waiters[i] == ch {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(r.waiters, regionID)
+	} else {
+		r.waiters[regionID] = waiters
+	}
+}
+
+// SplitRegionsAtKeys splits the regions at the keys in the range [startKey,
+// endKey], an empty endKey means no upper bound. The keys are grouped by the
+// regions containing them, and at most limit regions are split at the same
+// time.
+func (r *RegionSplitter) SplitRegionsAtKeys(ctx context.Context, startKey, endKey []byte, keys [][]byte, limit int) *SplitRegionsResult {
+	var splitKeys [][]byte
+	for _, key := range keys {
+		if bytes.Compare(key, startKey) < 0 || (len(endKey) > 0 && bytes.Compare(key, endKey) > 0) {
+			continue
+		}
+		splitKeys = append(splitKeys, key)
+	}
+	result := newSplitRegionsResult()
+	r.splitRegions(ctx, r.groupKeysByRegion(splitKeys), limit, result)
+	return result
+}
+
+// SplitRegionsByCount splits the regions in the range [startKey, endKey) until
+// there are count regions in the range. The range is split at its boundaries
+// first, then the largest regions are split into halves round by round.
+func (r *RegionSplitter) SplitRegionsByCount(ctx context.Context, startKey, endKey []byte, count int, limit int) *SplitRegionsResult {
+	var boundaries [][]byte
+	for _, key := range [][]byte{startKey, endKey} {
+		if len(key) > 0 {
+			boundaries = append(boundaries, key)
+		}
+	}
+	result := newSplitRegionsResult()
+	r.splitRegions(ctx, r.groupKeysByRegion(boundaries), limit, result)
+
+	for round := 0; round < maxSplitRegionsRounds && ctx.Err() == nil; round++ {
+		regions := r.cluster.ScanRegions(startKey, endKey, 0)
+		if len(regions) == 0 || len(regions) >= count {
+			break
+		}
+		sort.Slice(regions, func(i, j int) bool {
+			return regions[i].GetApproximateSize() > regions[j].GetApproximateSize()
+		})
+		if n := count - len(regions); n < len(regions) {
+			regions = regions[:n]
+		}
+		groups := make([]*splitGroup, 0, len(regions))
+		for _, region := range regions {
+			groups = append(groups, &splitGroup{region: region})
+		}
+		newRegions := len(result.NewRegionIDs)
+		r.splitRegions(ctx, groups, limit, result)
+		if len(result.NewRegionIDs) == newRegions {
+			// No region is split in this round, give up.
+			break
+		}
+	}
+	return result
+}
+
+// splitGroup is a region and the keys to split it at. The region is split
+// into halves if there is no key.
+type splitGroup struct {
+	region *core.RegionInfo
+	keys   [][]byte
+}
+
+// groupKeysByRegion groups the keys by the regions containing them, the keys
+// which are already the start keys of regions are skipped.
+func (r *RegionSplitter) groupKeysByRegion(keys [][]byte) []*splitGroup {
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+	// The region containing the last key is the last one to scan.
+	scanEnd := append(append([]byte{}, keys[len(keys)-1]...), 0)
+	regions := r.cluster.ScanRegions(keys[0], scanEnd, 0)
+
+	var (
+		groups []*splitGroup
+		last   []byte
+	)
+	for i, key := range keys {
+		if i > 0 && bytes.Equal(key, last) {
+			continue
+		}
+		last = key
+		for len(regions) > 0 && len(regions[0].GetEndKey()) > 0 && bytes.Compare(regions[0].GetEndKey(), key) <= 0 {
+			regions = regions[1:]
+		}
+		if len(regions) == 0 {
+			break
+		}
+		region := regions[0]
+		if bytes.Compare(region.GetStartKey(), key) >= 0 {
+			// The key is the start key of the region or in a hole.
+			continue
+		}
+		if len(groups) == 0 || groups[len(groups)-1].region.GetID() != region.GetID() {
+			groups = append(groups, &splitGroup{region: region})
+		}
+		g := groups[len(groups)-1]
+		g.keys = append(g.keys, key)
+	}
+	return groups
+}
+
+// splitRegions splits the regions in parallel, at most limit regions are
+// split at the same time.
+func (r *RegionSplitter) splitRegions(ctx context.Context, groups []*splitGroup, limit int, result *SplitRegionsResult) {
+	if limit <= 0 {
+		limit = DefaultSplitRegionsLimit
+	}
+	var (
+		wg  sync.WaitGroup
+		mu  sync.Mutex
+		sem = make(chan struct{}, limit)
+	)
+	for _, g := range groups {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			mu.Lock()
+			result.Failures[g.region.GetID()] = ctx.Err().Error()
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func(g *splitGroup) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			newRegionIDs, err := r.splitRegion(ctx, g)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Failures[g.region.GetID()] = err.Error()
+				return
+			}
+			result.NewRegionIDs = append(result.NewRegionIDs, newRegionIDs...)
+		}(g)
+	}
+	wg.Wait()
+	sort.Slice(result.NewRegionIDs, func(i, j int) bool { return result.NewRegionIDs[i] < result.NewRegionIDs[j] })
+}
+
+// splitRegion adds the operator to split the region and waits for the split
+// reported.
+func (r *RegionSplitter) splitRegion(ctx context.Context, g *splitGroup) ([]uint64, error) {
+	policy := pdpb.CheckPolicy_USEKEY
+	if len(g.keys) == 0 {
+		policy = pdpb.CheckPolicy_APPROXIMATE
+	}
+	regionID := g.region.GetID()
+	ch := r.addWaiter(regionID)
+	op := operator.CreateSplitRegionOperator("admin-split-region", g.region, operator.OpAdmin, policy, g.keys)
+	if ok := r.opController.AddOperator(op); !ok {
+		r.removeWaiter(regionID, ch)
+		return nil, errors.Errorf("failed to add operator to split region %d", regionID)
+	}
+
+	timer := time.NewTimer(splitRegionWaitTimeout)
+	defer timer.Stop()
+	select {
+	case newRegionIDs := <-ch:
+		log.Info("region split for request", zap.Uint64("region-id", regionID), zap.Uint64s("new-region-ids", newRegionIDs))
+		return newRegionIDs, nil
+	case <-timer.C:
+		r.removeWaiter(regionID, ch)
+		return nil, errors.Errorf("timeout waiting for region %d to split", regionID)
+	case <-ctx.Done():
+		r.removeWaiter(regionID, ch)
+		return nil, errors.WithStack(ctx.Err())
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockhbstream"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+)
+
+var _ = Suite(&testRegionSplitterSuite{})
+
+type testRegionSplitterSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testRegionSplitterSuite) SetUpTest(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *testRegionSplitterSuite) TearDownTest(c *C) {
+	s.cancel()
+}
+
+func (s *testRegionSplitterSuite) newCluster() (*mockcluster.Cluster, *OperatorController, *RegionSplitter) {
+	tc := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	oc := NewOperatorController(s.ctx, tc, mockhbstream.NewHeartbeatStream())
+	tc.AddRegionStore(1, 3)
+	tc.AddLeaderRegionWithRange(1, "", "c", 1)
+	tc.AddLeaderRegionWithRange(2, "c", "e", 1)
+	tc.AddLeaderRegionWithRange(3, "e", "", 1)
+	return tc, oc, NewRegionSplitter(tc, oc)
+}
+
+// mockSplit acts as TiKV, it splits the regions as the split operators
+// require and reports the new regions until the context is done.
+func (s *testRegionSplitterSuite) mockSplit(tc *mockcluster.Cluster, oc *OperatorController, splitter *RegionSplitter) {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-s.ctx.Done():
+			return
+		}
+		for _, region := range tc.ScanRegions(nil, nil, 0) {
+			op := oc.GetOperator(region.GetID())
+			if op == nil || op.Desc() != "admin-split-region" {
+				continue
+			}
+			step, ok := op.Step(0).(operator.SplitRegion)
+			if !ok {
+				continue
+			}
+			keys := step.SplitKeys
+			if step.Policy == pdpb.CheckPolicy_APPROXIMATE {
+				keys = [][]byte{middleKey(region)}
+			}
+			var newRegionIDs []uint64
+			for _, key := range keys {
+				id, _ := tc.AllocID()
+				tc.PutRegion(region.Clone(core.WithNewRegionID(id), core.WithEndKey(key)))
+				region = region.Clone(core.WithStartKey(key))
+				newRegionIDs = append(newRegionIDs, id)
+			}
+			tc.PutRegion(region)
+			oc.RemoveOperator(op)
+			splitter.OnSplitReported(region.GetID(), newRegionIDs)
+		}
+	}
+}
+
+// middleKey returns a key inside the region for the approximate split. The
+// last byte is halved until the key is less than the end key, so that the
+// left halves of the split regions can be split again.
+func middleKey(region *core.RegionInfo) []byte {
+	key := append(append([]byte{}, region.GetStartKey()...), 'm')
+	endKey := region.GetEndKey()
+	for len(endKey) > 0 && bytes.Compare(key, endKey) >= 0 && key[len(key)-1] > 1 {
+		key[len(key)-1] /= 2
+	}
+	return key
+}
+
+func (s *testRegionSplitterSuite) TestSplitAtKeys(c *C) {
+	tc, oc, splitter := s.newCluster()
+	// Region 3 has a running operator.
+	c.Assert(oc.AddOperator(operator.CreateSplitRegionOperator("test", tc.GetRegion(3), operator.OpAdmin, pdpb.CheckPolicy_APPROXIMATE, nil)), IsTrue)
+	go s.mockSplit(tc, oc, splitter)
+
+	// "c" is already the start key of region 2, and "z" is out of the range.
+	keys := [][]byte{[]byte("bb"), []byte("b"), []byte("c"), []byte("d"), []byte("f"), []byte("b"), []byte("z")}
+	result := splitter.SplitRegionsAtKeys(s.ctx, []byte("a"), []byte("x"), keys, 1)
+	c.Assert(result.NewRegionIDs, HasLen, 3)
+	c.Assert(result.Failures, HasLen, 1)
+	c.Assert(result.Failures[3], Matches, ".*failed to add operator.*")
+
+	var startKeys []string
+	for _, region := range tc.ScanRegions(nil, []byte("e"), 0) {
+		startKeys = append(startKeys, string(region.GetStartKey()))
+	}
+	c.Assert(startKeys, DeepEquals, []string{"", "b", "bb", "c", "d"})
+	for _, id := range result.NewRegionIDs {
+		c.Assert(tc.GetRegion(id), NotNil)
+	}
+}
+
+func (s *testRegionSplitterSuite) TestSplitByCount(c *C) {
+	tc, oc, splitter := s.newCluster()
+	go s.mockSplit(tc, oc, splitter)
+
+	// The range is split at "b" and "e" first, then [b, e) is split into 6
+	// regions.
+	result := splitter.SplitRegionsByCount(s.ctx, []byte("b"), []byte("e"), 6, 2)
+	c.Assert(result.Failures, HasLen, 0)
+	c.Assert(tc.ScanRegions([]byte("b"), []byte("e"), 0), HasLen, 6)
+	c.Assert(result.NewRegionIDs, HasLen, 5)
+}
+
+func (s *testRegionSplitterSuite) TestSplitTimeout(c *C) {
+	defer func(timeout time.Duration) {
+		splitRegionWaitTimeout = timeout
+	}(splitRegionWaitTimeout)
+	splitRegionWaitTimeout = 50 * time.Millisecond
+
+	tc, _, splitter := s.newCluster()
+	result := splitter.SplitRegionsAtKeys(s.ctx, nil, nil, [][]byte{[]byte("a"), []byte("d")}, 0)
+	c.Assert(result.NewRegionIDs, HasLen, 0)
+	c.Assert(result.Failures, HasLen, 2)
+	c.Assert(result.Failures[1], Matches, ".*timeout.*")
+	c.Assert(result.Failures[2], Matches, ".*timeout.*")
+	c.Assert(splitter.waiters, HasLen, 0)
+	c.Assert(tc.GetRegionCount(), Equals, 3)
+}
@@ -0,0 +1,209 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"bytes"
+	"sync/atomic"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pingcap/pd/v4/server/schedule/storelimit"
+	"github.com/pingcap/pd/v4/server/statistics"
+)
+
+// SandboxCluster runs on a snapshot of a cluster. Operators can be applied to
+// it directly to predict the result of scheduling, the origin cluster is
+// never affected.
+type SandboxCluster struct {
+	*core.BasicCluster
+	opt.Config
+	statistics.RegionStatInformer
+	statistics.StoreStatInformer
+	cluster     opt.Cluster
+	ruleManager *placement.RuleManager
+	maxID       uint64
+}
+
+// NewSandboxCluster creates a SandboxCluster on the snapshot of the cluster.
+// The hot statistics and region labels are read from the origin cluster, and
+// the regions are fitted to the rules of ruleManager against the stores of the
+// snapshot. If opts is nil, the config of the origin cluster is used.
+//
+// The snapshot is usually taken by BasicCluster.Clone, which rebuilds the
+// region trees of the whole cluster, so the caller should not create
+// sandboxes concurrently.
+func NewSandboxCluster(cluster opt.Cluster, snapshot *core.BasicCluster, ruleManager *placement.RuleManager, opts opt.Config) *SandboxCluster {
+	if opts == nil {
+		opts = cluster
+	}
+	// IDs allocated by the sandbox should not conflict with existing ones.
+	var maxID uint64
+	for _, region := range snapshot.GetRegions() {
+		if region.GetID() > maxID {
+			maxID = region.GetID()
+		}
+		for _, peer := range region.GetPeers() {
+			if peer.GetId() > maxID {
+				maxID = peer.GetId()
+			}
+		}
+	}
+	return &SandboxCluster{
+		BasicCluster:       snapshot,
+		Config:             opts,
+		RegionStatInformer: cluster,
+		StoreStatInformer:  cluster,
+		cluster:            cluster,
+		ruleManager:        ruleManager,
+		maxID:              maxID,
+	}
+}
+
+// ScanRegions scans region with start key, until number greater than limit.
+func (c *SandboxCluster) ScanRegions(startKey, endKey []byte, limit int) []*core.RegionInfo {
+	return c.ScanRange(startKey, endKey, limit)
+}
+
+// AttachAvailableFunc does nothing since operators are never dispatched in
+// the sandbox.
+func (c *SandboxCluster) AttachAvailableFunc(storeID uint64, limitType storelimit.Type, f func() bool) {
+}
+
+// AllocID allocates a new ID which is only valid in the sandbox.
+func (c *SandboxCluster) AllocID() (uint64, error) {
+	return atomic.AddUint64(&c.maxID, 1), nil
+}
+
+// FitRegion tries to fit the region with placement rules against the stores
+// in the sandbox.
+func (c *SandboxCluster) FitRegion(region *core.RegionInfo) *placement.RegionFit {
+	return c.ruleManager.FitRegion(c, region)
+}
+
+// GetRegionLabeler returns the region labeler.
+func (c *SandboxCluster) GetRegionLabeler() *labeler.RegionLabeler {
+	return c.cluster.GetRegionLabeler()
+}
+
+// RemoveScheduler does nothing, schedulers running in the sandbox should
+// never remove the schedulers of the origin cluster.
+func (c *SandboxCluster) RemoveScheduler(name string) error {
+	return nil
+}
+
+// ApplyOperator applies all steps of the operator to the region in the
+// sandbox, and updates the status of the related stores.
+func (c *SandboxCluster) ApplyOperator(op *operator.Operator) {
+	origin := c.GetRegion(op.RegionID())
+	if origin == nil {
+		return
+	}
+	region := origin
+	for i := 0; i < op.Len(); i++ {
+		switch s := op.Step(i).(type) {
+		case operator.TransferLeader:
+			if p := region.GetStoreVoter(s.ToStore); p != nil {
+				region = region.Clone(core.WithLeader(p))
+			}
+		case operator.AddPeer:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: s.PeerID, StoreId: s.ToStore}))
+		case operator.AddLightPeer:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: s.PeerID, StoreId: s.ToStore}))
+		case operator.AddLearner:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: s.PeerID, StoreId: s.ToStore, IsLearner: true}))
+		case operator.AddLightLearner:
+			region = region.Clone(core.WithAddPeer(&metapb.Peer{Id: s.PeerID, StoreId: s.ToStore, IsLearner: true}))
+		case operator.PromoteLearner:
+			region = region.Clone(core.WithPromoteLearner(s.PeerID))
+		case operator.RemovePeer:
+			region = region.Clone(core.WithRemoveStorePeer(s.FromStore))
+		case operator.MergeRegion:
+			// The passive one is applied along with the active one.
+			if !s.IsPassive {
+				c.mergeRegion(origin, region, s.ToRegion.GetId())
+			}
+			return
+		}
+	}
+	c.PutRegion(region)
+	c.updateStoresStatus(origin, region)
+}
+
+func (c *SandboxCluster) mergeRegion(origin, source *core.RegionInfo, targetID uint64) {
+	target := c.GetRegion(targetID)
+	if target == nil {
+		return
+	}
+	startKey, endKey := target.GetStartKey(), target.GetEndKey()
+	if bytes.Equal(source.GetEndKey(), startKey) {
+		startKey = source.GetStartKey()
+	} else {
+		endKey = source.GetEndKey()
+	}
+	merged := target.Clone(
+		core.WithStartKey(startKey),
+		core.WithEndKey(endKey),
+		core.SetApproximateSize(target.GetApproximateSize()+source.GetApproximateSize()),
+		core.SetApproximateKeys(target.GetApproximateKeys()+source.GetApproximateKeys()),
+	)
+	c.RemoveRegion(origin)
+	c.PutRegion(merged)
+	c.updateStoresStatus(origin, merged)
+}
+
+func (c *SandboxCluster) updateStoresStatus(regions ...*core.RegionInfo) {
+	for _, region := range regions {
+		for id := range region.GetStoreIds() {
+			c.updateStoreStatus(id)
+		}
+	}
+}
+
+func (c *SandboxCluster) updateStoreStatus(id uint64) {
+	store := c.GetStore(id)
+	if store == nil {
+		return
+	}
+	regionSize := c.GetStoreRegionSize(id)
+	stats := &pdpb.StoreStats{}
+	if store.GetStoreStats() != nil {
+		stats = proto.Clone(store.GetStoreStats()).(*pdpb.StoreStats)
+	}
+	// Adjust the used and available space by the size change of regions.
+	delta := (regionSize - store.GetRegionSize()) * (1 << 20)
+	stats.UsedSize = addSize(stats.UsedSize, delta)
+	stats.Available = addSize(stats.Available, -delta)
+	c.PutStore(store.Clone(
+		core.SetStoreStats(stats),
+		core.SetLeaderCount(c.GetStoreLeaderCount(id)),
+		core.SetRegionCount(c.GetStoreRegionCount(id)),
+		core.SetPendingPeerCount(c.GetStorePendingPeerCount(id)),
+		core.SetLeaderSize(c.GetStoreLeaderRegionSize(id)),
+		core.SetRegionSize(regionSize),
+	))
+}
+
+func addSize(size uint64, delta int64) uint64 {
+	if delta < 0 && uint64(-delta) > size {
+		return 0
+	}
+	return uint64(int64(size) + delta)
+}
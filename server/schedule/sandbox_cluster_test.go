@@ -0,0 +1,125 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedule
+
+import (
+	"context"
+
+	. "github.com/pingcap/check"
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/pd/v4/pkg/mock/mockcluster"
+	"github.com/pingcap/pd/v4/pkg/mock/mockoption"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+)
+
+var _ = Suite(&testSandboxClusterSuite{})
+
+type testSandboxClusterSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testSandboxClusterSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *testSandboxClusterSuite) TearDownSuite(c *C) {
+	s.cancel()
+}
+
+func (s *testSandboxClusterSuite) TestApplyOperator(c *C) {
+	tc := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	tc.AddRegionStore(1, 1)
+	tc.AddRegionStore(2, 1)
+	tc.AddRegionStore(3, 0)
+	tc.AddLeaderRegion(1, 1, 2)
+	sandbox := NewSandboxCluster(tc, tc.BasicCluster.Clone(), tc.RuleManager, nil)
+
+	id, err := sandbox.AllocID()
+	c.Assert(err, IsNil)
+	c.Assert(tc.GetRegion(1).GetPeer(id), IsNil)
+
+	steps := []operator.OpStep{
+		operator.AddLearner{ToStore: 3, PeerID: id},
+		operator.PromoteLearner{ToStore: 3, PeerID: id},
+		operator.TransferLeader{FromStore: 1, ToStore: 3},
+		operator.RemovePeer{FromStore: 1},
+	}
+	op := operator.NewOperator("test", "test", 1, &metapb.RegionEpoch{}, operator.OpRegion|operator.OpLeader, steps...)
+	sandbox.ApplyOperator(op)
+
+	region := sandbox.GetRegion(1)
+	c.Assert(region.GetLeader().GetStoreId(), Equals, uint64(3))
+	c.Assert(region.GetStoreVoter(3).GetId(), Equals, id)
+	c.Assert(region.GetStorePeer(1), IsNil)
+	c.Assert(sandbox.GetStore(1).GetRegionCount(), Equals, 0)
+	c.Assert(sandbox.GetStore(3).GetLeaderCount(), Equals, 1)
+	c.Assert(sandbox.GetStore(3).GetRegionSize(), Equals, int64(10))
+
+	// The origin cluster is not affected.
+	c.Assert(tc.GetRegion(1).GetLeader().GetStoreId(), Equals, uint64(1))
+	c.Assert(tc.GetRegion(1).GetStorePeer(3), IsNil)
+	c.Assert(tc.GetStore(3).GetRegionCount(), Equals, 0)
+}
+
+func (s *testSandboxClusterSuite) TestFitRegion(c *C) {
+	tc := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	tc.AddLabelsStore(1, 1, map[string]string{"zone": "z1"})
+	tc.AddLeaderRegion(1, 1)
+	rule := &placement.Rule{
+		GroupID:          "pd",
+		ID:               "default",
+		Role:             placement.Voter,
+		Count:            1,
+		LabelConstraints: []placement.LabelConstraint{{Key: "zone", Op: placement.In, Values: []string{"z2"}}},
+	}
+	c.Assert(tc.SetRule(rule), IsNil)
+	sandbox := NewSandboxCluster(tc, tc.BasicCluster.Clone(), tc.RuleManager, nil)
+	c.Assert(sandbox.FitRegion(sandbox.GetRegion(1)).IsSatisfied(), IsFalse)
+
+	// The stores in the sandbox are used to fit the region.
+	sandbox.PutStore(sandbox.GetStore(1).Clone(core.SetStoreLabels([]*metapb.StoreLabel{{Key: "zone", Value: "z2"}})))
+	c.Assert(sandbox.FitRegion(sandbox.GetRegion(1)).IsSatisfied(), IsTrue)
+	c.Assert(tc.FitRegion(tc.GetRegion(1)).IsSatisfied(), IsFalse)
+}
+
+func (s *testSandboxClusterSuite) TestDryRunCheckers(c *C) {
+	tc := mockcluster.NewCluster(mockoption.NewScheduleOptions())
+	tc.AddRegionStore(1, 1)
+	tc.AddRegionStore(2, 1)
+	tc.AddRegionStore(3, 0)
+	tc.AddLeaderRegion(1, 1, 2)
+
+	_, err := DryRunCheckers(s.ctx, NewSandboxCluster(tc, tc.BasicCluster.Clone(), tc.RuleManager, nil), tc.RuleManager, 0)
+	c.Assert(err, NotNil)
+
+	sandbox := NewSandboxCluster(tc, tc.BasicCluster.Clone(), tc.RuleManager, nil)
+	result, err := DryRunCheckers(s.ctx, sandbox, tc.RuleManager, 2)
+	c.Assert(err, IsNil)
+	// The missing replica is added in the first round.
+	c.Assert(result.Operators, HasLen, 1)
+	c.Assert(result.Operators[0].Round, Equals, 1)
+	c.Assert(result.Operators[0].RegionID, Equals, uint64(1))
+	c.Assert(result.Operators[0].Desc, Equals, "make-up-replica")
+	c.Assert(result.Stores, HasLen, 3)
+	c.Assert(result.Stores[2].StoreID, Equals, uint64(3))
+	c.Assert(result.Stores[2].RegionCountBefore, Equals, 0)
+	c.Assert(result.Stores[2].RegionCountAfter, Equals, 1)
+	c.Assert(result.Stores[2].RegionScoreAfter, Greater, result.Stores[2].RegionScoreBefore)
+
+	c.Assert(sandbox.GetRegion(1).GetPeers(), HasLen, 3)
+	c.Assert(tc.GetRegion(1).GetPeers(), HasLen, 2)
+}
@@ -23,6 +23,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
@@ -42,6 +43,21 @@ type Scheduler interface {
 	IsScheduleAllowed(cluster opt.Cluster) bool
 }
 
+// DiagnosableScheduler is a scheduler which can record the plans of the last
+// rounds to explain why it creates or does not create operators.
+type DiagnosableScheduler interface {
+	Scheduler
+	GetPlanRecorder() *plan.Recorder
+}
+
+// BatchScheduler is a scheduler which can schedule for multiple times in a
+// round of scheduling, the operators created by the previous times are added
+// before the next time.
+type BatchScheduler interface {
+	Scheduler
+	GetBatch() int
+}
+
 // EncodeConfig encode the custom config for each scheduler.
 func EncodeConfig(v interface{}) ([]byte, error) {
 	return json.Marshal(v)
@@ -14,6 +14,7 @@
 package storelimit
 
 import (
+	"sync"
 	"time"
 
 	"github.com/juju/ratelimit"
@@ -27,9 +28,13 @@ const (
 )
 
 // RegionInfluence represents the influence of a operator step, which is used by store limit.
+// The tokens of the byte types are measured in MB, so a token is the
+// influence of 1MB snapshot.
 var RegionInfluence = map[Type]int64{
-	RegionAdd:    1000,
-	RegionRemove: 1000,
+	RegionAdd:         1000,
+	RegionRemove:      1000,
+	RegionAddBytes:    1,
+	RegionRemoveBytes: 1,
 }
 
 // SmallRegionInfluence represents the influence of a operator step
@@ -58,12 +63,18 @@ const (
 	RegionAdd Type = iota
 	// RegionRemove indicates the type of store limit that limits the removing region rate
 	RegionRemove
+	// RegionAddBytes indicates the type of store limit that limits the snapshot bytes of adding regions, in MB/s
+	RegionAddBytes
+	// RegionRemoveBytes indicates the type of store limit that limits the snapshot bytes of removing regions, in MB/s
+	RegionRemoveBytes
 )
 
 // TypeNameValue indicates the name of store limit type and the enum value
 var TypeNameValue = map[string]Type{
-	"region-add":    RegionAdd,
-	"region-remove": RegionRemove,
+	"region-add":          RegionAdd,
+	"region-remove":       RegionRemove,
+	"region-add-bytes":    RegionAddBytes,
+	"region-remove-bytes": RegionRemoveBytes,
 }
 
 // String returns the representation of the store limit mode
@@ -88,11 +99,34 @@ func (t Type) String() string {
 	return ""
 }
 
+// IsByteType returns true if the tokens of the type are measured in MB of
+// snapshots rather than the count of operators. The rate of a byte type is in
+// MB/s, and the limit only takes effect after it is set explicitly.
+func (t Type) IsByteType() bool {
+	return t == RegionAddBytes || t == RegionRemoveBytes
+}
+
+// RequiredTokens returns the number of tokens which should be available
+// before taking the cost. A byte limit can be overdrawn, otherwise a region
+// larger than the capacity of the bucket could never be scheduled, and the
+// following operators have to wait until the debt is paid off.
+func (t Type) RequiredTokens(cost int64) int64 {
+	if t.IsByteType() && cost > RegionInfluence[t] {
+		return RegionInfluence[t]
+	}
+	return cost
+}
+
 // StoreLimit limits the operators of a store
 type StoreLimit struct {
 	bucket          *ratelimit.Bucket
 	mode            Mode
 	regionInfluence int64
+
+	mu sync.Mutex
+	// refunded is the tokens given back by the canceled operators. The bucket
+	// can not be refilled by hand, so they are taken before the bucket.
+	refunded int64
 }
 
 // NewStoreLimit returns a StoreLimit object
@@ -116,7 +150,9 @@ func NewStoreLimit(rate float64, mode Mode, regionInfluence int64) *StoreLimit {
 
 // Available returns the number of available tokens
 func (l *StoreLimit) Available() int64 {
-	return l.bucket.Available()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.bucket.Available() + l.refunded
 }
 
 // Rate returns the fill rate of the bucket, in tokens per second.
@@ -126,9 +162,30 @@ func (l *StoreLimit) Rate() float64 {
 
 // Take takes count tokens from the bucket without blocking.
 func (l *StoreLimit) Take(count int64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.refunded >= count {
+		l.refunded -= count
+		return 0
+	}
+	count -= l.refunded
+	l.refunded = 0
 	return l.bucket.Take(count)
 }
 
+// Refund gives back count tokens taken by an operator which is canceled
+// before finishing. The available tokens never exceed the capacity.
+func (l *StoreLimit) Refund(count int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if room := l.bucket.Capacity() - l.bucket.Available() - l.refunded; count > room {
+		count = room
+	}
+	if count > 0 {
+		l.refunded += count
+	}
+}
+
 // Mode returns the store limit mode
 func (l *StoreLimit) Mode() Mode {
 	return l.mode
@@ -17,11 +17,12 @@ import (
 	"math/rand"
 	"time"
 
+	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 )
 
 // PriorityWeight is used to represent the weight of different priorities of operators.
-var PriorityWeight = []float64{1.0, 4.0, 9.0}
+var PriorityWeight = []float64{1.0, 4.0, 9.0, 16.0}
 
 // WaitingOperator is an interface of waiting operators.
 type WaitingOperator interface {
@@ -108,6 +109,152 @@ func (b *RandBuckets) GetOperator() []*operator.Operator {
 	return nil
 }
 
+// PriorityQueues is an implementation of waiting operators, which keeps a
+// queue for each priority. The queues are promoted by smooth weighted round
+// robin with PriorityWeight, so the lower priorities are never starved. In a
+// queue, each scheduler or checker has its own share, they are served by
+// smooth weighted round robin too, with the weights of the scheduler quotas,
+// to avoid one of them flooding the queue.
+type PriorityQueues struct {
+	queues []*priorityQueue
+	// weight returns the weight of the scheduler creating the operators
+	// with the description.
+	weight func(desc string) float64
+}
+
+type priorityQueue struct {
+	level   core.PriorityLevel
+	weight  float64
+	current float64
+	// descs is the order to serve the schedulers, currents is the current
+	// weights of them in the round robin.
+	descs    []string
+	currents map[string]float64
+	ops      map[string][]*operator.Operator
+	count    int
+}
+
+// NewPriorityQueues creates the priority queues. The schedulers have the same
+// weight if weight is nil.
+func NewPriorityQueues(weight func(desc string) float64) *PriorityQueues {
+	if weight == nil {
+		weight = func(string) float64 { return 1 }
+	}
+	queues := make([]*priorityQueue, 0, len(PriorityWeight))
+	for i, w := range PriorityWeight {
+		queues = append(queues, &priorityQueue{
+			level:    core.PriorityLevel(i),
+			weight:   w,
+			currents: make(map[string]float64),
+			ops:      make(map[string][]*operator.Operator),
+		})
+	}
+	return &PriorityQueues{queues: queues, weight: weight}
+}
+
+func (q *PriorityQueues) getQueue(op *operator.Operator) *priorityQueue {
+	level := int(op.GetPriorityLevel())
+	if level < 0 {
+		level = 0
+	}
+	if level >= len(q.queues) {
+		level = len(q.queues) - 1
+	}
+	return q.queues[level]
+}
+
+// PutOperator puts an operator into the queue of its priority.
+func (q *PriorityQueues) PutOperator(op *operator.Operator) {
+	queue := q.getQueue(op)
+	desc := op.Desc()
+	if _, ok := queue.ops[desc]; !ok {
+		queue.descs = append(queue.descs, desc)
+	}
+	queue.ops[desc] = append(queue.ops[desc], op)
+	queue.count++
+	waitingOperatorGauge.WithLabelValues(queue.level.String()).Set(float64(queue.count))
+}
+
+// ListOperator lists all operators from the highest priority to the lowest.
+func (q *PriorityQueues) ListOperator() []*operator.Operator {
+	var ops []*operator.Operator
+	for i := len(q.queues) - 1; i >= 0; i-- {
+		queue := q.queues[i]
+		for _, desc := range queue.descs {
+			ops = append(ops, queue.ops[desc]...)
+		}
+	}
+	return ops
+}
+
+// GetOperator gets an operator, or two merge operators, from the queues.
+func (q *PriorityQueues) GetOperator() []*operator.Operator {
+	var (
+		selected    *priorityQueue
+		totalWeight float64
+	)
+	for _, queue := range q.queues {
+		if queue.count == 0 {
+			continue
+		}
+		queue.current += queue.weight
+		totalWeight += queue.weight
+		if selected == nil || queue.current > selected.current {
+			selected = queue
+		}
+	}
+	if selected == nil {
+		return nil
+	}
+	selected.current -= totalWeight
+	ops := selected.pop(q.weight)
+	waitingOperatorGauge.WithLabelValues(selected.level.String()).Set(float64(selected.count))
+	return ops
+}
+
+// pop takes the operators from the scheduler selected by the weights.
+func (q *priorityQueue) pop(weight func(desc string) float64) []*operator.Operator {
+	var (
+		selected    string
+		totalWeight float64
+	)
+	for _, desc := range q.descs {
+		w := weight(desc)
+		q.currents[desc] += w
+		totalWeight += w
+		if selected == "" || q.currents[desc] > q.currents[selected] {
+			selected = desc
+		}
+	}
+	q.currents[selected] -= totalWeight
+	ops := q.ops[selected]
+	n := 1
+	// Merge operation has two operators, and thus it should be handled specifically.
+	if ops[0].Kind()&operator.OpMerge != 0 && len(ops) > 1 {
+		n = 2
+	}
+	res := ops[:n:n]
+	if len(ops) == n {
+		// Remove the scheduler without waiting operators.
+		delete(q.ops, selected)
+		delete(q.currents, selected)
+		for i, desc := range q.descs {
+			if desc == selected {
+				q.descs = append(q.descs[:i], q.descs[i+1:]...)
+				break
+			}
+		}
+	} else {
+		q.ops[selected] = ops[n:]
+	}
+	q.count -= n
+	if q.count == 0 {
+		// Reset the state of round robin for an empty queue.
+		q.current = 0
+	}
+	return res
+}
+
 // WaitingOperatorStatus is used to limit the count of each kind of operators.
 type WaitingOperatorStatus struct {
 	ops map[string]uint64
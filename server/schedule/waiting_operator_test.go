@@ -106,3 +106,74 @@ func (s *testWaitingOperatorSuite) TestRandomBucketsWithMergeRegion(c *C) {
 		c.Assert(rb.GetOperator(), IsNil)
 	}
 }
+
+func (s *testWaitingOperatorSuite) TestPriorityQueues(c *C) {
+	q := NewPriorityQueues(nil)
+	addOperators(q)
+	// The highest priority is listed first.
+	ops := q.ListOperator()
+	c.Assert(ops, HasLen, 3)
+	c.Assert(ops[0].Desc(), Equals, "testOperatorHigh")
+	c.Assert(ops[2].Desc(), Equals, "testOperatorLow")
+	for i := 0; i < 3; i++ {
+		c.Assert(q.GetOperator(), HasLen, 1)
+	}
+	c.Assert(q.GetOperator(), IsNil)
+}
+
+func (s *testWaitingOperatorSuite) TestPriorityQueuesFairness(c *C) {
+	q := NewPriorityQueues(nil)
+	newOp := func(desc string, id uint64, level core.PriorityLevel) *operator.Operator {
+		op := operator.NewOperator(desc, "test", id, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 1})
+		op.SetPriorityLevel(level)
+		return op
+	}
+	// The hot region scheduler floods the queue before the balance scheduler.
+	for i := uint64(1); i <= 10; i++ {
+		q.PutOperator(newOp("hot-region", i, core.NormalPriority))
+	}
+	for i := uint64(11); i <= 12; i++ {
+		q.PutOperator(newOp("balance-region", i, core.NormalPriority))
+	}
+	// The schedulers with the same priority are served in turn.
+	var descs []string
+	for i := 0; i < 5; i++ {
+		descs = append(descs, q.GetOperator()[0].Desc())
+	}
+	c.Assert(descs, DeepEquals, []string{"hot-region", "balance-region", "hot-region", "balance-region", "hot-region"})
+
+	// The urgent operators are promoted first, but the lower priorities are
+	// not starved.
+	for i := uint64(21); i <= 40; i++ {
+		q.PutOperator(newOp("replica-checker", i, core.UrgentPriority))
+	}
+	counts := make(map[string]int)
+	for i := 0; i < 20; i++ {
+		counts[q.GetOperator()[0].Desc()]++
+	}
+	c.Assert(counts["replica-checker"], Equals, 16)
+	c.Assert(counts["hot-region"], Equals, 4)
+}
+
+func (s *testWaitingOperatorSuite) TestPriorityQueuesWeight(c *C) {
+	weights := map[string]float64{"replace-offline-replica": 3}
+	q := NewPriorityQueues(func(desc string) float64 {
+		if weight, ok := weights[desc]; ok {
+			return weight
+		}
+		return 1
+	})
+	for i := uint64(1); i <= 10; i++ {
+		q.PutOperator(operator.NewOperator("balance-region", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 1}))
+	}
+	for i := uint64(11); i <= 20; i++ {
+		q.PutOperator(operator.NewOperator("replace-offline-replica", "test", i, &metapb.RegionEpoch{}, operator.OpRegion, operator.RemovePeer{FromStore: 1}))
+	}
+	// The schedulers are served in proportion to their weights.
+	counts := make(map[string]int)
+	for i := 0; i < 8; i++ {
+		counts[q.GetOperator()[0].Desc()]++
+	}
+	c.Assert(counts["replace-offline-replica"], Equals, 6)
+	c.Assert(counts["balance-region"], Equals, 2)
+}
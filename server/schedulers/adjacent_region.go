@@ -15,7 +15,9 @@ package schedulers
 
 import (
 	"bytes"
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -77,16 +79,39 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.storage = storage
 		return newBalanceAdjacentRegionScheduler(opController, conf), nil
 	})
 }
 
 type balanceAdjacentRegionConfig struct {
+	sync.RWMutex
+	storage *core.Storage
+
 	Name        string `json:"name"`
 	LeaderLimit uint64 `json:"leader-limit"`
 	PeerLimit   uint64 `json:"peer-limit"`
 }
 
+func (conf *balanceAdjacentRegionConfig) getLeaderLimit() uint64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.LeaderLimit
+}
+
+func (conf *balanceAdjacentRegionConfig) getPeerLimit() uint64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.PeerLimit
+}
+
+func (conf *balanceAdjacentRegionConfig) validate() error {
+	if conf.LeaderLimit == 0 && conf.PeerLimit == 0 {
+		return errors.New("leader-limit and peer-limit should not both be 0")
+	}
+	return nil
+}
+
 // balanceAdjacentRegionScheduler will disperse adjacent regions.
 // we will scan a part regions order by key, then select the longest
 // adjacent regions and disperse them. finally, we will guarantee
@@ -99,6 +124,7 @@ type balanceAdjacentRegionScheduler struct {
 	cacheRegions         *adjacentState
 	conf                 *balanceAdjacentRegionConfig
 	adjacentRegionsCount int
+	handler              http.Handler
 }
 
 type adjacentState struct {
@@ -130,6 +156,7 @@ func newBalanceAdjacentRegionScheduler(opController *schedule.OperatorController
 		selector:      selector.NewRandomSelector(filters),
 		conf:          conf,
 		lastKey:       []byte(""),
+		handler:       newOnlineConfigHandler(conf.Name, conf.storage, conf),
 	}
 	return s
 }
@@ -143,7 +170,11 @@ func (l *balanceAdjacentRegionScheduler) GetType() string {
 }
 
 func (l *balanceAdjacentRegionScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(l.conf)
+	return encodeOnlineConfig(l.conf)
+}
+
+func (l *balanceAdjacentRegionScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.handler.ServeHTTP(w, r)
 }
 
 func (l *balanceAdjacentRegionScheduler) GetMinInterval() time.Duration {
@@ -159,11 +190,11 @@ func (l *balanceAdjacentRegionScheduler) IsScheduleAllowed(cluster opt.Cluster)
 }
 
 func (l *balanceAdjacentRegionScheduler) allowBalanceLeader() bool {
-	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpLeader) < l.conf.LeaderLimit
+	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpLeader) < l.conf.getLeaderLimit()
 }
 
 func (l *balanceAdjacentRegionScheduler) allowBalancePeer() bool {
-	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpRegion) < l.conf.PeerLimit
+	return l.OpController.OperatorCount(operator.OpAdjacent|operator.OpRegion) < l.conf.getPeerLimit()
 }
 
 func (l *balanceAdjacentRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
@@ -14,6 +14,7 @@
 package schedulers
 
 import (
+	"net/http"
 	"sort"
 	"strconv"
 
@@ -23,6 +24,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/filter"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -59,13 +61,30 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.init(storage)
 		return newBalanceLeaderScheduler(opController, conf), nil
 	})
 }
 
 type balanceLeaderSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	onlineConfig
+	Name string `json:"name"`
+	// TolerantSizeRatio replaces the tolerant size ratio of the cluster for
+	// the scheduler if it is not 0.
+	TolerantSizeRatio float64 `json:"tolerant-size-ratio"`
+}
+
+func (conf *balanceLeaderSchedulerConfig) getTolerantSizeRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.TolerantSizeRatio
+}
+
+func (conf *balanceLeaderSchedulerConfig) validate() error {
+	if conf.TolerantSizeRatio < 0 {
+		return errors.New("tolerant-size-ratio should not be negative")
+	}
+	return conf.onlineConfig.validate()
 }
 
 type balanceLeaderScheduler struct {
@@ -74,6 +93,8 @@ type balanceLeaderScheduler struct {
 	opController *schedule.OperatorController
 	filters      []filter.Filter
 	counter      *prometheus.CounterVec
+	recorder     *plan.Recorder
+	handler      http.Handler
 }
 
 // newBalanceLeaderScheduler creates a scheduler that tends to keep leaders on
@@ -86,6 +107,7 @@ func newBalanceLeaderScheduler(opController *schedule.OperatorController, conf *
 		conf:          conf,
 		opController:  opController,
 		counter:       balanceLeaderCounter,
+		recorder:      plan.NewRecorder(),
 	}
 	for _, opt := range opts {
 		opt(s)
@@ -94,6 +116,7 @@ func newBalanceLeaderScheduler(opController *schedule.OperatorController, conf *
 		filter.StoreStateFilter{ActionScope: s.GetName(), TransferLeader: true},
 		filter.NewSpecialUseFilter(s.GetName()),
 	}
+	s.handler = newOnlineConfigHandler(s.GetName(), conf.storage, conf)
 	return s
 }
 
@@ -123,20 +146,35 @@ func (l *balanceLeaderScheduler) GetType() string {
 }
 
 func (l *balanceLeaderScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(l.conf)
+	return encodeOnlineConfig(l.conf)
+}
+
+func (l *balanceLeaderScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	l.handler.ServeHTTP(w, r)
+}
+
+func (l *balanceLeaderScheduler) GetBatch() int {
+	return l.conf.getBatch()
+}
+
+func (l *balanceLeaderScheduler) GetPlanRecorder() *plan.Recorder {
+	return l.recorder
 }
 
 func (l *balanceLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return l.opController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
+	return l.opController.OperatorCount(operator.OpLeader) < l.conf.getOperatorLimit(cluster.GetLeaderScheduleLimit())
 }
 
 func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
 	schedulerCounter.WithLabelValues(l.GetName(), "schedule").Inc()
+	p := l.recorder.NewPlan()
+	defer l.recorder.Record(p)
+	cluster = withTolerantSizeRatio(cluster, l.conf.getTolerantSizeRatio())
 
 	leaderSchedulePolicy := l.opController.GetLeaderSchedulePolicy()
 	stores := cluster.GetStores()
-	sources := filter.SelectSourceStores(stores, l.filters, cluster)
-	targets := filter.SelectTargetStores(stores, l.filters, cluster)
+	sources := filter.SelectSourceStoresWithPlan(stores, l.filters, cluster, p)
+	targets := filter.SelectTargetStoresWithPlan(stores, l.filters, cluster, 0, p)
 	opInfluence := l.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(core.LeaderKind, leaderSchedulePolicy)
 	sort.Slice(sources, func(i, j int) bool {
@@ -157,12 +195,15 @@ func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 			source := sources[i]
 			sourceID := source.GetID()
 			log.Debug("store leader score", zap.String("scheduler", l.GetName()), zap.Uint64("source-store", sourceID))
+			sourceOp := opInfluence.GetStoreInfluence(sourceID).ResourceProperty(kind)
+			p.AddSource(sourceID, source.LeaderScore(leaderSchedulePolicy, sourceOp))
 			sourceStoreLabel := strconv.FormatUint(sourceID, 10)
 			sourceAddress := source.GetAddress()
 			l.counter.WithLabelValues("high-score", sourceAddress, sourceStoreLabel).Inc()
 			for j := 0; j < balanceLeaderRetryLimit; j++ {
-				if ops := l.transferLeaderOut(cluster, source); len(ops) > 0 {
+				if ops := l.transferLeaderOut(cluster, source, p); len(ops) > 0 {
 					ops[0].Counters = append(ops[0].Counters, l.counter.WithLabelValues("transfer-out", sourceAddress, sourceStoreLabel))
+					p.SetResult(ops[0].String())
 					return ops
 				}
 			}
@@ -172,13 +213,16 @@ func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 			target := targets[i]
 			targetID := target.GetID()
 			log.Debug("store leader score", zap.String("scheduler", l.GetName()), zap.Uint64("target-store", targetID))
+			targetOp := opInfluence.GetStoreInfluence(targetID).ResourceProperty(kind)
+			p.AddTarget(targetID, target.LeaderScore(leaderSchedulePolicy, targetOp))
 			targetStoreLabel := strconv.FormatUint(targetID, 10)
 			targetAddress := target.GetAddress()
 			l.counter.WithLabelValues("low-score", targetAddress, targetStoreLabel).Inc()
 
 			for j := 0; j < balanceLeaderRetryLimit; j++ {
-				if ops := l.transferLeaderIn(cluster, target); len(ops) > 0 {
+				if ops := l.transferLeaderIn(cluster, target, p); len(ops) > 0 {
 					ops[0].Counters = append(ops[0].Counters, l.counter.WithLabelValues("transfer-in", targetAddress, targetStoreLabel))
+					p.SetResult(ops[0].String())
 					return ops
 				}
 			}
@@ -191,39 +235,42 @@ func (l *balanceLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 // transferLeaderOut transfers leader from the source store.
 // It randomly selects a health region from the source store, then picks
 // the best follower peer and transfers the leader.
-func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *core.StoreInfo) []*operator.Operator {
+func (l *balanceLeaderScheduler) transferLeaderOut(cluster opt.Cluster, source *core.StoreInfo, p *plan.Plan) []*operator.Operator {
 	sourceID := source.GetID()
-	region := cluster.RandLeaderRegion(sourceID, l.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandLeaderRegion(sourceID, l.conf.getRanges(), opt.HealthRegion(cluster), filter.RegionScheduleAllowed(cluster))
 	if region == nil {
 		log.Debug("store has no leader", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", sourceID))
 		schedulerCounter.WithLabelValues(l.GetName(), "no-leader-region").Inc()
+		p.AddStep(sourceID, 0, "no-leader-region")
 		return nil
 	}
 	targets := cluster.GetFollowerStores(region)
-	targets = filter.SelectTargetStores(targets, l.filters, cluster)
+	targets = filter.SelectTargetStoresWithPlan(targets, l.filters, cluster, region.GetID(), p)
 	leaderSchedulePolicy := l.opController.GetLeaderSchedulePolicy()
 	sort.Slice(targets, func(i, j int) bool {
 		return targets[i].LeaderScore(leaderSchedulePolicy, 0) < targets[j].LeaderScore(leaderSchedulePolicy, 0)
 	})
 	for _, target := range targets {
-		if op := l.createOperator(cluster, region, source, target); len(op) > 0 {
+		if op := l.createOperator(cluster, region, source, target, p); len(op) > 0 {
 			return op
 		}
 	}
 	log.Debug("region has no target store", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
 	schedulerCounter.WithLabelValues(l.GetName(), "no-target-store").Inc()
+	p.AddStep(sourceID, region.GetID(), "no-target-store")
 	return nil
 }
 
 // transferLeaderIn transfers leader to the target store.
 // It randomly selects a health region from the target store, then picks
 // the worst follower peer and transfers the leader.
-func (l *balanceLeaderScheduler) transferLeaderIn(cluster opt.Cluster, target *core.StoreInfo) []*operator.Operator {
+func (l *balanceLeaderScheduler) transferLeaderIn(cluster opt.Cluster, target *core.StoreInfo, p *plan.Plan) []*operator.Operator {
 	targetID := target.GetID()
-	region := cluster.RandFollowerRegion(targetID, l.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandFollowerRegion(targetID, l.conf.getRanges(), opt.HealthRegion(cluster), filter.RegionScheduleAllowed(cluster))
 	if region == nil {
 		log.Debug("store has no follower", zap.String("scheduler", l.GetName()), zap.Uint64("store-id", targetID))
 		schedulerCounter.WithLabelValues(l.GetName(), "no-follower-region").Inc()
+		p.AddStep(targetID, 0, "no-follower-region")
 		return nil
 	}
 	leaderStoreID := region.GetLeader().GetStoreId()
@@ -235,28 +282,39 @@ func (l *balanceLeaderScheduler) transferLeaderIn(cluster opt.Cluster, target *c
 			zap.Uint64("store-id", leaderStoreID),
 		)
 		schedulerCounter.WithLabelValues(l.GetName(), "no-leader").Inc()
+		p.AddStep(targetID, region.GetID(), "no-leader")
 		return nil
 	}
-	return l.createOperator(cluster, region, source, target)
+	return l.createOperator(cluster, region, source, target, p)
 }
 
 // createOperator creates the operator according to the source and target store.
-// If the region is hot or the difference between the two stores is tolerable, then
+// If the region is hot, or has no load when balancing by load, or the difference
+// between the two stores is tolerable, then
 // no new operator need to be created, otherwise create an operator that transfers
 // the leader from the source store to the target store for the region.
-func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *core.RegionInfo, source, target *core.StoreInfo) []*operator.Operator {
+func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *core.RegionInfo, source, target *core.StoreInfo, p *plan.Plan) []*operator.Operator {
 	if cluster.IsRegionHot(region) {
 		log.Debug("region is hot region, ignore it", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
 		schedulerCounter.WithLabelValues(l.GetName(), "region-hot").Inc()
+		p.AddStep(source.GetID(), region.GetID(), "region-hot")
 		return nil
 	}
 
 	sourceID := source.GetID()
 	targetID := target.GetID()
 
-	opInfluence := l.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(core.LeaderKind, cluster.GetLeaderSchedulePolicy())
-	if !shouldBalance(cluster, source, target, region, kind, opInfluence, l.GetName()) {
+	if kind.Policy == core.ByLoad && int64(region.GetKeysReadRate()) == 0 {
+		// Transferring the leader of an idle region doesn't change the load.
+		log.Debug("region has no load, ignore it", zap.String("scheduler", l.GetName()), zap.Uint64("region-id", region.GetID()))
+		schedulerCounter.WithLabelValues(l.GetName(), "region-no-load").Inc()
+		p.AddStep(sourceID, region.GetID(), "region-no-load")
+		return nil
+	}
+
+	opInfluence := l.opController.GetOpInfluence(cluster)
+	if !shouldBalance(cluster, source, target, region, kind, opInfluence, l.GetName(), p) {
 		schedulerCounter.WithLabelValues(l.GetName(), "skip").Inc()
 		return nil
 	}
@@ -264,6 +322,7 @@ func (l *balanceLeaderScheduler) createOperator(cluster opt.Cluster, region *cor
 	op, err := operator.CreateTransferLeaderOperator(BalanceLeaderType, cluster, region, region.GetLeader().GetStoreId(), targetID, operator.OpBalance)
 	if err != nil {
 		log.Debug("fail to create balance leader operator", zap.Error(err))
+		p.AddStep(sourceID, region.GetID(), "create-operator-fail")
 		return nil
 	}
 	sourceLabel := strconv.FormatUint(sourceID, 10)
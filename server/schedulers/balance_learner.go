@@ -0,0 +1,227 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/pingcap/kvproto/pkg/metapb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+func init() {
+	schedule.RegisterSliceDecoderBuilder(BalanceLearnerType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			conf, ok := v.(*balanceLearnerSchedulerConfig)
+			if !ok {
+				return ErrScheduleConfigNotExist
+			}
+			ranges, err := getKeyRanges(args)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			conf.Ranges = ranges
+			conf.Name = BalanceLearnerName
+			return nil
+		}
+	})
+	schedule.RegisterScheduler(BalanceLearnerType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := &balanceLearnerSchedulerConfig{}
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		conf.init(storage)
+		return newBalanceLearnerScheduler(opController, conf), nil
+	})
+}
+
+const (
+	// balanceLearnerRetryLimit is the limit to retry schedule for selected store.
+	balanceLearnerRetryLimit = 10
+	// BalanceLearnerName is balance learner scheduler name.
+	BalanceLearnerName = "balance-learner-scheduler"
+	// BalanceLearnerType is balance learner scheduler type.
+	BalanceLearnerType = "balance-learner"
+)
+
+type balanceLearnerSchedulerConfig struct {
+	onlineConfig
+	Name string `json:"name"`
+}
+
+type balanceLearnerScheduler struct {
+	*BaseScheduler
+	conf    *balanceLearnerSchedulerConfig
+	filters []filter.Filter
+	handler http.Handler
+}
+
+// newBalanceLearnerScheduler creates a scheduler that tends to keep learners
+// on each store balanced. Only learners are moved, voters are never touched.
+func newBalanceLearnerScheduler(opController *schedule.OperatorController, conf *balanceLearnerSchedulerConfig) schedule.Scheduler {
+	base := NewBaseScheduler(opController)
+	s := &balanceLearnerScheduler{
+		BaseScheduler: base,
+		conf:          conf,
+		filters: []filter.Filter{
+			filter.StoreStateFilter{ActionScope: conf.Name, MoveRegion: true},
+			filter.NewSpecialUseFilter(conf.Name),
+		},
+	}
+	s.handler = newOnlineConfigHandler(conf.Name, conf.storage, conf)
+	return s
+}
+
+func (s *balanceLearnerScheduler) GetName() string {
+	return s.conf.Name
+}
+
+func (s *balanceLearnerScheduler) GetType() string {
+	return BalanceLearnerType
+}
+
+func (s *balanceLearnerScheduler) EncodeConfig() ([]byte, error) {
+	return encodeOnlineConfig(s.conf)
+}
+
+func (s *balanceLearnerScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *balanceLearnerScheduler) GetBatch() int {
+	return s.conf.getBatch()
+}
+
+func (s *balanceLearnerScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
+	return s.OpController.OperatorCount(operator.OpRegion) < s.conf.getOperatorLimit(cluster.GetRegionScheduleLimit())
+}
+
+// learnerScore returns the total size of learner regions of the store, with
+// the influence of running operators.
+func (s *balanceLearnerScheduler) learnerScore(cluster opt.Cluster, storeID uint64, opInfluence operator.OpInfluence) int64 {
+	kind := core.NewScheduleKind(core.RegionKind, core.BySize)
+	return cluster.GetStoreLearnerRegionSize(storeID) + opInfluence.GetStoreInfluence(storeID).ResourceProperty(kind)
+}
+
+func (s *balanceLearnerScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	stores := filter.SelectSourceStores(cluster.GetStores(), s.filters, cluster)
+	opInfluence := s.OpController.GetOpInfluence(cluster)
+	scores := make(map[uint64]int64, len(stores))
+	for _, store := range stores {
+		scores[store.GetID()] = s.learnerScore(cluster, store.GetID(), opInfluence)
+	}
+	sort.Slice(stores, func(i, j int) bool {
+		return scores[stores[i].GetID()] > scores[stores[j].GetID()]
+	})
+	for _, source := range stores {
+		sourceID := source.GetID()
+		if cluster.GetStoreLearnerCount(sourceID) == 0 {
+			continue
+		}
+		for i := 0; i < balanceLearnerRetryLimit; i++ {
+			region := cluster.RandLearnerRegion(sourceID, s.conf.getRanges(), opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), filter.RegionScheduleAllowed(cluster))
+			if region == nil {
+				schedulerCounter.WithLabelValues(s.GetName(), "no-learner-region").Inc()
+				break
+			}
+			log.Debug("select region", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
+
+			// Skip hot regions.
+			if cluster.IsRegionHot(region) {
+				schedulerCounter.WithLabelValues(s.GetName(), "region-hot").Inc()
+				continue
+			}
+
+			if op := s.transferLearner(cluster, region, source, scores[sourceID], opInfluence); op != nil {
+				op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+				return []*operator.Operator{op}
+			}
+		}
+	}
+	return nil
+}
+
+// transferLearner moves the learner on the source store to the store with the
+// least learners among the stores satisfying the placement of the learner.
+func (s *balanceLearnerScheduler) transferLearner(cluster opt.Cluster, region *core.RegionInfo, source *core.StoreInfo, sourceScore int64, opInfluence operator.OpInfluence) *operator.Operator {
+	oldPeer := region.GetStoreLearner(source.GetID())
+	if oldPeer == nil {
+		return nil
+	}
+	filters := []filter.Filter{
+		filter.StoreStateFilter{ActionScope: s.GetName(), MoveRegion: true},
+		filter.NewExcludedFilter(s.GetName(), nil, region.GetStoreIds()),
+		filter.NewSpecialUseFilter(s.GetName()),
+	}
+	if cluster.IsPlacementRulesEnabled() {
+		rf := cluster.FitRegion(region).GetRuleFit(oldPeer.GetId())
+		if rf == nil {
+			schedulerCounter.WithLabelValues(s.GetName(), "skip-orphan-peer").Inc()
+			return nil
+		}
+		filters = append(filters,
+			filter.NewLabelConstaintFilter(s.GetName(), rf.Rule.LabelConstraints),
+			filter.NewRuleFitFilter(s.GetName(), cluster, region, source.GetID()),
+		)
+	} else {
+		filters = append(filters, filter.NewDistinctScoreFilter(s.GetName(), cluster.GetLocationLabels(), cluster.GetRegionStores(region), source))
+	}
+
+	var (
+		target      *core.StoreInfo
+		targetScore int64
+	)
+	for _, store := range filter.SelectTargetStores(cluster.GetStores(), filters, cluster) {
+		score := s.learnerScore(cluster, store.GetID(), opInfluence)
+		if target == nil || score < targetScore {
+			target, targetScore = store, score
+		}
+	}
+	if target == nil {
+		schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
+		return nil
+	}
+	// The source should still have no less learners than the target after the
+	// move, to avoid moving the learner back and forth.
+	regionSize := region.GetApproximateSize()
+	if sourceScore-regionSize < targetScore+regionSize {
+		schedulerCounter.WithLabelValues(s.GetName(), "skip").Inc()
+		return nil
+	}
+
+	newPeer := &metapb.Peer{StoreId: target.GetID(), IsLearner: true}
+	op, err := operator.CreateMovePeerOperator("balance-learner", cluster, region, operator.OpBalance, source.GetID(), newPeer)
+	if err != nil {
+		schedulerCounter.WithLabelValues(s.GetName(), "create-operator-fail").Inc()
+		return nil
+	}
+	sourceLabel := strconv.FormatUint(source.GetID(), 10)
+	targetLabel := strconv.FormatUint(target.GetID(), 10)
+	op.Counters = append(op.Counters,
+		balanceLearnerCounter.WithLabelValues("move-learner", source.GetAddress()+"-out", sourceLabel),
+		balanceLearnerCounter.WithLabelValues("move-learner", target.GetAddress()+"-in", targetLabel),
+		balanceDirectionCounter.WithLabelValues(s.GetName(), sourceLabel, targetLabel),
+	)
+	return op
+}
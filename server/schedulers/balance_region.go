@@ -14,6 +14,7 @@
 package schedulers
 
 import (
+	"net/http"
 	"sort"
 	"strconv"
 
@@ -25,6 +26,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/filter"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
@@ -51,6 +53,7 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.init(storage)
 		return newBalanceRegionScheduler(opController, conf), nil
 	})
 }
@@ -65,8 +68,24 @@ const (
 )
 
 type balanceRegionSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	onlineConfig
+	Name string `json:"name"`
+	// TolerantSizeRatio replaces the tolerant size ratio of the cluster for
+	// the scheduler if it is not 0.
+	TolerantSizeRatio float64 `json:"tolerant-size-ratio"`
+}
+
+func (conf *balanceRegionSchedulerConfig) getTolerantSizeRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.TolerantSizeRatio
+}
+
+func (conf *balanceRegionSchedulerConfig) validate() error {
+	if conf.TolerantSizeRatio < 0 {
+		return errors.New("tolerant-size-ratio should not be negative")
+	}
+	return conf.onlineConfig.validate()
 }
 
 type balanceRegionScheduler struct {
@@ -75,6 +94,8 @@ type balanceRegionScheduler struct {
 	opController *schedule.OperatorController
 	filters      []filter.Filter
 	counter      *prometheus.CounterVec
+	recorder     *plan.Recorder
+	handler      http.Handler
 }
 
 // newBalanceRegionScheduler creates a scheduler that tends to keep regions on
@@ -86,6 +107,7 @@ func newBalanceRegionScheduler(opController *schedule.OperatorController, conf *
 		conf:          conf,
 		opController:  opController,
 		counter:       balanceRegionCounter,
+		recorder:      plan.NewRecorder(),
 	}
 	for _, setOption := range opts {
 		setOption(scheduler)
@@ -94,6 +116,7 @@ func newBalanceRegionScheduler(opController *schedule.OperatorController, conf *
 		filter.StoreStateFilter{ActionScope: scheduler.GetName(), MoveRegion: true},
 		filter.NewSpecialUseFilter(scheduler.GetName()),
 	}
+	scheduler.handler = newOnlineConfigHandler(scheduler.GetName(), conf.storage, conf)
 	return scheduler
 }
 
@@ -123,17 +146,33 @@ func (s *balanceRegionScheduler) GetType() string {
 }
 
 func (s *balanceRegionScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(s.conf)
+	return encodeOnlineConfig(s.conf)
+}
+
+func (s *balanceRegionScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *balanceRegionScheduler) GetBatch() int {
+	return s.conf.getBatch()
+}
+
+func (s *balanceRegionScheduler) GetPlanRecorder() *plan.Recorder {
+	return s.recorder
 }
 
 func (s *balanceRegionScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.opController.OperatorCount(operator.OpRegion) < cluster.GetRegionScheduleLimit()
+	return s.opController.OperatorCount(operator.OpRegion) < s.conf.getOperatorLimit(cluster.GetRegionScheduleLimit())
 }
 
 func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	p := s.recorder.NewPlan()
+	defer s.recorder.Record(p)
+	cluster = withTolerantSizeRatio(cluster, s.conf.getTolerantSizeRatio())
+	ranges := s.conf.getRanges()
 	stores := cluster.GetStores()
-	stores = filter.SelectSourceStores(stores, s.filters, cluster)
+	stores = filter.SelectSourceStoresWithPlan(stores, s.filters, cluster, p)
 	opInfluence := s.opController.GetOpInfluence(cluster)
 	kind := core.NewScheduleKind(core.RegionKind, core.BySize)
 	sort.Slice(stores, func(i, j int) bool {
@@ -144,25 +183,28 @@ func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 	})
 	for _, source := range stores {
 		sourceID := source.GetID()
+		sourceOp := opInfluence.GetStoreInfluence(sourceID).ResourceProperty(kind)
+		p.AddSource(sourceID, source.RegionScore(cluster.GetHighSpaceRatio(), cluster.GetLowSpaceRatio(), sourceOp))
 
 		for i := 0; i < balanceRegionRetryLimit; i++ {
 			// Priority pick the region that has a pending peer.
 			// Pending region may means the disk is overload, remove the pending region firstly.
-			region := cluster.RandPendingRegion(sourceID, s.conf.Ranges, opt.HealthAllowPending(cluster), opt.ReplicatedRegion(cluster))
+			region := cluster.RandPendingRegion(sourceID, ranges, opt.HealthAllowPending(cluster), opt.ReplicatedRegion(cluster), filter.RegionScheduleAllowed(cluster))
 			if region == nil {
 				// Then pick the region that has a follower in the source store.
-				region = cluster.RandFollowerRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster))
+				region = cluster.RandFollowerRegion(sourceID, ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), filter.RegionScheduleAllowed(cluster))
 			}
 			if region == nil {
 				// Then pick the region has the leader in the source store.
-				region = cluster.RandLeaderRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster))
+				region = cluster.RandLeaderRegion(sourceID, ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), filter.RegionScheduleAllowed(cluster))
 			}
 			if region == nil {
 				// Finally pick learner.
-				region = cluster.RandLearnerRegion(sourceID, s.conf.Ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster))
+				region = cluster.RandLearnerRegion(sourceID, ranges, opt.HealthRegion(cluster), opt.ReplicatedRegion(cluster), filter.RegionScheduleAllowed(cluster))
 			}
 			if region == nil {
 				schedulerCounter.WithLabelValues(s.GetName(), "no-region").Inc()
+				p.AddStep(sourceID, 0, "no-region")
 				continue
 			}
 			log.Debug("select region", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
@@ -171,12 +213,14 @@ func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 			if cluster.IsRegionHot(region) {
 				log.Debug("region is hot", zap.String("scheduler", s.GetName()), zap.Uint64("region-id", region.GetID()))
 				schedulerCounter.WithLabelValues(s.GetName(), "region-hot").Inc()
+				p.AddStep(sourceID, region.GetID(), "region-hot")
 				continue
 			}
 
 			oldPeer := region.GetStorePeer(sourceID)
-			if op := s.transferPeer(cluster, region, oldPeer); op != nil {
+			if op := s.transferPeer(cluster, region, oldPeer, p); op != nil {
 				op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+				p.SetResult(op.String())
 				return []*operator.Operator{op}
 			}
 		}
@@ -185,7 +229,7 @@ func (s *balanceRegionScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 }
 
 // transferPeer selects the best store to create a new peer to replace the old peer.
-func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.RegionInfo, oldPeer *metapb.Peer) *operator.Operator {
+func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.RegionInfo, oldPeer *metapb.Peer, p *plan.Plan) *operator.Operator {
 	// scoreGuard guarantees that the distinct score will not decrease.
 	stores := cluster.GetRegionStores(region)
 	sourceStoreID := oldPeer.GetStoreId()
@@ -204,6 +248,7 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 			rf := fit.GetRuleFit(oldPeer.GetId())
 			if rf == nil {
 				schedulerCounter.WithLabelValues(s.GetName(), "skip-orphan-peer").Inc()
+				p.AddStep(sourceStoreID, region.GetID(), "skip-orphan-peer")
 				return nil
 			}
 			target = checker.SelectStoreToReplacePeerByRule(s.GetName(), cluster, region, fit, rf, oldPeer, scoreGuard, excludeFilter)
@@ -217,6 +262,7 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 		}
 		if target == nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "no-replacement").Inc()
+			p.AddStep(sourceStoreID, region.GetID(), "no-replacement")
 			return nil
 		}
 		exclude[target.GetID()] = struct{}{} // exclude next round.
@@ -228,7 +274,7 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 
 		opInfluence := s.opController.GetOpInfluence(cluster)
 		kind := core.NewScheduleKind(core.RegionKind, core.BySize)
-		if !shouldBalance(cluster, source, target, region, kind, opInfluence, s.GetName()) {
+		if !shouldBalance(cluster, source, target, region, kind, opInfluence, s.GetName(), p) {
 			schedulerCounter.WithLabelValues(s.GetName(), "skip").Inc()
 			continue
 		}
@@ -237,6 +283,7 @@ func (s *balanceRegionScheduler) transferPeer(cluster opt.Cluster, region *core.
 		op, err := operator.CreateMovePeerOperator("balance-region", cluster, region, operator.OpBalance, oldPeer.GetStoreId(), newPeer)
 		if err != nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "create-operator-fail").Inc()
+			p.AddStep(sourceID, regionID, "create-operator-fail")
 			return nil
 		}
 		sourceLabel := strconv.FormatUint(sourceID, 10)
@@ -30,7 +30,10 @@ import (
 	"github.com/pingcap/pd/v4/server/kv"
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/checker"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 )
 
 func newTestReplication(mso *mockoption.ScheduleOptions, maxReplicas int, locationLabels ...string) {
@@ -121,7 +124,7 @@ func (s *testBalanceSuite) TestShouldBalance(c *C) {
 		tc.PutRegion(region)
 		tc.LeaderSchedulePolicy = t.kind.String()
 		kind := core.NewScheduleKind(core.LeaderKind, t.kind)
-		c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), ""), Equals, t.expectedResult)
+		c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), "", nil), Equals, t.expectedResult)
 	}
 
 	for _, t := range tests {
@@ -133,7 +136,7 @@ func (s *testBalanceSuite) TestShouldBalance(c *C) {
 			region := tc.GetRegion(1).Clone(core.SetApproximateSize(t.regionSize))
 			tc.PutRegion(region)
 			kind := core.NewScheduleKind(core.RegionKind, t.kind)
-			c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), ""), Equals, t.expectedResult)
+			c.Assert(shouldBalance(tc, source, target, region, kind, oc.GetOpInfluence(tc), "", nil), Equals, t.expectedResult)
 		}
 	}
 }
@@ -236,6 +239,27 @@ func (s *testBalanceLeaderSchedulerSuite) TestBalanceLimit(c *C) {
 	c.Check(s.schedule(), NotNil)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestBalanceLeaderScheduleDeny(c *C) {
+	// Stores:     1    2    3    4
+	// Leaders:    16   0    0    0
+	// Region1:    L    F    F    F
+	s.tc.AddLeaderStore(1, 16)
+	s.tc.AddLeaderStore(2, 0)
+	s.tc.AddLeaderStore(3, 0)
+	s.tc.AddLeaderStore(4, 0)
+	s.tc.AddLeaderRegion(1, 1, 2, 3, 4)
+	c.Check(s.schedule(), NotNil)
+
+	// The region labeled with schedule=deny is not picked.
+	rule := &labeler.LabelRule{
+		ID:     "deny",
+		Labels: []labeler.RegionLabel{{Key: labeler.ScheduleOptionLabel, Value: labeler.ScheduleOptionValueDeny}},
+		Ranges: []labeler.KeyRange{{StartKeyHex: "", EndKeyHex: ""}},
+	}
+	c.Assert(s.tc.GetRegionLabeler().SetLabelRule(rule), IsNil)
+	c.Check(s.schedule(), IsNil)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestBalanceLeaderSchedulePolicy(c *C) {
 	// Stores:          1       2       3       4
 	// Leader Count:    10      10      10      10
@@ -252,6 +276,42 @@ func (s *testBalanceLeaderSchedulerSuite) TestBalanceLeaderSchedulePolicy(c *C)
 	c.Check(s.schedule(), NotNil)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestBalanceLeaderByLoadPolicy(c *C) {
+	// Stores:          1       2       3       4
+	// Leader Count:    10      10      10      10
+	// Leader Load:     1000    100     100     100
+	// Region1:         L       F       F       F
+	s.tc.LeaderSchedulePolicy = core.ByLoad.String()
+	s.tc.AddLeaderStore(1, 10)
+	s.tc.AddLeaderStore(2, 10)
+	s.tc.AddLeaderStore(3, 10)
+	s.tc.AddLeaderStore(4, 10)
+	s.tc.UpdateStoreLeaderLoad(1, 1000)
+	s.tc.UpdateStoreLeaderLoad(2, 100)
+	s.tc.UpdateStoreLeaderLoad(3, 100)
+	s.tc.UpdateStoreLeaderLoad(4, 100)
+	s.tc.AddLeaderRegion(1, 1, 2, 3, 4)
+	// Transferring the leader of an idle region doesn't help.
+	c.Check(s.schedule(), IsNil)
+
+	// The region is read 300 keys per second.
+	region := s.tc.GetRegion(1).Clone(core.SetReadKeys(3000), core.SetReportInterval(10))
+	s.tc.PutRegion(region)
+	c.Assert(region.GetKeysReadRate(), Equals, 300.0)
+	op := s.schedule()
+	c.Assert(op, NotNil)
+	c.Assert(op[0].Step(0).(operator.TransferLeader).FromStore, Equals, uint64(1))
+
+	// The difference is less than the tolerant load.
+	s.tc.UpdateStoreLeaderLoad(1, 330)
+	s.tc.UpdateStoreLeaderLoad(2, 300)
+	s.tc.UpdateStoreLeaderLoad(3, 300)
+	s.tc.UpdateStoreLeaderLoad(4, 300)
+	region = region.Clone(core.SetReadKeys(100))
+	s.tc.PutRegion(region)
+	c.Check(s.schedule(), IsNil)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestBalanceLeaderTolerantRatio(c *C) {
 	// default leader tolerant ratio is 5, when schedule by count
 	// Stores:          1       2       3       4
@@ -337,6 +397,23 @@ func (s *testBalanceLeaderSchedulerSuite) TestBalanceFilter(c *C) {
 	c.Assert(s.schedule(), HasLen, 0)
 }
 
+func (s *testBalanceLeaderSchedulerSuite) TestWitness(c *C) {
+	// Stores:     1    2    3    4
+	// Leaders:    1    2    3   16
+	// Region1:    W    F    F    L
+	s.tc.AddLeaderStore(1, 1)
+	s.tc.AddLeaderStore(2, 2)
+	s.tc.AddLeaderStore(3, 3)
+	s.tc.AddLeaderStore(4, 16)
+	s.tc.AddLeaderRegionWithWitnesses(1, 4, []uint64{1}, 1, 2, 3)
+	// Witness never becomes leader, store 2 is chosen instead.
+	testutil.CheckTransferLeader(c, s.schedule()[0], operator.OpBalance, 4, 2)
+
+	// Region1:    W    W    W    L
+	s.tc.AddLeaderRegionWithWitnesses(1, 4, []uint64{1, 2, 3}, 1, 2, 3)
+	c.Assert(s.schedule(), HasLen, 0)
+}
+
 func (s *testBalanceLeaderSchedulerSuite) TestLeaderWeight(c *C) {
 	// Stores:     1       2       3       4
 	// Leaders:    10      10      10      10
@@ -558,6 +635,58 @@ func (s *testBalanceRegionSchedulerSuite) TestBalance(c *C) {
 	c.Assert(sb.Schedule(tc), NotNil)
 }
 
+func (s *testBalanceRegionSchedulerSuite) TestDiagnostic(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(s.ctx, nil, nil)
+
+	sb, err := schedule.CreateScheduler(BalanceRegionType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(BalanceRegionType, []string{"", ""}))
+	c.Assert(err, IsNil)
+	recorder := sb.(schedule.DiagnosableScheduler).GetPlanRecorder()
+
+	opt.SetMaxReplicas(1)
+	tc.AddRegionStore(1, 6)
+	tc.AddRegionStore(2, 8)
+	tc.AddRegionStore(3, 16)
+	tc.AddRegionStore(4, 16)
+	tc.AddLeaderRegion(1, 4)
+	tc.SetStoreBusy(3, true)
+
+	// Nothing is recorded by default.
+	c.Assert(sb.Schedule(tc), NotNil)
+	c.Assert(recorder.GetDiagnostic().Plans, HasLen, 0)
+
+	c.Assert(recorder.Enable(2), IsNil)
+	ops := sb.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	d := recorder.GetDiagnostic()
+	c.Assert(d.Plans, HasLen, 1)
+	p := d.Plans[0]
+	c.Assert(p.Filtered, DeepEquals, []plan.FilterRecord{{StoreID: 3, Role: plan.SourceRole, Filter: "store-state-filter", Reason: "busy"}})
+	c.Assert(p.Sources, HasLen, 1)
+	c.Assert(p.Sources[0].StoreID, Equals, uint64(4))
+	c.Assert(p.Scores, HasLen, 1)
+	c.Assert(p.Scores[0].SourceID, Equals, uint64(4))
+	c.Assert(p.Scores[0].TargetID, Equals, uint64(1))
+	c.Assert(p.Scores[0].ShouldBalance, IsTrue)
+	c.Assert(p.Result, Equals, ops[0].String())
+
+	// The region is not replicated, no region can be selected.
+	opt.SetMaxReplicas(3)
+	c.Assert(sb.Schedule(tc), IsNil)
+	d = recorder.GetDiagnostic()
+	c.Assert(d.Plans, HasLen, 2)
+	p = d.Plans[1]
+	c.Assert(p.Sources, HasLen, 3)
+	c.Assert(p.Steps[0], DeepEquals, plan.StepRecord{StoreID: 4, Reason: "no-region"})
+	c.Assert(p.Scores, HasLen, 0)
+	c.Assert(p.Result, Equals, plan.ResultNoOperator)
+
+	recorder.Disable()
+	c.Assert(sb.Schedule(tc), IsNil)
+	c.Assert(recorder.GetDiagnostic().Plans, HasLen, 0)
+}
+
 func (s *testBalanceRegionSchedulerSuite) TestReplicas3(c *C) {
 	opt := mockoption.NewScheduleOptions()
 	newTestReplication(opt, 3, "zone", "rack", "host")
@@ -804,6 +933,86 @@ func (s *testBalanceRegionSchedulerSuite) checkReplacePendingRegion(c *C, tc *mo
 	testutil.CheckTransferPeer(c, sb.Schedule(tc)[0], operator.OpBalance, 1, 4)
 }
 
+var _ = Suite(&testBalanceLearnerSchedulerSuite{})
+
+type testBalanceLearnerSchedulerSuite struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func (s *testBalanceLearnerSchedulerSuite) SetUpSuite(c *C) {
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+}
+
+func (s *testBalanceLearnerSchedulerSuite) TearDownSuite(c *C) {
+	s.cancel()
+}
+
+func (s *testBalanceLearnerSchedulerSuite) TestBalance(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(s.ctx, nil, nil)
+
+	// 2 voters + 1 learner on the tiflash stores.
+	opt.EnablePlacementRules = true
+	tc.RuleManager.SetRule(&placement.Rule{
+		GroupID: "pd",
+		ID:      "default",
+		Role:    placement.Voter,
+		Count:   2,
+	})
+	tc.RuleManager.SetRule(&placement.Rule{
+		GroupID:          "pd",
+		ID:               "learner",
+		Role:             placement.Learner,
+		Count:            1,
+		LabelConstraints: []placement.LabelConstraint{{Key: "engine", Op: placement.In, Values: []string{"tiflash"}}},
+	})
+
+	sb, err := schedule.CreateScheduler(BalanceLearnerType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(BalanceLearnerType, []string{"", ""}))
+	c.Assert(err, IsNil)
+
+	// Add stores 1, 2, 3 for voters and stores 4, 5, 6 for learners.
+	tc.AddRegionStore(1, 3)
+	tc.AddRegionStore(2, 3)
+	tc.AddRegionStore(3, 0)
+	tc.AddLabelsStore(4, 2, map[string]string{"engine": "tiflash"})
+	tc.AddLabelsStore(5, 0, map[string]string{"engine": "tiflash"})
+	tc.AddLabelsStore(6, 1, map[string]string{"engine": "tiflash"})
+	addLearnerRegion := func(id, learnerStore uint64) {
+		peers := []*metapb.Peer{
+			{Id: id*10 + 1, StoreId: 1},
+			{Id: id*10 + 2, StoreId: 2},
+			{Id: id*10 + 3, StoreId: learnerStore, IsLearner: true},
+		}
+		region := core.NewRegionInfo(&metapb.Region{
+			Id:          id,
+			StartKey:    []byte(fmt.Sprintf("%02d", id)),
+			EndKey:      []byte(fmt.Sprintf("%02d", id+1)),
+			RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+			Peers:       peers,
+		}, peers[0], core.SetApproximateSize(10))
+		tc.PutRegion(region)
+	}
+
+	// Store 4 has only one more learner than store 5, no need to balance.
+	addLearnerRegion(1, 4)
+	addLearnerRegion(2, 6)
+	c.Assert(sb.Schedule(tc), IsNil)
+
+	// The learner is moved to store 5 with the least learners, store 3 is
+	// ignored since it does not satisfy the rule of the learner.
+	addLearnerRegion(3, 4)
+	ops := sb.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	testutil.CheckTransferLearner(c, ops[0], operator.OpBalance, 4, 5)
+
+	// No store satisfies the rule of the learner.
+	tc.SetStoreOffline(5)
+	tc.SetStoreOffline(6)
+	c.Assert(sb.Schedule(tc), IsNil)
+}
+
 var _ = Suite(&testReplicaCheckerSuite{})
 
 type testReplicaCheckerSuite struct{}
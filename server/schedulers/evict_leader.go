@@ -27,7 +27,6 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/schedule/selector"
-	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 	"go.uber.org/zap"
 )
@@ -46,33 +45,34 @@ const (
 func init() {
 	schedule.RegisterSliceDecoderBuilder(EvictLeaderType, func(args []string) schedule.ConfigDecoder {
 		return func(v interface{}) error {
-			if len(args) != 1 {
-				return errors.New("should specify the store-id")
-			}
 			conf, ok := v.(*evictLeaderSchedulerConfig)
 			if !ok {
 				return ErrScheduleConfigNotExist
 			}
-
-			id, err := strconv.ParseUint(args[0], 10, 64)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			ranges, err := getKeyRanges(args[1:])
+			id, percent, ranges, err := parseStoreLeaderArgs(args)
 			if err != nil {
-				return errors.WithStack(err)
+				return err
 			}
 			conf.StoreIDWithRanges[id] = ranges
+			if percent >= 0 {
+				conf.StoreIDWithLeaderPercent[id] = percent
+			}
 			return nil
-
 		}
 	})
 
 	schedule.RegisterScheduler(EvictLeaderType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-		conf := &evictLeaderSchedulerConfig{StoreIDWithRanges: make(map[uint64][]core.KeyRange), storage: storage}
+		conf := &evictLeaderSchedulerConfig{
+			StoreIDWithRanges:        make(map[uint64][]core.KeyRange),
+			StoreIDWithLeaderPercent: make(map[uint64]int),
+			storage:                  storage,
+		}
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		if conf.StoreIDWithLeaderPercent == nil {
+			conf.StoreIDWithLeaderPercent = make(map[uint64]int)
+		}
 		conf.cluster = opController.GetCluster()
 		return newEvictLeaderScheduler(opController, conf), nil
 	})
@@ -82,33 +82,43 @@ type evictLeaderSchedulerConfig struct {
 	mu                sync.RWMutex
 	storage           *core.Storage
 	StoreIDWithRanges map[uint64][]core.KeyRange `json:"store-id-ranges"`
-	cluster           opt.Cluster
+	// StoreIDWithLeaderPercent is the max percent of the regions on the store
+	// to keep as leaders, only the leaders in the ranges are evicted to reach
+	// it. All leaders in the ranges are evicted if the store is absent.
+	StoreIDWithLeaderPercent map[uint64]int `json:"store-id-leader-percent,omitempty"`
+	cluster                  opt.Cluster
 }
 
 func (conf *evictLeaderSchedulerConfig) BuildWithArgs(args []string) error {
-	if len(args) != 1 {
-		return errors.New("should specify the store-id")
-	}
-
-	id, err := strconv.ParseUint(args[0], 10, 64)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	ranges, err := getKeyRanges(args[1:])
+	id, percent, ranges, err := parseStoreLeaderArgs(args)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 	conf.mu.Lock()
 	defer conf.mu.Unlock()
 	conf.StoreIDWithRanges[id] = ranges
+	if percent >= 0 {
+		conf.StoreIDWithLeaderPercent[id] = percent
+	} else {
+		delete(conf.StoreIDWithLeaderPercent, id)
+	}
 	return nil
 }
 
 func (conf *evictLeaderSchedulerConfig) Clone() *evictLeaderSchedulerConfig {
 	conf.mu.RLock()
 	defer conf.mu.RUnlock()
+	storeIDWithRanges := make(map[uint64][]core.KeyRange, len(conf.StoreIDWithRanges))
+	for id, ranges := range conf.StoreIDWithRanges {
+		storeIDWithRanges[id] = ranges
+	}
+	storeIDWithLeaderPercent := make(map[uint64]int, len(conf.StoreIDWithLeaderPercent))
+	for id, percent := range conf.StoreIDWithLeaderPercent {
+		storeIDWithLeaderPercent[id] = percent
+	}
 	return &evictLeaderSchedulerConfig{
-		StoreIDWithRanges: conf.StoreIDWithRanges,
+		StoreIDWithRanges:        storeIDWithRanges,
+		StoreIDWithLeaderPercent: storeIDWithLeaderPercent,
 	}
 }
 
@@ -131,13 +141,25 @@ func (conf *evictLeaderSchedulerConfig) getSchedulerName() string {
 func (conf *evictLeaderSchedulerConfig) getRanges(id uint64) []string {
 	conf.mu.RLock()
 	defer conf.mu.RUnlock()
-	var res []string
-	ranges := conf.StoreIDWithRanges[id]
-	for index := range ranges {
-		res = append(res, (string)(ranges[index].StartKey))
-		res = append(res, (string)(ranges[index].EndKey))
+	return encodeKeyRanges(conf.StoreIDWithRanges[id])
+}
+
+func (conf *evictLeaderSchedulerConfig) getLeaderPercent(id uint64) (int, bool) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	percent, ok := conf.StoreIDWithLeaderPercent[id]
+	return percent, ok
+}
+
+// getProgress returns the progress of evicting leaders of each store.
+func (conf *evictLeaderSchedulerConfig) getProgress() map[uint64]*leaderProgress {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	progress := make(map[uint64]*leaderProgress, len(conf.StoreIDWithRanges))
+	for id := range conf.StoreIDWithRanges {
+		progress[id] = calcEvictLeaderProgress(conf.cluster, id, conf.StoreIDWithLeaderPercent[id])
 	}
-	return res
+	return progress
 }
 
 func (conf *evictLeaderSchedulerConfig) mayBeRemoveStoreFromConfig(id uint64) (succ bool, last bool) {
@@ -147,6 +169,7 @@ func (conf *evictLeaderSchedulerConfig) mayBeRemoveStoreFromConfig(id uint64) (s
 	succ, last = false, false
 	if exists {
 		delete(conf.StoreIDWithRanges, id)
+		delete(conf.StoreIDWithLeaderPercent, id)
 		conf.cluster.UnblockStore(id)
 		succ = true
 		last = len(conf.StoreIDWithRanges) == 0
@@ -162,7 +185,7 @@ type evictLeaderScheduler struct {
 }
 
 // newEvictLeaderScheduler creates an admin scheduler that transfers all leaders
-// out of a store.
+// out of a store, or only keeps a percent of regions as leaders on the store.
 func newEvictLeaderScheduler(opController *schedule.OperatorController, conf *evictLeaderSchedulerConfig) schedule.Scheduler {
 	filters := []filter.Filter{
 		filter.StoreStateFilter{ActionScope: EvictLeaderName, TransferLeader: true},
@@ -220,32 +243,103 @@ func (s *evictLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
 
-func (s *evictLeaderScheduler) scheduleOnce(cluster opt.Cluster) []*operator.Operator {
+func (s *evictLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	conf := s.conf.Clone()
+	storeRanges := conf.StoreIDWithRanges
+	var storeLimits map[uint64]int
+	if len(conf.StoreIDWithLeaderPercent) > 0 {
+		storeLimits = make(map[uint64]int, len(conf.StoreIDWithLeaderPercent))
+		for id, percent := range conf.StoreIDWithLeaderPercent {
+			// The leaders being transferred out are counted as evicted.
+			p := calcEvictLeaderProgress(cluster, id, percent)
+			limit := p.Remaining - countRunningLeaderOperators(s.OpController, s.GetType(), id, true)
+			if limit <= 0 {
+				schedulerCounter.WithLabelValues(s.GetName(), "reach-target").Inc()
+				delete(storeRanges, id)
+				continue
+			}
+			storeLimits[id] = limit
+		}
+	}
+	return scheduleEvictLeaderBatch(s.GetName(), s.GetType(), cluster, storeRanges, storeLimits, s.selector)
+}
+
+// countRunningLeaderOperators counts the running operators created by the
+// scheduler which transfer leaders out of the store if out is true, or into
+// the store otherwise.
+func countRunningLeaderOperators(oc *schedule.OperatorController, desc string, storeID uint64, out bool) int {
+	count := 0
+	for _, op := range oc.GetOperators() {
+		if op.Desc() != desc {
+			continue
+		}
+		for i := 0; i < op.Len(); i++ {
+			step, ok := op.Step(i).(operator.TransferLeader)
+			if !ok {
+				continue
+			}
+			if (out && step.FromStore == storeID) || (!out && step.ToStore == storeID) {
+				count++
+				break
+			}
+		}
+	}
+	return count
+}
+
+// scheduleEvictLeaderBatch creates at most EvictLeaderBatchSize operators to
+// transfer leaders out of the stores in the key ranges. The stores in
+// storeLimits get at most the limited count of operators.
+func scheduleEvictLeaderBatch(name, typ string, cluster opt.Cluster, storeRanges map[uint64][]core.KeyRange, storeLimits map[uint64]int, selector *selector.RandomSelector) []*operator.Operator {
 	var ops []*operator.Operator
-	for id, ranges := range s.conf.StoreIDWithRanges {
+	for i := 0; i < EvictLeaderBatchSize; i++ {
+		once := scheduleEvictLeaderOnce(name, typ, cluster, storeRanges, storeLimits, selector)
+		// no more regions
+		if len(once) == 0 {
+			break
+		}
+		ops = uniqueAppendOperators(ops, once...)
+		// the batch has been fulfilled
+		if len(ops) > EvictLeaderBatchSize {
+			break
+		}
+	}
+	return ops
+}
+
+func scheduleEvictLeaderOnce(name, typ string, cluster opt.Cluster, storeRanges map[uint64][]core.KeyRange, storeLimits map[uint64]int, selector *selector.RandomSelector) []*operator.Operator {
+	var ops []*operator.Operator
+	for id, ranges := range storeRanges {
+		if limit, ok := storeLimits[id]; ok && limit <= 0 {
+			continue
+		}
 		region := cluster.RandLeaderRegion(id, ranges, opt.HealthRegion(cluster))
 		if region == nil {
-			schedulerCounter.WithLabelValues(s.GetName(), "no-leader").Inc()
+			schedulerCounter.WithLabelValues(name, "no-leader").Inc()
 			continue
 		}
-		target := s.selector.SelectTarget(cluster, cluster.GetFollowerStores(region))
+		target := selector.SelectTarget(cluster, cluster.GetFollowerStores(region))
 		if target == nil {
-			schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
+			schedulerCounter.WithLabelValues(name, "no-target-store").Inc()
 			continue
 		}
-		op, err := operator.CreateTransferLeaderOperator(EvictLeaderType, cluster, region, region.GetLeader().GetStoreId(), target.GetID(), operator.OpLeader)
+		op, err := operator.CreateTransferLeaderOperator(typ, cluster, region, region.GetLeader().GetStoreId(), target.GetID(), operator.OpLeader)
 		if err != nil {
 			log.Debug("fail to create evict leader operator", zap.Error(err))
 			continue
 		}
 		op.SetPriorityLevel(core.HighPriority)
-		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(s.GetName(), "new-operator"))
+		op.Counters = append(op.Counters, schedulerCounter.WithLabelValues(name, "new-operator"))
 		ops = append(ops, op)
+		if _, ok := storeLimits[id]; ok {
+			storeLimits[id]--
+		}
 	}
 	return ops
 }
 
-func (s *evictLeaderScheduler) uniqueAppend(dst []*operator.Operator, src ...*operator.Operator) []*operator.Operator {
+func uniqueAppendOperators(dst []*operator.Operator, src ...*operator.Operator) []*operator.Operator {
 	regionIDs := make(map[uint64]struct{})
 	for i := range dst {
 		regionIDs[dst[i].RegionID()] = struct{}{}
@@ -260,28 +354,6 @@ func (s *evictLeaderScheduler) uniqueAppend(dst []*operator.Operator, src ...*op
 	return dst
 }
 
-func (s *evictLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
-	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
-	var ops []*operator.Operator
-	s.conf.mu.RLock()
-	defer s.conf.mu.RUnlock()
-
-	for i := 0; i < EvictLeaderBatchSize; i++ {
-		once := s.scheduleOnce(cluster)
-		// no more regions
-		if len(once) == 0 {
-			break
-		}
-		ops = s.uniqueAppend(ops, once...)
-		// the batch has been fulfilled
-		if len(ops) > EvictLeaderBatchSize {
-			break
-		}
-	}
-
-	return ops
-}
-
 type evictLeaderHandler struct {
 	rd     *render.Render
 	config *evictLeaderSchedulerConfig
@@ -298,22 +370,36 @@ func (handler *evictLeaderHandler) UpdateConfig(w http.ResponseWriter, r *http.R
 	idFloat, ok := input["store_id"].(float64)
 	if ok {
 		id = (uint64)(idFloat)
-		if _, exists = handler.config.StoreIDWithRanges[id]; !exists {
-			if err := handler.config.cluster.BlockStore(id); err != nil {
-				handler.rd.JSON(w, http.StatusInternalServerError, err)
-				return
-			}
-		}
+		_, exists = handler.config.StoreIDWithRanges[id]
 		args = append(args, strconv.FormatUint(id, 10))
 	}
 
-	ranges, ok := (input["ranges"]).([]string)
-	if ok {
-		args = append(args, ranges...)
+	if percent, ok := input["leader_percent"].(float64); ok {
+		args = append(args, strconv.Itoa(int(percent))+"%")
+	} else if percent, ok := handler.config.getLeaderPercent(id); ok && exists {
+		args = append(args, strconv.Itoa(percent)+"%")
+	}
+	if ranges, ok := input["ranges"]; ok {
+		keys, err := parseRangesInput(ranges)
+		if err != nil {
+			handler.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		args = append(args, keys...)
 	} else if exists {
 		args = append(args, handler.config.getRanges(id)...)
 	}
 
+	if _, _, _, err := parseStoreLeaderArgs(args); err != nil {
+		handler.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !exists {
+		if err := handler.config.cluster.BlockStore(id); err != nil {
+			handler.rd.JSON(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
 	handler.config.BuildWithArgs(args)
 	err := handler.config.Persist()
 	if err != nil {
@@ -328,6 +414,10 @@ func (handler *evictLeaderHandler) ListConfig(w http.ResponseWriter, r *http.Req
 	handler.rd.JSON(w, http.StatusOK, conf)
 }
 
+func (handler *evictLeaderHandler) ListProgress(w http.ResponseWriter, r *http.Request) {
+	handler.rd.JSON(w, http.StatusOK, handler.config.getProgress())
+}
+
 func (handler *evictLeaderHandler) DeleteConfig(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["store_id"]
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -370,6 +460,7 @@ func newEvictLeaderHandler(config *evictLeaderSchedulerConfig) http.Handler {
 	router := mux.NewRouter()
 	router.HandleFunc("/config", h.UpdateConfig).Methods("POST")
 	router.HandleFunc("/list", h.ListConfig).Methods("GET")
+	router.HandleFunc("/progress", h.ListProgress).Methods("GET")
 	router.HandleFunc("/delete/{store_id}", h.DeleteConfig).Methods("DELETE")
 	return router
 }
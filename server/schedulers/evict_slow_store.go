@@ -0,0 +1,342 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/filter"
+	"github.com/pingcap/pd/v4/server/schedule/operator"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/selector"
+	"github.com/unrolled/render"
+	"go.uber.org/zap"
+)
+
+const (
+	// EvictSlowStoreName is evict slow store scheduler name.
+	EvictSlowStoreName = "evict-slow-store-scheduler"
+	// EvictSlowStoreType is evict slow store scheduler type.
+	EvictSlowStoreType = "evict-slow-store"
+)
+
+const (
+	// slowStoreEvictThreshold is the slow score from which a store is
+	// considered slow.
+	slowStoreEvictThreshold = 3.0
+	// slowStoreRecoverThreshold is the slow score below which an evicted store
+	// is considered recovered.
+	slowStoreRecoverThreshold = 1.5
+	// busyScoreWeight is the weight of the busy ratio in the slow score.
+	busyScoreWeight = 2.0
+	// minSlowStoreCandidates is the min number of stores to compare with each
+	// other, a store can not be considered slower than its peers with fewer
+	// stores.
+	minSlowStoreCandidates = 3
+)
+
+// slowStoreRecoverTime is the time the slow score of an evicted store should
+// keep recovered before its leaders are allowed to come back.
+var slowStoreRecoverTime = 10 * time.Minute
+
+func init() {
+	schedule.RegisterSliceDecoderBuilder(EvictSlowStoreType, func(args []string) schedule.ConfigDecoder {
+		return func(v interface{}) error {
+			if _, ok := v.(*evictSlowStoreSchedulerConfig); !ok {
+				return ErrScheduleConfigNotExist
+			}
+			return nil
+		}
+	})
+
+	schedule.RegisterScheduler(EvictSlowStoreType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
+		conf := &evictSlowStoreSchedulerConfig{storage: storage}
+		if err := decoder(conf); err != nil {
+			return nil, err
+		}
+		return newEvictSlowStoreScheduler(opController, conf), nil
+	})
+}
+
+type evictSlowStoreSchedulerConfig struct {
+	mu      sync.RWMutex
+	storage *core.Storage
+	// EvictStore is the slow store whose leaders are evicted, 0 means no
+	// store is evicted.
+	EvictStore uint64 `json:"evict-store"`
+}
+
+func (conf *evictSlowStoreSchedulerConfig) getEvictStore() uint64 {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	return conf.EvictStore
+}
+
+func (conf *evictSlowStoreSchedulerConfig) setEvictStore(id uint64) error {
+	conf.mu.Lock()
+	defer conf.mu.Unlock()
+	old := conf.EvictStore
+	conf.EvictStore = id
+	data, err := schedule.EncodeConfig(conf)
+	if err == nil {
+		err = conf.storage.SaveScheduleConfig(EvictSlowStoreName, data)
+	}
+	if err != nil {
+		conf.EvictStore = old // revert
+	}
+	return err
+}
+
+type evictSlowStoreScheduler struct {
+	*BaseScheduler
+	conf     *evictSlowStoreSchedulerConfig
+	selector *selector.RandomSelector
+	handler  http.Handler
+
+	mu sync.RWMutex
+	// scores are the slow scores of stores calculated in the last round.
+	scores map[uint64]float64
+	// recoverSince is the time from which the evicted store keeps recovered.
+	recoverSince time.Time
+}
+
+// newEvictSlowStoreScheduler creates a scheduler that detects the store which
+// is clearly slower than others, and transfers all leaders out of it until it
+// recovers.
+func newEvictSlowStoreScheduler(opController *schedule.OperatorController, conf *evictSlowStoreSchedulerConfig) schedule.Scheduler {
+	filters := []filter.Filter{
+		filter.StoreStateFilter{ActionScope: EvictSlowStoreName, TransferLeader: true},
+	}
+	s := &evictSlowStoreScheduler{
+		BaseScheduler: NewBaseScheduler(opController),
+		conf:          conf,
+		selector:      selector.NewRandomSelector(filters),
+	}
+	s.handler = newEvictSlowStoreHandler(s)
+	return s
+}
+
+func (s *evictSlowStoreScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *evictSlowStoreScheduler) GetName() string {
+	return EvictSlowStoreName
+}
+
+func (s *evictSlowStoreScheduler) GetType() string {
+	return EvictSlowStoreType
+}
+
+func (s *evictSlowStoreScheduler) EncodeConfig() ([]byte, error) {
+	s.conf.mu.RLock()
+	defer s.conf.mu.RUnlock()
+	return schedule.EncodeConfig(s.conf)
+}
+
+func (s *evictSlowStoreScheduler) Prepare(cluster opt.Cluster) error {
+	if id := s.conf.getEvictStore(); id != 0 {
+		return cluster.BlockStore(id)
+	}
+	return nil
+}
+
+func (s *evictSlowStoreScheduler) Cleanup(cluster opt.Cluster) {
+	if id := s.conf.getEvictStore(); id != 0 {
+		cluster.UnblockStore(id)
+	}
+}
+
+func (s *evictSlowStoreScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
+	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
+}
+
+func (s *evictSlowStoreScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
+	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
+	scores := calcSlowScores(cluster)
+	s.mu.Lock()
+	s.scores = scores
+	s.mu.Unlock()
+
+	evictStore := s.conf.getEvictStore()
+	if evictStore != 0 {
+		if s.checkRecovered(cluster, evictStore, scores) {
+			s.recoverStore(cluster, evictStore)
+			return nil
+		}
+		return s.evictLeaders(cluster, evictStore)
+	}
+
+	var slowStores []uint64
+	for id, score := range scores {
+		if score >= slowStoreEvictThreshold {
+			slowStores = append(slowStores, id)
+		}
+	}
+	// If more than one store is slow, the slowness is more likely to be
+	// caused by the workload instead of the stores.
+	if len(slowStores) != 1 {
+		if len(slowStores) > 1 {
+			schedulerCounter.WithLabelValues(s.GetName(), "multiple-slow-stores").Inc()
+		}
+		return nil
+	}
+	if err := s.evictStore(cluster, slowStores[0]); err != nil {
+		log.Warn("failed to evict the slow store", zap.Uint64("store-id", slowStores[0]), zap.Error(err))
+		return nil
+	}
+	return s.evictLeaders(cluster, slowStores[0])
+}
+
+func (s *evictSlowStoreScheduler) evictLeaders(cluster opt.Cluster, storeID uint64) []*operator.Operator {
+	storeRanges := map[uint64][]core.KeyRange{storeID: {core.NewKeyRange("", "")}}
+	return scheduleEvictLeaderBatch(s.GetName(), s.GetType(), cluster, storeRanges, nil, s.selector)
+}
+
+func (s *evictSlowStoreScheduler) evictStore(cluster opt.Cluster, storeID uint64) error {
+	if err := cluster.BlockStore(storeID); err != nil {
+		return err
+	}
+	if err := s.conf.setEvictStore(storeID); err != nil {
+		cluster.UnblockStore(storeID)
+		return err
+	}
+	log.Info("evict leaders of the slow store", zap.Uint64("store-id", storeID))
+	schedulerCounter.WithLabelValues(s.GetName(), "evict-store").Inc()
+	return nil
+}
+
+// checkRecovered checks whether the slow score of the evicted store keeps
+// recovered for enough time. The store is also recovered if it is removed.
+func (s *evictSlowStoreScheduler) checkRecovered(cluster opt.Cluster, storeID uint64, scores map[uint64]float64) bool {
+	if store := cluster.GetStore(storeID); store == nil || store.IsTombstone() {
+		return true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	score, ok := scores[storeID]
+	if !ok || score >= slowStoreRecoverThreshold {
+		s.recoverSince = time.Time{}
+		return false
+	}
+	if s.recoverSince.IsZero() {
+		s.recoverSince = time.Now()
+	}
+	return time.Since(s.recoverSince) >= slowStoreRecoverTime
+}
+
+func (s *evictSlowStoreScheduler) recoverStore(cluster opt.Cluster, storeID uint64) {
+	if err := s.conf.setEvictStore(0); err != nil {
+		log.Warn("failed to recover the slow store", zap.Uint64("store-id", storeID), zap.Error(err))
+		return
+	}
+	cluster.UnblockStore(storeID)
+	s.mu.Lock()
+	s.recoverSince = time.Time{}
+	s.mu.Unlock()
+	log.Info("the slow store is recovered", zap.Uint64("store-id", storeID))
+	schedulerCounter.WithLabelValues(s.GetName(), "recover-store").Inc()
+}
+
+// calcSlowScores calculates the slow scores of the up stores. The slow score
+// of a store is the ratio of its operation latency to the median latency of
+// other stores, plus the ratio of recent heartbeats reporting it is busy
+// multiplied by busyScoreWeight. The score of a normal store is about 1.
+func calcSlowScores(cluster opt.Cluster) map[uint64]float64 {
+	storesStats := cluster.GetStoresStats()
+	latencies := storesStats.GetStoresOpLatency()
+	busyRatios := storesStats.GetStoresBusyRatio()
+
+	var candidates []uint64
+	for _, store := range cluster.GetStores() {
+		if !store.IsUp() || store.IsDisconnected() || store.DownTime() > cluster.GetMaxStoreDownTime() {
+			continue
+		}
+		if _, ok := latencies[store.GetID()]; ok {
+			candidates = append(candidates, store.GetID())
+		}
+	}
+	scores := make(map[uint64]float64, len(candidates))
+	if len(candidates) < minSlowStoreCandidates {
+		return scores
+	}
+	for _, id := range candidates {
+		others := make([]float64, 0, len(candidates)-1)
+		for _, other := range candidates {
+			if other != id {
+				others = append(others, latencies[other])
+			}
+		}
+		latencyRatio := 1.0
+		if median := medianOf(others); median > 0 {
+			latencyRatio = latencies[id] / median
+		}
+		scores[id] = latencyRatio + busyRatios[id]*busyScoreWeight
+	}
+	return scores
+}
+
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+type evictSlowStoreHandler struct {
+	rd        *render.Render
+	scheduler *evictSlowStoreScheduler
+}
+
+// slowStoreState is the state of the evict-slow-store-scheduler.
+type slowStoreState struct {
+	EvictStore uint64             `json:"evict-store"`
+	SlowScores map[uint64]float64 `json:"slow-scores"`
+}
+
+func (handler *evictSlowStoreHandler) ListConfig(w http.ResponseWriter, r *http.Request) {
+	s := handler.scheduler
+	state := &slowStoreState{
+		EvictStore: s.conf.getEvictStore(),
+		SlowScores: make(map[uint64]float64),
+	}
+	s.mu.RLock()
+	for id, score := range s.scores {
+		state.SlowScores[id] = score
+	}
+	s.mu.RUnlock()
+	handler.rd.JSON(w, http.StatusOK, state)
+}
+
+func newEvictSlowStoreHandler(scheduler *evictSlowStoreScheduler) http.Handler {
+	h := &evictSlowStoreHandler{
+		scheduler: scheduler,
+		rd:        render.New(render.Options{IndentJSON: true}),
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/list", h.ListConfig).Methods("GET")
+	return router
+}
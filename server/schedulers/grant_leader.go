@@ -25,7 +25,6 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
-	"github.com/pkg/errors"
 	"github.com/unrolled/render"
 	"go.uber.org/zap"
 )
@@ -40,34 +39,35 @@ const (
 func init() {
 	schedule.RegisterSliceDecoderBuilder(GrantLeaderType, func(args []string) schedule.ConfigDecoder {
 		return func(v interface{}) error {
-			if len(args) != 1 {
-				return errors.New("should specify the store-id")
-			}
-
 			conf, ok := v.(*grantLeaderSchedulerConfig)
 			if !ok {
 				return ErrScheduleConfigNotExist
 			}
-
-			id, err := strconv.ParseUint(args[0], 10, 64)
-			if err != nil {
-				return errors.WithStack(err)
-			}
-			ranges, err := getKeyRanges(args[1:])
+			id, percent, ranges, err := parseStoreLeaderArgs(args)
 			if err != nil {
-				return errors.WithStack(err)
+				return err
 			}
 			conf.StoreIDWithRanges[id] = ranges
+			if percent >= 0 {
+				conf.StoreIDWithLeaderPercent[id] = percent
+			}
 			return nil
 		}
 	})
 
 	schedule.RegisterScheduler(GrantLeaderType, func(opController *schedule.OperatorController, storage *core.Storage, decoder schedule.ConfigDecoder) (schedule.Scheduler, error) {
-		conf := &grantLeaderSchedulerConfig{StoreIDWithRanges: make(map[uint64][]core.KeyRange), storage: storage}
+		conf := &grantLeaderSchedulerConfig{
+			StoreIDWithRanges:        make(map[uint64][]core.KeyRange),
+			StoreIDWithLeaderPercent: make(map[uint64]int),
+			storage:                  storage,
+		}
 		conf.cluster = opController.GetCluster()
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		if conf.StoreIDWithLeaderPercent == nil {
+			conf.StoreIDWithLeaderPercent = make(map[uint64]int)
+		}
 		return newGrantLeaderScheduler(opController, conf), nil
 	})
 }
@@ -76,33 +76,43 @@ type grantLeaderSchedulerConfig struct {
 	mu                sync.RWMutex
 	storage           *core.Storage
 	StoreIDWithRanges map[uint64][]core.KeyRange `json:"store-id-ranges"`
-	cluster           opt.Cluster
+	// StoreIDWithLeaderPercent is the min percent of the regions on the store
+	// to make leaders, only the leaders in the ranges are granted to reach it.
+	// All leaders in the ranges are granted if the store is absent.
+	StoreIDWithLeaderPercent map[uint64]int `json:"store-id-leader-percent,omitempty"`
+	cluster                  opt.Cluster
 }
 
 func (conf *grantLeaderSchedulerConfig) BuildWithArgs(args []string) error {
-	if len(args) != 1 {
-		return errors.New("should specify the store-id")
-	}
-
-	id, err := strconv.ParseUint(args[0], 10, 64)
-	if err != nil {
-		return errors.WithStack(err)
-	}
-	ranges, err := getKeyRanges(args[1:])
+	id, percent, ranges, err := parseStoreLeaderArgs(args)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
 	conf.mu.Lock()
 	defer conf.mu.Unlock()
 	conf.StoreIDWithRanges[id] = ranges
+	if percent >= 0 {
+		conf.StoreIDWithLeaderPercent[id] = percent
+	} else {
+		delete(conf.StoreIDWithLeaderPercent, id)
+	}
 	return nil
 }
 
 func (conf *grantLeaderSchedulerConfig) Clone() *grantLeaderSchedulerConfig {
 	conf.mu.RLock()
 	defer conf.mu.RUnlock()
+	storeIDWithRanges := make(map[uint64][]core.KeyRange, len(conf.StoreIDWithRanges))
+	for id, ranges := range conf.StoreIDWithRanges {
+		storeIDWithRanges[id] = ranges
+	}
+	storeIDWithLeaderPercent := make(map[uint64]int, len(conf.StoreIDWithLeaderPercent))
+	for id, percent := range conf.StoreIDWithLeaderPercent {
+		storeIDWithLeaderPercent[id] = percent
+	}
 	return &grantLeaderSchedulerConfig{
-		StoreIDWithRanges: conf.StoreIDWithRanges,
+		StoreIDWithRanges:        storeIDWithRanges,
+		StoreIDWithLeaderPercent: storeIDWithLeaderPercent,
 	}
 }
 
@@ -125,13 +135,29 @@ func (conf *grantLeaderSchedulerConfig) getSchedulerName() string {
 func (conf *grantLeaderSchedulerConfig) getRanges(id uint64) []string {
 	conf.mu.RLock()
 	defer conf.mu.RUnlock()
-	var res []string
-	ranges := conf.StoreIDWithRanges[id]
-	for index := range ranges {
-		res = append(res, (string)(ranges[index].StartKey))
-		res = append(res, (string)(ranges[index].EndKey))
+	return encodeKeyRanges(conf.StoreIDWithRanges[id])
+}
+
+func (conf *grantLeaderSchedulerConfig) getLeaderPercent(id uint64) (int, bool) {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	percent, ok := conf.StoreIDWithLeaderPercent[id]
+	return percent, ok
+}
+
+// getProgress returns the progress of granting leaders to each store.
+func (conf *grantLeaderSchedulerConfig) getProgress() map[uint64]*leaderProgress {
+	conf.mu.RLock()
+	defer conf.mu.RUnlock()
+	progress := make(map[uint64]*leaderProgress, len(conf.StoreIDWithRanges))
+	for id := range conf.StoreIDWithRanges {
+		percent, ok := conf.StoreIDWithLeaderPercent[id]
+		if !ok {
+			percent = 100
+		}
+		progress[id] = calcGrantLeaderProgress(conf.cluster, id, percent)
 	}
-	return res
+	return progress
 }
 
 func (conf *grantLeaderSchedulerConfig) mayBeRemoveStoreFromConfig(id uint64) (succ bool, last bool) {
@@ -141,6 +167,7 @@ func (conf *grantLeaderSchedulerConfig) mayBeRemoveStoreFromConfig(id uint64) (s
 	succ, last = false, false
 	if exists {
 		delete(conf.StoreIDWithRanges, id)
+		delete(conf.StoreIDWithLeaderPercent, id)
 		conf.cluster.UnblockStore(id)
 		succ = true
 		last = len(conf.StoreIDWithRanges) == 0
@@ -156,7 +183,7 @@ type grantLeaderScheduler struct {
 }
 
 // newGrantLeaderScheduler creates an admin scheduler that transfers all leaders
-// to a store.
+// to a store, or only makes a percent of regions on the store leaders.
 func newGrantLeaderScheduler(opController *schedule.OperatorController, conf *grantLeaderSchedulerConfig) schedule.Scheduler {
 	base := NewBaseScheduler(opController)
 	handler := newGrantLeaderHandler(conf)
@@ -210,9 +237,16 @@ func (s *grantLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
 func (s *grantLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
 	schedulerCounter.WithLabelValues(s.GetName(), "schedule").Inc()
 	var ops []*operator.Operator
-	s.conf.mu.RLock()
-	defer s.conf.mu.RUnlock()
-	for id, ranges := range s.conf.StoreIDWithRanges {
+	conf := s.conf.Clone()
+	for id, ranges := range conf.StoreIDWithRanges {
+		if percent, ok := conf.StoreIDWithLeaderPercent[id]; ok {
+			// The leaders being transferred in are counted as granted.
+			p := calcGrantLeaderProgress(cluster, id, percent)
+			if p.Remaining <= countRunningLeaderOperators(s.OpController, s.GetType(), id, false) {
+				schedulerCounter.WithLabelValues(s.GetName(), "reach-target").Inc()
+				continue
+			}
+		}
 		region := cluster.RandFollowerRegion(id, ranges, opt.HealthRegion(cluster))
 		if region == nil {
 			schedulerCounter.WithLabelValues(s.GetName(), "no-follower").Inc()
@@ -248,22 +282,36 @@ func (handler *grantLeaderHandler) UpdateConfig(w http.ResponseWriter, r *http.R
 	idFloat, ok := input["store_id"].(float64)
 	if ok {
 		id = (uint64)(idFloat)
-		if _, exists = handler.config.StoreIDWithRanges[id]; !exists {
-			if err := handler.config.cluster.BlockStore(id); err != nil {
-				handler.rd.JSON(w, http.StatusInternalServerError, err)
-				return
-			}
-		}
+		_, exists = handler.config.StoreIDWithRanges[id]
 		args = append(args, strconv.FormatUint(id, 10))
 	}
 
-	ranges, ok := (input["ranges"]).([]string)
-	if ok {
-		args = append(args, ranges...)
+	if percent, ok := input["leader_percent"].(float64); ok {
+		args = append(args, strconv.Itoa(int(percent))+"%")
+	} else if percent, ok := handler.config.getLeaderPercent(id); ok && exists {
+		args = append(args, strconv.Itoa(percent)+"%")
+	}
+	if ranges, ok := input["ranges"]; ok {
+		keys, err := parseRangesInput(ranges)
+		if err != nil {
+			handler.rd.JSON(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		args = append(args, keys...)
 	} else if exists {
 		args = append(args, handler.config.getRanges(id)...)
 	}
 
+	if _, _, _, err := parseStoreLeaderArgs(args); err != nil {
+		handler.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !exists {
+		if err := handler.config.cluster.BlockStore(id); err != nil {
+			handler.rd.JSON(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
 	handler.config.BuildWithArgs(args)
 	err := handler.config.Persist()
 	if err != nil {
@@ -278,6 +326,10 @@ func (handler *grantLeaderHandler) ListConfig(w http.ResponseWriter, r *http.Req
 	handler.rd.JSON(w, http.StatusOK, conf)
 }
 
+func (handler *grantLeaderHandler) ListProgress(w http.ResponseWriter, r *http.Request) {
+	handler.rd.JSON(w, http.StatusOK, handler.config.getProgress())
+}
+
 func (handler *grantLeaderHandler) DeleteConfig(w http.ResponseWriter, r *http.Request) {
 	idStr := mux.Vars(r)["store_id"]
 	id, err := strconv.ParseUint(idStr, 10, 64)
@@ -320,6 +372,7 @@ func newGrantLeaderHandler(config *grantLeaderSchedulerConfig) http.Handler {
 	router := mux.NewRouter()
 	router.HandleFunc("/config", h.UpdateConfig).Methods("POST")
 	router.HandleFunc("/list", h.ListConfig).Methods("GET")
+	router.HandleFunc("/progress", h.ListProgress).Methods("GET")
 	router.HandleFunc("/delete/{store_id}", h.DeleteConfig).Methods("DELETE")
 	return router
 }
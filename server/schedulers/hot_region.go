@@ -31,6 +31,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
 	"github.com/pingcap/pd/v4/server/statistics"
+	"github.com/pkg/errors"
 	"github.com/prometheus/client_golang/prometheus"
 	"go.uber.org/zap"
 )
@@ -85,6 +86,45 @@ const (
 // schedulePeerPr the probability of schedule the hot peer.
 var schedulePeerPr = 0.66
 
+// The dimensions of the load of stores and hot peers.
+const (
+	byteDim = iota
+	keyDim
+	cpuDim
+	dimLen
+)
+
+const (
+	byteDimName = "byte"
+	keyDimName  = "key"
+	cpuDimName  = "cpu"
+)
+
+var dimNames = [dimLen]string{byteDimName, keyDimName, cpuDimName}
+
+// parsePriorities parses the pair of dimensions in the order of priority.
+func parsePriorities(priorities []string) ([2]int, error) {
+	var dims [2]int
+	if len(priorities) != 2 {
+		return dims, errors.Errorf("priorities should be a pair of dimensions, but got %v", priorities)
+	}
+	for i, name := range priorities {
+		dims[i] = -1
+		for dim := range dimNames {
+			if dimNames[dim] == name {
+				dims[i] = dim
+			}
+		}
+		if dims[i] < 0 {
+			return dims, errors.Errorf("unknown dimension %s, should be one of %v", name, dimNames)
+		}
+	}
+	if dims[0] == dims[1] {
+		return dims, errors.Errorf("duplicated dimension %s", priorities[0])
+	}
+	return dims, nil
+}
+
 type hotScheduler struct {
 	name string
 	*BaseScheduler
@@ -196,6 +236,7 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 	storesStat := cluster.GetStoresStats()
 
 	minHotDegree := cluster.GetHotRegionCacheHitsThreshold()
+	storeCPU := storesStat.GetStoresCPUUsage()
 	{ // update read statistics
 		regionRead := cluster.RegionReadStats()
 		storeByte := storesStat.GetStoresBytesReadStat()
@@ -204,6 +245,7 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 		h.stLoadInfos[readLeader] = summaryStoresLoad(
 			storeByte,
 			storeKey,
+			storeCPU,
 			h.pendingSums[readLeader],
 			regionRead,
 			minHotDegree,
@@ -219,6 +261,7 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 		h.stLoadInfos[writeLeader] = summaryStoresLoad(
 			storeByte,
 			storeKey,
+			storeCPU,
 			h.pendingSums[writeLeader],
 			regionWrite,
 			minHotDegree,
@@ -228,6 +271,7 @@ func (h *hotScheduler) prepareForBalance(cluster opt.Cluster) {
 		h.stLoadInfos[writePeer] = summaryStoresLoad(
 			storeByte,
 			storeKey,
+			storeCPU,
 			h.pendingSums[writePeer],
 			regionWrite,
 			minHotDegree,
@@ -297,6 +341,7 @@ func (h *hotScheduler) gcRegionPendings() {
 func summaryStoresLoad(
 	storeByteRate map[uint64]float64,
 	storeKeyRate map[uint64]float64,
+	storeCPU map[uint64]float64,
 	pendings map[uint64]Influence,
 	storeHotPeers map[uint64][]*statistics.HotPeerStat,
 	minHotDegree int,
@@ -308,11 +353,17 @@ func summaryStoresLoad(
 	loadDetail := make(map[uint64]*storeLoadDetail, len(storeByteRate))
 	allByteSum := 0.0
 	allKeySum := 0.0
+	allCPUSum := 0.0
 	allCount := 0.0
 
 	// Stores without byte rate statistics is not available to schedule.
 	for id, byteRate := range storeByteRate {
 		keyRate := storeKeyRate[id]
+		cpu := storeCPU[id]
+		cpuPerKey := 0.0
+		if keyRate > 0 {
+			cpuPerKey = cpu / keyRate
+		}
 
 		// Find all hot peers first
 		hotPeers := make([]*statistics.HotPeerStat, 0)
@@ -328,6 +379,7 @@ func summaryStoresLoad(
 			if kind == core.LeaderKind && rwTy == write {
 				byteRate = byteSum
 				keyRate = keySum
+				cpu = keySum * cpuPerKey
 			}
 
 			// Metric for debug.
@@ -342,19 +394,22 @@ func summaryStoresLoad(
 		}
 		allByteSum += byteRate
 		allKeySum += keyRate
+		allCPUSum += cpu
 		allCount += float64(len(hotPeers))
 
 		// Build store load prediction from current load and pending influence.
 		stLoadPred := (&storeLoad{
 			ByteRate: byteRate,
 			KeyRate:  keyRate,
+			CPU:      cpu,
 			Count:    float64(len(hotPeers)),
 		}).ToLoadPred(pendings[id])
 
 		// Construct store load info.
 		loadDetail[id] = &storeLoadDetail{
-			LoadPred: stLoadPred,
-			HotPeers: hotPeers,
+			LoadPred:  stLoadPred,
+			HotPeers:  hotPeers,
+			CPUPerKey: cpuPerKey,
 		}
 	}
 	storeLen := float64(len(storeByteRate))
@@ -362,9 +417,11 @@ func summaryStoresLoad(
 	for id, detail := range loadDetail {
 		byteExp := allByteSum / storeLen
 		keyExp := allKeySum / storeLen
+		cpuExp := allCPUSum / storeLen
 		countExp := allCount / storeLen
 		detail.LoadPred.Future.ExpByteRate = byteExp
 		detail.LoadPred.Future.ExpKeyRate = keyExp
+		detail.LoadPred.Future.ExpCPU = cpuExp
 		detail.LoadPred.Future.ExpCount = countExp
 		// Debug
 		{
@@ -494,6 +551,12 @@ type balanceSolver struct {
 	maxSrc   *storeLoad
 	minDst   *storeLoad
 	rankStep *storeLoad
+
+	// byPriorities is true if the dimensions are not the default ones, then
+	// stores and peers are ranked by firstPriority and secondPriority.
+	byPriorities   bool
+	firstPriority  int
+	secondPriority int
 }
 
 type solution struct {
@@ -525,6 +588,7 @@ func (bs *balanceSolver) init() {
 	bs.minDst = &storeLoad{
 		ByteRate: math.MaxFloat64,
 		KeyRate:  math.MaxFloat64,
+		CPU:      math.MaxFloat64,
 		Count:    math.MaxFloat64,
 	}
 	maxCur := &storeLoad{}
@@ -538,8 +602,19 @@ func (bs *balanceSolver) init() {
 	bs.rankStep = &storeLoad{
 		ByteRate: maxCur.ByteRate * bs.sche.conf.GetByteRankStepRatio(),
 		KeyRate:  maxCur.KeyRate * bs.sche.conf.GetKeyRankStepRatio(),
+		CPU:      maxCur.CPU * bs.sche.conf.GetCPURankStepRatio(),
 		Count:    maxCur.Count * bs.sche.conf.GetCountRankStepRatio(),
 	}
+
+	priorities := bs.sche.conf.GetReadPriorities()
+	if bs.rwTy == write {
+		priorities = bs.sche.conf.GetWritePriorities()
+	}
+	// The priorities are validated when they are set.
+	if dims, err := parsePriorities(priorities); err == nil && dims != [2]int{byteDim, keyDim} {
+		bs.byPriorities = true
+		bs.firstPriority, bs.secondPriority = dims[0], dims[1]
+	}
 }
 
 func getUnhealthyStores(cluster opt.Cluster) []uint64 {
@@ -647,8 +722,7 @@ func (bs *balanceSolver) filterSrcStores() map[uint64]*storeLoadDetail {
 		if len(detail.HotPeers) == 0 {
 			continue
 		}
-		if detail.LoadPred.min().ByteRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.ExpByteRate &&
-			detail.LoadPred.min().KeyRate > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.ExpKeyRate {
+		if bs.isSrcStoreHot(detail) {
 			ret[id] = detail
 			balanceHotRegionCounter.WithLabelValues("src-store-succ", strconv.FormatUint(id, 10)).Inc()
 		}
@@ -736,6 +810,11 @@ func (bs *balanceSolver) isRegionAvailable(region *core.RegionInfo) bool {
 		return false
 	}
 
+	if !filter.RegionScheduleAllowed(bs.cluster)(region) {
+		schedulerCounter.WithLabelValues(bs.sche.GetName(), "schedule-deny").Inc()
+		return false
+	}
+
 	if !opt.IsRegionReplicated(bs.cluster, region) {
 		log.Debug("region has abnormal replica count", zap.String("scheduler", bs.sche.GetName()), zap.Uint64("region-id", region.GetID()))
 		schedulerCounter.WithLabelValues(bs.sche.GetName(), "abnormal-replica").Inc()
@@ -816,8 +895,18 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 	for _, store := range candidates {
 		if filter.Target(bs.cluster, store, filters) {
 			detail := bs.stLoadDetail[store.GetID()]
-			if detail.LoadPred.max().ByteRate*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.ExpByteRate &&
-				detail.LoadPred.max().KeyRate*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.ExpKeyRate {
+			if bs.byPriorities {
+				// Only the stores whose first priority is cold enough can be
+				// the destination.
+				if bs.isDstStoreCold(detail, bs.firstPriority) {
+					ret[store.GetID()] = detail
+					balanceHotRegionCounter.WithLabelValues("dst-store-succ", strconv.FormatUint(store.GetID(), 10)).Inc()
+				} else {
+					balanceHotRegionCounter.WithLabelValues("dst-store-fail", strconv.FormatUint(store.GetID(), 10)).Inc()
+				}
+				continue
+			}
+			if bs.isDstStoreCold(detail, byteDim) && bs.isDstStoreCold(detail, keyDim) {
 				ret[store.GetID()] = bs.stLoadDetail[store.GetID()]
 				balanceHotRegionCounter.WithLabelValues("dst-store-succ", strconv.FormatUint(store.GetID(), 10)).Inc()
 			}
@@ -828,6 +917,54 @@ func (bs *balanceSolver) filterDstStores() map[uint64]*storeLoadDetail {
 	return ret
 }
 
+// isSrcStoreHot checks whether the load of the store is higher than the
+// expectation enough to be the source store.
+func (bs *balanceSolver) isSrcStoreHot(detail *storeLoadDetail) bool {
+	isHot := func(dim int) bool {
+		return detail.LoadPred.min().get(dim) > bs.sche.conf.GetSrcToleranceRatio()*detail.LoadPred.Future.getExp(dim)
+	}
+	if bs.byPriorities {
+		return isHot(bs.firstPriority)
+	}
+	return isHot(byteDim) && isHot(keyDim)
+}
+
+// isDstStoreCold checks whether the load of the dimension of the store is
+// lower than the expectation enough to be the destination store.
+func (bs *balanceSolver) isDstStoreCold(detail *storeLoadDetail, dim int) bool {
+	return detail.LoadPred.max().get(dim)*bs.sche.conf.GetDstToleranceRatio() < detail.LoadPred.Future.getExp(dim)
+}
+
+// getPeerLoad returns the load of the dimension of the peer. The cpu usage of
+// a peer is estimated by its key rate and the cpu usage per key of its store.
+func (bs *balanceSolver) getPeerLoad(peer *statistics.HotPeerStat, dim int) float64 {
+	switch dim {
+	case byteDim:
+		return peer.GetByteRate()
+	case keyDim:
+		return peer.GetKeyRate()
+	case cpuDim:
+		if detail, ok := bs.stLoadDetail[peer.StoreID]; ok {
+			return peer.GetKeyRate() * detail.CPUPerKey
+		}
+	}
+	return 0
+}
+
+// isPeerHot checks whether the load of the dimension of the source peer is
+// high enough to be scheduled.
+func (bs *balanceSolver) isPeerHot(dim int) bool {
+	load := bs.getPeerLoad(bs.cur.srcPeerStat, dim)
+	switch dim {
+	case byteDim:
+		return load > bs.sche.conf.GetMinHotByteRate()
+	case keyDim:
+		return load >= bs.sche.conf.GetMinHotKeyRate()
+	default:
+		return load > 0
+	}
+}
+
 // calcProgressiveRank calculates `bs.cur.progressiveRank`.
 // See the comments of `solution.progressiveRank` for more about progressive rank.
 func (bs *balanceSolver) calcProgressiveRank() {
@@ -835,6 +972,10 @@ func (bs *balanceSolver) calcProgressiveRank() {
 	dstLd := bs.stLoadDetail[bs.cur.dstStoreID].LoadPred.max()
 	peer := bs.cur.srcPeerStat
 	rank := int64(0)
+	if bs.byPriorities {
+		bs.cur.progressiveRank = bs.calcProgressiveRankByPriorities(srcLd, dstLd)
+		return
+	}
 	if bs.rwTy == write && bs.opTy == transferLeader {
 		// In this condition, CPU usage is the matter.
 		// Only consider about key rate.
@@ -868,6 +1009,34 @@ func (bs *balanceSolver) calcProgressiveRank() {
 	bs.cur.progressiveRank = rank
 }
 
+// calcProgressiveRankByPriorities calculates the progressive rank by the
+// dimensions in the order of priority. A solution is only acceptable if it
+// balances the first priority and does not make the second priority worse.
+func (bs *balanceSolver) calcProgressiveRankByPriorities(srcLd, dstLd *storeLoad) int64 {
+	getDecRatio := func(dim int) float64 {
+		peerLoad := bs.getPeerLoad(bs.cur.srcPeerStat, dim)
+		srcDec := srcLd.get(dim) - peerLoad
+		if srcDec <= 0 {
+			srcDec = 1
+		}
+		return (dstLd.get(dim) + peerLoad) / srcDec
+	}
+	first, second := bs.firstPriority, bs.secondPriority
+	greatDecRatio := bs.sche.conf.GetGreatDecRatio()
+	if !bs.isPeerHot(first) || getDecRatio(first) > greatDecRatio {
+		return 0
+	}
+	switch {
+	case bs.isPeerHot(second) && getDecRatio(second) <= greatDecRatio:
+		// Both dimensions are balanced, the best choice.
+		return -2
+	case dstLd.get(second)+bs.getPeerLoad(bs.cur.srcPeerStat, second) <= math.Max(srcLd.get(second), dstLd.get(second)):
+		// The first priority is balanced, and the second one is not worsened.
+		return -1
+	}
+	return 0
+}
+
 // betterThan checks if `bs.cur` is a better solution than `old`.
 func (bs *balanceSolver) betterThan(old *solution) bool {
 	if old == nil {
@@ -896,6 +1065,11 @@ func (bs *balanceSolver) betterThan(old *solution) bool {
 	if bs.cur.srcPeerStat != old.srcPeerStat {
 		// compare region
 
+		if bs.byPriorities {
+			// prefer region with larger load of the first priority, to converge faster
+			return bs.getPeerLoad(bs.cur.srcPeerStat, bs.firstPriority) > bs.getPeerLoad(old.srcPeerStat, bs.firstPriority)
+		}
+
 		if bs.rwTy == write && bs.opTy == transferLeader {
 			switch {
 			case bs.cur.srcPeerStat.GetKeyRate() > old.srcPeerStat.GetKeyRate():
@@ -938,7 +1112,18 @@ func (bs *balanceSolver) compareSrcStore(st1, st2 uint64) int {
 	if st1 != st2 {
 		// compare source store
 		var lpCmp storeLPCmp
-		if bs.rwTy == write && bs.opTy == transferLeader {
+		if bs.byPriorities {
+			first, second := bs.firstPriority, bs.secondPriority
+			lpCmp = sliceLPCmp(
+				minLPCmp(negLoadCmp(sliceLoadCmp(
+					stLdRankCmp(stLdDim(first), stepRank(bs.maxSrc.get(first), bs.rankStep.get(first))),
+					stLdRankCmp(stLdDim(second), stepRank(bs.maxSrc.get(second), bs.rankStep.get(second))),
+				))),
+				diffCmp(
+					stLdRankCmp(stLdDim(first), stepRank(0, bs.rankStep.get(first))),
+				),
+			)
+		} else if bs.rwTy == write && bs.opTy == transferLeader {
 			lpCmp = sliceLPCmp(
 				minLPCmp(negLoadCmp(sliceLoadCmp(
 					stLdRankCmp(stLdKeyRate, stepRank(bs.maxSrc.KeyRate, bs.rankStep.KeyRate)),
@@ -974,7 +1159,18 @@ func (bs *balanceSolver) compareDstStore(st1, st2 uint64) int {
 	if st1 != st2 {
 		// compare destination store
 		var lpCmp storeLPCmp
-		if bs.rwTy == write && bs.opTy == transferLeader {
+		if bs.byPriorities {
+			first, second := bs.firstPriority, bs.secondPriority
+			lpCmp = sliceLPCmp(
+				maxLPCmp(sliceLoadCmp(
+					stLdRankCmp(stLdDim(first), stepRank(bs.minDst.get(first), bs.rankStep.get(first))),
+					stLdRankCmp(stLdDim(second), stepRank(bs.minDst.get(second), bs.rankStep.get(second))),
+				)),
+				diffCmp(
+					stLdRankCmp(stLdDim(first), stepRank(0, bs.rankStep.get(first))),
+				),
+			)
+		} else if bs.rwTy == write && bs.opTy == transferLeader {
 			lpCmp = sliceLPCmp(
 				maxLPCmp(sliceLoadCmp(
 					stLdRankCmp(stLdKeyRate, stepRank(bs.minDst.KeyRate, bs.rankStep.KeyRate)),
@@ -1078,6 +1274,7 @@ func (bs *balanceSolver) buildOperators() ([]*operator.Operator, []Influence) {
 	infl := Influence{
 		ByteRate: bs.cur.srcPeerStat.GetByteRate(),
 		KeyRate:  bs.cur.srcPeerStat.GetKeyRate(),
+		CPU:      bs.getPeerLoad(bs.cur.srcPeerStat, cpuDim),
 		Count:    1,
 	}
 
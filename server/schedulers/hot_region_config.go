@@ -38,12 +38,15 @@ func initHotRegionScheduleConfig() *hotRegionSchedulerConfig {
 		MaxZombieRounds:       3,
 		ByteRateRankStepRatio: 0.05,
 		KeyRateRankStepRatio:  0.05,
+		CPURankStepRatio:      0.05,
 		CountRankStepRatio:    0.01,
 		GreatDecRatio:         0.95,
 		MinorDecRatio:         0.99,
 		MaxPeerNum:            1000,
 		SrcToleranceRatio:     1.05, // Tolerate 5% difference
 		DstToleranceRatio:     1.05, // Tolerate 5% difference
+		ReadPriorities:        []string{byteDimName, keyDimName},
+		WritePriorities:       []string{byteDimName, keyDimName},
 	}
 }
 
@@ -60,11 +63,19 @@ type hotRegionSchedulerConfig struct {
 	// step = max current * rank step ratio
 	ByteRateRankStepRatio float64 `json:"byte-rate-rank-step-ratio"`
 	KeyRateRankStepRatio  float64 `json:"key-rate-rank-step-ratio"`
+	CPURankStepRatio      float64 `json:"cpu-rank-step-ratio"`
 	CountRankStepRatio    float64 `json:"count-rank-step-ratio"`
 	GreatDecRatio         float64 `json:"great-dec-ratio"`
 	MinorDecRatio         float64 `json:"minor-dec-ratio"`
 	SrcToleranceRatio     float64 `json:"src-tolerance-ratio"`
 	DstToleranceRatio     float64 `json:"dst-tolerance-ratio"`
+
+	// ReadPriorities and WritePriorities are the pairs of dimensions to
+	// balance, which can be "byte", "key" or "cpu". The stores are ranked by
+	// the first dimension, and a region is moved only if it balances the
+	// first dimension without making the second one worse.
+	ReadPriorities  []string `json:"read-priorities"`
+	WritePriorities []string `json:"write-priorities"`
 }
 
 func (conf *hotRegionSchedulerConfig) EncodeConfig() ([]byte, error) {
@@ -121,6 +132,39 @@ func (conf *hotRegionSchedulerConfig) GetKeyRankStepRatio() float64 {
 	return conf.KeyRateRankStepRatio
 }
 
+func (conf *hotRegionSchedulerConfig) GetCPURankStepRatio() float64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.CPURankStepRatio
+}
+
+func (conf *hotRegionSchedulerConfig) GetReadPriorities() []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	return append(conf.ReadPriorities[:0:0], conf.ReadPriorities...)
+}
+
+func (conf *hotRegionSchedulerConfig) GetWritePriorities() []string {
+	conf.RLock()
+	defer conf.RUnlock()
+	return append(conf.WritePriorities[:0:0], conf.WritePriorities...)
+}
+
+func (conf *hotRegionSchedulerConfig) SetPriorities(read, write []string) {
+	conf.Lock()
+	defer conf.Unlock()
+	conf.ReadPriorities, conf.WritePriorities = read, write
+}
+
+func (conf *hotRegionSchedulerConfig) validate() error {
+	for _, priorities := range [][]string{conf.ReadPriorities, conf.WritePriorities} {
+		if _, err := parsePriorities(priorities); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (conf *hotRegionSchedulerConfig) GetCountRankStepRatio() float64 {
 	conf.RLock()
 	defer conf.RUnlock()
@@ -181,6 +225,13 @@ func (conf *hotRegionSchedulerConfig) handleSetConfig(w http.ResponseWriter, r *
 		rd.JSON(w, http.StatusInternalServerError, err.Error())
 		return
 	}
+	if err := conf.validate(); err != nil {
+		// Restore the old config, the fields absent from oldc are never
+		// changed.
+		_ = json.Unmarshal(oldc, conf)
+		rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
 	newc, _ := json.Marshal(conf)
 	if !bytes.Equal(oldc, newc) {
 		conf.persist()
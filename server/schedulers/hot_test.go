@@ -632,6 +632,57 @@ func (s *testHotReadRegionSchedulerSuite) TestWithKeyRate(c *C) {
 	}
 }
 
+func (s *testHotReadRegionSchedulerSuite) TestWithPriorities(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statistics.Denoising = false
+	opt := mockoption.NewScheduleOptions()
+	hb, err := schedule.CreateScheduler(HotReadRegionType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), nil)
+	c.Assert(err, IsNil)
+	opt.HotRegionCacheHitsThreshold = 0
+
+	tc := mockcluster.NewCluster(opt)
+	tc.AddRegionStore(1, 20)
+	tc.AddRegionStore(2, 20)
+	tc.AddRegionStore(3, 20)
+	tc.AddRegionStore(4, 20)
+	tc.AddRegionStore(5, 20)
+
+	//| store_id | read_bytes_rate | read_keys_rate | cpu_usage |
+	//|----------|-----------------|----------------|-----------|
+	//|    1     |       10MB      |       9MB      |     90    |
+	//|    2     |      8.5MB      |       9MB      |     50    |
+	//|    3     |      8.5MB      |       9MB      |     40    |
+	//|    4     |      8.5MB      |       9MB      |     50    |
+	//|    5     |      8.5MB      |       9MB      |     50    |
+	tc.UpdateStorageReadStats(1, 10*MB*statistics.StoreHeartBeatReportInterval, 9*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStoreCPUUsage(1, 90)
+	for _, id := range []uint64{2, 3, 4, 5} {
+		tc.UpdateStorageReadStats(id, 8.5*MB*statistics.StoreHeartBeatReportInterval, 9*MB*statistics.StoreHeartBeatReportInterval)
+		tc.UpdateStoreCPUUsage(id, 50)
+	}
+	tc.UpdateStoreCPUUsage(3, 40)
+
+	addRegionInfo(tc, read, []testRegionInfo{
+		{1, []uint64{1, 2, 3}, 1 * MB, 1 * MB},
+		{2, []uint64{2, 1, 3}, 0.5 * MB, 0.5 * MB},
+	})
+
+	// The key rate is balanced, so the default priorities do nothing.
+	c.Assert(hb.Schedule(tc), HasLen, 0)
+
+	// Balance the cpu usage first, the byte rate should not be worsened.
+	hb.(*hotScheduler).conf.SetPriorities([]string{cpuDimName, byteDimName}, []string{byteDimName, keyDimName})
+	op := hb.Schedule(tc)[0]
+	testutil.CheckTransferLeader(c, op, operator.OpHotRegion, 1, 3)
+
+	// The byte rate of store 1 is not high enough to accept the region.
+	hb.(*hotScheduler).clearPendingInfluence()
+	tc.UpdateStorageReadStats(1, 9*MB*statistics.StoreHeartBeatReportInterval, 9*MB*statistics.StoreHeartBeatReportInterval)
+	tc.UpdateStoreCPUUsage(1, 90)
+	c.Assert(hb.Schedule(tc), HasLen, 0)
+}
+
 func (s *testHotReadRegionSchedulerSuite) TestWithPendingInfluence(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
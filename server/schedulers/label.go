@@ -14,6 +14,8 @@
 package schedulers
 
 import (
+	"net/http"
+
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
@@ -54,19 +56,21 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.init(storage)
 		return newLabelScheduler(opController, conf), nil
 	})
 }
 
 type labelSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	onlineConfig
+	Name string `json:"name"`
 }
 
 type labelScheduler struct {
 	*BaseScheduler
 	conf     *labelSchedulerConfig
 	selector *selector.BalanceSelector
+	handler  http.Handler
 }
 
 // LabelScheduler is mainly based on the store's label information for scheduling.
@@ -77,11 +81,13 @@ func newLabelScheduler(opController *schedule.OperatorController, conf *labelSch
 		filter.StoreStateFilter{ActionScope: LabelName, TransferLeader: true},
 	}
 	kind := core.NewScheduleKind(core.LeaderKind, core.ByCount)
-	return &labelScheduler{
+	s := &labelScheduler{
 		BaseScheduler: NewBaseScheduler(opController),
 		conf:          conf,
 		selector:      selector.NewBalanceSelector(kind, filters),
 	}
+	s.handler = newOnlineConfigHandler(conf.Name, conf.storage, conf)
+	return s
 }
 
 func (s *labelScheduler) GetName() string {
@@ -93,11 +99,19 @@ func (s *labelScheduler) GetType() string {
 }
 
 func (s *labelScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(s.conf)
+	return encodeOnlineConfig(s.conf)
+}
+
+func (s *labelScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *labelScheduler) GetBatch() int {
+	return s.conf.getBatch()
 }
 
 func (s *labelScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
+	return s.OpController.OperatorCount(operator.OpLeader) < s.conf.getOperatorLimit(cluster.GetLeaderScheduleLimit())
 }
 
 func (s *labelScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
@@ -115,7 +129,7 @@ func (s *labelScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
 	}
 	log.Debug("label scheduler reject leader store list", zap.Reflect("stores", rejectLeaderStores))
 	for id := range rejectLeaderStores {
-		if region := cluster.RandLeaderRegion(id, s.conf.Ranges); region != nil {
+		if region := cluster.RandLeaderRegion(id, s.conf.getRanges()); region != nil {
 			log.Debug("label scheduler selects region to transfer leader", zap.Uint64("region-id", region.GetID()))
 			excludeStores := make(map[uint64]struct{})
 			for _, p := range region.GetDownPeers() {
@@ -0,0 +1,285 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schedulers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule"
+	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pkg/errors"
+	"github.com/unrolled/render"
+)
+
+const (
+	defaultScheduleBatch = 1
+	maxScheduleBatch     = 10
+)
+
+// onlineConfig is embedded in the configs of the schedulers working in key
+// ranges. Its fields, as well as the fields of the outer config, can be
+// changed online through the scheduler config API.
+type onlineConfig struct {
+	sync.RWMutex
+	storage *core.Storage
+
+	Ranges []core.KeyRange `json:"ranges"`
+	// Batch is the max times to schedule in a round of scheduling.
+	Batch int `json:"batch"`
+	// OperatorLimit replaces the schedule limit of the cluster for the
+	// scheduler if it is not 0.
+	OperatorLimit uint64 `json:"operator-limit"`
+}
+
+func (conf *onlineConfig) init(storage *core.Storage) {
+	conf.storage = storage
+	if conf.Batch == 0 {
+		conf.Batch = defaultScheduleBatch
+	}
+}
+
+func (conf *onlineConfig) getRanges() []core.KeyRange {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.Ranges
+}
+
+func (conf *onlineConfig) getBatch() int {
+	conf.RLock()
+	defer conf.RUnlock()
+	if conf.Batch <= 0 {
+		return defaultScheduleBatch
+	}
+	return conf.Batch
+}
+
+// getOperatorLimit returns the operator limit of the scheduler, the limit of
+// the cluster is used if the scheduler has no limit.
+func (conf *onlineConfig) getOperatorLimit(clusterLimit uint64) uint64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	if conf.OperatorLimit == 0 {
+		return clusterLimit
+	}
+	return conf.OperatorLimit
+}
+
+func (conf *onlineConfig) validate() error {
+	if conf.Batch < 1 || conf.Batch > maxScheduleBatch {
+		return errors.Errorf("batch should be in [1, %d]", maxScheduleBatch)
+	}
+	if len(conf.Ranges) == 0 {
+		return errors.New("ranges should not be empty")
+	}
+	for _, r := range conf.Ranges {
+		if len(r.EndKey) > 0 && bytes.Compare(r.StartKey, r.EndKey) >= 0 {
+			return errors.Errorf("invalid range [%q, %q)", r.StartKey, r.EndKey)
+		}
+	}
+	return nil
+}
+
+// tolerantCluster replaces the tolerant size ratio of the cluster with the one
+// of a scheduler.
+type tolerantCluster struct {
+	opt.Cluster
+	tolerantSizeRatio float64
+}
+
+// withTolerantSizeRatio returns the cluster using the tolerant size ratio if
+// it is not 0.
+func withTolerantSizeRatio(cluster opt.Cluster, ratio float64) opt.Cluster {
+	if ratio == 0 {
+		return cluster
+	}
+	return &tolerantCluster{Cluster: cluster, tolerantSizeRatio: ratio}
+}
+
+func (c *tolerantCluster) GetTolerantSizeRatio() float64 {
+	return c.tolerantSizeRatio
+}
+
+// onlineConfigurable is the config of a scheduler which can be changed online.
+type onlineConfigurable interface {
+	Lock()
+	Unlock()
+	RLock()
+	RUnlock()
+	validate() error
+}
+
+type onlineConfigHandler struct {
+	rd      *render.Render
+	name    string
+	storage *core.Storage
+	conf    onlineConfigurable
+}
+
+// newOnlineConfigHandler creates the handler of the config of a scheduler.
+// GET /list shows the config, and POST /config updates the config items in
+// the JSON body. The items must be the fields of the config and are
+// validated, then the config is persisted and takes effect in the next round
+// of scheduling.
+func newOnlineConfigHandler(name string, storage *core.Storage, conf onlineConfigurable) http.Handler {
+	h := &onlineConfigHandler{
+		rd:      render.New(render.Options{IndentJSON: true}),
+		name:    name,
+		storage: storage,
+		conf:    conf,
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/list", h.handleGetConfig).Methods("GET")
+	router.HandleFunc("/config", h.handleSetConfig).Methods("POST")
+	return router
+}
+
+func (h *onlineConfigHandler) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	h.conf.RLock()
+	defer h.conf.RUnlock()
+	h.rd.JSON(w, http.StatusOK, h.conf)
+}
+
+func (h *onlineConfigHandler) handleSetConfig(w http.ResponseWriter, r *http.Request) {
+	data, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	items := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &items); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(items) == 0 {
+		h.rd.JSON(w, http.StatusBadRequest, "no config item")
+		return
+	}
+	fields := configItems(reflect.TypeOf(h.conf).Elem())
+	for item := range items {
+		if !fields[item] {
+			h.rd.JSON(w, http.StatusBadRequest, "config item "+item+" not found")
+			return
+		}
+	}
+	if data, err = unescapeRanges(items, data); err != nil {
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	h.conf.Lock()
+	defer h.conf.Unlock()
+	old, err := json.Marshal(h.conf)
+	if err != nil {
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := json.Unmarshal(data, h.conf); err != nil {
+		_ = json.Unmarshal(old, h.conf)
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.conf.validate(); err != nil {
+		_ = json.Unmarshal(old, h.conf)
+		h.rd.JSON(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	updated, err := json.Marshal(h.conf)
+	if err != nil {
+		_ = json.Unmarshal(old, h.conf)
+		h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if bytes.Equal(old, updated) {
+		h.rd.Text(w, http.StatusOK, "no changed")
+		return
+	}
+	if h.storage != nil {
+		if err := h.storage.SaveScheduleConfig(h.name, updated); err != nil {
+			_ = json.Unmarshal(old, h.conf)
+			h.rd.JSON(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	h.rd.Text(w, http.StatusOK, "success")
+}
+
+// unescapeRanges converts the ranges given as a list of escaped start keys and
+// end keys, which is how pd-ctl sends the keys, to the key ranges of the
+// config. The ranges in other formats are left unchanged.
+func unescapeRanges(items map[string]json.RawMessage, data []byte) ([]byte, error) {
+	raw, ok := items["ranges"]
+	if !ok {
+		return data, nil
+	}
+	var keys []string
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return data, nil
+	}
+	if len(keys)%2 != 0 {
+		return nil, errors.New("ranges should be pairs of start key and end key")
+	}
+	// The empty ranges are kept to be rejected by the validation.
+	ranges := make([]core.KeyRange, 0, len(keys)/2)
+	var err error
+	if len(keys) > 0 {
+		if ranges, err = getKeyRanges(keys); err != nil {
+			return nil, err
+		}
+	}
+	if items["ranges"], err = json.Marshal(ranges); err != nil {
+		return nil, err
+	}
+	return json.Marshal(items)
+}
+
+// configItems returns the JSON names of the fields which can be changed
+// online, the fields of the embedded structs are included.
+func configItems(t reflect.Type) map[string]bool {
+	items := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			for item := range configItems(f.Type) {
+				items[item] = true
+			}
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if i := strings.Index(tag, ","); i != -1 {
+			tag = tag[:i]
+		}
+		// The name identifies the scheduler and can't be changed.
+		if tag == "" || tag == "-" || tag == "name" {
+			continue
+		}
+		items[tag] = true
+	}
+	return items
+}
+
+// encodeOnlineConfig encodes the config with the read lock held.
+func encodeOnlineConfig(conf onlineConfigurable) ([]byte, error) {
+	conf.RLock()
+	defer conf.RUnlock()
+	return schedule.EncodeConfig(conf)
+}
@@ -15,6 +15,7 @@ package schedulers
 
 import (
 	"math/rand"
+	"net/http"
 
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/core"
@@ -56,19 +57,21 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.init(storage)
 		return newRandomMergeScheduler(opController, conf), nil
 	})
 }
 
 type randomMergeSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	onlineConfig
+	Name string `json:"name"`
 }
 
 type randomMergeScheduler struct {
 	*BaseScheduler
 	conf     *randomMergeSchedulerConfig
 	selector *selector.RandomSelector
+	handler  http.Handler
 }
 
 // newRandomMergeScheduler creates an admin scheduler that randomly picks two adjacent regions
@@ -78,11 +81,13 @@ func newRandomMergeScheduler(opController *schedule.OperatorController, conf *ra
 		filter.StoreStateFilter{ActionScope: conf.Name, MoveRegion: true},
 	}
 	base := NewBaseScheduler(opController)
-	return &randomMergeScheduler{
+	s := &randomMergeScheduler{
 		BaseScheduler: base,
 		conf:          conf,
 		selector:      selector.NewRandomSelector(filters),
 	}
+	s.handler = newOnlineConfigHandler(conf.Name, conf.storage, conf)
+	return s
 }
 
 func (s *randomMergeScheduler) GetName() string {
@@ -94,11 +99,19 @@ func (s *randomMergeScheduler) GetType() string {
 }
 
 func (s *randomMergeScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(s.conf)
+	return encodeOnlineConfig(s.conf)
+}
+
+func (s *randomMergeScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *randomMergeScheduler) GetBatch() int {
+	return s.conf.getBatch()
 }
 
 func (s *randomMergeScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.OpController.OperatorCount(operator.OpMerge) < cluster.GetMergeScheduleLimit()
+	return s.OpController.OperatorCount(operator.OpMerge) < s.conf.getOperatorLimit(cluster.GetMergeScheduleLimit())
 }
 
 func (s *randomMergeScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
@@ -110,7 +123,7 @@ func (s *randomMergeScheduler) Schedule(cluster opt.Cluster) []*operator.Operato
 		schedulerCounter.WithLabelValues(s.GetName(), "no-source-store").Inc()
 		return nil
 	}
-	region := cluster.RandLeaderRegion(store.GetID(), s.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandLeaderRegion(store.GetID(), s.conf.getRanges(), opt.HealthRegion(cluster))
 	if region == nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "no-region").Inc()
 		return nil
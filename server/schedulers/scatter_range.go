@@ -160,13 +160,13 @@ func newScatterRangeScheduler(opController *schedule.OperatorController, config
 		name:          name,
 		balanceLeader: newBalanceLeaderScheduler(
 			opController,
-			&balanceLeaderSchedulerConfig{Ranges: []core.KeyRange{core.NewKeyRange("", "")}},
+			&balanceLeaderSchedulerConfig{onlineConfig: onlineConfig{Ranges: []core.KeyRange{core.NewKeyRange("", "")}}},
 			WithBalanceLeaderName("scatter-range-leader"),
 			WithBalanceLeaderCounter(scatterRangeLeaderCounter),
 		),
 		balanceRegion: newBalanceRegionScheduler(
 			opController,
-			&balanceRegionSchedulerConfig{Ranges: []core.KeyRange{core.NewKeyRange("", "")}},
+			&balanceRegionSchedulerConfig{onlineConfig: onlineConfig{Ranges: []core.KeyRange{core.NewKeyRange("", "")}}},
 			WithBalanceRegionName("scatter-range-region"),
 			WithBalanceRegionCounter(scatterRangeRegionCounter),
 		),
@@ -15,6 +15,7 @@ package schedulers
 
 import (
 	"context"
+	"time"
 
 	. "github.com/pingcap/check"
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -230,10 +231,11 @@ func (s *testScatterRegionSuite) scatter(c *C, numStores, numRegions uint64) {
 
 	for i := uint64(1); i <= numRegions; i++ {
 		region := tc.GetRegion(i)
-		if op, _ := scatterer.Scatter(region); op != nil {
+		scatterer.Scatter(region, "", func(op *operator.Operator) bool {
 			s.checkOperator(op, c)
 			schedule.ApplyOperator(tc, op)
-		}
+			return true
+		})
 	}
 
 	countPeers := make(map[uint64]uint64)
@@ -274,12 +276,132 @@ func (s *testScatterRegionSuite) TestStoreLimit(c *C) {
 
 	for i := uint64(1); i <= 5; i++ {
 		region := tc.GetRegion(i)
-		if op, _ := scatterer.Scatter(region); op != nil {
-			c.Assert(oc.AddWaitingOperator(op), Equals, 1)
+		c.Assert(scatterer.Scatter(region, "", func(op *operator.Operator) bool {
+			return oc.AddWaitingOperator(op) == 1
+		}), IsNil)
+	}
+}
+
+func (s *testScatterRegionSuite) TestScatterGroupLeaders(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	for i := uint64(1); i <= 3; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	// All leaders are on store 1, and the peers can not be moved.
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+	}
+
+	scatterer := schedule.NewRegionScatterer(tc)
+	for i := uint64(1); i <= 6; i++ {
+		// Regions 1~3 and 4~6 are in different groups.
+		group := "a"
+		if i > 3 {
+			group = "b"
+		}
+		scatterer.Scatter(tc.GetRegion(i), group, func(op *operator.Operator) bool {
+			schedule.ApplyOperator(tc, op)
+			return true
+		})
+	}
+
+	for _, regionIDs := range [][]uint64{{1, 2, 3}, {4, 5, 6}} {
+		countLeaders := make(map[uint64]int)
+		for _, id := range regionIDs {
+			region := tc.GetRegion(id)
+			c.Assert(region.GetPeers(), HasLen, 3)
+			countLeaders[region.GetLeader().GetStoreId()]++
 		}
+		// Each store has one leader of the group.
+		c.Assert(countLeaders, HasLen, 3)
+	}
+}
+
+func (s *testScatterRegionSuite) TestScatterRegions(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	for i := uint64(1); i <= 6; i++ {
+		tc.AddRegionStore(i, 0)
+	}
+	var regions []*core.RegionInfo
+	for i := uint64(1); i <= 6; i++ {
+		regions = append(regions, tc.AddLeaderRegion(i, 1, 2, 3))
+	}
+	// Region 7 has only one peer.
+	regions = append(regions, tc.AddLeaderRegion(7, 1))
+
+	scatterer := schedule.NewRegionScatterer(tc)
+	// Region 1 is kept and counted in the group, and the operators of regions
+	// 2~6 fail to be added and are not counted.
+	failures := make(map[uint64]error)
+	scatterer.ScatterRegions(regions, "test", func(op *operator.Operator) bool { return false }, failures)
+	c.Assert(failures, HasLen, 6)
+	c.Assert(failures[1], IsNil)
+	for i := uint64(1); i <= 6; i++ {
+		c.Assert(tc.GetRegion(i).GetStoreIds(), DeepEquals, map[uint64]struct{}{1: {}, 2: {}, 3: {}})
+	}
+	c.Assert(scatterer.Scatter(regions[1], "test", func(op *operator.Operator) bool { return false }), NotNil)
+
+	failures = make(map[uint64]error)
+	scatterer.ScatterRegions(regions, "test", func(op *operator.Operator) bool {
+		s.checkOperator(op, c)
+		schedule.ApplyOperator(tc, op)
+		return true
+	}, failures)
+	c.Assert(failures, HasLen, 1)
+	c.Assert(failures[7], NotNil)
+
+	countPeers := make(map[uint64]int)
+	for i := uint64(1); i <= 6; i++ {
+		for _, peer := range tc.GetRegion(i).GetPeers() {
+			countPeers[peer.GetStoreId()]++
+		}
+	}
+	c.Assert(countPeers, HasLen, 6)
+	for _, count := range countPeers {
+		c.Assert(count, Equals, 3)
 	}
 }
 
+func (s *testScatterRegionSuite) TestScatterWithRules(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	opt.EnablePlacementRules = true
+	tc.RuleManager.SetRule(&placement.Rule{
+		GroupID:          "pd",
+		ID:               "default",
+		Role:             placement.Voter,
+		Count:            3,
+		LabelConstraints: []placement.LabelConstraint{{Key: "zone", Op: placement.In, Values: []string{"z1"}}},
+	})
+	// Stores 1~4 satisfy the rule while stores 5 and 6 do not.
+	for i := uint64(1); i <= 4; i++ {
+		tc.AddLabelsStore(i, 0, map[string]string{"zone": "z1"})
+	}
+	tc.AddLabelsStore(5, 0, map[string]string{"zone": "z2"})
+	tc.AddLabelsStore(6, 0, map[string]string{"zone": "z2"})
+	for i := uint64(1); i <= 4; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3)
+	}
+
+	scatterer := schedule.NewRegionScatterer(tc)
+	for i := uint64(1); i <= 4; i++ {
+		scatterer.Scatter(tc.GetRegion(i), "test", func(op *operator.Operator) bool {
+			schedule.ApplyOperator(tc, op)
+			return true
+		})
+	}
+
+	countPeers := make(map[uint64]int)
+	for i := uint64(1); i <= 4; i++ {
+		for _, peer := range tc.GetRegion(i).GetPeers() {
+			countPeers[peer.GetStoreId()]++
+		}
+	}
+	c.Assert(countPeers, DeepEquals, map[uint64]int{1: 3, 2: 3, 3: 3, 4: 3})
+}
+
 var _ = Suite(&testRejectLeaderSuite{})
 
 type testRejectLeaderSuite struct{}
@@ -456,6 +578,139 @@ func (s *testEvictLeaderSuite) TestEvictLeader(c *C) {
 	testutil.CheckTransferLeader(c, op[0], operator.OpLeader, 1, 2)
 }
 
+func (s *testEvictLeaderSuite) TestEvictLeaderByPercent(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+	oc := schedule.NewOperatorController(ctx, nil, nil)
+
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	// Add regions 1 to 10 in ranges [a, b) to [j, k), the leaders of the
+	// first 6 regions are in store 1.
+	for i := 0; i < 10; i++ {
+		start, end := string(rune('a'+i)), string(rune('a'+i+1))
+		if i < 6 {
+			tc.AddLeaderRegionWithRange(uint64(i+1), start, end, 1, 2, 3)
+		} else {
+			tc.AddLeaderRegionWithRange(uint64(i+1), start, end, 2, 1, 3)
+		}
+	}
+	for id := uint64(1); id <= 3; id++ {
+		tc.UpdateStoreStatus(id)
+	}
+	c.Assert(calcEvictLeaderProgress(tc, 1, 50), DeepEquals, &leaderProgress{Regions: 10, Leaders: 6, Target: 5, Remaining: 1})
+	c.Assert(calcGrantLeaderProgress(tc, 3, 25), DeepEquals, &leaderProgress{Regions: 10, Leaders: 0, Target: 3, Remaining: 3})
+
+	// Keep at most 50% of regions as leaders on store 1.
+	sl, err := schedule.CreateScheduler(EvictLeaderType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(EvictLeaderType, []string{"1", "50%"}))
+	c.Assert(err, IsNil)
+	// Only 1 leader is evicted though the batch size is larger.
+	ops := sl.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].Step(0).(operator.TransferLeader).FromStore, Equals, uint64(1))
+	// The target is reached.
+	tc.AddLeaderRegionWithRange(1, "a", "b", 2, 1, 3)
+	for id := uint64(1); id <= 3; id++ {
+		tc.UpdateStoreStatus(id)
+	}
+	c.Assert(sl.Schedule(tc), HasLen, 0)
+
+	// Evict all leaders of store 1 in range [a, c).
+	sl, err = schedule.CreateScheduler(EvictLeaderType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(EvictLeaderType, []string{"1", "0%", "a", "c"}))
+	c.Assert(err, IsNil)
+	ops = sl.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].RegionID(), Equals, uint64(2))
+	c.Assert(ops[0].Step(0).(operator.TransferLeader).FromStore, Equals, uint64(1))
+
+	// Make at least 25% of regions leaders on store 3.
+	gl, err := schedule.CreateScheduler(GrantLeaderType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(GrantLeaderType, []string{"3", "25%"}))
+	c.Assert(err, IsNil)
+	ops = gl.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	c.Assert(ops[0].Step(0).(operator.TransferLeader).ToStore, Equals, uint64(3))
+	for i := 0; i < 3; i++ {
+		start, end := string(rune('a'+i)), string(rune('a'+i+1))
+		tc.AddLeaderRegionWithRange(uint64(i+1), start, end, 3, 1, 2)
+	}
+	for id := uint64(1); id <= 3; id++ {
+		tc.UpdateStoreStatus(id)
+	}
+	c.Assert(gl.Schedule(tc), HasLen, 0)
+
+	// The args are invalid.
+	for _, args := range [][]string{{}, {"1", "101%"}, {"1", "a"}, {"1", "50%", "a"}, {"x"}} {
+		_, err = schedule.CreateScheduler(EvictLeaderType, oc, core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(EvictLeaderType, args))
+		c.Assert(err, NotNil)
+	}
+}
+
+var _ = Suite(&testEvictSlowStoreSuite{})
+
+type testEvictSlowStoreSuite struct{}
+
+func (s *testEvictSlowStoreSuite) TestEvictSlowStore(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+
+	// Add stores 1, 2, 3, 4
+	tc.AddLeaderStore(1, 0)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderStore(4, 0)
+	// Add regions 1, 2 with leaders in stores 1, 2
+	tc.AddLeaderRegion(1, 1, 2)
+	tc.AddLeaderRegion(2, 2, 1)
+
+	es, err := schedule.CreateScheduler(EvictSlowStoreType, schedule.NewOperatorController(ctx, nil, nil), core.NewStorage(kv.NewMemoryKV()), schedule.ConfigSliceDecoder(EvictSlowStoreType, []string{}))
+	c.Assert(err, IsNil)
+	c.Assert(es.IsScheduleAllowed(tc), IsTrue)
+
+	// No store is slow.
+	tc.ObserveStoreSlowStats(1, 10, false)
+	tc.ObserveStoreSlowStats(2, 10, false)
+	tc.ObserveStoreSlowStats(3, 12, false)
+	tc.ObserveStoreSlowStats(4, 10, false)
+	c.Assert(es.Schedule(tc), HasLen, 0)
+	c.Assert(es.(*evictSlowStoreScheduler).conf.getEvictStore(), Equals, uint64(0))
+
+	// Both stores 1 and 2 are slow, maybe caused by the workload.
+	for i := 0; i < 3; i++ {
+		tc.ObserveStoreSlowStats(1, 100, false)
+		tc.ObserveStoreSlowStats(2, 100, false)
+	}
+	c.Assert(es.Schedule(tc), HasLen, 0)
+	c.Assert(es.(*evictSlowStoreScheduler).conf.getEvictStore(), Equals, uint64(0))
+
+	// Only store 1 is slow, evict its leaders.
+	for i := 0; i < 3; i++ {
+		tc.ObserveStoreSlowStats(2, 10, false)
+	}
+	ops := es.Schedule(tc)
+	c.Assert(ops, HasLen, 1)
+	testutil.CheckTransferLeader(c, ops[0], operator.OpLeader, 1, 2)
+	c.Assert(es.(*evictSlowStoreScheduler).conf.getEvictStore(), Equals, uint64(1))
+	c.Assert(tc.GetStore(1).IsBlocked(), IsTrue)
+
+	// Store 1 recovers, but the score should keep recovered for a while.
+	for i := 0; i < 3; i++ {
+		tc.ObserveStoreSlowStats(1, 10, false)
+	}
+	c.Assert(es.Schedule(tc), HasLen, 1)
+	c.Assert(es.(*evictSlowStoreScheduler).conf.getEvictStore(), Equals, uint64(1))
+
+	defer func(t time.Duration) { slowStoreRecoverTime = t }(slowStoreRecoverTime)
+	slowStoreRecoverTime = 0
+	c.Assert(es.Schedule(tc), HasLen, 0)
+	c.Assert(es.(*evictSlowStoreScheduler).conf.getEvictStore(), Equals, uint64(0))
+	c.Assert(tc.GetStore(1).IsBlocked(), IsFalse)
+}
+
 var _ = Suite(&testShuffleRegionSuite{})
 
 type testShuffleRegionSuite struct{}
@@ -631,3 +886,43 @@ func (s *testSpecialUseSuite) TestSpecialUseReserved(c *C) {
 	ops = bs.Schedule(tc)
 	c.Assert(ops, HasLen, 0)
 }
+
+var _ = Suite(&testDryRunSuite{})
+
+type testDryRunSuite struct{}
+
+func (s *testDryRunSuite) TestDryRunScheduler(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opt := mockoption.NewScheduleOptions()
+	tc := mockcluster.NewCluster(opt)
+
+	// Stores:     1    2    3    4
+	// Leaders:    16   0    0    0
+	tc.AddLeaderStore(1, 16)
+	tc.AddLeaderStore(2, 0)
+	tc.AddLeaderStore(3, 0)
+	tc.AddLeaderStore(4, 0)
+	for i := uint64(1); i <= 16; i++ {
+		tc.AddLeaderRegion(i, 1, 2, 3, 4)
+	}
+
+	sandbox := schedule.NewSandboxCluster(tc, tc.BasicCluster.Clone(), tc.RuleManager, nil)
+	_, err := schedule.DryRunScheduler(ctx, sandbox, "not-exist", nil, 1)
+	c.Assert(err, NotNil)
+
+	result, err := schedule.DryRunScheduler(ctx, sandbox, BalanceLeaderName, []string{"", ""}, 2)
+	c.Assert(err, IsNil)
+	c.Assert(result.Operators, HasLen, 2)
+	c.Assert(result.Operators[0].Round, Equals, 1)
+	c.Assert(result.Operators[1].Round, Equals, 2)
+	c.Assert(result.Stores, HasLen, 4)
+	c.Assert(result.Stores[0].LeaderCountBefore, Equals, 16)
+	c.Assert(result.Stores[0].LeaderCountAfter, Equals, 14)
+	c.Assert(result.Stores[0].LeaderScoreAfter, Less, result.Stores[0].LeaderScoreBefore)
+
+	// The operators are applied to the sandbox only.
+	c.Assert(sandbox.GetStoreLeaderCount(1), Equals, 14)
+	c.Assert(tc.GetStoreLeaderCount(1), Equals, 16)
+	c.Assert(tc.GetStore(1).GetLeaderCount(), Equals, 16)
+}
@@ -15,7 +15,9 @@ package schedulers
 
 import (
 	"math/rand"
+	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pingcap/kvproto/pkg/metapb"
@@ -25,6 +27,7 @@ import (
 	"github.com/pingcap/pd/v4/server/schedule/filter"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pkg/errors"
 	"go.uber.org/zap"
 )
 
@@ -60,15 +63,32 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.storage = storage
 		return newShuffleHotRegionScheduler(opController, conf), nil
 	})
 }
 
 type shuffleHotRegionSchedulerConfig struct {
+	sync.RWMutex
+	storage *core.Storage
+
 	Name  string `json:"name"`
 	Limit uint64 `json:"limit"`
 }
 
+func (conf *shuffleHotRegionSchedulerConfig) getLimit() uint64 {
+	conf.RLock()
+	defer conf.RUnlock()
+	return conf.Limit
+}
+
+func (conf *shuffleHotRegionSchedulerConfig) validate() error {
+	if conf.Limit == 0 {
+		return errors.New("limit should be greater than 0")
+	}
+	return nil
+}
+
 // ShuffleHotRegionScheduler mainly used to test.
 // It will randomly pick a hot peer, and move the peer
 // to a random store, and then transfer the leader to
@@ -79,6 +99,7 @@ type shuffleHotRegionScheduler struct {
 	r           *rand.Rand
 	conf        *shuffleHotRegionSchedulerConfig
 	types       []rwType
+	handler     http.Handler
 }
 
 // newShuffleHotRegionScheduler creates an admin scheduler that random balance hot regions
@@ -89,6 +110,7 @@ func newShuffleHotRegionScheduler(opController *schedule.OperatorController, con
 		conf:          conf,
 		types:         []rwType{read, write},
 		r:             rand.New(rand.NewSource(time.Now().UnixNano())),
+		handler:       newOnlineConfigHandler(conf.Name, conf.storage, conf),
 	}
 	for ty := resourceType(0); ty < resourceTypeLen; ty++ {
 		ret.stLoadInfos[ty] = map[uint64]*storeLoadDetail{}
@@ -105,11 +127,15 @@ func (s *shuffleHotRegionScheduler) GetType() string {
 }
 
 func (s *shuffleHotRegionScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(s.conf)
+	return encodeOnlineConfig(s.conf)
+}
+
+func (s *shuffleHotRegionScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
 }
 
 func (s *shuffleHotRegionScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.OpController.OperatorCount(operator.OpHotRegion) < s.conf.Limit &&
+	return s.OpController.OperatorCount(operator.OpHotRegion) < s.conf.getLimit() &&
 		s.OpController.OperatorCount(operator.OpRegion) < cluster.GetRegionScheduleLimit() &&
 		s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
 }
@@ -129,6 +155,7 @@ func (s *shuffleHotRegionScheduler) dispatch(typ rwType, cluster opt.Cluster) []
 		s.stLoadInfos[readLeader] = summaryStoresLoad(
 			storesStats.GetStoresBytesReadStat(),
 			storesStats.GetStoresKeysReadStat(),
+			storesStats.GetStoresCPUUsage(),
 			map[uint64]Influence{},
 			cluster.RegionReadStats(),
 			minHotDegree,
@@ -140,6 +167,7 @@ func (s *shuffleHotRegionScheduler) dispatch(typ rwType, cluster opt.Cluster) []
 		s.stLoadInfos[writeLeader] = summaryStoresLoad(
 			storesStats.GetStoresBytesWriteStat(),
 			storesStats.GetStoresKeysWriteStat(),
+			storesStats.GetStoresCPUUsage(),
 			map[uint64]Influence{},
 			cluster.RegionWriteStats(),
 			minHotDegree,
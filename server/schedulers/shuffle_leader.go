@@ -14,6 +14,8 @@
 package schedulers
 
 import (
+	"net/http"
+
 	"github.com/pingcap/log"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule"
@@ -54,19 +56,21 @@ func init() {
 		if err := decoder(conf); err != nil {
 			return nil, err
 		}
+		conf.init(storage)
 		return newShuffleLeaderScheduler(opController, conf), nil
 	})
 }
 
 type shuffleLeaderSchedulerConfig struct {
-	Name   string          `json:"name"`
-	Ranges []core.KeyRange `json:"ranges"`
+	onlineConfig
+	Name string `json:"name"`
 }
 
 type shuffleLeaderScheduler struct {
 	*BaseScheduler
 	conf     *shuffleLeaderSchedulerConfig
 	selector *selector.RandomSelector
+	handler  http.Handler
 }
 
 // newShuffleLeaderScheduler creates an admin scheduler that shuffles leaders
@@ -77,11 +81,13 @@ func newShuffleLeaderScheduler(opController *schedule.OperatorController, conf *
 		filter.NewSpecialUseFilter(conf.Name),
 	}
 	base := NewBaseScheduler(opController)
-	return &shuffleLeaderScheduler{
+	s := &shuffleLeaderScheduler{
 		BaseScheduler: base,
 		conf:          conf,
 		selector:      selector.NewRandomSelector(filters),
 	}
+	s.handler = newOnlineConfigHandler(conf.Name, conf.storage, conf)
+	return s
 }
 
 func (s *shuffleLeaderScheduler) GetName() string {
@@ -93,11 +99,19 @@ func (s *shuffleLeaderScheduler) GetType() string {
 }
 
 func (s *shuffleLeaderScheduler) EncodeConfig() ([]byte, error) {
-	return schedule.EncodeConfig(s.conf)
+	return encodeOnlineConfig(s.conf)
+}
+
+func (s *shuffleLeaderScheduler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *shuffleLeaderScheduler) GetBatch() int {
+	return s.conf.getBatch()
 }
 
 func (s *shuffleLeaderScheduler) IsScheduleAllowed(cluster opt.Cluster) bool {
-	return s.OpController.OperatorCount(operator.OpLeader) < cluster.GetLeaderScheduleLimit()
+	return s.OpController.OperatorCount(operator.OpLeader) < s.conf.getOperatorLimit(cluster.GetLeaderScheduleLimit())
 }
 
 func (s *shuffleLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Operator {
@@ -111,7 +125,7 @@ func (s *shuffleLeaderScheduler) Schedule(cluster opt.Cluster) []*operator.Opera
 		schedulerCounter.WithLabelValues(s.GetName(), "no-target-store").Inc()
 		return nil
 	}
-	region := cluster.RandFollowerRegion(targetStore.GetID(), s.conf.Ranges, opt.HealthRegion(cluster))
+	region := cluster.RandFollowerRegion(targetStore.GetID(), s.conf.getRanges(), opt.HealthRegion(cluster))
 	if region == nil {
 		schedulerCounter.WithLabelValues(s.GetName(), "no-follower").Inc()
 		return nil
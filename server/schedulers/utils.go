@@ -17,6 +17,7 @@ import (
 	"math"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/montanaflynn/stats"
@@ -24,6 +25,7 @@ import (
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/server/schedule/operator"
 	"github.com/pingcap/pd/v4/server/schedule/opt"
+	"github.com/pingcap/pd/v4/server/schedule/plan"
 	"github.com/pingcap/pd/v4/server/statistics"
 	"github.com/pkg/errors"
 	"go.uber.org/zap"
@@ -34,6 +36,10 @@ const (
 	adjustRatio             float64 = 0.005
 	leaderTolerantSizeRatio float64 = 5.0
 	minTolerantSizeRatio    float64 = 1.0
+	// leaderLoadTolerantRatio is the ratio of the average leader load, the
+	// leader load of stores is considered balanced if the difference is less
+	// than it.
+	leaderLoadTolerantRatio float64 = 0.1
 )
 
 var (
@@ -66,7 +72,7 @@ func minDuration(a, b time.Duration) time.Duration {
 	return b
 }
 
-func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *core.RegionInfo, kind core.ScheduleKind, opInfluence operator.OpInfluence, scheduleName string) bool {
+func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *core.RegionInfo, kind core.ScheduleKind, opInfluence operator.OpInfluence, scheduleName string, p *plan.Plan) bool {
 	// The reason we use max(regionSize, averageRegionSize) to check is:
 	// 1. prevent moving small regions between stores with close scores, leading to unnecessary balance.
 	// 2. prevent moving huge regions, leading to over balance.
@@ -84,6 +90,17 @@ func shouldBalance(cluster opt.Cluster, source, target *core.StoreInfo, region *
 	}
 	// Make sure after move, source score is still greater than target score.
 	shouldBalance := sourceScore > targetScore
+	p.AddScore(plan.ScoreRecord{
+		RegionID:         region.GetID(),
+		SourceID:         sourceID,
+		TargetID:         targetID,
+		SourceScore:      sourceScore,
+		TargetScore:      targetScore,
+		SourceInfluence:  sourceInfluence,
+		TargetInfluence:  targetInfluence,
+		TolerantResource: tolerantResource,
+		ShouldBalance:    shouldBalance,
+	})
 
 	if !shouldBalance {
 		log.Debug("skip balance "+kind.Resource.String(),
@@ -107,6 +124,15 @@ func getTolerantResource(cluster opt.Cluster, region *core.RegionInfo, kind core
 		leaderCount := int64(1.0 * tolerantSizeRatio)
 		return leaderCount
 	}
+	if kind.Resource == core.LeaderKind && kind.Policy == core.ByLoad {
+		// Both the source and the target are adjusted by the tolerant load,
+		// so half of the tolerant difference is used.
+		leaderLoad := int64(region.GetKeysReadRate())
+		if tolerantLoad := int64(averageLeaderLoad(cluster) * leaderLoadTolerantRatio / 2); leaderLoad < tolerantLoad {
+			leaderLoad = tolerantLoad
+		}
+		return leaderLoad
+	}
 
 	regionSize := region.GetApproximateSize()
 	if regionSize < cluster.GetAverageRegionSize() {
@@ -116,6 +142,24 @@ func getTolerantResource(cluster opt.Cluster, region *core.RegionInfo, kind core
 	return regionSize
 }
 
+// averageLeaderLoad returns the average leader load of the up stores.
+func averageLeaderLoad(cluster opt.Cluster) float64 {
+	var (
+		totalLoad float64
+		count     int
+	)
+	for _, store := range cluster.GetStores() {
+		if store.IsUp() {
+			totalLoad += store.GetLeaderLoad()
+			count++
+		}
+	}
+	if count == 0 {
+		return 0
+	}
+	return totalLoad / float64(count)
+}
+
 func adjustTolerantRatio(cluster opt.Cluster) float64 {
 	tolerantSizeRatio := cluster.GetTolerantSizeRatio()
 	if tolerantSizeRatio == 0 {
@@ -167,16 +211,126 @@ func getKeyRanges(args []string) ([]core.KeyRange, error) {
 	return ranges, nil
 }
 
+// encodeKeyRanges is the reverse of getKeyRanges.
+func encodeKeyRanges(ranges []core.KeyRange) []string {
+	res := make([]string, 0, len(ranges)*2)
+	for _, r := range ranges {
+		res = append(res, url.QueryEscape(string(r.StartKey)), url.QueryEscape(string(r.EndKey)))
+	}
+	return res
+}
+
+// parseStoreLeaderArgs parses the args of evict-leader-scheduler and
+// grant-leader-scheduler, which are in the format of
+// `<store-id> [<leader-percent>%] [<start-key> <end-key>]...`. The keys are
+// escaped, so the leader percent can be told from them by the suffix "%".
+// The leader percent is -1 if it is not specified.
+func parseStoreLeaderArgs(args []string) (uint64, int, []core.KeyRange, error) {
+	if len(args) < 1 {
+		return 0, 0, nil, errors.New("should specify the store-id")
+	}
+	id, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		return 0, 0, nil, errors.WithStack(err)
+	}
+	args = args[1:]
+	percent := -1
+	if len(args) > 0 && strings.HasSuffix(args[0], "%") {
+		if percent, err = strconv.Atoi(strings.TrimSuffix(args[0], "%")); err != nil {
+			return 0, 0, nil, errors.WithStack(err)
+		}
+		if percent < 0 || percent > 100 {
+			return 0, 0, nil, errors.Errorf("leader percent %d%% should be in [0%%, 100%%]", percent)
+		}
+		args = args[1:]
+	}
+	if len(args)%2 != 0 {
+		return 0, 0, nil, errors.New("the start key and the end key of ranges should be paired")
+	}
+	ranges, err := getKeyRanges(args)
+	if err != nil {
+		return 0, 0, nil, errors.WithStack(err)
+	}
+	return id, percent, ranges, nil
+}
+
+// parseRangesInput parses the ranges in the HTTP request, which is a list of
+// the escaped start keys and end keys.
+func parseRangesInput(input interface{}) ([]string, error) {
+	ranges, ok := input.([]interface{})
+	if !ok {
+		return nil, errors.New("the ranges should be a list of keys")
+	}
+	keys := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		key, ok := r.(string)
+		if !ok {
+			return nil, errors.New("the keys of ranges should be strings")
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// leaderProgress is the progress of transferring leaders out of or into a
+// store.
+type leaderProgress struct {
+	// Regions is the count of regions with a peer on the store.
+	Regions int `json:"regions"`
+	// Leaders is the count of leaders on the store.
+	Leaders int `json:"leaders"`
+	// Target is the max count of leaders to keep for evict-leader-scheduler,
+	// or the min count of leaders to grant for grant-leader-scheduler.
+	Target int `json:"target"`
+	// Remaining is the count of leaders still to transfer to reach the target.
+	Remaining int `json:"remaining"`
+}
+
+// countStoreLeaders returns the counts of regions and leaders cached in the
+// store, so that the regions are not scanned on every schedule. The counts are
+// of the whole store, the key ranges only limit which leaders are transferred.
+func countStoreLeaders(cluster opt.Cluster, storeID uint64) (regions, leaders int) {
+	store := cluster.GetStore(storeID)
+	if store == nil {
+		return 0, 0
+	}
+	return store.GetRegionCount(), store.GetLeaderCount()
+}
+
+// calcEvictLeaderProgress calculates the progress to keep at most the percent
+// of the regions on the store as leaders.
+func calcEvictLeaderProgress(cluster opt.Cluster, storeID uint64, percent int) *leaderProgress {
+	regions, leaders := countStoreLeaders(cluster, storeID)
+	p := &leaderProgress{Regions: regions, Leaders: leaders, Target: regions * percent / 100}
+	if leaders > p.Target {
+		p.Remaining = leaders - p.Target
+	}
+	return p
+}
+
+// calcGrantLeaderProgress calculates the progress to make at least the
+// percent of the regions on the store as leaders.
+func calcGrantLeaderProgress(cluster opt.Cluster, storeID uint64, percent int) *leaderProgress {
+	regions, leaders := countStoreLeaders(cluster, storeID)
+	p := &leaderProgress{Regions: regions, Leaders: leaders, Target: (regions*percent + 99) / 100}
+	if leaders < p.Target {
+		p.Remaining = p.Target - leaders
+	}
+	return p
+}
+
 // Influence records operator influence.
 type Influence struct {
 	ByteRate float64
 	KeyRate  float64
+	CPU      float64
 	Count    float64
 }
 
 func (infl Influence) add(rhs *Influence, w float64) Influence {
 	infl.ByteRate += rhs.ByteRate * w
 	infl.KeyRate += rhs.KeyRate * w
+	infl.CPU += rhs.CPU * w
 	infl.Count += rhs.Count * w
 	return infl
 }
@@ -213,10 +367,12 @@ func summaryPendingInfluence(pendings map[*pendingInfluence]struct{}, f func(*op
 type storeLoad struct {
 	ByteRate float64
 	KeyRate  float64
+	CPU      float64
 	Count    float64
 
 	ExpByteRate float64
 	ExpKeyRate  float64
+	ExpCPU      float64
 	ExpCount    float64
 }
 
@@ -224,6 +380,7 @@ func (load *storeLoad) ToLoadPred(infl Influence) *storeLoadPred {
 	future := *load
 	future.ByteRate += infl.ByteRate
 	future.KeyRate += infl.KeyRate
+	future.CPU += infl.CPU
 	future.Count += infl.Count
 	return &storeLoadPred{
 		Current: *load,
@@ -243,6 +400,38 @@ func stLdCount(ld *storeLoad) float64 {
 	return ld.Count
 }
 
+// get returns the load of the dimension.
+func (load *storeLoad) get(dim int) float64 {
+	switch dim {
+	case byteDim:
+		return load.ByteRate
+	case keyDim:
+		return load.KeyRate
+	case cpuDim:
+		return load.CPU
+	}
+	return 0
+}
+
+// getExp returns the expected load of the dimension.
+func (load *storeLoad) getExp(dim int) float64 {
+	switch dim {
+	case byteDim:
+		return load.ExpByteRate
+	case keyDim:
+		return load.ExpKeyRate
+	case cpuDim:
+		return load.ExpCPU
+	}
+	return 0
+}
+
+func stLdDim(dim int) func(ld *storeLoad) float64 {
+	return func(ld *storeLoad) float64 {
+		return ld.get(dim)
+	}
+}
+
 type storeLoadCmp func(ld1, ld2 *storeLoad) int
 
 func negLoadCmp(cmp storeLoadCmp) storeLoadCmp {
@@ -297,6 +486,7 @@ func (lp *storeLoadPred) diff() *storeLoad {
 	return &storeLoad{
 		ByteRate: mx.ByteRate - mn.ByteRate,
 		KeyRate:  mx.KeyRate - mn.KeyRate,
+		CPU:      mx.CPU - mn.CPU,
 		Count:    mx.Count - mn.Count,
 	}
 }
@@ -336,6 +526,7 @@ func minLoad(a, b *storeLoad) *storeLoad {
 	return &storeLoad{
 		ByteRate: math.Min(a.ByteRate, b.ByteRate),
 		KeyRate:  math.Min(a.KeyRate, b.KeyRate),
+		CPU:      math.Min(a.CPU, b.CPU),
 		Count:    math.Min(a.Count, b.Count),
 	}
 }
@@ -344,6 +535,7 @@ func maxLoad(a, b *storeLoad) *storeLoad {
 	return &storeLoad{
 		ByteRate: math.Max(a.ByteRate, b.ByteRate),
 		KeyRate:  math.Max(a.KeyRate, b.KeyRate),
+		CPU:      math.Max(a.CPU, b.CPU),
 		Count:    math.Max(a.Count, b.Count),
 	}
 }
@@ -351,6 +543,9 @@ func maxLoad(a, b *storeLoad) *storeLoad {
 type storeLoadDetail struct {
 	LoadPred *storeLoadPred
 	HotPeers []*statistics.HotPeerStat
+	// CPUPerKey is used to estimate the cpu usage of a peer by its key rate,
+	// since the cpu usage is only reported by stores.
+	CPUPerKey float64
 }
 
 func (li *storeLoadDetail) toHotPeersStat() *statistics.HotPeersStat {
@@ -115,7 +115,10 @@ type Server struct {
 	// for baiscCluster operation.
 	basicCluster *core.BasicCluster
 	// for tso.
-	tso *tso.TimestampOracle
+	tso                 *tso.TimestampOracle
+	tsoAllocatorManager *tso.AllocatorManager
+	tsoProxy            *tsoFollowerProxy
+	localTSOQuerier     *localTSOQuerier
 	// for raft cluster
 	cluster *cluster.RaftCluster
 	// For async region heartbeat.
@@ -340,17 +343,30 @@ func (s *Server) startServer(ctx context.Context) error {
 
 	s.rootPath = path.Join(pdRootPath, strconv.FormatUint(s.clusterID, 10))
 	s.member.MemberInfo(s.cfg, s.Name(), s.rootPath)
+	if err = s.member.CheckTSOSuffix(s.cfg.TSOSuffixBits, s.cfg.TSOSuffix); err != nil {
+		return err
+	}
+	if err = s.member.SetMemberTSOSuffix(s.member.ID(), s.cfg.TSOSuffixBits, s.cfg.TSOSuffix); err != nil {
+		return err
+	}
 	s.member.SetMemberDeployPath(s.member.ID())
 	s.member.SetMemberBinaryVersion(s.member.ID(), PDReleaseVersion)
 	s.member.SetMemberGitHash(s.member.ID(), PDGitHash)
 	s.idAllocator = id.NewAllocatorImpl(s.client, s.rootPath, s.member.MemberValue())
-	s.tso = tso.NewTimestampOracle(
+	s.tsoAllocatorManager = tso.NewAllocatorManager(
 		s.client,
 		s.rootPath,
 		s.member.MemberValue(),
+		s.cfg.DCLocation,
+		s.cfg.LeaderLease,
 		s.cfg.TsoSaveInterval.Duration,
 		func() time.Duration { return s.persistOptions.GetMaxResetTSGap() },
 	)
+	s.tsoAllocatorManager.SetSuffix(s.cfg.TSOSuffixBits, s.cfg.TSOSuffix)
+	s.tso = s.tsoAllocatorManager.GetGlobalAllocator()
+	s.tsoProxy = newTSOFollowerProxy(s)
+	s.localTSOQuerier = newLocalTSOQuerier(s)
+	s.tsoAllocatorManager.SetLocalTSOQuerier(s.localTSOQuerier.query)
 	kvBase := kv.NewEtcdKVBase(s.client, s.rootPath)
 	path := filepath.Join(s.cfg.DataDir, "region-meta")
 	regionStorage, err := core.NewRegionStorage(ctx, path)
@@ -403,6 +419,9 @@ func (s *Server) Close() {
 	log.Info("closing server")
 
 	s.stopServerLoop()
+	if s.localTSOQuerier != nil {
+		s.localTSOQuerier.close()
+	}
 
 	if s.client != nil {
 		s.client.Close()
@@ -463,10 +482,30 @@ func (s *Server) LoopContext() context.Context {
 
 func (s *Server) startServerLoop(ctx context.Context) {
 	s.serverLoopCtx, s.serverLoopCancel = context.WithCancel(ctx)
-	s.serverLoopWg.Add(3)
+	s.serverLoopWg.Add(5)
 	go s.leaderLoop()
 	go s.etcdLeaderLoop()
 	go s.serverMetricsLoop()
+	go s.localTSOLoop()
+	go s.localTSOLeaderLoop()
+	if s.cfg.EnableTSOFollowerProxy {
+		s.serverLoopWg.Add(1)
+		go s.tsoProxy.run(s.serverLoopCtx)
+	}
+}
+
+func (s *Server) localTSOLoop() {
+	defer logutil.LogPanic()
+	defer s.serverLoopWg.Done()
+
+	s.tsoAllocatorManager.LocalAllocatorLoop(s.serverLoopCtx)
+}
+
+func (s *Server) localTSOLeaderLoop() {
+	defer logutil.LogPanic()
+	defer s.serverLoopWg.Done()
+
+	s.tsoAllocatorManager.WatchLocalLeadersLoop(s.serverLoopCtx)
 }
 
 func (s *Server) stopServerLoop() {
@@ -751,7 +790,7 @@ func (s *Server) SetReplicationConfig(cfg config.ReplicationConfig) error {
 		}
 		if cfg.EnablePlacementRules {
 			// initialize rule manager.
-			if err := raftCluster.GetRuleManager().Initialize(int(cfg.MaxReplicas), cfg.LocationLabels); err != nil {
+			if err := raftCluster.GetRuleManager().Initialize(int(cfg.MaxReplicas), cfg.LocationLabels, cfg.IsolationLevel); err != nil {
 				return err
 			}
 		} else {
@@ -1070,7 +1109,7 @@ func (s *Server) campaignLeader() {
 	log.Info("campaign leader ok", zap.String("campaign-leader-name", s.Name()))
 
 	log.Debug("sync timestamp for tso")
-	if err := s.tso.SyncTimestamp(lease); err != nil {
+	if err := s.tsoAllocatorManager.SyncGlobalTimestamp(lease); err != nil {
 		log.Error("failed to sync timestamp", zap.Error(err))
 		return
 	}
@@ -1113,7 +1152,7 @@ func (s *Server) campaignLeader() {
 				return
 			}
 		case <-tsTicker.C:
-			if err = s.tso.UpdateTimestamp(); err != nil {
+			if err = s.tsoAllocatorManager.UpdateGlobalTimestamp(); err != nil {
 				log.Error("failed to update timestamp", zap.Error(err))
 				return
 			}
@@ -95,6 +95,36 @@ func (s *testLeaderServerSuite) TearDownSuite(c *C) {
 	}
 }
 
+func (s *testLeaderServerSuite) TestCheckTSOSuffix(c *C) {
+	svrs := make([]*Server, 0, len(s.svrs))
+	for _, svr := range s.svrs {
+		svrs = append(svrs, svr)
+	}
+	leader := mustWaitLeader(c, svrs)
+	bits, suffix := leader.cfg.TSOSuffixBits, leader.cfg.TSOSuffix
+	for _, svr := range svrs {
+		c.Assert(svr.member.CheckTSOSuffix(bits, suffix), IsNil)
+		if svr == leader {
+			continue
+		}
+		// The members must have the same suffix as the leader.
+		c.Assert(svr.member.CheckTSOSuffix(bits+1, suffix), NotNil)
+		c.Assert(svr.member.CheckTSOSuffix(bits+1, suffix+1), NotNil)
+	}
+	// The bits saved by the leader are changed once it restarts with the new
+	// bits, so the followers are checked against the new bits.
+	c.Assert(leader.member.SetMemberTSOSuffix(leader.member.ID(), bits+1, suffix+1), IsNil)
+	defer func() {
+		c.Assert(leader.member.SetMemberTSOSuffix(leader.member.ID(), bits, suffix), IsNil)
+	}()
+	for _, svr := range svrs {
+		if svr != leader {
+			c.Assert(svr.member.CheckTSOSuffix(bits, suffix), NotNil)
+			c.Assert(svr.member.CheckTSOSuffix(bits+1, suffix+1), IsNil)
+		}
+	}
+}
+
 var _ = Suite(&testServerSuite{})
 
 type testServerSuite struct{}
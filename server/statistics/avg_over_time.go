@@ -53,6 +53,9 @@ func NewAvgOverTime(interval time.Duration) *AvgOverTime {
 
 // Get returns change rate in the last interval.
 func (aot *AvgOverTime) Get() float64 {
+	if aot.intervalSum == 0 {
+		return 0
+	}
 	return aot.deltaSum / aot.intervalSum.Seconds()
 }
 
@@ -68,6 +71,16 @@ func (aot *AvgOverTime) Add(delta float64, interval time.Duration) {
 	aot.que.PushBack(deltaWithInterval{delta, interval})
 	aot.deltaSum += delta
 	aot.intervalSum += interval
+	// Drop the changes out of the last avgInterval.
+	for aot.que.Len() > 1 {
+		front := aot.que.Front().(deltaWithInterval)
+		if aot.intervalSum-front.interval < aot.avgInterval {
+			break
+		}
+		aot.que.PopFront()
+		aot.deltaSum -= front.delta
+		aot.intervalSum -= front.interval
+	}
 }
 
 // Set sets AvgOverTime to the given average.
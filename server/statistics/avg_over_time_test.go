@@ -68,3 +68,17 @@ func (t *testAvgOverTimeSuite) TestChange(c *C) {
 	c.Assert(aot.Get(), LessEqual, 678.)
 	c.Assert(aot.Get(), GreaterEqual, 99.)
 }
+
+func (t *testAvgOverTimeSuite) TestWindow(c *C) {
+	aot := NewAvgOverTime(5 * time.Second)
+	c.Assert(aot.Get(), Equals, 0.)
+	for i := 0; i < 10; i++ {
+		aot.Add(1000, time.Second)
+	}
+	c.Assert(aot.Get(), Equals, 1000.)
+	// The changes out of the window are dropped.
+	for i := 0; i < 5; i++ {
+		aot.Add(0, time.Second)
+	}
+	c.Assert(aot.Get(), Equals, 0.)
+}
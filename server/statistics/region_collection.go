@@ -15,6 +15,7 @@ package statistics
 
 import (
 	"github.com/pingcap/pd/v4/server/core"
+	"github.com/pingcap/pd/v4/server/schedule/placement"
 )
 
 // RegionStatisticType represents the type of the region's status.
@@ -29,23 +30,30 @@ const (
 	OfflinePeer
 	LearnerPeer
 	EmptyRegion
+	UnderIsolation
 )
 
 const nonIsolation = "none"
 
 // RegionStatistics is used to record the status of regions.
 type RegionStatistics struct {
-	opt   ScheduleOptions
-	stats map[RegionStatisticType]map[uint64]*core.RegionInfo
-	index map[uint64]RegionStatisticType
+	opt         ScheduleOptions
+	ruleManager *placement.RuleManager
+	stores      core.StoreSetInformer
+	stats       map[RegionStatisticType]map[uint64]*core.RegionInfo
+	index       map[uint64]RegionStatisticType
 }
 
-// NewRegionStatistics creates a new RegionStatistics.
-func NewRegionStatistics(opt ScheduleOptions) *RegionStatistics {
+// NewRegionStatistics creates a new RegionStatistics. The ruleManager and
+// stores are used to check the isolation level of regions when placement
+// rules are enabled, ruleManager can be nil if it is not needed.
+func NewRegionStatistics(opt ScheduleOptions, ruleManager *placement.RuleManager, stores core.StoreSetInformer) *RegionStatistics {
 	r := &RegionStatistics{
-		opt:   opt,
-		stats: make(map[RegionStatisticType]map[uint64]*core.RegionInfo),
-		index: make(map[uint64]RegionStatisticType),
+		opt:         opt,
+		ruleManager: ruleManager,
+		stores:      stores,
+		stats:       make(map[RegionStatisticType]map[uint64]*core.RegionInfo),
+		index:       make(map[uint64]RegionStatisticType),
 	}
 	r.stats[MissPeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[ExtraPeer] = make(map[uint64]*core.RegionInfo)
@@ -54,6 +62,7 @@ func NewRegionStatistics(opt ScheduleOptions) *RegionStatistics {
 	r.stats[OfflinePeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[LearnerPeer] = make(map[uint64]*core.RegionInfo)
 	r.stats[EmptyRegion] = make(map[uint64]*core.RegionInfo)
+	r.stats[UnderIsolation] = make(map[uint64]*core.RegionInfo)
 	return r
 }
 
@@ -121,12 +130,77 @@ func (r *RegionStatistics) Observe(region *core.RegionInfo, stores []*core.Store
 	}
 
 	if oldIndex, ok := r.index[regionID]; ok {
+		// UnderIsolation is updated by ObserveIsolation.
+		if oldIndex&UnderIsolation != 0 {
+			r.stats[UnderIsolation][regionID] = region
+			peerTypeIndex |= UnderIsolation
+		}
 		deleteIndex = oldIndex &^ peerTypeIndex
 	}
 	r.deleteEntry(deleteIndex, regionID)
 	r.index[regionID] = peerTypeIndex
 }
 
+// ObserveIsolation records whether the region is under isolation. It is
+// separated from Observe because checking the isolation with placement rules
+// needs to fit the region, which is too expensive for every heartbeat.
+func (r *RegionStatistics) ObserveIsolation(region *core.RegionInfo, underIsolation bool) {
+	regionID := region.GetID()
+	index, ok := r.index[regionID]
+	if !ok {
+		return
+	}
+	if underIsolation {
+		r.stats[UnderIsolation][regionID] = region
+		r.index[regionID] = index | UnderIsolation
+		return
+	}
+	delete(r.stats[UnderIsolation], regionID)
+	r.index[regionID] = index &^ UnderIsolation
+}
+
+// IsUnderIsolation checks whether the peers of the region are isolated at a
+// lower level than the configured isolation level. It doesn't access the
+// recorded statistics, so it can be called concurrently with Observe.
+func (r *RegionStatistics) IsUnderIsolation(region *core.RegionInfo, stores []*core.StoreInfo) bool {
+	if r.opt.IsPlacementRulesEnabled() {
+		if r.ruleManager == nil || r.stores == nil {
+			return false
+		}
+		fit := r.ruleManager.FitRegion(r.stores, region)
+		for _, rf := range fit.RuleFits {
+			if !rf.IsIsolationSatisfied() {
+				return true
+			}
+		}
+		return false
+	}
+	isolationLevel, labels := r.opt.GetIsolationLevel(), r.opt.GetLocationLabels()
+	if isolationLevel == "" || len(stores) <= 1 {
+		return false
+	}
+	requiredIdx := -1
+	for i, label := range labels {
+		if label == isolationLevel {
+			requiredIdx = i
+			break
+		}
+	}
+	if requiredIdx < 0 {
+		return false
+	}
+	regionIsolation := getRegionLabelIsolation(stores, labels)
+	if regionIsolation == nonIsolation {
+		return true
+	}
+	for i, label := range labels {
+		if label == regionIsolation {
+			return i > requiredIdx
+		}
+	}
+	return false
+}
+
 // ClearDefunctRegion is used to handle the overlap region.
 func (r *RegionStatistics) ClearDefunctRegion(regionID uint64) {
 	if oldIndex, ok := r.index[regionID]; ok {
@@ -143,6 +217,7 @@ func (r *RegionStatistics) Collect() {
 	regionStatusGauge.WithLabelValues("offline-peer-region-count").Set(float64(len(r.stats[OfflinePeer])))
 	regionStatusGauge.WithLabelValues("learner-peer-region-count").Set(float64(len(r.stats[LearnerPeer])))
 	regionStatusGauge.WithLabelValues("empty-region-count").Set(float64(len(r.stats[EmptyRegion])))
+	regionStatusGauge.WithLabelValues("under-isolation-region-count").Set(float64(len(r.stats[UnderIsolation])))
 }
 
 // Reset resets the metrics of the regions' status.
@@ -58,7 +58,7 @@ func (t *testRegionStatisticsSuite) TestRegionStatistics(c *C) {
 	r2 := &metapb.Region{Id: 2, Peers: peers[0:2], StartKey: []byte("cc"), EndKey: []byte("dd")}
 	region1 := core.NewRegionInfo(r1, peers[0])
 	region2 := core.NewRegionInfo(r2, peers[0])
-	regionStats := NewRegionStatistics(opt)
+	regionStats := NewRegionStatistics(opt, nil, nil)
 	regionStats.Observe(region1, stores)
 	c.Assert(len(regionStats.stats[ExtraPeer]), Equals, 1)
 	c.Assert(len(regionStats.stats[LearnerPeer]), Equals, 1)
@@ -101,6 +101,49 @@ func (t *testRegionStatisticsSuite) TestRegionStatistics(c *C) {
 	c.Assert(len(regionStats.stats[OfflinePeer]), Equals, 0)
 }
 
+func (t *testRegionStatisticsSuite) TestRegionIsolationStatistics(c *C) {
+	opt := mockoption.NewScheduleOptions()
+	opt.LocationLabels = []string{"zone", "host"}
+	opt.IsolationLevel = "zone"
+	stores := []*core.StoreInfo{
+		core.NewStoreInfoWithLabel(1, 1, map[string]string{"zone": "z1", "host": "h1"}),
+		core.NewStoreInfoWithLabel(2, 1, map[string]string{"zone": "z1", "host": "h2"}),
+		core.NewStoreInfoWithLabel(3, 1, map[string]string{"zone": "z2", "host": "h3"}),
+		core.NewStoreInfoWithLabel(4, 1, map[string]string{"zone": "z3", "host": "h4"}),
+	}
+	peers := []*metapb.Peer{
+		{Id: 5, StoreId: 1},
+		{Id: 6, StoreId: 2},
+		{Id: 7, StoreId: 3},
+	}
+	region := core.NewRegionInfo(&metapb.Region{Id: 1, Peers: peers}, peers[0])
+	regionStats := NewRegionStatistics(opt, nil, nil)
+
+	// Store 1 and 2 are in the same zone.
+	c.Assert(regionStats.IsUnderIsolation(region, stores[:3]), IsTrue)
+	// The region must be observed first.
+	regionStats.ObserveIsolation(region, true)
+	c.Assert(regionStats.stats[UnderIsolation], HasLen, 0)
+	regionStats.Observe(region, stores[:3])
+	c.Assert(regionStats.stats[UnderIsolation], HasLen, 0)
+	regionStats.ObserveIsolation(region, true)
+	c.Assert(regionStats.stats[UnderIsolation], HasLen, 1)
+	// The heartbeats keep the isolation state.
+	region = region.Clone(core.SetApproximateSize(100))
+	regionStats.Observe(region, stores[:3])
+	c.Assert(regionStats.stats[UnderIsolation], HasLen, 1)
+	c.Assert(regionStats.stats[UnderIsolation][1], Equals, region)
+
+	// Move the peer from store 2 to store 4.
+	region = region.Clone(core.WithRemoveStorePeer(2), core.WithAddPeer(&metapb.Peer{Id: 8, StoreId: 4}))
+	regionStores := []*core.StoreInfo{stores[0], stores[2], stores[3]}
+	c.Assert(regionStats.IsUnderIsolation(region, regionStores), IsFalse)
+	regionStats.Observe(region, regionStores)
+	regionStats.ObserveIsolation(region, false)
+	c.Assert(regionStats.stats[UnderIsolation], HasLen, 0)
+	c.Assert(regionStats.index[1]&UnderIsolation, Equals, RegionStatisticType(0))
+}
+
 func (t *testRegionStatisticsSuite) TestRegionLabelIsolationLevel(c *C) {
 	locationLabels := []string{"zone", "rack", "host"}
 	labelLevelStats := NewLabelStatistics()
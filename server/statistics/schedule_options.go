@@ -23,6 +23,7 @@ import (
 // TODO: merge the Options to schedule.Options
 type ScheduleOptions interface {
 	GetLocationLabels() []string
+	GetIsolationLevel() string
 
 	GetLowSpaceRatio() float64
 	GetHighSpaceRatio() float64
@@ -48,6 +49,7 @@ type ScheduleOptions interface {
 	IsRemoveExtraReplicaEnabled() bool
 	IsRemoveDownReplicaEnabled() bool
 	IsReplaceOfflineReplicaEnabled() bool
+	IsPlacementRulesEnabled() bool
 
 	GetMaxStoreDownTime() time.Duration
 }
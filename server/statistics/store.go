@@ -202,6 +202,28 @@ func (s *StoresStats) GetStoresCPUUsage() map[uint64]float64 {
 	return res
 }
 
+// GetStoresOpLatency returns the operation latency stat of all StoreInfo.
+func (s *StoresStats) GetStoresOpLatency() map[uint64]float64 {
+	s.RLock()
+	defer s.RUnlock()
+	res := make(map[uint64]float64, len(s.rollingStoresStats))
+	for storeID, stats := range s.rollingStoresStats {
+		res[storeID] = stats.GetOpLatency()
+	}
+	return res
+}
+
+// GetStoresBusyRatio returns the busy ratio stat of all StoreInfo.
+func (s *StoresStats) GetStoresBusyRatio() map[uint64]float64 {
+	s.RLock()
+	defer s.RUnlock()
+	res := make(map[uint64]float64, len(s.rollingStoresStats))
+	for storeID, stats := range s.rollingStoresStats {
+		res[storeID] = stats.GetBusyRatio()
+	}
+	return res
+}
+
 // GetStoresDiskReadRate returns the disk read rate stat of all StoreInfo.
 func (s *StoresStats) GetStoresDiskReadRate() map[uint64]float64 {
 	s.RLock()
@@ -244,6 +266,17 @@ func (s *StoresStats) GetStoreBytesReadRate(storeID uint64) float64 {
 	return 0
 }
 
+// GetStoreKeysReadAvgRate returns the keys read rate of the specified store
+// averaged over time.
+func (s *StoresStats) GetStoreKeysReadAvgRate(storeID uint64) float64 {
+	s.RLock()
+	defer s.RUnlock()
+	if storeStat, ok := s.rollingStoresStats[storeID]; ok {
+		return storeStat.GetKeysReadAvgRate()
+	}
+	return 0
+}
+
 // GetStoresBytesWriteStat returns the bytes write stat of all StoreInfo.
 func (s *StoresStats) GetStoresBytesWriteStat() map[uint64]float64 {
 	s.RLock()
@@ -297,19 +330,27 @@ type RollingStoreStats struct {
 	bytesReadRate           *TimeMedian
 	keysWriteRate           *TimeMedian
 	keysReadRate            *TimeMedian
+	keysReadAvgRate         *AvgOverTime
 	totalCPUUsage           MovingAvg
 	totalBytesDiskReadRate  MovingAvg
 	totalBytesDiskWriteRate MovingAvg
+	totalOpLatency          MovingAvg
+	busyRatio               MovingAvg
 }
 
 const (
 	storeStatsRollingWindows = 3
+	// storeBusyRollingWindows is the number of heartbeats to calculate the
+	// ratio of a store reporting busy.
+	storeBusyRollingWindows = 10
 	// DefaultAotSize is default size of average over time.
 	DefaultAotSize = 2
 	// DefaultWriteMfSize is default size of write median filter
 	DefaultWriteMfSize = 5
 	// DefaultReadMfSize is default size of read median filter
 	DefaultReadMfSize = 3
+	// storeLoadAvgInterval is the interval to average the load of a store.
+	storeLoadAvgInterval = storeStatsRollingWindows * StoreHeartBeatReportInterval * time.Second
 )
 
 // NewRollingStoreStats creates a RollingStoreStats.
@@ -319,9 +360,12 @@ func newRollingStoreStats() *RollingStoreStats {
 		bytesReadRate:           NewTimeMedian(DefaultAotSize, DefaultReadMfSize),
 		keysWriteRate:           NewTimeMedian(DefaultAotSize, DefaultWriteMfSize),
 		keysReadRate:            NewTimeMedian(DefaultAotSize, DefaultReadMfSize),
+		keysReadAvgRate:         NewAvgOverTime(storeLoadAvgInterval),
 		totalCPUUsage:           NewMedianFilter(storeStatsRollingWindows),
 		totalBytesDiskReadRate:  NewMedianFilter(storeStatsRollingWindows),
 		totalBytesDiskWriteRate: NewMedianFilter(storeStatsRollingWindows),
+		totalOpLatency:          NewMedianFilter(storeStatsRollingWindows),
+		busyRatio:               NewAvgFilter(storeBusyRollingWindows),
 	}
 }
 
@@ -344,11 +388,23 @@ func (r *RollingStoreStats) Observe(stats *pdpb.StoreStats) {
 	r.bytesReadRate.Add(float64(stats.BytesRead), time.Duration(interval)*time.Second)
 	r.keysWriteRate.Add(float64(stats.KeysWritten), time.Duration(interval)*time.Second)
 	r.keysReadRate.Add(float64(stats.KeysRead), time.Duration(interval)*time.Second)
+	if interval > 0 {
+		r.keysReadAvgRate.Add(float64(stats.KeysRead), time.Duration(interval)*time.Second)
+	}
 
 	// Updates the cpu usages and disk rw rates of store.
 	r.totalCPUUsage.Add(collect(stats.GetCpuUsages()))
 	r.totalBytesDiskReadRate.Add(collect(stats.GetReadIoRates()))
 	r.totalBytesDiskWriteRate.Add(collect(stats.GetWriteIoRates()))
+	r.totalOpLatency.Add(collect(stats.GetOpLatencies()))
+	r.busyRatio.Add(busyValue(stats.GetIsBusy()))
+}
+
+func busyValue(isBusy bool) float64 {
+	if isBusy {
+		return 1
+	}
+	return 0
 }
 
 // Set sets the statistics (for test).
@@ -364,6 +420,10 @@ func (r *RollingStoreStats) Set(stats *pdpb.StoreStats) {
 	r.bytesReadRate.Set(float64(stats.BytesRead) / float64(interval))
 	r.keysWriteRate.Set(float64(stats.KeysWritten) / float64(interval))
 	r.keysReadRate.Set(float64(stats.KeysRead) / float64(interval))
+	r.keysReadAvgRate.Set(float64(stats.KeysRead) / float64(interval))
+	r.totalCPUUsage.Set(collect(stats.GetCpuUsages()))
+	r.totalOpLatency.Set(collect(stats.GetOpLatencies()))
+	r.busyRatio.Set(busyValue(stats.GetIsBusy()))
 }
 
 // GetBytesRate returns the bytes write rate and the bytes read rate.
@@ -408,6 +468,14 @@ func (r *RollingStoreStats) GetKeysReadRate() float64 {
 	return r.keysReadRate.Get()
 }
 
+// GetKeysReadAvgRate returns the keys read rate averaged over the last
+// storeLoadAvgInterval.
+func (r *RollingStoreStats) GetKeysReadAvgRate() float64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.keysReadAvgRate.Get()
+}
+
 // GetCPUUsage returns the total cpu usages of threads in the store.
 func (r *RollingStoreStats) GetCPUUsage() float64 {
 	r.RLock()
@@ -415,6 +483,22 @@ func (r *RollingStoreStats) GetCPUUsage() float64 {
 	return r.totalCPUUsage.Get()
 }
 
+// GetOpLatency returns the total latencies of operations in the store, such as
+// handling and applying raft logs.
+func (r *RollingStoreStats) GetOpLatency() float64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.totalOpLatency.Get()
+}
+
+// GetBusyRatio returns the ratio of recent heartbeats reporting the store is
+// busy.
+func (r *RollingStoreStats) GetBusyRatio() float64 {
+	r.RLock()
+	defer r.RUnlock()
+	return r.busyRatio.Get()
+}
+
 // GetDiskReadRate returns the total read disk io rate of threads in the store.
 func (r *RollingStoreStats) GetDiskReadRate() float64 {
 	r.RLock()
@@ -82,3 +82,52 @@ func (r *MedianFilter) Set(n float64) {
 	r.records[0] = n
 	r.count = 1
 }
+
+// AvgFilter works as a simple moving average with specified window size.
+// There are at most `size` data points for calculating.
+type AvgFilter struct {
+	records []float64
+	size    uint64
+	count   uint64
+}
+
+// NewAvgFilter returns an AvgFilter.
+func NewAvgFilter(size int) *AvgFilter {
+	return &AvgFilter{
+		records: make([]float64, size),
+		size:    uint64(size),
+	}
+}
+
+// Add adds a data point.
+func (r *AvgFilter) Add(n float64) {
+	r.records[r.count%r.size] = n
+	r.count++
+}
+
+// Get returns the average of the data set.
+func (r *AvgFilter) Get() float64 {
+	if r.count == 0 {
+		return 0
+	}
+	records := r.records
+	if r.count < r.size {
+		records = r.records[:r.count]
+	}
+	var sum float64
+	for _, n := range records {
+		sum += n
+	}
+	return sum / float64(len(records))
+}
+
+// Reset cleans the data set.
+func (r *AvgFilter) Reset() {
+	r.count = 0
+}
+
+// Set = Reset + Add.
+func (r *AvgFilter) Set(n float64) {
+	r.records[0] = n
+	r.count = 1
+}
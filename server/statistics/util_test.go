@@ -81,3 +81,16 @@ func (t *testMovingAvg) TestMedianFilter(c *C) {
 	t.checkAdd(c, mf, data, expected)
 	t.checkSet(c, mf, data, expected)
 }
+
+func (t *testMovingAvg) TestAvgFilter(c *C) {
+	var empty float64 = 0
+	data := []float64{1, 0, 1, 1, 0, 0, 0}
+	expected := []float64{1, 0.5, 2.0 / 3, 0.75, 0.6, 0.4, 0.4}
+
+	af := NewAvgFilter(5)
+	c.Assert(af.Get(), Equals, empty)
+
+	t.checkReset(c, af, empty)
+	t.checkAdd(c, af, data, expected)
+	t.checkSet(c, af, data, expected)
+}
@@ -0,0 +1,605 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tso
+
+import (
+	"context"
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/etcdutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
+	"github.com/pingcap/pd/v4/server/kv"
+	"github.com/pingcap/pd/v4/server/member"
+	"github.com/pkg/errors"
+	"go.etcd.io/etcd/clientv3"
+	"go.etcd.io/etcd/mvcc/mvccpb"
+	"go.uber.org/zap"
+)
+
+const (
+	// GlobalDCLocation is the dc-location of the global TSO, which is also
+	// used if a request has no dc-location.
+	GlobalDCLocation = "global"
+
+	localTSOPrefix        = "lta"
+	localTSOCheckInterval = 200 * time.Millisecond
+	localTSOQueryTimeout  = 3 * time.Second
+)
+
+var (
+	// ErrNotLocalTSOLeader is returned if the server is not the leader of the
+	// local TSO of the requested data center.
+	ErrNotLocalTSOLeader = errors.New("not the leader of the local tso")
+	// ErrLocalTSONotSynced is returned if the local TSO is not synced by the
+	// global TSO yet.
+	ErrLocalTSONotSynced = errors.New("local tso is not synced by the global tso")
+)
+
+func localTSORootPath(rootPath string, dcLocation string) string {
+	return path.Join(rootPath, localTSOPrefix, dcLocation)
+}
+
+func localTimestampPath(rootPath string, dcLocation string) string {
+	return path.Join(localTSORootPath(rootPath, dcLocation), "timestamp")
+}
+
+func localLeaderPath(rootPath string, dcLocation string) string {
+	return path.Join(localTSORootPath(rootPath, dcLocation), "leader")
+}
+
+// LocalTSOQuerier gets the current timestamp of the local TSO of the data
+// center from its leader, on behalf of the global TSO.
+type LocalTSOQuerier func(ctx context.Context, dcLocation string, leader *pdpb.Member) (pdpb.Timestamp, error)
+
+type localTSOResult struct {
+	ts  pdpb.Timestamp
+	err error
+}
+
+// AllocatorManager manages the global TSO and the local TSO of the data
+// center of the server. The local TSO of a data center is led by a PD member
+// in the data center, which is elected among them like the PD leader. The
+// global TSO is led by the PD leader, and it is always greater than the
+// timestamps allocated by all the local TSOs before it is requested.
+//
+// To keep the order, the global TSO gets the current timestamps of all the
+// local TSOs for each global request, and synthesizes the global timestamp
+// beyond them. The concurrent global requests share the queries. A local TSO
+// serves the clients only after the global TSO gets its timestamp, so the
+// global TSO always knows all the local TSOs which have allocated timestamps.
+type AllocatorManager struct {
+	client      *clientv3.Client
+	rootPath    string
+	member      string
+	memberID    uint64
+	dcLocation  string
+	leaderLease int64
+
+	global *TimestampOracle
+	// local is nil if the server has no dc-location.
+	local       *TimestampOracle
+	localLeader int32
+	// localSynced is 1 once the global TSO gets the timestamp of the local TSO
+	// after the server becomes the leader of the local TSO.
+	localSynced int32
+
+	querier LocalTSOQuerier
+	// localLeaders are the leaders of the local TSOs, by the data centers.
+	// The watched changes not after leadersRevision, the revision of the last
+	// reload, are ignored.
+	leadersMu       sync.RWMutex
+	localLeaders    map[string]*pdpb.Member
+	leadersRevision int64
+	// The waiters of the global requests share the next query of the local
+	// TSOs, which is sent after all of them arrive.
+	queryMu      sync.Mutex
+	queryWaiters []chan localTSOResult
+	querying     bool
+}
+
+// NewAllocatorManager creates an AllocatorManager. The local TSO is disabled
+// if the dcLocation is empty.
+func NewAllocatorManager(client *clientv3.Client, rootPath string, member string, dcLocation string, leaderLease int64, saveInterval time.Duration, maxResetTsGap func() time.Duration) *AllocatorManager {
+	am := &AllocatorManager{
+		client:       client,
+		rootPath:     rootPath,
+		member:       member,
+		dcLocation:   dcLocation,
+		leaderLease:  leaderLease,
+		global:       NewTimestampOracle(client, rootPath, member, saveInterval, maxResetTsGap),
+		localLeaders: make(map[string]*pdpb.Member),
+	}
+	m := &pdpb.Member{}
+	if err := m.Unmarshal([]byte(member)); err == nil {
+		am.memberID = m.GetMemberId()
+	}
+	if dcLocation != "" {
+		am.local = NewLocalTimestampOracle(client, rootPath, dcLocation, member, saveInterval, maxResetTsGap)
+	}
+	return am
+}
+
+// SetSuffix sets the fixed suffix of the logical part of the timestamps of
+// all the TSOs.
+func (am *AllocatorManager) SetSuffix(suffixBits int, suffix int64) {
+	am.global.SetSuffix(suffixBits, suffix)
+	if am.local != nil {
+		am.local.SetSuffix(suffixBits, suffix)
+	}
+}
+
+// SetLocalTSOQuerier sets the querier used by the global TSO to get the
+// timestamps of the local TSOs led by other servers.
+func (am *AllocatorManager) SetLocalTSOQuerier(querier LocalTSOQuerier) {
+	am.querier = querier
+}
+
+// GetGlobalAllocator returns the global TSO.
+func (am *AllocatorManager) GetGlobalAllocator() *TimestampOracle {
+	return am.global
+}
+
+// GetDCLocation returns the data center of the server.
+func (am *AllocatorManager) GetDCLocation() string {
+	return am.dcLocation
+}
+
+// IsLocalLeader returns whether the server is the leader of the local TSO of
+// its data center.
+func (am *AllocatorManager) IsLocalLeader() bool {
+	return atomic.LoadInt32(&am.localLeader) == 1
+}
+
+// GetTSO allocates the timestamps from the TSO of the data center. The global
+// TSO is used if the dcLocation is empty or GlobalDCLocation.
+func (am *AllocatorManager) GetTSO(dcLocation string, count uint32) (pdpb.Timestamp, error) {
+	if dcLocation == "" || dcLocation == GlobalDCLocation {
+		return am.getGlobalTSO(count)
+	}
+	if dcLocation != am.dcLocation || !am.IsLocalLeader() {
+		return pdpb.Timestamp{}, errors.WithMessagef(ErrNotLocalTSOLeader, "dc-location %s", dcLocation)
+	}
+	if atomic.LoadInt32(&am.localSynced) == 0 {
+		return pdpb.Timestamp{}, errors.WithMessagef(ErrLocalTSONotSynced, "dc-location %s", dcLocation)
+	}
+	return am.local.GetRespTS(count)
+}
+
+// SyncLocalTSO allocates a timestamp from the local TSO of the data center for
+// the global TSO, and marks the local TSO synced.
+func (am *AllocatorManager) SyncLocalTSO(dcLocation string) (pdpb.Timestamp, error) {
+	if dcLocation != am.dcLocation || !am.IsLocalLeader() {
+		return pdpb.Timestamp{}, errors.WithMessagef(ErrNotLocalTSOLeader, "dc-location %s", dcLocation)
+	}
+	ts, err := am.local.GetRespTS(1)
+	if err != nil {
+		return pdpb.Timestamp{}, err
+	}
+	atomic.StoreInt32(&am.localSynced, 1)
+	return ts, nil
+}
+
+// getGlobalTSO allocates the global timestamps beyond the current timestamps
+// of all the local TSOs. It fails if any local TSO can't be reached, since
+// the order can't be kept then.
+func (am *AllocatorManager) getGlobalTSO(count uint32) (pdpb.Timestamp, error) {
+	maxLocal, err := am.getMaxLocalTSO()
+	if err != nil {
+		return pdpb.Timestamp{}, err
+	}
+	if maxLocal.GetPhysical() > 0 {
+		if err := am.global.AdvanceTimestamp(physicalTime(maxLocal.GetPhysical())); err != nil {
+			return pdpb.Timestamp{}, err
+		}
+	}
+	return am.global.GetRespTS(count)
+}
+
+func physicalTime(physical int64) time.Time {
+	return time.Unix(0, physical*int64(time.Millisecond))
+}
+
+// getMaxLocalTSO gets the max current timestamp of the local TSOs. The caller
+// waits for the next query sent after it arrives, which is shared by all the
+// callers waiting for it.
+func (am *AllocatorManager) getMaxLocalTSO() (pdpb.Timestamp, error) {
+	am.leadersMu.RLock()
+	noLocal := len(am.localLeaders) == 0
+	am.leadersMu.RUnlock()
+	if noLocal {
+		return pdpb.Timestamp{}, nil
+	}
+
+	ch := make(chan localTSOResult, 1)
+	am.queryMu.Lock()
+	am.queryWaiters = append(am.queryWaiters, ch)
+	if !am.querying {
+		am.querying = true
+		go am.queryLocalTSOLoop()
+	}
+	am.queryMu.Unlock()
+	res := <-ch
+	return res.ts, res.err
+}
+
+func (am *AllocatorManager) queryLocalTSOLoop() {
+	for {
+		am.queryMu.Lock()
+		waiters := am.queryWaiters
+		am.queryWaiters = nil
+		if len(waiters) == 0 {
+			am.querying = false
+			am.queryMu.Unlock()
+			return
+		}
+		am.queryMu.Unlock()
+
+		ts, err := am.queryLocalTSOs()
+		for _, ch := range waiters {
+			ch <- localTSOResult{ts: ts, err: err}
+		}
+	}
+}
+
+// queryLocalTSOs gets the timestamps of all the local TSOs in parallel, and
+// returns the max one.
+func (am *AllocatorManager) queryLocalTSOs() (pdpb.Timestamp, error) {
+	am.leadersMu.RLock()
+	leaders := make(map[string]*pdpb.Member, len(am.localLeaders))
+	for dcLocation, leader := range am.localLeaders {
+		leaders[dcLocation] = leader
+	}
+	am.leadersMu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), localTSOQueryTimeout)
+	defer cancel()
+	results := make(chan localTSOResult, len(leaders))
+	for dcLocation, leader := range leaders {
+		go func(dcLocation string, leader *pdpb.Member) {
+			ts, err := am.queryLocalTSO(ctx, dcLocation, leader)
+			results <- localTSOResult{ts: ts, err: errors.WithMessagef(err, "query local tso of %s", dcLocation)}
+		}(dcLocation, leader)
+	}
+	var maxTS pdpb.Timestamp
+	for range leaders {
+		res := <-results
+		if res.err != nil {
+			tsoCounter.WithLabelValues("err_query_local_ts").Inc()
+			return pdpb.Timestamp{}, res.err
+		}
+		if res.ts.GetPhysical() > maxTS.GetPhysical() ||
+			(res.ts.GetPhysical() == maxTS.GetPhysical() && res.ts.GetLogical() > maxTS.GetLogical()) {
+			maxTS = res.ts
+		}
+	}
+	return maxTS, nil
+}
+
+func (am *AllocatorManager) queryLocalTSO(ctx context.Context, dcLocation string, leader *pdpb.Member) (pdpb.Timestamp, error) {
+	if dcLocation == am.dcLocation && leader.GetMemberId() == am.memberID {
+		return am.SyncLocalTSO(dcLocation)
+	}
+	if am.querier == nil {
+		return pdpb.Timestamp{}, errors.New("no local tso querier")
+	}
+	return am.querier(ctx, dcLocation, leader)
+}
+
+// SyncGlobalTimestamp synchronizes the global TSO after the server becomes
+// the PD leader. The leaders of the local TSOs are reloaded, since the global
+// TSO must know all of them before it serves. The global TSO is advanced
+// beyond the saved time windows of the local TSOs once, which covers the
+// timestamps allocated by the local leaders stepped down.
+func (am *AllocatorManager) SyncGlobalTimestamp(lease *member.LeaderLease) error {
+	if err := am.global.SyncTimestamp(lease); err != nil {
+		return err
+	}
+	resp, err := etcdutil.EtcdKVGet(am.client, path.Join(am.rootPath, localTSOPrefix)+"/", clientv3.WithPrefix())
+	if err != nil {
+		return err
+	}
+	am.resetLocalLeaders(resp.Kvs, resp.Header.GetRevision())
+	for _, item := range resp.Kvs {
+		if !strings.HasSuffix(string(item.Key), "/timestamp") {
+			continue
+		}
+		window, err := typeutil.ParseTimestamp(item.Value)
+		if err != nil {
+			return err
+		}
+		if err := am.global.AdvanceTimestamp(window); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateGlobalTimestamp updates the global TSO, and gets the timestamps of
+// the local TSOs in the background if no query is running, which syncs the
+// new local leaders before any global request comes.
+func (am *AllocatorManager) UpdateGlobalTimestamp() error {
+	if err := am.global.UpdateTimestamp(); err != nil {
+		return err
+	}
+	am.queryMu.Lock()
+	querying := am.querying
+	am.queryMu.Unlock()
+	if !querying {
+		go func() {
+			if _, err := am.getMaxLocalTSO(); err != nil {
+				log.Debug("failed to get the timestamps of local tso", zap.Error(err))
+			}
+		}()
+	}
+	return nil
+}
+
+// WatchLocalLeadersLoop caches the leaders of the local TSOs, and keeps them
+// up to date by watching the leader keys until the context is done.
+func (am *AllocatorManager) WatchLocalLeadersLoop(ctx context.Context) {
+	prefix := path.Join(am.rootPath, localTSOPrefix) + "/"
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("server is closed, exit local tso leader watch loop")
+			return
+		default:
+		}
+
+		resp, err := etcdutil.EtcdKVGet(am.client, prefix, clientv3.WithPrefix())
+		if err != nil {
+			log.Error("load local tso leaders meet error", zap.Error(err))
+			time.Sleep(localTSOCheckInterval)
+			continue
+		}
+		am.resetLocalLeaders(resp.Kvs, resp.Header.GetRevision())
+		am.watchLocalLeaders(ctx, prefix, resp.Header.GetRevision()+1)
+	}
+}
+
+func (am *AllocatorManager) watchLocalLeaders(ctx context.Context, prefix string, revision int64) {
+	watcher := clientv3.NewWatcher(am.client)
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rch := watcher.Watch(ctx, prefix, clientv3.WithPrefix(), clientv3.WithRev(revision))
+	for wresp := range rch {
+		if wresp.CompactRevision != 0 || wresp.Canceled {
+			// Reload the leaders and watch again.
+			log.Warn("local tso leader watcher is interrupted", zap.Int64("revision", revision), zap.Error(wresp.Err()))
+			time.Sleep(localTSOCheckInterval)
+			return
+		}
+		for _, ev := range wresp.Events {
+			dcLocation, ok := parseLocalLeaderKey(prefix, string(ev.Kv.Key))
+			if !ok {
+				continue
+			}
+			switch ev.Type {
+			case mvccpb.PUT:
+				am.putLocalLeader(dcLocation, ev.Kv)
+			case mvccpb.DELETE:
+				am.deleteLocalLeader(dcLocation, ev.Kv)
+			}
+		}
+	}
+}
+
+// parseLocalLeaderKey returns the data center of the leader key of a local
+// TSO.
+func parseLocalLeaderKey(prefix string, key string) (string, bool) {
+	if !strings.HasPrefix(key, prefix) || !strings.HasSuffix(key, "/leader") {
+		return "", false
+	}
+	return strings.TrimSuffix(strings.TrimPrefix(key, prefix), "/leader"), true
+}
+
+func (am *AllocatorManager) resetLocalLeaders(items []*mvccpb.KeyValue, revision int64) {
+	prefix := path.Join(am.rootPath, localTSOPrefix) + "/"
+	leaders := make(map[string]*pdpb.Member)
+	for _, item := range items {
+		dcLocation, ok := parseLocalLeaderKey(prefix, string(item.Key))
+		if !ok {
+			continue
+		}
+		leader := &pdpb.Member{}
+		if err := leader.Unmarshal(item.Value); err != nil {
+			log.Error("parse local tso leader meet error", zap.String("dc-location", dcLocation), zap.Error(err))
+			continue
+		}
+		leaders[dcLocation] = leader
+	}
+	am.leadersMu.Lock()
+	defer am.leadersMu.Unlock()
+	if revision < am.leadersRevision {
+		return
+	}
+	am.localLeaders, am.leadersRevision = leaders, revision
+}
+
+func (am *AllocatorManager) putLocalLeader(dcLocation string, item *mvccpb.KeyValue) {
+	leader := &pdpb.Member{}
+	if err := leader.Unmarshal(item.Value); err != nil {
+		log.Error("parse local tso leader meet error", zap.String("dc-location", dcLocation), zap.Error(err))
+		return
+	}
+	am.leadersMu.Lock()
+	defer am.leadersMu.Unlock()
+	if item.ModRevision > am.leadersRevision {
+		am.localLeaders[dcLocation] = leader
+	}
+}
+
+// deleteLocalLeader removes the leader stepped down. The global TSO is
+// advanced beyond the saved time window of the local TSO first, which covers
+// the timestamps allocated by the leader after the last query.
+func (am *AllocatorManager) deleteLocalLeader(dcLocation string, item *mvccpb.KeyValue) {
+	if am.global.isSynced() {
+		if err := am.advanceBeyondLocalWindow(dcLocation); err != nil {
+			log.Error("advance global tso beyond local tso window meet error", zap.String("dc-location", dcLocation), zap.Error(err))
+		}
+	}
+	am.leadersMu.Lock()
+	defer am.leadersMu.Unlock()
+	if item.ModRevision > am.leadersRevision {
+		delete(am.localLeaders, dcLocation)
+	}
+}
+
+func (am *AllocatorManager) advanceBeyondLocalWindow(dcLocation string) error {
+	resp, err := etcdutil.EtcdKVGet(am.client, localTimestampPath(am.rootPath, dcLocation))
+	if err != nil {
+		return err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil
+	}
+	window, err := typeutil.ParseTimestamp(resp.Kvs[0].Value)
+	if err != nil {
+		return err
+	}
+	return am.global.AdvanceTimestamp(window)
+}
+
+// LocalAllocatorLoop campaigns the leader of the local TSO of the data center
+// and serves it until the context is done. It returns immediately if the
+// server has no dc-location.
+func (am *AllocatorManager) LocalAllocatorLoop(ctx context.Context) {
+	if am.local == nil {
+		return
+	}
+	leaderPath := localLeaderPath(am.rootPath, am.dcLocation)
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("server is closed, exit local tso allocator loop", zap.String("dc-location", am.dcLocation))
+			return
+		default:
+		}
+
+		resp, err := etcdutil.EtcdKVGet(am.client, leaderPath)
+		if err != nil {
+			log.Error("get local tso leader meet error", zap.String("dc-location", am.dcLocation), zap.Error(err))
+			time.Sleep(localTSOCheckInterval)
+			continue
+		}
+		if len(resp.Kvs) > 0 {
+			// Wait for the current leader to step down or its lease to expire.
+			am.watchLocalLeader(ctx, leaderPath, resp.Kvs[0].ModRevision)
+			continue
+		}
+		am.campaignLocalLeader(ctx, leaderPath)
+		time.Sleep(localTSOCheckInterval)
+	}
+}
+
+func (am *AllocatorManager) watchLocalLeader(ctx context.Context, leaderPath string, revision int64) {
+	watcher := clientv3.NewWatcher(am.client)
+	defer watcher.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for {
+		rch := watcher.Watch(ctx, leaderPath, clientv3.WithRev(revision))
+		for wresp := range rch {
+			if wresp.CompactRevision != 0 {
+				revision = wresp.CompactRevision
+				break
+			}
+			if wresp.Canceled {
+				log.Error("local tso leader watcher is canceled", zap.Int64("revision", revision), zap.Error(wresp.Err()))
+				return
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type == mvccpb.DELETE {
+					log.Info("local tso leader is deleted", zap.String("dc-location", am.dcLocation))
+					return
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
+
+func (am *AllocatorManager) campaignLocalLeader(ctx context.Context, leaderPath string) {
+	lease := member.NewLeaderLease(am.client)
+	defer lease.Close()
+	if err := lease.Grant(am.leaderLease); err != nil {
+		log.Error("grant local tso leader lease meet error", zap.Error(err))
+		return
+	}
+	// The leader key must not exist, so the CreateRevision is 0.
+	resp, err := kv.NewSlowLogTxn(am.client).
+		If(clientv3.Compare(clientv3.CreateRevision(leaderPath), "=", 0)).
+		Then(clientv3.OpPut(leaderPath, am.member, clientv3.WithLease(lease.ID))).
+		Commit()
+	if err != nil {
+		log.Error("campaign local tso leader meet error", zap.String("dc-location", am.dcLocation), zap.Error(err))
+		return
+	}
+	if !resp.Succeeded {
+		log.Info("failed to campaign local tso leader, other server may campaign ok", zap.String("dc-location", am.dcLocation))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go lease.KeepAlive(ctx)
+
+	if err := am.local.SyncTimestamp(lease); err != nil {
+		log.Error("failed to sync local timestamp", zap.String("dc-location", am.dcLocation), zap.Error(err))
+		return
+	}
+	defer am.local.ResetTimestamp()
+
+	atomic.StoreInt32(&am.localLeader, 1)
+	defer func() {
+		atomic.StoreInt32(&am.localSynced, 0)
+		atomic.StoreInt32(&am.localLeader, 0)
+	}()
+	log.Info("campaign local tso leader ok", zap.String("dc-location", am.dcLocation))
+
+	tsTicker := time.NewTicker(UpdateTimestampStep)
+	defer tsTicker.Stop()
+	leaderTicker := time.NewTicker(localTSOCheckInterval)
+	defer leaderTicker.Stop()
+	for {
+		select {
+		case <-leaderTicker.C:
+			if lease.IsExpired() {
+				log.Info("local tso leader lease expired, step down", zap.String("dc-location", am.dcLocation))
+				return
+			}
+		case <-tsTicker.C:
+			if err := am.local.UpdateTimestamp(); err != nil {
+				log.Error("failed to update local timestamp", zap.String("dc-location", am.dcLocation), zap.Error(err))
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
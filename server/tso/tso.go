@@ -15,6 +15,7 @@ package tso
 
 import (
 	"path"
+	"sync"
 	"sync/atomic"
 	"time"
 	"unsafe"
@@ -41,6 +42,9 @@ const (
 
 // TimestampOracle is used to maintain the logic of tso.
 type TimestampOracle struct {
+	// updateMu serializes the updates of the physical time and the time
+	// window, which may come from the update loop and the requests.
+	updateMu sync.Mutex
 	// For tso, set after pd becomes leader.
 	ts            unsafe.Pointer
 	lastSavedTime atomic.Value
@@ -51,6 +55,13 @@ type TimestampOracle struct {
 	client        *clientv3.Client
 	saveInterval  time.Duration
 	maxResetTsGap func() time.Duration
+	// dcLocation is the data center of the local TSO, it is empty for the
+	// global TSO.
+	dcLocation string
+	// The lowest suffixBits bits of the logical part are the fixed suffix,
+	// and the logical counter steps by 1<<suffixBits.
+	suffixBits int
+	suffix     int64
 }
 
 // NewTimestampOracle creates a new TimestampOracle.
@@ -65,15 +76,53 @@ func NewTimestampOracle(client *clientv3.Client, rootPath string, member string,
 	}
 }
 
+// NewLocalTimestampOracle creates a TimestampOracle of the data center. Its
+// time window is saved under the path of the data center, and it can only be
+// saved by the leader of the local TSO of the data center.
+func NewLocalTimestampOracle(client *clientv3.Client, rootPath string, dcLocation string, member string, saveInterval time.Duration, maxResetTsGap func() time.Duration) *TimestampOracle {
+	t := NewTimestampOracle(client, rootPath, member, saveInterval, maxResetTsGap)
+	t.dcLocation = dcLocation
+	return t
+}
+
+// SetSuffix sets the fixed suffix of the logical part of the timestamps. It
+// should be called before the TSO is used.
+func (t *TimestampOracle) SetSuffix(suffixBits int, suffix int64) {
+	t.suffixBits, t.suffix = suffixBits, suffix
+}
+
+// GetDCLocation returns the data center of the TSO, it is empty for the global
+// TSO.
+func (t *TimestampOracle) GetDCLocation() string {
+	return t.dcLocation
+}
+
 type atomicObject struct {
 	physical time.Time
 	logical  int64
 }
 
 func (t *TimestampOracle) getTimestampPath() string {
+	if t.dcLocation != "" {
+		return localTimestampPath(t.rootPath, t.dcLocation)
+	}
 	return path.Join(t.rootPath, "timestamp")
 }
 
+func (t *TimestampOracle) getLeaderPath() string {
+	if t.dcLocation != "" {
+		return localLeaderPath(t.rootPath, t.dcLocation)
+	}
+	return path.Join(t.rootPath, "leader")
+}
+
+func (t *TimestampOracle) getMetricLabel() string {
+	if t.dcLocation != "" {
+		return "tso-" + t.dcLocation
+	}
+	return "tso"
+}
+
 func (t *TimestampOracle) loadTimestamp() (time.Time, error) {
 	data, err := etcdutil.GetValue(t.client, t.getTimestampPath())
 	if err != nil {
@@ -91,7 +140,7 @@ func (t *TimestampOracle) saveTimestamp(ts time.Time) error {
 	data := typeutil.Uint64ToBytes(uint64(ts.UnixNano()))
 	key := t.getTimestampPath()
 
-	leaderPath := path.Join(t.rootPath, "leader")
+	leaderPath := t.getLeaderPath()
 	txn := kv.NewSlowLogTxn(t.client).If(append([]clientv3.Cmp{}, clientv3.Compare(clientv3.Value(leaderPath), "=", t.member))...)
 	resp, err := txn.Then(clientv3.OpPut(key, string(data))).Commit()
 	if err != nil {
@@ -108,6 +157,8 @@ func (t *TimestampOracle) saveTimestamp(ts time.Time) error {
 
 // SyncTimestamp is used to synchronize the timestamp.
 func (t *TimestampOracle) SyncTimestamp(lease *member.LeaderLease) error {
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
 	tsoCounter.WithLabelValues("sync").Inc()
 
 	last, err := t.loadTimestamp()
@@ -151,6 +202,8 @@ func (t *TimestampOracle) ResetUserTimestamp(tso uint64) error {
 		tsoCounter.WithLabelValues("err_lease_reset_ts").Inc()
 		return errors.New("Setup timestamp failed, lease expired")
 	}
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
 	physical, _ := tsoutil.ParseTS(tso)
 	next := physical.Add(time.Millisecond)
 	prev := (*atomicObject)(atomic.LoadPointer(&t.ts))
@@ -174,23 +227,25 @@ func (t *TimestampOracle) ResetUserTimestamp(tso uint64) error {
 	update := &atomicObject{
 		physical: next,
 	}
-	atomic.CompareAndSwapPointer(&t.ts, unsafe.Pointer(prev), unsafe.Pointer(update))
+	atomic.StorePointer(&t.ts, unsafe.Pointer(update))
 	tsoCounter.WithLabelValues("reset_tso_ok").Inc()
 	return nil
 }
 
 // UpdateTimestamp is used to update the timestamp.
 // This function will do two things:
-// 1. When the logical time is going to be used up, the current physical time needs to increase.
-// 2. If the time window is not enough, which means the saved etcd time minus the next physical time
-//    is less than or equal to `updateTimestampGuard`, it will need to be updated and save the
-//    next physical time plus `TsoSaveInterval` into etcd.
+//  1. When the logical time is going to be used up, the current physical time needs to increase.
+//  2. If the time window is not enough, which means the saved etcd time minus the next physical time
+//     is less than or equal to `updateTimestampGuard`, it will need to be updated and save the
+//     next physical time plus `TsoSaveInterval` into etcd.
 //
 // Here is some constraints that this function must satisfy:
 // 1. The physical time is monotonically increasing.
 // 2. The saved time is monotonically increasing.
 // 3. The physical time is always less than the saved timestamp.
 func (t *TimestampOracle) UpdateTimestamp() error {
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
 	prev := (*atomicObject)(atomic.LoadPointer(&t.ts))
 	now := time.Now()
 
@@ -242,13 +297,51 @@ func (t *TimestampOracle) UpdateTimestamp() error {
 	}
 
 	atomic.StorePointer(&t.ts, unsafe.Pointer(current))
-	tsoGauge.WithLabelValues("tso").Set(float64(next.Unix()))
+	tsoGauge.WithLabelValues(t.getMetricLabel()).Set(float64(next.Unix()))
 
 	return nil
 }
 
+// AdvanceTimestamp makes the physical time of the TSO greater than the
+// specified time in milliseconds, and the time window is saved if needed. It
+// does nothing if the physical time is already greater.
+func (t *TimestampOracle) AdvanceTimestamp(after time.Time) error {
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
+	prev := (*atomicObject)(atomic.LoadPointer(&t.ts))
+	if prev == nil || prev.physical == typeutil.ZeroTime {
+		return errors.New("advance timestamp failed, timestamp is not synced")
+	}
+	// The physical part of the timestamps is in milliseconds.
+	next := after.Truncate(time.Millisecond).Add(time.Millisecond)
+	if !prev.physical.Before(next) {
+		return nil
+	}
+	if typeutil.SubTimeByWallClock(t.lastSavedTime.Load().(time.Time), next) <= updateTimestampGuard {
+		save := next.Add(t.saveInterval)
+		if err := t.saveTimestamp(save); err != nil {
+			tsoCounter.WithLabelValues("err_save_advance_ts").Inc()
+			return err
+		}
+	}
+	update := &atomicObject{
+		physical: next,
+	}
+	atomic.StorePointer(&t.ts, unsafe.Pointer(update))
+	tsoCounter.WithLabelValues("advance_ts_ok").Inc()
+	return nil
+}
+
+// isSynced returns whether the timestamp is synced and not reset.
+func (t *TimestampOracle) isSynced() bool {
+	current := (*atomicObject)(atomic.LoadPointer(&t.ts))
+	return current != nil && current.physical != typeutil.ZeroTime
+}
+
 // ResetTimestamp is used to reset the timestamp.
 func (t *TimestampOracle) ResetTimestamp() {
+	t.updateMu.Lock()
+	defer t.updateMu.Unlock()
 	zero := &atomicObject{
 		physical: typeutil.ZeroTime,
 	}
@@ -278,7 +371,8 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 		}
 
 		resp.Physical = current.physical.UnixNano() / int64(time.Millisecond)
-		resp.Logical = atomic.AddInt64(&current.logical, int64(count))
+		// The lowest suffixBits bits of the counter are always 0.
+		resp.Logical = atomic.AddInt64(&current.logical, int64(count)<<uint(t.suffixBits))
 		if resp.Logical >= maxLogical {
 			log.Error("logical part outside of max logical interval, please check ntp time",
 				zap.Reflect("response", resp),
@@ -290,6 +384,7 @@ func (t *TimestampOracle) GetRespTS(count uint32) (pdpb.Timestamp, error) {
 		if t.lease == nil || t.lease.IsExpired() {
 			return pdpb.Timestamp{}, errors.New("alloc timestamp failed, lease expired")
 		}
+		resp.Logical |= t.suffix
 		return resp, nil
 	}
 	return resp, errors.New("can not get timestamp")
@@ -0,0 +1,197 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pingcap/pd/v4/pkg/logutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+const (
+	// tsoProxyMetadataKey is the key of the gRPC metadata to ask a follower
+	// to proxy the TSO requests of the stream to the leader.
+	tsoProxyMetadataKey = "pd-tso-follower-proxy"
+
+	maxMergeTSOProxyRequests = 10000
+	tsoProxyTimeout          = 3 * time.Second
+)
+
+var errTSOProxyLength = errors.New("tso length in proxy response is incorrect")
+
+type tsoProxyRequest struct {
+	count uint32
+	ts    pdpb.Timestamp
+	done  chan error
+}
+
+// tsoFollowerProxy forwards the TSO requests received by a follower to the
+// leader. The requests of all the streams are batched over a single stream to
+// the leader, and the response is split back by the counts of the requests.
+type tsoFollowerProxy struct {
+	s        *Server
+	requests chan *tsoProxyRequest
+
+	// The stream and its connection are only used in the proxy loop.
+	leaderAddr string
+	conn       *grpc.ClientConn
+	stream     pdpb.PD_TsoClient
+	cancel     context.CancelFunc
+}
+
+func newTSOFollowerProxy(s *Server) *tsoFollowerProxy {
+	return &tsoFollowerProxy{
+		s:        s,
+		requests: make(chan *tsoProxyRequest, maxMergeTSOProxyRequests),
+	}
+}
+
+// GetTS forwards a TSO request to the leader and waits for the response.
+func (p *tsoFollowerProxy) GetTS(ctx context.Context, count uint32) (pdpb.Timestamp, error) {
+	req := &tsoProxyRequest{
+		count: count,
+		done:  make(chan error, 1),
+	}
+	select {
+	case p.requests <- req:
+	case <-ctx.Done():
+		return pdpb.Timestamp{}, errors.WithStack(ctx.Err())
+	}
+	select {
+	case err := <-req.done:
+		return req.ts, err
+	case <-ctx.Done():
+		return pdpb.Timestamp{}, errors.WithStack(ctx.Err())
+	}
+}
+
+func (p *tsoFollowerProxy) run(ctx context.Context) {
+	defer logutil.LogPanic()
+	defer p.s.serverLoopWg.Done()
+	defer p.reset()
+
+	var requests []*tsoProxyRequest
+	for {
+		select {
+		case first := <-p.requests:
+			requests = append(requests[:0], first)
+			pending := len(p.requests)
+			for i := 0; i < pending; i++ {
+				requests = append(requests, <-p.requests)
+			}
+		case <-ctx.Done():
+			log.Info("server is closed, exit tso follower proxy loop")
+			return
+		}
+
+		err := p.forward(ctx, requests)
+		for _, req := range requests {
+			req.done <- err
+		}
+		if err != nil {
+			log.Error("failed to proxy tso requests to leader", zap.String("leader-addr", p.leaderAddr), zap.Error(err))
+			p.reset()
+		}
+	}
+}
+
+// forward sends the requests to the leader in a batch. The leader returns
+// the highest timestamp of the batch, so each request gets the highest one of
+// its part. The logical part steps by 1<<suffixBits.
+func (p *tsoFollowerProxy) forward(ctx context.Context, requests []*tsoProxyRequest) error {
+	if err := p.prepareStream(ctx); err != nil {
+		return err
+	}
+	var count uint32
+	for _, req := range requests {
+		count += req.count
+	}
+	// The stream is broken if the leader doesn't respond in time.
+	timer := time.AfterFunc(tsoProxyTimeout, p.cancel)
+	defer timer.Stop()
+	err := p.stream.Send(&pdpb.TsoRequest{
+		Header: &pdpb.RequestHeader{ClusterId: p.s.clusterID},
+		Count:  count,
+	})
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	resp, err := p.stream.Recv()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if resp.GetCount() != count {
+		return errors.WithStack(errTSOProxyLength)
+	}
+	physical, logical := resp.GetTimestamp().GetPhysical(), resp.GetTimestamp().GetLogical()
+	suffixBits := uint(p.s.cfg.TSOSuffixBits)
+	logical -= int64(count) << suffixBits
+	for _, req := range requests {
+		logical += int64(req.count) << suffixBits
+		req.ts = pdpb.Timestamp{Physical: physical, Logical: logical}
+	}
+	return nil
+}
+
+// prepareStream creates the stream to the leader if there is no stream or the
+// leader is changed.
+func (p *tsoFollowerProxy) prepareStream(ctx context.Context) error {
+	leader := p.s.GetLeader()
+	if len(leader.GetClientUrls()) == 0 {
+		return errors.New("no leader to proxy tso requests")
+	}
+	addr := leader.GetClientUrls()[0]
+	if p.stream != nil && addr == p.leaderAddr {
+		return nil
+	}
+	p.reset()
+
+	tlsCfg, err := p.s.GetSecurityConfig().ToTLSConfig()
+	if err != nil {
+		return err
+	}
+	dialCtx, cancel := context.WithTimeout(ctx, tsoProxyTimeout)
+	defer cancel()
+	conn, err := grpcutil.GetClientConn(dialCtx, addr, tlsCfg)
+	if err != nil {
+		return err
+	}
+	streamCtx, streamCancel := context.WithCancel(ctx)
+	stream, err := pdpb.NewPDClient(conn).Tso(streamCtx)
+	if err != nil {
+		streamCancel()
+		conn.Close()
+		return errors.WithStack(err)
+	}
+	log.Info("create tso proxy stream to leader", zap.String("leader-addr", addr))
+	p.leaderAddr, p.conn, p.stream, p.cancel = addr, conn, stream, streamCancel
+	return nil
+}
+
+func (p *tsoFollowerProxy) reset() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	p.leaderAddr, p.conn, p.stream, p.cancel = "", nil, nil, nil
+}
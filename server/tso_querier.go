@@ -0,0 +1,158 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pingcap/kvproto/pkg/pdpb"
+	"github.com/pingcap/log"
+	"github.com/pingcap/pd/v4/pkg/grpcutil"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// tsoSyncMetadataKey is the key of the gRPC metadata to tell the leader of a
+// local TSO that the requests of the stream are from the global TSO.
+const tsoSyncMetadataKey = "pd-tso-sync"
+
+// localTSOQuerier gets the timestamps of the local TSOs from their leaders on
+// behalf of the global TSO. It keeps a stream to the leader of each data
+// center, so the data centers are queried in parallel, while the stream of a
+// data center serves one query at a time.
+type localTSOQuerier struct {
+	s       *Server
+	mu      sync.Mutex
+	streams map[string]*localTSOStream
+}
+
+type localTSOStream struct {
+	sync.Mutex
+	addr   string
+	conn   *grpc.ClientConn
+	stream pdpb.PD_TsoClient
+	cancel context.CancelFunc
+}
+
+func newLocalTSOQuerier(s *Server) *localTSOQuerier {
+	return &localTSOQuerier{
+		s:       s,
+		streams: make(map[string]*localTSOStream),
+	}
+}
+
+// query gets a timestamp from the leader of the local TSO of the data center.
+func (q *localTSOQuerier) query(ctx context.Context, dcLocation string, leader *pdpb.Member) (pdpb.Timestamp, error) {
+	if len(leader.GetClientUrls()) == 0 {
+		return pdpb.Timestamp{}, errors.Errorf("no address of local tso leader %s", leader.GetName())
+	}
+	addr := leader.GetClientUrls()[0]
+	q.mu.Lock()
+	st, ok := q.streams[dcLocation]
+	if !ok {
+		st = &localTSOStream{}
+		q.streams[dcLocation] = st
+	}
+	q.mu.Unlock()
+
+	st.Lock()
+	defer st.Unlock()
+	if st.stream == nil || st.addr != addr {
+		st.reset()
+		if err := st.connect(ctx, q.s, addr, dcLocation); err != nil {
+			return pdpb.Timestamp{}, err
+		}
+	}
+	ts, err := st.request(ctx, q.s.clusterID)
+	if err != nil {
+		log.Warn("failed to query local tso", zap.String("dc-location", dcLocation), zap.String("leader-addr", addr), zap.Error(err))
+		st.reset()
+		return pdpb.Timestamp{}, err
+	}
+	return ts, nil
+}
+
+func (q *localTSOQuerier) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, st := range q.streams {
+		st.Lock()
+		st.reset()
+		st.Unlock()
+	}
+}
+
+func (st *localTSOStream) connect(ctx context.Context, s *Server, addr string, dcLocation string) error {
+	tlsCfg, err := s.GetSecurityConfig().ToTLSConfig()
+	if err != nil {
+		return err
+	}
+	conn, err := grpcutil.GetClientConn(ctx, addr, tlsCfg)
+	if err != nil {
+		return err
+	}
+	streamCtx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(s.serverLoopCtx,
+		dcLocationMetadataKey, dcLocation, tsoSyncMetadataKey, "true"))
+	stream, err := pdpb.NewPDClient(conn).Tso(streamCtx)
+	if err != nil {
+		cancel()
+		conn.Close()
+		return errors.WithStack(err)
+	}
+	st.addr, st.conn, st.stream, st.cancel = addr, conn, stream, cancel
+	return nil
+}
+
+// request sends a request over the stream, the stream is broken if the leader
+// doesn't respond before the ctx is done.
+func (st *localTSOStream) request(ctx context.Context, clusterID uint64) (pdpb.Timestamp, error) {
+	done := make(chan struct{})
+	defer close(done)
+	go func(cancel context.CancelFunc) {
+		select {
+		case <-ctx.Done():
+			cancel()
+		case <-done:
+		}
+	}(st.cancel)
+
+	err := st.stream.Send(&pdpb.TsoRequest{
+		Header: &pdpb.RequestHeader{ClusterId: clusterID},
+		Count:  1,
+	})
+	if err != nil {
+		return pdpb.Timestamp{}, errors.WithStack(err)
+	}
+	resp, err := st.stream.Recv()
+	if err != nil {
+		return pdpb.Timestamp{}, errors.WithStack(err)
+	}
+	if resp.GetTimestamp() == nil {
+		return pdpb.Timestamp{}, errors.New("no timestamp in local tso response")
+	}
+	return *resp.GetTimestamp(), nil
+}
+
+func (st *localTSOStream) reset() {
+	if st.cancel != nil {
+		st.cancel()
+	}
+	if st.conn != nil {
+		st.conn.Close()
+	}
+	st.addr, st.conn, st.stream, st.cancel = "", nil, nil, nil
+}
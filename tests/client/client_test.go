@@ -31,10 +31,12 @@ import (
 	"github.com/pingcap/pd/v4/pkg/mock/mockid"
 	"github.com/pingcap/pd/v4/pkg/testutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/server/core"
 	"github.com/pingcap/pd/v4/tests"
 	"go.etcd.io/etcd/clientv3"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc/metadata"
 )
 
 func Test(t *testing.T) {
@@ -187,6 +189,143 @@ func (s *clientTestSuite) TestLeaderTransfer(c *C) {
 	wg.Wait()
 }
 
+func (s *clientTestSuite) TestTSOFollowerProxy(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(conf *config.Config) { conf.EnableTSOFollowerProxy = true })
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, pd.SecurityOption{}, pd.WithTSOFollowerProxy())
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	testutil.WaitUntil(c, func(c *C) bool {
+		_, _, err = cli.GetTS(context.TODO())
+		return err == nil
+	})
+
+	// The timestamps got by each goroutine never fall back, even if the
+	// leader changes.
+	quit := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var lastTS uint64
+			for {
+				select {
+				case <-quit:
+					return
+				default:
+				}
+				physical, logical, err := cli.GetTS(context.TODO())
+				if err == nil {
+					ts := s.makeTS(physical, logical)
+					c.Assert(lastTS, Less, ts)
+					lastTS = ts
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	}
+	etcdCli, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		DialTimeout: time.Second,
+	})
+	c.Assert(err, IsNil)
+	defer etcdCli.Close()
+	leaderPath := filepath.Join("/pd", strconv.FormatUint(cli.GetClusterID(context.Background()), 10), "leader")
+	for i := 0; i < 3; i++ {
+		cluster.WaitLeader()
+		_, err = etcdCli.Delete(context.TODO(), leaderPath)
+		c.Assert(err, IsNil)
+		time.Sleep(time.Second)
+	}
+	close(quit)
+	wg.Wait()
+
+	// The timestamps can be got after the leader changes.
+	cluster.WaitLeader()
+	testutil.WaitUntil(c, func(c *C) bool {
+		_, _, err = cli.GetTS(context.TODO())
+		return err == nil
+	})
+}
+
+func (s *clientTestSuite) TestFollowerHandle(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(conf *config.Config) { conf.PDServerCfg.UseRegionStorage = true })
+	c.Assert(err, IsNil)
+	defer cluster.Destroy()
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	rc := leaderServer.GetServer().GetRaftCluster()
+	c.Assert(rc, NotNil)
+	region := &metapb.Region{
+		Id:          100,
+		RegionEpoch: &metapb.RegionEpoch{ConfVer: 1, Version: 1},
+		StartKey:    []byte("a"),
+		EndKey:      []byte("z"),
+		Peers:       []*metapb.Peer{{Id: 101, StoreId: 1}},
+	}
+	c.Assert(rc.HandleRegionHeartbeat(core.NewRegionInfo(region, region.Peers[0])), IsNil)
+
+	// The followers handle the reads only if the request allows it.
+	clusterID := leaderServer.GetClusterID()
+	for name, svr := range cluster.GetServers() {
+		if name == leaderServer.GetServer().Name() {
+			continue
+		}
+		grpcPDClient := testutil.MustNewGrpcClient(c, svr.GetAddr())
+		req := &pdpb.GetRegionRequest{Header: testutil.NewRequestHeader(clusterID), RegionKey: []byte("b")}
+		_, err = grpcPDClient.GetRegion(context.Background(), req)
+		c.Assert(err, ErrorMatches, ".*not leader.*")
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "pd-allow-follower-handle", "true")
+		testutil.WaitUntil(c, func(c *C) bool {
+			resp, err := grpcPDClient.GetRegion(ctx, req)
+			return err == nil && resp.GetRegion().GetId() == region.GetId()
+		})
+		storeResp, err := grpcPDClient.GetStore(ctx, &pdpb.GetStoreRequest{Header: testutil.NewRequestHeader(clusterID), StoreId: 1})
+		c.Assert(err, IsNil)
+		c.Assert(storeResp.GetStore().GetId(), Equals, uint64(1))
+	}
+
+	var endpoints []string
+	for _, s := range cluster.GetServers() {
+		endpoints = append(endpoints, s.GetConfig().AdvertiseClientUrls)
+	}
+	cli, err := pd.NewClientWithContext(s.ctx, endpoints, pd.SecurityOption{}, pd.WithFollowerHandle())
+	c.Assert(err, IsNil)
+	defer cli.Close()
+	r, _, err := cli.GetRegion(context.Background(), []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(r.GetId(), Equals, region.GetId())
+
+	// The reads fail over to the followers once the leader is down. A
+	// follower is stopped first so that the last one can never be elected,
+	// which makes sure the reads are served by a follower. The store reads
+	// load from etcd and need a quorum, so they are only checked above.
+	c.Assert(cluster.GetServer(cluster.GetFollower()).Stop(), IsNil)
+	c.Assert(leaderServer.Stop(), IsNil)
+	r, _, err = cli.GetRegion(context.Background(), []byte("b"))
+	c.Assert(err, IsNil)
+	c.Assert(r.GetId(), Equals, region.GetId())
+	r, _, err = cli.GetRegionByID(context.Background(), region.GetId())
+	c.Assert(err, IsNil)
+	c.Assert(r.GetId(), Equals, region.GetId())
+	c.Assert(cluster.GetLeader(), Equals, "")
+}
+
 func (s *clientTestSuite) waitLeader(c *C, cli client, leader string) {
 	testutil.WaitUntil(c, func(c *C) bool {
 		cli.ScheduleCheckLeader()
@@ -351,6 +490,32 @@ func (s *testClientSuite) TestTSORace(c *C) {
 	wg.Wait()
 }
 
+func (s *testClientSuite) TestTSOBatchAndDispatchers(c *C) {
+	cli, err := pd.NewClientWithContext(s.ctx, s.srv.GetEndpoints(), pd.SecurityOption{},
+		pd.WithMaxTSOBatchWaitInterval(time.Millisecond), pd.WithTSODispatcherCount(4))
+	c.Assert(err, IsNil)
+	defer cli.Close()
+
+	var wg sync.WaitGroup
+	count := 10
+	wg.Add(count)
+	for i := 0; i < count; i++ {
+		go func() {
+			defer wg.Done()
+			// The timestamps got one by one never fall back, even if they
+			// are from different streams.
+			var last int64
+			for i := 0; i < 100; i++ {
+				p, l, err := cli.GetTS(context.Background())
+				c.Assert(err, IsNil)
+				c.Assert(p<<18+l, Greater, last)
+				last = p<<18 + l
+			}
+		}()
+	}
+	wg.Wait()
+}
+
 func (s *testClientSuite) TestGetRegion(c *C) {
 	regionID := regionIDAllocator.alloc()
 	region := &metapb.Region{
@@ -28,6 +28,7 @@ import (
 	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
 	"github.com/pingcap/pd/v4/server/config"
+	"github.com/pingcap/pd/v4/server/schedule/labeler"
 	"github.com/pingcap/pd/v4/server/schedule/placement"
 	"github.com/pingcap/pd/v4/tests"
 	"github.com/pingcap/pd/v4/tests/pdctl"
@@ -297,6 +298,164 @@ func (s *configTestSuite) TestPlacementRules(c *C) {
 	c.Assert(rules[0].Key(), Equals, [2]string{"pd", "test1"})
 }
 
+func (s *configTestSuite) TestPlacementRuleGroups(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+
+	store := metapb.Store{
+		Id:    1,
+		State: metapb.StoreState_Up,
+	}
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	svr := leaderServer.GetServer()
+	pdctl.MustPutStore(c, svr, store.Id, store.State, store.Labels)
+	defer cluster.Destroy()
+
+	_, output, err := pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "enable")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+
+	// test show
+	var groups []placement.RuleGroup
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "show")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &groups)
+	c.Assert(err, IsNil)
+	c.Assert(groups, HasLen, 0)
+
+	// test set
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "set", "pd", "42", "true")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "set", "group2", "100", "false")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "set", "group3", "foo", "false")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsFalse)
+
+	// show all
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "show")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &groups)
+	c.Assert(err, IsNil)
+	c.Assert(groups, DeepEquals, []placement.RuleGroup{
+		{ID: "pd", Index: 42, Override: true},
+		{ID: "group2", Index: 100, Override: false},
+	})
+
+	// delete
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "delete", "group2")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+
+	// show again
+	var group placement.RuleGroup
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "show", "pd")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &group)
+	c.Assert(err, IsNil)
+	c.Assert(group, DeepEquals, placement.RuleGroup{ID: "pd", Index: 42, Override: true})
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "placement-rules", "rule-group", "show", "group2")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "404"), IsTrue)
+}
+
+func (s *configTestSuite) TestRegionLabelRules(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cluster, err := tests.NewTestCluster(ctx, 1)
+	c.Assert(err, IsNil)
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+	pdAddr := cluster.GetConfig().GetClientURL()
+	cmd := pdctl.InitCommand()
+
+	store := metapb.Store{
+		Id:    1,
+		State: metapb.StoreState_Up,
+	}
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	c.Assert(leaderServer.BootstrapCluster(), IsNil)
+	svr := leaderServer.GetServer()
+	pdctl.MustPutStore(c, svr, store.Id, store.State, store.Labels)
+	pdctl.MustPutRegion(c, cluster, 1, 1, []byte("a"), []byte("b"))
+	defer cluster.Destroy()
+
+	// test show
+	var rules []labeler.LabelRule
+	_, output, err := pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "show")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &rules)
+	c.Assert(err, IsNil)
+	c.Assert(rules, HasLen, 0)
+
+	// test set
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "set", "rule1", "merge_option=deny,k1=v1", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "set", "rule2", "k2=v2", "6162", "6163", "--ttl", "1h")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "set", "rule3", "k3", "", "")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsFalse)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "set", "rule3", "k3=v3", "zz", "")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "400"), IsTrue)
+
+	// show all
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "show")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &rules)
+	c.Assert(err, IsNil)
+	c.Assert(rules, HasLen, 2)
+	c.Assert(rules[0].ID, Equals, "rule1")
+	c.Assert(rules[1].ID, Equals, "rule2")
+	c.Assert(rules[1].TTL, Equals, "1h")
+
+	// show region labels
+	var labels []labeler.RegionLabel
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "region", "1")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &labels)
+	c.Assert(err, IsNil)
+	c.Assert(labels, DeepEquals, []labeler.RegionLabel{
+		{Key: "merge_option", Value: "deny"},
+		{Key: "k1", Value: "v1"},
+	})
+
+	// delete
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "delete", "rule1")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "Success!"), IsTrue)
+
+	// show again
+	var rule labeler.LabelRule
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "show", "rule2")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &rule)
+	c.Assert(err, IsNil)
+	c.Assert(rule.Labels, DeepEquals, []labeler.RegionLabel{{Key: "k2", Value: "v2"}})
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "show", "rule1")
+	c.Assert(err, IsNil)
+	c.Assert(strings.Contains(string(output), "404"), IsTrue)
+	_, output, err = pdctl.ExecuteCommandC(cmd, "-u", pdAddr, "config", "region-label", "region", "1")
+	c.Assert(err, IsNil)
+	err = json.Unmarshal(output, &labels)
+	c.Assert(err, IsNil)
+	c.Assert(labels, HasLen, 0)
+}
+
 func (s *configTestSuite) TestReplicationMode(c *C) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
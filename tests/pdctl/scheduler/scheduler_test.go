@@ -253,10 +253,13 @@ func (s *schedulerTestSuite) TestScheduler(c *C) {
 		"byte-rate-rank-step-ratio": 0.05,
 		"key-rate-rank-step-ratio":  0.05,
 		"count-rank-step-ratio":     0.01,
+		"cpu-rank-step-ratio":       0.05,
 		"great-dec-ratio":           0.95,
 		"minor-dec-ratio":           0.99,
 		"src-tolerance-ratio":       1.05,
 		"dst-tolerance-ratio":       1.05,
+		"read-priorities":           []interface{}{"byte", "key"},
+		"write-priorities":          []interface{}{"byte", "key"},
 	}
 	c.Assert(conf, DeepEquals, expected1)
 	mustExec([]string{"-u", pdAddr, "scheduler", "config", "balance-hot-region-scheduler", "set", "src-tolerance-ratio", "1.02"}, nil)
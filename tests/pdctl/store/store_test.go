@@ -195,6 +195,21 @@ func (s *storeTestSuite) TestStore(c *C) {
 	c.Assert(allRegionAddLimit["3"]["mode"].(string), Equals, "manual")
 	_, ok = allRegionAddLimit["2"]
 	c.Assert(ok, IsFalse)
+
+	// store limit <store_id> <rate> <byte type>
+	args = []string{"-u", pdAddr, "store", "limit", "1", "64", "region-add-bytes"}
+	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	limits = leaderServer.GetRaftCluster().GetOperatorController().GetAllStoresLimit(storelimit.RegionAddBytes)
+	c.Assert(limits[1].Rate(), Equals, float64(64))
+	_, ok = limits[3]
+	c.Assert(ok, IsFalse)
+	echo = pdctl.GetEcho([]string{"-u", pdAddr, "store", "limit", "region-add-bytes"})
+	allRegionAddBytesLimit := make(map[string]map[string]interface{})
+	json.Unmarshal([]byte(echo), &allRegionAddBytesLimit)
+	c.Assert(allRegionAddBytesLimit["1"]["rate"].(float64), Equals, float64(64))
+	c.Assert(allRegionAddBytesLimit["1"]["mode"].(string), Equals, "manual")
+
 	// store limit
 	args = []string{"-u", pdAddr, "store", "limit"}
 	_, _, err = pdctl.ExecuteCommandC(cmd, args...)
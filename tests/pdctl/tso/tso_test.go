@@ -57,4 +57,11 @@ func (s *tsoTestSuite) TestTSO(c *C) {
 	physicalTime := time.Unix(int64(physical/1000), int64(physical%1000)*time.Millisecond.Nanoseconds())
 	str := fmt.Sprintln("system: ", physicalTime) + fmt.Sprintln("logic: ", logicalTime)
 	c.Assert(str, Equals, string(output))
+
+	// tso command with the suffix
+	args = []string{"-u", "127.0.0.1", "tso", ts, "--suffix-bits", "2"}
+	_, output, err = pdctl.ExecuteCommandC(cmd, args...)
+	c.Assert(err, IsNil)
+	str += fmt.Sprintln("suffix: ", logicalTime&3)
+	c.Assert(str, Equals, string(output))
 }
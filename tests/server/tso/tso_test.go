@@ -24,9 +24,14 @@ import (
 	"github.com/pingcap/failpoint"
 	"github.com/pingcap/kvproto/pkg/pdpb"
 	"github.com/pingcap/pd/v4/pkg/testutil"
+	"github.com/pingcap/pd/v4/pkg/typeutil"
 	"github.com/pingcap/pd/v4/server"
+	"github.com/pingcap/pd/v4/server/config"
 	"github.com/pingcap/pd/v4/tests"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 func Test(t *testing.T) {
@@ -170,6 +175,189 @@ func (s *testTsoSuite) TestTsoCount0(c *C) {
 	c.Assert(err, NotNil)
 }
 
+func (s *testTsoSuite) getTimestamp(c *C, grpcPDClient pdpb.PDClient, clusterID uint64, dcLocation string) (*pdpb.Timestamp, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if dcLocation != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "pd-dc-location", dcLocation)
+	}
+	tsoClient, err := grpcPDClient.Tso(ctx)
+	c.Assert(err, IsNil)
+	defer tsoClient.CloseSend()
+	req := &pdpb.TsoRequest{
+		Header: testutil.NewRequestHeader(clusterID),
+		Count:  1,
+	}
+	c.Assert(tsoClient.Send(req), IsNil)
+	resp, err := tsoClient.Recv()
+	if err != nil {
+		return nil, err
+	}
+	return resp.GetTimestamp(), nil
+}
+
+func (s *testTsoSuite) TestLocalTso(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1, func(conf *config.Config) { conf.DCLocation = "dc-1" })
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	grpcPDClient := testutil.MustNewGrpcClient(c, leaderServer.GetAddr())
+	clusterID := leaderServer.GetClusterID()
+
+	// The server campaigns the leader of the local TSO of dc-1.
+	var local *pdpb.Timestamp
+	testutil.WaitUntil(c, func(c *C) bool {
+		local, err = s.getTimestamp(c, grpcPDClient, clusterID, "dc-1")
+		return err == nil
+	})
+	// The server is not in dc-2.
+	_, err = s.getTimestamp(c, grpcPDClient, clusterID, "dc-2")
+	c.Assert(err, NotNil)
+	c.Assert(status.Code(err), Equals, codes.Unavailable)
+
+	// The global timestamps are greater than the local ones allocated before.
+	for i := 0; i < 10; i++ {
+		local, err = s.getTimestamp(c, grpcPDClient, clusterID, "dc-1")
+		c.Assert(err, IsNil)
+		global, err := s.getTimestamp(c, grpcPDClient, clusterID, "")
+		c.Assert(err, IsNil)
+		c.Assert(global.GetPhysical(), Greater, local.GetPhysical())
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func tsLess(ts, other *pdpb.Timestamp) bool {
+	if ts.GetPhysical() == other.GetPhysical() {
+		return ts.GetLogical() < other.GetLogical()
+	}
+	return ts.GetPhysical() < other.GetPhysical()
+}
+
+func (s *testTsoSuite) TestLocalTsoMultiDC(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 3, func(conf *config.Config) {
+		conf.DCLocation = "dc-" + conf.Name
+		conf.TsoSaveInterval = typeutil.NewDuration(200 * time.Millisecond)
+	})
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	clusterID := leaderServer.GetClusterID()
+	leaderClient := testutil.MustNewGrpcClient(c, leaderServer.GetAddr())
+	clients := make(map[string]pdpb.PDClient)
+	for name, server := range cluster.GetServers() {
+		clients["dc-"+name] = testutil.MustNewGrpcClient(c, server.GetAddr())
+	}
+	// Each server leads the local TSO of its data center, which serves after
+	// it is synced by the global TSO.
+	for dcLocation, client := range clients {
+		testutil.WaitUntil(c, func(c *C) bool {
+			_, err = s.getTimestamp(c, client, clusterID, dcLocation)
+			return err == nil
+		})
+	}
+
+	// The global timestamps are greater than the local ones of all the data
+	// centers allocated before, and they don't run ahead of the wall clock
+	// once the time windows saved before the election are passed.
+	time.Sleep(time.Second)
+	for i := 0; i < 10; i++ {
+		var locals []*pdpb.Timestamp
+		for dcLocation, client := range clients {
+			local, err := s.getTimestamp(c, client, clusterID, dcLocation)
+			c.Assert(err, IsNil)
+			locals = append(locals, local)
+		}
+		global, err := s.getTimestamp(c, leaderClient, clusterID, "")
+		c.Assert(err, IsNil)
+		for _, local := range locals {
+			c.Assert(tsLess(local, global), IsTrue)
+		}
+		c.Assert(global.GetPhysical(), LessEqual, time.Now().Add(50*time.Millisecond).UnixNano()/int64(time.Millisecond))
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The global TSO keeps serving after a local TSO leader steps down.
+	var stopped string
+	for name, server := range cluster.GetServers() {
+		if name != leaderServer.GetConfig().Name {
+			stopped = "dc-" + name
+			c.Assert(server.Stop(), IsNil)
+			break
+		}
+	}
+	testutil.WaitUntil(c, func(c *C) bool {
+		_, err = s.getTimestamp(c, leaderClient, clusterID, "")
+		return err == nil
+	})
+	for dcLocation, client := range clients {
+		if dcLocation != stopped {
+			_, err = s.getTimestamp(c, client, clusterID, dcLocation)
+			c.Assert(err, IsNil)
+		}
+	}
+}
+
+func (s *testTsoSuite) TestTsoSuffix(c *C) {
+	cluster, err := tests.NewTestCluster(s.ctx, 1, func(conf *config.Config) {
+		conf.TSOSuffixBits = 2
+		conf.TSOSuffix = 1
+	})
+	defer cluster.Destroy()
+	c.Assert(err, IsNil)
+
+	err = cluster.RunInitialServers()
+	c.Assert(err, IsNil)
+	cluster.WaitLeader()
+
+	leaderServer := cluster.GetServer(cluster.GetLeader())
+	grpcPDClient := testutil.MustNewGrpcClient(c, leaderServer.GetAddr())
+	clusterID := leaderServer.GetClusterID()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	tsoClient, err := grpcPDClient.Tso(ctx)
+	c.Assert(err, IsNil)
+	defer tsoClient.CloseSend()
+	req := &pdpb.TsoRequest{
+		Header: testutil.NewRequestHeader(clusterID),
+		Count:  10,
+	}
+	last := &pdpb.Timestamp{}
+	for i := 0; i < 20; i++ {
+		if i == 10 {
+			// The suffix is kept after resetting the timestamp.
+			physical := time.Now().Add(time.Minute).UnixNano() / int64(time.Millisecond)
+			c.Assert(leaderServer.GetServer().GetHandler().ResetTS(uint64(physical<<18)), IsNil)
+		}
+		c.Assert(tsoClient.Send(req), IsNil)
+		resp, err := tsoClient.Recv()
+		c.Assert(err, IsNil)
+		ts := resp.GetTimestamp()
+		c.Assert(ts.GetLogical()&3, Equals, int64(1))
+		// The client learns the suffix bits from the header.
+		md, err := tsoClient.Header()
+		c.Assert(err, IsNil)
+		c.Assert(md.Get("pd-tso-suffix-bits"), DeepEquals, []string{"2"})
+		// The first timestamp of the batch is greater than the last one.
+		if ts.GetPhysical() == last.GetPhysical() {
+			c.Assert(ts.GetLogical()-9<<2, Greater, last.GetLogical())
+		} else {
+			c.Assert(ts.GetPhysical(), Greater, last.GetPhysical())
+		}
+		last = ts
+	}
+}
+
 var _ = Suite(&testTimeFallBackSuite{})
 
 type testTimeFallBackSuite struct {
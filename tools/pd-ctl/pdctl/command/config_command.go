@@ -16,7 +16,6 @@ package command
 import (
 	"bytes"
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"net/http"
 	"path"
@@ -37,6 +36,10 @@ var (
 	clusterVersionPrefix  = "pd/api/v1/config/cluster-version"
 	rulesPrefix           = "pd/api/v1/config/rules"
 	rulePrefix            = "pd/api/v1/config/rule"
+	ruleGroupPrefix       = "pd/api/v1/config/rule_group"
+	ruleGroupsPrefix      = "pd/api/v1/config/rule_groups"
+	labelRulesPrefix      = "pd/api/v1/config/region-label/rules"
+	labelRulePrefix       = "pd/api/v1/config/region-label/rule"
 	replicationModePrefix = "pd/api/v1/config/replication-mode"
 )
 
@@ -50,6 +53,7 @@ func NewConfigCommand() *cobra.Command {
 	conf.AddCommand(NewSetConfigCommand())
 	conf.AddCommand(NewDeleteConfigCommand())
 	conf.AddCommand(NewPlacementRulesCommand())
+	conf.AddCommand(NewRegionLabelCommand())
 	return conf
 }
 
@@ -427,7 +431,27 @@ func NewPlacementRulesCommand() *cobra.Command {
 		Run:   putPlacementRulesFunc,
 	}
 	save.Flags().String("in", "rules.json", "the filename contains rules")
-	c.AddCommand(enable, disable, show, load, save)
+	ruleGroup := &cobra.Command{
+		Use:   "rule-group",
+		Short: "rule group configurations",
+	}
+	groupShow := &cobra.Command{
+		Use:   "show [id]",
+		Short: "show rule group configuration(s)",
+		Run:   showRuleGroupFunc,
+	}
+	groupSet := &cobra.Command{
+		Use:   "set <id> <index> <override>",
+		Short: "update rule group configuration",
+		Run:   updateRuleGroupFunc,
+	}
+	groupDelete := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete rule group configuration",
+		Run:   delRuleGroupFunc,
+	}
+	ruleGroup.AddCommand(groupShow, groupSet, groupDelete)
+	c.AddCommand(enable, disable, show, load, save, ruleGroup)
 	return c
 }
 
@@ -511,23 +535,191 @@ func putPlacementRulesFunc(cmd *cobra.Command, args []string) {
 		cmd.Println(err)
 		return
 	}
+	// Rules with zero count are deleted, all changes are applied atomically.
+	ops := make([]placement.RuleOp, 0, len(rules))
 	for _, r := range rules {
 		if r.Count > 0 {
-			b, _ := json.Marshal(r)
-			_, err = doRequest(cmd, rulePrefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
-			if err != nil {
-				fmt.Printf("failed to save rule %s/%s: %v\n", r.GroupID, r.ID, err)
-				return
-			}
-			fmt.Printf("saved rule %s/%s\n", r.GroupID, r.ID)
+			ops = append(ops, placement.RuleOp{Rule: r, Action: placement.RuleOpAdd})
 		} else {
-			_, err = doRequest(cmd, path.Join(rulePrefix, r.GroupID, r.ID), http.MethodDelete)
-			if err != nil {
-				fmt.Printf("failed to delete rule %s/%s: %v\n", r.GroupID, r.ID, err)
-				return
-			}
-			fmt.Printf("deleted rule %s/%s\n", r.GroupID, r.ID)
+			ops = append(ops, placement.RuleOp{Rule: r, Action: placement.RuleOpDel})
+		}
+	}
+	b, _ := json.Marshal(ops)
+	_, err = doRequest(cmd, path.Join(rulesPrefix, "batch"), http.MethodPost, WithBody("application/json", bytes.NewBuffer(b)))
+	if err != nil {
+		cmd.Printf("failed to save rules: %v\n", err)
+		return
+	}
+	for _, r := range rules {
+		if r.Count > 0 {
+			cmd.Printf("saved rule %s/%s\n", r.GroupID, r.ID)
+		} else {
+			cmd.Printf("deleted rule %s/%s\n", r.GroupID, r.ID)
+		}
+	}
+	cmd.Println("Success!")
+}
+
+func showRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	reqPath := ruleGroupsPrefix
+	if len(args) > 0 {
+		reqPath = path.Join(ruleGroupPrefix, args[0])
+	}
+	res, err := doRequest(cmd, reqPath, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func updateRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 3 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	index, err := strconv.ParseInt(args[1], 10, 32)
+	if err != nil {
+		cmd.Printf("index %s should be a number\n", args[1])
+		return
+	}
+	var override bool
+	switch strings.ToLower(args[2]) {
+	case "false":
+	case "true":
+		override = true
+	default:
+		cmd.Printf("override %s should be a boolean\n", args[2])
+		return
+	}
+	postJSON(cmd, ruleGroupPrefix, map[string]interface{}{
+		"id":       args[0],
+		"index":    index,
+		"override": override,
+	})
+}
+
+func delRuleGroupFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	_, err := doRequest(cmd, path.Join(ruleGroupPrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
+// NewRegionLabelCommand region label rules subcommand
+func NewRegionLabelCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "region-label",
+		Short: "region label rules configuration",
+	}
+	show := &cobra.Command{
+		Use:   "show [id]",
+		Short: "show region label rule(s)",
+		Run:   showRegionLabelRuleFunc,
+	}
+	set := &cobra.Command{
+		Use:   "set <id> <key>=<value>[,<key>=<value>...] <start-key-hex> <end-key-hex>",
+		Short: "update region label rule",
+		Run:   updateRegionLabelRuleFunc,
+	}
+	set.Flags().String("ttl", "", "the duration the rule lives, e.g. 1h30m, never expires if empty")
+	del := &cobra.Command{
+		Use:   "delete <id>",
+		Short: "delete region label rule",
+		Run:   delRegionLabelRuleFunc,
+	}
+	region := &cobra.Command{
+		Use:   "region <region_id>",
+		Short: "show labels of the region",
+		Run:   showRegionLabelsFunc,
+	}
+	c.AddCommand(show, set, del, region)
+	return c
+}
+
+func showRegionLabelRuleFunc(cmd *cobra.Command, args []string) {
+	if len(args) > 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	reqPath := labelRulesPrefix
+	if len(args) > 0 {
+		reqPath = path.Join(labelRulePrefix, args[0])
+	}
+	res, err := doRequest(cmd, reqPath, http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(res)
+}
+
+func updateRegionLabelRuleFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 4 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	var labels []map[string]string
+	for _, kv := range strings.Split(args[1], ",") {
+		pair := strings.Split(kv, "=")
+		if len(pair) != 2 {
+			cmd.Printf("label %s should be in the form of <key>=<value>\n", kv)
+			return
 		}
+		labels = append(labels, map[string]string{"key": pair[0], "value": pair[1]})
+	}
+	input := map[string]interface{}{
+		"id":     args[0],
+		"labels": labels,
+		"ranges": []map[string]string{{"start_key": args[2], "end_key": args[3]}},
+	}
+	if ttl, _ := cmd.Flags().GetString("ttl"); ttl != "" {
+		input["ttl"] = ttl
+	}
+	postJSON(cmd, labelRulePrefix, input)
+}
+
+func delRegionLabelRuleFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+
+	_, err := doRequest(cmd, path.Join(labelRulePrefix, args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Println(err)
+		return
 	}
 	cmd.Println("Success!")
 }
+
+func showRegionLabelsFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
+		cmd.Println("region_id should be a number")
+		return
+	}
+
+	res, err := doRequest(cmd, path.Join(regionIDPrefix, args[0], "labels"), http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(res)
+}
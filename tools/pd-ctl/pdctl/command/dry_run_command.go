@@ -0,0 +1,110 @@
+// Copyright 2020 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"path"
+
+	"github.com/spf13/cobra"
+)
+
+const dryRunPrefix = "pd/api/v1/dry-run"
+
+// NewDryRunCommand returns a dry-run subcommand of rootCmd.
+func NewDryRunCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dry-run <subcommand>",
+		Short: "show the operators that would be created by schedulers or checkers, without dispatching them",
+	}
+	cmd.AddCommand(NewDryRunSchedulerCommand())
+	cmd.AddCommand(NewDryRunCheckerCommand())
+	return cmd
+}
+
+// NewDryRunSchedulerCommand returns a subcommand of dry-run to run a scheduler.
+func NewDryRunSchedulerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scheduler <scheduler_type> [<args>...]",
+		Short: "run a scheduler on a snapshot of the cluster",
+		Run:   dryRunSchedulerCommandFunc,
+	}
+	addDryRunFlags(cmd)
+	return cmd
+}
+
+// NewDryRunCheckerCommand returns a subcommand of dry-run to run the checkers.
+func NewDryRunCheckerCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "checker",
+		Short: "run the checkers on a snapshot of the cluster",
+		Run:   dryRunCheckerCommandFunc,
+	}
+	addDryRunFlags(cmd)
+	return cmd
+}
+
+func addDryRunFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("rounds", 1, "the rounds to run")
+	cmd.Flags().String("config", "", `the schedule config items to overwrite in JSON format, e.g. {"leader-schedule-limit": 8}`)
+}
+
+func dryRunSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	input := map[string]interface{}{
+		"name": args[0],
+		"args": args[1:],
+	}
+	doDryRun(cmd, path.Join(dryRunPrefix, "scheduler"), input)
+}
+
+func dryRunCheckerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	doDryRun(cmd, path.Join(dryRunPrefix, "checker"), make(map[string]interface{}))
+}
+
+func doDryRun(cmd *cobra.Command, prefix string, input map[string]interface{}) {
+	rounds, err := cmd.Flags().GetInt("rounds")
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	input["rounds"] = rounds
+	if cfg, _ := cmd.Flags().GetString("config"); cfg != "" {
+		if !json.Valid([]byte(cfg)) {
+			cmd.Println("config should be in JSON format")
+			return
+		}
+		input["config"] = json.RawMessage(cfg)
+	}
+	reqData, err := json.Marshal(input)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	r, err := doRequest(cmd, prefix, http.MethodPost, WithBody("application/json", bytes.NewBuffer(reqData)))
+	if err != nil {
+		cmd.Printf("Failed to dry run: %s\n", err)
+		return
+	}
+	cmd.Println(r)
+}
@@ -27,6 +27,7 @@ import (
 
 var (
 	schedulersPrefix         = "pd/api/v1/schedulers"
+	checkersPrefix           = "pd/api/v1/checkers"
 	schedulerConfigPrefix    = "pd/api/v1/scheduler-config"
 	evictLeaderSchedulerName = "evict-leader-scheduler"
 	grantLeaderSchedulerName = "grant-leader-scheduler"
@@ -44,6 +45,8 @@ func NewSchedulerCommand() *cobra.Command {
 	c.AddCommand(NewRemoveSchedulerCommand())
 	c.AddCommand(NewPauseSchedulerCommand())
 	c.AddCommand(NewResumeSchedulerCommand())
+	c.AddCommand(NewDiagnoseSchedulerCommand())
+	c.AddCommand(NewActiveWindowSchedulerCommand())
 	c.AddCommand(NewConfigSchedulerCommand())
 	return c
 }
@@ -84,6 +87,7 @@ func NewShowSchedulerCommand() *cobra.Command {
 		Short: "show schedulers",
 		Run:   showSchedulerCommandFunc,
 	}
+	c.Flags().Bool("detail", false, "show the pause and active window state of schedulers")
 	return c
 }
 
@@ -97,13 +101,75 @@ func NewResumeSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewDiagnoseSchedulerCommand returns a command to show the recorded plans of a scheduler.
+func NewDiagnoseSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "diagnose <scheduler>",
+		Short: "show why a scheduler creates or does not create operators in the last rounds",
+		Run:   diagnoseSchedulerCommandFunc,
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "enable <scheduler> [rounds]",
+		Short: "start to record the plans of the last rounds of a scheduler",
+		Run:   enableDiagnoseSchedulerCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "disable <scheduler>",
+		Short: "stop recording the plans of a scheduler",
+		Run:   disableDiagnoseSchedulerCommandFunc,
+	})
+	return c
+}
+
+func diagnoseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, path.Join(schedulersPrefix, args[0], "diagnostic"), http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func enableDiagnoseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 && len(args) != 2 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	input := map[string]interface{}{"enable": true}
+	if len(args) == 2 {
+		rounds, err := strconv.Atoi(args[1])
+		if err != nil {
+			cmd.Println(cmd.UsageString())
+			return
+		}
+		input["rounds"] = rounds
+	}
+	postJSON(cmd, path.Join(schedulersPrefix, args[0], "diagnostic"), input)
+}
+
+func disableDiagnoseSchedulerCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	postJSON(cmd, path.Join(schedulersPrefix, args[0], "diagnostic"), map[string]interface{}{"enable": false})
+}
+
 func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 0 {
 		cmd.Println(cmd.UsageString())
 		return
 	}
 
-	r, err := doRequest(cmd, schedulersPrefix, http.MethodGet)
+	prefix := schedulersPrefix
+	if detail, _ := cmd.Flags().GetBool("detail"); detail {
+		prefix += "?detail=true"
+	}
+	r, err := doRequest(cmd, prefix, http.MethodGet)
 	if err != nil {
 		cmd.Println(err)
 		return
@@ -111,6 +177,68 @@ func showSchedulerCommandFunc(cmd *cobra.Command, args []string) {
 	cmd.Println(r)
 }
 
+// NewActiveWindowSchedulerCommand returns a command to manage the active
+// window of a scheduler or a checker.
+func NewActiveWindowSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "active-window <scheduler|checker>",
+		Short: "show the active window of a scheduler or a checker",
+		Run:   showActiveWindowCommandFunc,
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "set <scheduler|checker> <only|except> [weekdays] <HH:MM-HH:MM>[; ...]",
+		Short: "set the active window in UTC, such as `only 01:00-06:00` or `except Fri 18:00-20:00`",
+		Run:   setActiveWindowCommandFunc,
+	})
+	c.AddCommand(&cobra.Command{
+		Use:   "delete <scheduler|checker>",
+		Short: "delete the active window, the scheduler or the checker is always active then",
+		Run:   deleteActiveWindowCommandFunc,
+	})
+	return c
+}
+
+func activeWindowPath(name string) string {
+	if strings.HasSuffix(name, "-checker") {
+		return path.Join(checkersPrefix, name, "active-window")
+	}
+	return path.Join(schedulersPrefix, name, "active-window")
+}
+
+func showActiveWindowCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, activeWindowPath(args[0]), http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
+func setActiveWindowCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) < 3 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	postJSON(cmd, activeWindowPath(args[0]), map[string]interface{}{"spec": strings.Join(args[1:], " ")})
+}
+
+func deleteActiveWindowCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	_, err := doRequest(cmd, activeWindowPath(args[0]), http.MethodDelete)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println("Success!")
+}
+
 // NewAddSchedulerCommand returns a command to add scheduler.
 func NewAddSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -119,12 +247,14 @@ func NewAddSchedulerCommand() *cobra.Command {
 	}
 	c.AddCommand(NewGrantLeaderSchedulerCommand())
 	c.AddCommand(NewEvictLeaderSchedulerCommand())
+	c.AddCommand(NewEvictSlowStoreSchedulerCommand())
 	c.AddCommand(NewShuffleLeaderSchedulerCommand())
 	c.AddCommand(NewShuffleRegionSchedulerCommand())
 	c.AddCommand(NewShuffleHotRegionSchedulerCommand())
 	c.AddCommand(NewScatterRangeSchedulerCommand())
 	c.AddCommand(NewBalanceLeaderSchedulerCommand())
 	c.AddCommand(NewBalanceRegionSchedulerCommand())
+	c.AddCommand(NewBalanceLearnerSchedulerCommand())
 	c.AddCommand(NewBalanceHotRegionSchedulerCommand())
 	c.AddCommand(NewRandomMergeSchedulerCommand())
 	c.AddCommand(NewBalanceAdjacentRegionSchedulerCommand())
@@ -135,20 +265,22 @@ func NewAddSchedulerCommand() *cobra.Command {
 // NewGrantLeaderSchedulerCommand returns a command to add a grant-leader-scheduler.
 func NewGrantLeaderSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "grant-leader-scheduler <store_id>",
+		Use:   "grant-leader-scheduler <store_id> [<start_key> <end_key>]...",
 		Short: "add a scheduler to grant leader to a store",
 		Run:   addSchedulerForStoreCommandFunc,
 	}
+	c.Flags().Int("leader-percent", -1, "only make at least the percent of regions on the store leaders, by granting leaders in the ranges")
 	return c
 }
 
 // NewEvictLeaderSchedulerCommand returns a command to add a evict-leader-scheduler.
 func NewEvictLeaderSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
-		Use:   "evict-leader-scheduler <store_id>",
+		Use:   "evict-leader-scheduler <store_id> [<start_key> <end_key>]...",
 		Short: "add a scheduler to evict leader from a store",
 		Run:   addSchedulerForStoreCommandFunc,
 	}
+	c.Flags().Int("leader-percent", -1, "only keep at most the percent of regions on the store as leaders, by evicting leaders in the ranges")
 	return c
 }
 
@@ -169,7 +301,7 @@ func checkSchedulerExist(cmd *cobra.Command, schedulerName string) (bool, error)
 }
 
 func addSchedulerForStoreCommandFunc(cmd *cobra.Command, args []string) {
-	if len(args) != 1 {
+	if len(args) < 1 || len(args)%2 != 1 {
 		cmd.Println(cmd.UsageString())
 		return
 	}
@@ -196,11 +328,37 @@ func addSchedulerForStoreCommandFunc(cmd *cobra.Command, args []string) {
 		input := make(map[string]interface{})
 		input["name"] = cmd.Name()
 		input["store_id"] = storeID
+		setStoreLeaderOptions(cmd, input, args[1:])
 		postJSON(cmd, schedulersPrefix, input)
 	}
 
 }
 
+// setStoreLeaderOptions sets the leader percent and the key ranges of
+// evict-leader-scheduler and grant-leader-scheduler to the input.
+func setStoreLeaderOptions(cmd *cobra.Command, input map[string]interface{}, keys []string) {
+	if percent, err := cmd.Flags().GetInt("leader-percent"); err == nil && percent >= 0 {
+		input["leader_percent"] = percent
+	}
+	if len(keys) > 0 {
+		ranges := make([]string, 0, len(keys))
+		for _, key := range keys {
+			ranges = append(ranges, url.QueryEscape(key))
+		}
+		input["ranges"] = ranges
+	}
+}
+
+// NewEvictSlowStoreSchedulerCommand returns a command to add an evict-slow-store-scheduler.
+func NewEvictSlowStoreSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "evict-slow-store-scheduler",
+		Short: "add a scheduler to detect and evict leaders of the slow store",
+		Run:   addSchedulerCommandFunc,
+	}
+	return c
+}
+
 // NewShuffleLeaderSchedulerCommand returns a command to add a shuffle-leader-scheduler.
 func NewShuffleLeaderSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -271,6 +429,16 @@ func NewBalanceRegionSchedulerCommand() *cobra.Command {
 	return c
 }
 
+// NewBalanceLearnerSchedulerCommand returns a command to add a balance-learner-scheduler.
+func NewBalanceLearnerSchedulerCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "balance-learner-scheduler",
+		Short: "add a scheduler to balance learners between stores",
+		Run:   addSchedulerCommandFunc,
+	}
+	return c
+}
+
 // NewBalanceHotRegionSchedulerCommand returns a command to add a balance-hot-region-scheduler.
 func NewBalanceHotRegionSchedulerCommand() *cobra.Command {
 	c := &cobra.Command{
@@ -430,9 +598,43 @@ func NewConfigSchedulerCommand() *cobra.Command {
 	c.AddCommand(
 		newConfigEvictLeaderCommand(),
 		newConfigGrantLeaderCommand(),
+		newConfigEvictSlowStoreCommand(),
 		newConfigHotRegionCommand(),
 		newConfigShuffleRegionCommand(),
 	)
+	for _, name := range onlineConfigSchedulers {
+		c.AddCommand(newConfigOnlineCommand(name))
+	}
+	return c
+}
+
+// onlineConfigSchedulers are the schedulers whose config items can be listed
+// and set by the same commands.
+var onlineConfigSchedulers = []string{
+	"balance-leader-scheduler",
+	"balance-region-scheduler",
+	"balance-learner-scheduler",
+	"label-scheduler",
+	"random-merge-scheduler",
+	"shuffle-leader-scheduler",
+	"shuffle-hot-region-scheduler",
+	"balance-adjacent-region-scheduler",
+}
+
+func newConfigOnlineCommand(name string) *cobra.Command {
+	c := &cobra.Command{
+		Use:   name,
+		Short: name + " config",
+		Run:   listSchedulerConfigCommandFunc,
+	}
+	c.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "list the config item",
+		Run:   listSchedulerConfigCommandFunc})
+	c.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "set the config item, the value of ranges is <start_key>,<end_key>[,<start_key>,<end_key>]...",
+		Run:   func(cmd *cobra.Command, args []string) { postSchedulerConfigCommandFunc(cmd, name, args) }})
 	return c
 }
 
@@ -459,14 +661,20 @@ func newConfigEvictLeaderCommand() *cobra.Command {
 		Short: "evict-leader-scheduler config",
 		Run:   listSchedulerConfigCommandFunc,
 	}
-	c.AddCommand(&cobra.Command{
-		Use:   "add-store <store-id>",
+	addStore := &cobra.Command{
+		Use:   "add-store <store-id> [<start_key> <end_key>]...",
 		Short: "add a store to evict leader list",
 		Run:   func(cmd *cobra.Command, args []string) { addStoreToSchedulerConfig(cmd, c.Name(), args) },
-	}, &cobra.Command{
+	}
+	addStore.Flags().Int("leader-percent", -1, "only keep at most the percent of regions on the store as leaders, by evicting leaders in the ranges")
+	c.AddCommand(addStore, &cobra.Command{
 		Use:   "delete-store <store-id>",
 		Short: "delete a store from evict leader list",
 		Run:   func(cmd *cobra.Command, args []string) { deleteStoreFromSchedulerConfig(cmd, c.Name(), args) },
+	}, &cobra.Command{
+		Use:   "progress",
+		Short: "show the leaders remaining versus the target of each store",
+		Run:   func(cmd *cobra.Command, args []string) { showSchedulerProgressCommandFunc(cmd, c.Name(), args) },
 	})
 	return c
 }
@@ -477,18 +685,33 @@ func newConfigGrantLeaderCommand() *cobra.Command {
 		Short: "grant-leader-scheduler config",
 		Run:   listSchedulerConfigCommandFunc,
 	}
-	c.AddCommand(&cobra.Command{
-		Use:   "add-store <store-id>",
+	addStore := &cobra.Command{
+		Use:   "add-store <store-id> [<start_key> <end_key>]...",
 		Short: "add a store to grant leader list",
 		Run:   func(cmd *cobra.Command, args []string) { addStoreToSchedulerConfig(cmd, c.Name(), args) },
-	}, &cobra.Command{
+	}
+	addStore.Flags().Int("leader-percent", -1, "only make at least the percent of regions on the store leaders, by granting leaders in the ranges")
+	c.AddCommand(addStore, &cobra.Command{
 		Use:   "delete-store <store-id>",
 		Short: "delete a store from grant leader list",
 		Run:   func(cmd *cobra.Command, args []string) { deleteStoreFromSchedulerConfig(cmd, c.Name(), args) },
+	}, &cobra.Command{
+		Use:   "progress",
+		Short: "show the leaders remaining versus the target of each store",
+		Run:   func(cmd *cobra.Command, args []string) { showSchedulerProgressCommandFunc(cmd, c.Name(), args) },
 	})
 	return c
 }
 
+func newConfigEvictSlowStoreCommand() *cobra.Command {
+	c := &cobra.Command{
+		Use:   "evict-slow-store-scheduler",
+		Short: "evict-slow-store-scheduler config",
+		Run:   listSchedulerConfigCommandFunc,
+	}
+	return c
+}
+
 func newConfigShuffleRegionCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "shuffle-region-scheduler",
@@ -508,7 +731,7 @@ func newConfigShuffleRegionCommand() *cobra.Command {
 }
 
 func addStoreToSchedulerConfig(cmd *cobra.Command, schedulerName string, args []string) {
-	if len(args) != 1 {
+	if len(args) < 1 || len(args)%2 != 1 {
 		cmd.Println(cmd.UsageString())
 		return
 	}
@@ -520,10 +743,24 @@ func addStoreToSchedulerConfig(cmd *cobra.Command, schedulerName string, args []
 	input := make(map[string]interface{})
 	input["name"] = schedulerName
 	input["store_id"] = storeID
+	setStoreLeaderOptions(cmd, input, args[1:])
 
 	postJSON(cmd, path.Join(schedulerConfigPrefix, schedulerName, "config"), input)
 }
 
+func showSchedulerProgressCommandFunc(cmd *cobra.Command, schedulerName string, args []string) {
+	if len(args) != 0 {
+		cmd.Println(cmd.UsageString())
+		return
+	}
+	r, err := doRequest(cmd, path.Join(schedulerConfigPrefix, schedulerName, "progress"), http.MethodGet)
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	cmd.Println(r)
+}
+
 func listSchedulerConfigCommandFunc(cmd *cobra.Command, args []string) {
 	if len(args) != 0 {
 		cmd.Println(cmd.UsageString())
@@ -554,6 +791,24 @@ func postSchedulerConfigCommandFunc(cmd *cobra.Command, schedulerName string, ar
 	if err != nil {
 		val = value
 	}
+	// The priorities are a list of dimensions separated by comma.
+	if strings.HasSuffix(key, "-priorities") {
+		val = strings.Split(value, ",")
+	}
+	// The ranges are pairs of start key and end key separated by comma, the
+	// keys are escaped as the ones of `scheduler add`.
+	if key == "ranges" {
+		keys := strings.Split(value, ",")
+		if len(keys)%2 != 0 {
+			cmd.Println("ranges should be pairs of start key and end key")
+			return
+		}
+		ranges := make([]string, 0, len(keys))
+		for _, key := range keys {
+			ranges = append(ranges, url.QueryEscape(key))
+		}
+		val = ranges
+	}
 	input[key] = val
 	postJSON(cmd, path.Join(schedulerConfigPrefix, schedulerName, "config"), input)
 }
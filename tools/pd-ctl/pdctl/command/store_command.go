@@ -91,7 +91,7 @@ func NewStoreLimitCommand() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "limit [<type>]|[<store_id>|<all> <limit> <type>]",
 		Short: "show or set a store's rate limit",
-		Long:  "show or set a store's rate limit, <type> can be 'region-add'(default) or 'region-remove'",
+		Long:  "show or set a store's rate limit, <type> can be 'region-add'(default), 'region-remove', 'region-add-bytes' or 'region-remove-bytes'. The rate of 'region-add' and 'region-remove' is the number of operators per minute, and the rate of 'region-add-bytes' and 'region-remove-bytes' is the snapshot size in MB/s",
 		Run:   storeLimitCommandFunc,
 	}
 	return c
@@ -27,6 +27,7 @@ func NewTSOCommand() *cobra.Command {
 		Short: "parse TSO to the system and logic time",
 		Run:   showTSOCommandFunc,
 	}
+	cmd.Flags().Int("suffix-bits", 0, "the bits of the suffix of the logical time, which is the tso-suffix-bits of the cluster")
 	return cmd
 }
 
@@ -41,7 +42,21 @@ func showTSOCommandFunc(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	suffixBits, err := cmd.Flags().GetInt("suffix-bits")
+	if err != nil {
+		cmd.Println(err)
+		return
+	}
+	if suffixBits < 0 || suffixBits > tsoutil.MaxSuffixBits {
+		cmd.Printf("suffix-bits should be in [0, %d]\n", tsoutil.MaxSuffixBits)
+		return
+	}
+
 	physicalTime, logical := tsoutil.ParseTS(ts)
 	cmd.Println("system: ", physicalTime)
 	cmd.Println("logic: ", logical)
+	if suffixBits > 0 {
+		_, suffix := tsoutil.ParseLogical(int64(logical), suffixBits)
+		cmd.Println("suffix: ", suffix)
+	}
 }